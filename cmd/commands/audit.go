@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"shikposh-backend/internal/account/service_layer/command_handler"
+	"shikposh-backend/internal/unit_of_work"
+
+	"github.com/ali-mahdavi-dev/framework/adapter"
+	"github.com/spf13/cobra"
+)
+
+func auditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "manage the security audit log",
+	}
+
+	purge := &cobra.Command{
+		Use:   "purge",
+		Short: "delete audit log entries older than audit.retention",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			initializeConfigs()
+			return purgeAuditEvents()
+		},
+	}
+
+	cmd.AddCommand(purge)
+
+	return cmd
+}
+
+func purgeAuditEvents() error {
+	db, err := initializeDatabase(&cfg)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(db)
+
+	eventCh := make(chan adapter.EventWithWaitGroup, 1)
+	handler := command_handler.NewAuditHandler(unitofwork.New(db, eventCh), &cfg)
+
+	purged, err := handler.PurgeAuditEvents(context.Background(), time.Now())
+	if err != nil {
+		return err
+	}
+
+	log.Printf("%d audit events purged", purged)
+
+	return nil
+}
@@ -17,6 +17,7 @@ import (
 
 	config "shikposh-backend/config"
 	"shikposh-backend/internal/account"
+	accountadapter "shikposh-backend/internal/account/adapter"
 	"shikposh-backend/internal/products"
 	mw "shikposh-backend/pkg/middleware"
 
@@ -46,6 +47,7 @@ type serverComponents struct {
 	server        *fiber.App
 	tracer        *tracing.Tracer
 	elasticsearch elasticsearchx.Connection
+	middleware    *mw.Middleware
 }
 
 func startServer(cfg *config.Config) error {
@@ -210,10 +212,16 @@ func setupServer(components *serverComponents, cfg *config.Config) error {
 }
 
 func setupMiddleware(components *serverComponents, cfg *config.Config) error {
+	accessTokens, err := accountadapter.NewAccessTokenKeys(cfg.JWT)
+	if err != nil {
+		return fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+
 	middleware := mw.NewMiddleware(
-		mw.MiddlewareConfig{JWTSecret: cfg.JWT.Secret},
+		mw.MiddlewareConfig{AccessTokens: accessTokens},
 		components.db,
 	)
+	components.middleware = middleware
 
 	// Register tracing middleware first (if enabled)
 	if components.tracer != nil && cfg.Jaeger.Enabled {
@@ -231,11 +239,11 @@ func setupRoutes(components *serverComponents, cfg *config.Config) error {
 	registerSwagger(components.server)
 
 	// Bootstrap application routes
-	if err := account.Bootstrap(components.server, components.db, cfg); err != nil {
+	if err := account.Bootstrap(components.server, components.db, cfg, components.middleware); err != nil {
 		return fmt.Errorf("failed to bootstrap account module: %w", err)
 	}
 
-	if err := products.Bootstrap(components.server, components.db, cfg, components.elasticsearch); err != nil {
+	if err := products.Bootstrap(components.server, components.db, cfg, components.elasticsearch, components.middleware); err != nil {
 		return fmt.Errorf("failed to bootstrap products module: %w", err)
 	}
 
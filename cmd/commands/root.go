@@ -28,6 +28,9 @@ func init() {
 
 	rootCmd.AddCommand(runHTTPServerCMD())
 	rootCmd.AddCommand(migrateCmd())
+	rootCmd.AddCommand(userCmd())
+	rootCmd.AddCommand(auditCmd())
+	rootCmd.AddCommand(searchCmd())
 }
 
 func Execute() {
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"shikposh-backend/internal/products"
+	"shikposh-backend/internal/products/adapter/search"
+	"shikposh-backend/internal/products/service_layer/command_handler"
+	"shikposh-backend/internal/unit_of_work"
+
+	"github.com/ali-mahdavi-dev/framework/adapter"
+	"github.com/spf13/cobra"
+)
+
+func searchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search",
+		Short: "manage the product search index",
+	}
+
+	var (
+		batchSize    int
+		keepPrevious bool
+	)
+	reindex := &cobra.Command{
+		Use:   "reindex",
+		Short: "build a fresh products index from the database and swap the products alias to it",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			initializeConfigs()
+			return reindexProducts(batchSize, keepPrevious)
+		},
+	}
+	reindex.Flags().IntVar(&batchSize, "batch-size", command_handler.DefaultReindexBatchSize, "products indexed per bulk request")
+	reindex.Flags().BoolVar(&keepPrevious, "keep-previous", false, "keep the replaced indices instead of deleting them")
+
+	cmd.AddCommand(reindex)
+
+	return cmd
+}
+
+func reindexProducts(batchSize int, keepPrevious bool) error {
+	db, err := initializeDatabase(&cfg)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(db)
+
+	eventCh := make(chan adapter.EventWithWaitGroup, 1)
+	indexAdmin := search.NewIndexAdmin(cfg.Elasticsearch, &http.Client{Timeout: products.SearchIndexAdminTimeout})
+	handler := command_handler.NewSearchIndexHandler(unitofwork.New(db, eventCh), indexAdmin)
+
+	result, err := handler.Reindex(context.Background(), time.Now(), batchSize, keepPrevious)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("%d products indexed into %s, which the %s alias now points at", result.Indexed, result.Index, search.ProductIndexAlias)
+	if result.CaughtUp > 0 {
+		log.Printf("%d changes made during the reindex applied to %s", result.CaughtUp, result.Index)
+	}
+	if len(result.Replaced) > 0 {
+		log.Printf("replaced %v", result.Replaced)
+	}
+
+	return nil
+}
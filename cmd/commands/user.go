@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	accountadapter "shikposh-backend/internal/account/adapter"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/service_layer/command_handler"
+	"shikposh-backend/internal/unit_of_work"
+
+	"github.com/ali-mahdavi-dev/framework/adapter"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+var ErrAssignRoleArgsRequired = errors.New("username and role are required")
+
+func userCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "user",
+		Short: "manage user accounts",
+	}
+
+	assignRole := &cobra.Command{
+		Use:   "assign-role [username] [role]",
+		Short: "assign a role (admin, catalog-manager, support) to a user",
+		RunE: func(_ *cobra.Command, args []string) error {
+			initializeConfigs()
+			if len(args) != 2 {
+				return ErrAssignRoleArgsRequired
+			}
+
+			return assignUserRole(args[0], args[1])
+		},
+	}
+
+	purgeDeleted := &cobra.Command{
+		Use:   "purge-deleted",
+		Short: "erase closed accounts whose deletion grace period has passed",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			initializeConfigs()
+			return purgeDeletedUsers()
+		},
+	}
+
+	cmd.AddCommand(assignRole)
+	cmd.AddCommand(purgeDeleted)
+
+	return cmd
+}
+
+func assignUserRole(username, role string) error {
+	db, err := initializeDatabase(&cfg)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(db)
+
+	handler, err := newUserHandler(db)
+	if err != nil {
+		return err
+	}
+
+	err = handler.AssignRoleHandler(context.Background(), &commands.AssignRole{
+		UserName: username,
+		Role:     role,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("role %s assigned to %s", role, username)
+
+	return nil
+}
+
+func purgeDeletedUsers() error {
+	db, err := initializeDatabase(&cfg)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(db)
+
+	handler, err := newUserHandler(db)
+	if err != nil {
+		return err
+	}
+
+	purged, err := handler.PurgeDeletedAccounts(context.Background(), time.Now())
+	if err != nil {
+		return err
+	}
+
+	log.Printf("%d deleted accounts purged", purged)
+
+	return nil
+}
+
+// newUserHandler builds a user handler for one-off commands, which neither
+// deliver events nor track login attempts across runs.
+func newUserHandler(db *gorm.DB) (*command_handler.UserHandler, error) {
+	accessTokens, err := accountadapter.NewAccessTokenKeys(cfg.JWT)
+	if err != nil {
+		return nil, err
+	}
+
+	eventCh := make(chan adapter.EventWithWaitGroup, 1)
+	return command_handler.NewUserHandler(unitofwork.New(db, eventCh), &cfg, accessTokens, accountadapter.NewMemoryLoginAttemptStore()), nil
+}
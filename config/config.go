@@ -19,8 +19,17 @@ type Config struct {
 	Cors          CorsConfig
 	Logger        LoggerConfig
 	Otp           OtpConfig
+	Sms           SmsConfig
+	Login         LoginConfig
+	Oidc          OidcConfig
 	JWT           JWTConfig
 	Jaeger        JaegerConfig
+	Mail          MailConfig
+	Verification  VerificationConfig
+	Account       AccountConfig
+	Storage       StorageConfig
+	Avatar        AvatarConfig
+	Audit         AuditConfig
 }
 
 type ServerConfig struct {
@@ -63,28 +72,145 @@ type RedisConfig struct {
 	PoolTimeout        time.Duration
 }
 
+// PasswordConfig holds the rules every new password must follow.
+// ResetTokenExpireDuration is expressed in minutes and ResetURL is the page
+// that receives the reset token as ?token=.
 type PasswordConfig struct {
-	IncludeChars     bool
-	IncludeDigits    bool
-	MinLength        int
-	MaxLength        int
-	IncludeUppercase bool
-	IncludeLowercase bool
+	IncludeChars             bool
+	IncludeDigits            bool
+	MinLength                int
+	MaxLength                int
+	IncludeUppercase         bool
+	IncludeLowercase         bool
+	ResetTokenExpireDuration time.Duration
+	ResetURL                 string
 }
 
 type CorsConfig struct {
 	AllowOrigins string
 }
 
+// OtpConfig durations are expressed in seconds. Limiter is the minimum time
+// between two codes sent to the same phone and Store is "redis" or "memory".
 type OtpConfig struct {
-	ExpireTime time.Duration
-	Digits     int
-	Limiter    time.Duration
+	ExpireTime  time.Duration
+	Digits      int
+	Limiter     time.Duration
+	MaxAttempts int
+	Store       string
 }
 
+// SmsConfig selects the SMS sender; only "log" is available.
+type SmsConfig struct {
+	Sender string
+}
+
+// LoginConfig durations are expressed in seconds. Failed password logins are
+// counted per username and per IP within FailureWindow. Once a key reached
+// FreeAttempts failures (IPFreeAttempts for an IP) every further failure
+// blocks it for a delay doubling from BackoffBase up to BackoffMax.
+// LockoutThreshold failures lock the account for LockoutDuration and mail a
+// link to UnlockURL. Store is "redis" or "memory".
+type LoginConfig struct {
+	FreeAttempts     int
+	IPFreeAttempts   int
+	FailureWindow    time.Duration
+	BackoffBase      time.Duration
+	BackoffMax       time.Duration
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	UnlockURL        string
+	Store            string
+}
+
+// OidcConfig durations are expressed in seconds. AuthorizationExpireTime is
+// how long a user has to come back from a provider once they were sent there.
+type OidcConfig struct {
+	AuthorizationExpireTime time.Duration
+	Providers               []OidcProviderConfig
+}
+
+// OidcProviderConfig is an OpenID Connect provider users can sign in with.
+// Its endpoints are discovered from Issuer. RedirectURL is the frontend page
+// the provider sends users back to and must be registered with the provider.
+// Scopes are requested in addition to openid.
+type OidcProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// JWTConfig durations are expressed in minutes. Access tokens are signed by
+// the key of Keys named SigningKeyID; the other keys only verify tokens
+// issued before a rotation. Secret signs HS256 tokens while no keys are
+// configured. Issuer and Audience become the iss and aud claims.
 type JWTConfig struct {
-	AccessTokenExpireDuration time.Duration
-	Secret                    string
+	AccessTokenExpireDuration  time.Duration
+	RefreshTokenExpireDuration time.Duration
+	Secret                     string
+	Issuer                     string
+	Audience                   string
+	SigningKeyID               string
+	Keys                       []JWTKeyConfig
+}
+
+// JWTKeyConfig is an RSA or Ed25519 key, given as inline PEM or as the path
+// of a PEM file. A key with only a public half can verify but not sign.
+type JWTKeyConfig struct {
+	ID             string
+	PrivateKey     string
+	PrivateKeyFile string
+	PublicKey      string
+	PublicKeyFile  string
+}
+
+// MailConfig selects the mail sender. Sender is "log" (the default) or
+// "file", which writes every mail into Dir.
+type MailConfig struct {
+	Sender string
+	Dir    string
+	From   string
+}
+
+// VerificationConfig durations are expressed in minutes. RequireVerifiedEmail
+// blocks login until the user verified their email address.
+type VerificationConfig struct {
+	RequireVerifiedEmail bool
+	TokenExpireDuration  time.Duration
+	ResendInterval       time.Duration
+	VerifyURL            string
+}
+
+// AccountConfig durations are expressed in hours. Deleted accounts are
+// erased DeletionGracePeriod after their deletion; PurgeInterval is how often
+// the server looks for accounts due for erasure, 0 disables it.
+type AccountConfig struct {
+	DeletionGracePeriod time.Duration
+	PurgeInterval       time.Duration
+}
+
+// AuditConfig durations are expressed in hours. Audit log entries are kept
+// for Retention; PurgeInterval is how often the server deletes older ones,
+// 0 disables it.
+type AuditConfig struct {
+	Retention     time.Duration
+	PurgeInterval time.Duration
+}
+
+// StorageConfig selects where uploaded files are kept. Driver is "local"
+// (the default), which writes them into Dir and serves them under BaseURL.
+type StorageConfig struct {
+	Driver  string
+	Dir     string
+	BaseURL string
+}
+
+// AvatarConfig limits avatar uploads. MaxUploadSize is expressed in kilobytes.
+type AvatarConfig struct {
+	MaxUploadSize int64
 }
 
 type JaegerConfig struct {
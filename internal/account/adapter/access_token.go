@@ -0,0 +1,322 @@
+package adapter
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"shikposh-backend/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidAccessToken = errors.New("invalid access token")
+
+// AccessTokenClaims are the claims carried by an access token. The user is
+// the standard sub claim. Every access token is bound to the session it was
+// issued for, so revoking the session invalidates it before it expires.
+type AccessTokenClaims struct {
+	UserID    uint64 `json:"-"`
+	SessionID uint64 `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// JSONWebKey is the public half of a signing key as published in the JWKS.
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC and Ed25519 keys; Y is only set for EC keys
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JSONWebKeySet is served at /.well-known/jwks.json.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+type signingKey struct {
+	id     string
+	method jwt.SigningMethod
+	// private is nil for keys that only verify tokens signed before a rotation.
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// AccessTokenKeys signs and verifies access tokens. Tokens are signed with
+// RS256 or EdDSA by the key named in jwt.signingKeyId and carry its ID in the
+// kid header; the other keys of the set keep verifying tokens they signed
+// before a rotation. Without configured keys tokens are HS256-signed with
+// jwt.secret, which suits development but cannot be verified elsewhere.
+type AccessTokenKeys struct {
+	signing  *signingKey
+	keys     map[string]*signingKey
+	secret   []byte
+	issuer   string
+	audience string
+}
+
+// NewAccessTokenKeys loads the key set of cfg. Each key is read from its
+// inline PEM or, failing that, from its PEM file.
+func NewAccessTokenKeys(cfg config.JWTConfig) (*AccessTokenKeys, error) {
+	k := &AccessTokenKeys{
+		keys:     make(map[string]*signingKey, len(cfg.Keys)),
+		secret:   []byte(cfg.Secret),
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+	}
+
+	for _, keyCfg := range cfg.Keys {
+		if keyCfg.ID == "" {
+			return nil, errors.New("NewAccessTokenKeys: every jwt key needs an id")
+		}
+		if _, ok := k.keys[keyCfg.ID]; ok {
+			return nil, fmt.Errorf("NewAccessTokenKeys: duplicate jwt key id %q", keyCfg.ID)
+		}
+
+		key, err := loadSigningKey(keyCfg)
+		if err != nil {
+			return nil, fmt.Errorf("NewAccessTokenKeys fail load jwt key %q: %w", keyCfg.ID, err)
+		}
+		k.keys[key.id] = key
+	}
+
+	if len(k.keys) == 0 {
+		if len(k.secret) == 0 {
+			return nil, errors.New("NewAccessTokenKeys: neither jwt keys nor a jwt secret are configured")
+		}
+		return k, nil
+	}
+
+	signing, ok := k.keys[cfg.SigningKeyID]
+	if !ok {
+		return nil, fmt.Errorf("NewAccessTokenKeys: signing key %q is not in the key set", cfg.SigningKeyID)
+	}
+	if signing.private == nil {
+		return nil, fmt.Errorf("NewAccessTokenKeys: signing key %q has no private key", cfg.SigningKeyID)
+	}
+	k.signing = signing
+
+	return k, nil
+}
+
+// Generate signs a short-lived access token for the given session.
+func (k *AccessTokenKeys) Generate(expiresAt time.Time, userID, sessionID uint64) (string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", fmt.Errorf("AccessTokenKeys.Generate fail generate token id: %w", err)
+	}
+
+	claims := AccessTokenClaims{
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    k.issuer,
+			Subject:   strconv.FormatUint(userID, 10),
+			ID:        jti,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	if k.audience != "" {
+		claims.Audience = jwt.ClaimStrings{k.audience}
+	}
+
+	if k.signing == nil {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(k.secret)
+		if err != nil {
+			return "", fmt.Errorf("AccessTokenKeys.Generate fail sign token: %w", err)
+		}
+		return token, nil
+	}
+
+	token := jwt.NewWithClaims(k.signing.method, claims)
+	token.Header["kid"] = k.signing.id
+	signed, err := token.SignedString(k.signing.private)
+	if err != nil {
+		return "", fmt.Errorf("AccessTokenKeys.Generate fail sign token: %w", err)
+	}
+
+	return signed, nil
+}
+
+// Parse verifies the signature, expiry, issuer and audience of an access
+// token and returns its claims. Once keys are configured, HS256 tokens are
+// rejected.
+func (k *AccessTokenKeys) Parse(tokenStr string) (*AccessTokenClaims, error) {
+	options := []jwt.ParserOption{jwt.WithExpirationRequired()}
+	if k.issuer != "" {
+		options = append(options, jwt.WithIssuer(k.issuer))
+	}
+	if k.audience != "" {
+		options = append(options, jwt.WithAudience(k.audience))
+	}
+
+	claims := new(AccessTokenClaims)
+	token, err := jwt.ParseWithClaims(tokenStr, claims, k.verificationKey, options...)
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidAccessToken
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil || userID == 0 || claims.SessionID == 0 {
+		return nil, ErrInvalidAccessToken
+	}
+	claims.UserID = userID
+
+	return claims, nil
+}
+
+// JWKS returns the public keys of the set, so other services can verify
+// access tokens on their own. The HS256 secret is never published.
+func (k *AccessTokenKeys) JWKS() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(k.keys))}
+	for _, key := range k.keys {
+		jwk := JSONWebKey{Kid: key.id, Use: "sig", Alg: key.method.Alg()}
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	slices.SortFunc(set.Keys, func(a, b JSONWebKey) int {
+		return strings.Compare(a.Kid, b.Kid)
+	})
+
+	return set
+}
+
+func (k *AccessTokenKeys) verificationKey(token *jwt.Token) (interface{}, error) {
+	if len(k.keys) == 0 {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return k.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.keys[kid]
+	if !ok || token.Method.Alg() != key.method.Alg() {
+		return nil, jwt.ErrSignatureInvalid
+	}
+
+	return key.public, nil
+}
+
+// loadSigningKey reads a private key, which can sign, or a public key, which
+// only verifies. The algorithm follows from the key type.
+func loadSigningKey(cfg config.JWTKeyConfig) (*signingKey, error) {
+	privatePEM, err := readPEM(cfg.PrivateKey, cfg.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if privatePEM != nil {
+		private, err := parsePrivateKey(privatePEM)
+		if err != nil {
+			return nil, err
+		}
+		return newSigningKey(cfg.ID, private, private.Public())
+	}
+
+	publicPEM, err := readPEM(cfg.PublicKey, cfg.PublicKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if publicPEM == nil {
+		return nil, errors.New("no private or public key configured")
+	}
+
+	public, err := x509.ParsePKIXPublicKey(publicPEM.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key: %w", err)
+	}
+	return newSigningKey(cfg.ID, nil, public)
+}
+
+func newSigningKey(id string, private crypto.Signer, public crypto.PublicKey) (*signingKey, error) {
+	key := &signingKey{id: id, private: private, public: public}
+	switch public.(type) {
+	case *rsa.PublicKey:
+		key.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use an RSA or Ed25519 key", public)
+	}
+
+	return key, nil
+}
+
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+
+	return signer, nil
+}
+
+// readPEM decodes the inline PEM or, when it is empty, the PEM file. It
+// returns nil when neither is set.
+func readPEM(inline, file string) (*pem.Block, error) {
+	data := []byte(inline)
+	if inline == "" {
+		if file == "" {
+			return nil, nil
+		}
+
+		var err error
+		data, err = os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	return block, nil
+}
+
+func newTokenID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,53 @@
+package adapter
+
+import (
+	"container/list"
+	"sync"
+)
+
+// avatarCache keeps the most recently served avatars by ETag, so popular
+// avatars are not decoded, composed and encoded on every request.
+type avatarCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+func newAvatarCache(capacity int) *avatarCache {
+	return &avatarCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+func (c *avatarCache) Get(etag string) (*RenderedAvatar, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[etag]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*RenderedAvatar), true
+}
+
+func (c *avatarCache) Add(avatar *RenderedAvatar) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[avatar.ETag]; ok {
+		element.Value = avatar
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[avatar.ETag] = c.order.PushFront(avatar)
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*RenderedAvatar).ETag)
+	}
+}
@@ -8,27 +8,112 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
 	"io/fs"
 	"math/rand"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/HugoSmits86/nativewebp"
 	"github.com/disintegration/imaging"
 
 	"github.com/ali-mahdavi-dev/framework/infrastructure/logging"
 )
 
+const (
+	// DefaultAvatarSize is the size of the avatar layers.
+	DefaultAvatarSize = 256
+	MinAvatarSize     = 16
+	MaxAvatarSize     = 512
+
+	avatarCacheSize   = 256
+	avatarJPEGQuality = 90
+)
+
+var (
+	ErrInvalidAvatarSize       = fmt.Errorf("avatar size must be between %d and %d", MinAvatarSize, MaxAvatarSize)
+	ErrUnsupportedAvatarFormat = errors.New("avatar format must be png, webp or jpeg")
+	ErrInvalidAvatarBackground = errors.New("avatar background must be a hex colour like ffcc00")
+)
+
+type AvatarFormat string
+
+const (
+	AvatarFormatPNG  AvatarFormat = "png"
+	AvatarFormatWebP AvatarFormat = "webp"
+	AvatarFormatJPEG AvatarFormat = "jpeg"
+)
+
+func (f AvatarFormat) ContentType() string {
+	return "image/" + string(f)
+}
+
+// AvatarOptions says how an avatar is rendered. Without a background the
+// avatar is transparent, except as JPEG, which is drawn on white.
+type AvatarOptions struct {
+	Size       int
+	Format     AvatarFormat
+	Background *color.NRGBA
+}
+
+// ParseAvatarOptions reads the options of the avatar query parameters. Empty
+// parameters take their defaults.
+func ParseAvatarOptions(size, format, background string) (AvatarOptions, error) {
+	opts := AvatarOptions{Size: DefaultAvatarSize, Format: AvatarFormatPNG}
+
+	if size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil || n < MinAvatarSize || n > MaxAvatarSize {
+			return opts, ErrInvalidAvatarSize
+		}
+		opts.Size = n
+	}
+
+	switch strings.ToLower(format) {
+	case "", "png":
+	case "webp":
+		opts.Format = AvatarFormatWebP
+	case "jpeg", "jpg":
+		opts.Format = AvatarFormatJPEG
+	default:
+		return opts, ErrUnsupportedAvatarFormat
+	}
+
+	if background != "" {
+		hexColour := strings.TrimPrefix(background, "#")
+		rgb, err := hex.DecodeString(hexColour)
+		if err != nil || len(rgb) != 3 {
+			return opts, ErrInvalidAvatarBackground
+		}
+		opts.Background = &color.NRGBA{R: rgb[0], G: rgb[1], B: rgb[2], A: 0xff}
+	}
+
+	return opts, nil
+}
+
+// RenderedAvatar is an encoded avatar ready to be served.
+type RenderedAvatar struct {
+	Body        []byte
+	ContentType string
+	ETag        string
+}
+
 type AvatarGenerator struct {
 	bodies      []string
 	accessories []string
 	glasses     []string
 	hats        []string
 	imagesFS    embed.FS
+	cache       *avatarCache
 }
 
 func NewAvatarGenerator(imagesFS embed.FS) (*AvatarGenerator, error) {
 	var err error
-	g := &AvatarGenerator{imagesFS: imagesFS}
+	g := &AvatarGenerator{imagesFS: imagesFS, cache: newAvatarCache(avatarCacheSize)}
 
 	if g.bodies, err = loadLayerPaths(imagesFS, "assets/images/bodies/*"); err != nil {
 		logging.Error("Failed to load body images").
@@ -129,6 +214,65 @@ func (g *AvatarGenerator) Generate(identifier string) (image.Image, error) {
 	return avatar, nil
 }
 
+// ETag identifies the avatar Render returns for the identifier and options.
+// Avatars are deterministic, so it is known without rendering anything.
+func (g *AvatarGenerator) ETag(identifier string, opts AvatarOptions) string {
+	background := "none"
+	if opts.Background != nil {
+		background = fmt.Sprintf("%02x%02x%02x", opts.Background.R, opts.Background.G, opts.Background.B)
+	}
+	return fmt.Sprintf(`"%016x-%d-%s-%s"`, uint64(seedFromHash(identifier)), opts.Size, opts.Format, background)
+}
+
+// Render returns the encoded avatar of the identifier, from the cache when it
+// was rendered recently.
+func (g *AvatarGenerator) Render(identifier string, opts AvatarOptions) (*RenderedAvatar, error) {
+	etag := g.ETag(identifier, opts)
+	if avatar, ok := g.cache.Get(etag); ok {
+		return avatar, nil
+	}
+
+	img, err := g.Generate(identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Size != img.Bounds().Dx() {
+		img = imaging.Resize(img, opts.Size, opts.Size, imaging.Lanczos)
+	}
+
+	background := opts.Background
+	if background == nil && opts.Format == AvatarFormatJPEG {
+		background = &color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	}
+	if background != nil {
+		img = imaging.Overlay(imaging.New(opts.Size, opts.Size, background), img, image.Point{0, 0}, 1.0)
+	}
+
+	var body bytes.Buffer
+	switch opts.Format {
+	case AvatarFormatWebP:
+		err = nativewebp.Encode(&body, img, nil)
+	case AvatarFormatJPEG:
+		err = jpeg.Encode(&body, img, &jpeg.Options{Quality: avatarJPEGQuality})
+	default:
+		err = png.Encode(&body, img)
+	}
+	if err != nil {
+		logging.Error("Failed to encode avatar").
+			WithString("identifier", identifier).
+			WithString("format", string(opts.Format)).
+			WithError(err).
+			Log()
+		return nil, fmt.Errorf("failed to encode avatar as %s: %w", opts.Format, err)
+	}
+
+	avatar := &RenderedAvatar{Body: body.Bytes(), ContentType: opts.Format.ContentType(), ETag: etag}
+	g.cache.Add(avatar)
+
+	return avatar, nil
+}
+
 func loadLayerPaths(folder embed.FS, path string) ([]string, error) {
 	files, err := fs.Glob(folder, path)
 	if err != nil {
@@ -0,0 +1,68 @@
+package adapter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"github.com/disintegration/imaging"
+	"golang.org/x/image/webp"
+)
+
+// maxAvatarUploadDimension bounds the width and height of uploaded images, so
+// a small file cannot decode into a huge bitmap.
+const maxAvatarUploadDimension = 4096
+
+var (
+	ErrUnsupportedAvatarUpload = errors.New("avatar must be a PNG, JPEG, GIF or WebP image")
+	ErrAvatarUploadDimensions  = fmt.Errorf("avatar must be at most %dx%d pixels", maxAvatarUploadDimension, maxAvatarUploadDimension)
+)
+
+// avatarUploadDecoders are the accepted image types by their sniffed MIME type.
+var avatarUploadDecoders = map[string]struct {
+	decode       func(io.Reader) (image.Image, error)
+	decodeConfig func(io.Reader) (image.Config, error)
+}{
+	"image/png":  {png.Decode, png.DecodeConfig},
+	"image/jpeg": {jpeg.Decode, jpeg.DecodeConfig},
+	"image/gif":  {gif.Decode, gif.DecodeConfig},
+	"image/webp": {webp.Decode, webp.DecodeConfig},
+}
+
+// ProcessAvatarUpload crops an uploaded image to its centred square, scales
+// it to DefaultAvatarSize and returns it as PNG. The type is sniffed from the
+// content; the file name and the declared content type are not trusted.
+func ProcessAvatarUpload(content []byte) ([]byte, error) {
+	decoder, ok := avatarUploadDecoders[http.DetectContentType(content)]
+	if !ok {
+		return nil, ErrUnsupportedAvatarUpload
+	}
+
+	cfg, err := decoder.decodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, ErrUnsupportedAvatarUpload
+	}
+	if cfg.Width > maxAvatarUploadDimension || cfg.Height > maxAvatarUploadDimension {
+		return nil, ErrAvatarUploadDimensions
+	}
+
+	img, err := decoder.decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, ErrUnsupportedAvatarUpload
+	}
+
+	avatar := imaging.Fill(img, DefaultAvatarSize, DefaultAvatarSize, imaging.Center, imaging.Lanczos)
+
+	var out bytes.Buffer
+	if err := png.Encode(&out, avatar); err != nil {
+		return nil, fmt.Errorf("ProcessAvatarUpload fail encode avatar: %w", err)
+	}
+
+	return out.Bytes(), nil
+}
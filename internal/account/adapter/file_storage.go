@@ -0,0 +1,101 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"shikposh-backend/config"
+)
+
+// defaultStorageBaseURL is used when storage.baseURL is not configured.
+const defaultStorageBaseURL = "/media"
+
+// FileStorage keeps uploaded files such as avatars. Keys are slash-separated
+// paths like avatars/42-abc.png.
+type FileStorage interface {
+	Save(ctx context.Context, key string, content []byte) error
+	// Delete removes the file of key. Deleting a missing file is not an error.
+	Delete(ctx context.Context, key string) error
+	// URL is where clients download the file of key.
+	URL(key string) string
+}
+
+// NewFileStorage returns the storage selected by storage.driver.
+func NewFileStorage(cfg config.StorageConfig) (FileStorage, error) {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultStorageBaseURL
+	}
+
+	switch cfg.Driver {
+	case "", "local":
+		if cfg.Dir == "" {
+			return nil, errors.New("NewFileStorage storage.dir is required by the local storage")
+		}
+		if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+			return nil, fmt.Errorf("NewFileStorage fail create storage directory: %w", err)
+		}
+		return &localFileStorage{dir: cfg.Dir, baseURL: baseURL}, nil
+	default:
+		return nil, fmt.Errorf("NewFileStorage unknown storage driver %q", cfg.Driver)
+	}
+}
+
+// localFileStorage writes files below its directory, which the server serves
+// under baseURL.
+type localFileStorage struct {
+	dir     string
+	baseURL string
+}
+
+func (s *localFileStorage) Save(ctx context.Context, key string, content []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("localFileStorage.Save fail create directory: %w", err)
+	}
+
+	// Write next to the target and rename, so a half-written file is never served.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return fmt.Errorf("localFileStorage.Save fail write file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("localFileStorage.Save fail move file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *localFileStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("localFileStorage.Delete fail remove file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *localFileStorage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// path maps key into the storage directory, refusing keys that would escape it.
+func (s *localFileStorage) path(key string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", fmt.Errorf("localFileStorage invalid key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
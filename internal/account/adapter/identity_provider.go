@@ -0,0 +1,379 @@
+package adapter
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"shikposh-backend/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownIdentityProvider = errors.New("unknown identity provider")
+	// ErrIdentityProviderRejected is returned when the provider refuses to
+	// redeem an authorization code, e.g. because it was already used.
+	ErrIdentityProviderRejected = errors.New("identity provider rejected the authorization code")
+	ErrInvalidIdentityToken     = errors.New("invalid identity token")
+)
+
+// ExternalIdentity is what an identity provider asserts about the user who
+// signed in. Subject identifies the account at the provider and never changes.
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	GivenName     string
+	FamilyName    string
+}
+
+// IdentityProvider signs users in with an account they have elsewhere.
+type IdentityProvider interface {
+	Name() string
+	// AuthCodeURL returns the URL that sends the user to the provider. The
+	// provider sends them back to its redirect URL with a code and state.
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	// Exchange redeems the code and returns the identity of the user. It
+	// fails unless the ID token is valid and carries nonce.
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*ExternalIdentity, error)
+}
+
+// IdentityProviders holds the configured providers by name.
+type IdentityProviders map[string]IdentityProvider
+
+// NewIdentityProviders creates an OpenID Connect provider for each entry of
+// oidc.providers. Endpoints are discovered on first use, so an unreachable
+// provider does not keep the server from starting.
+func NewIdentityProviders(cfg config.OidcConfig, client *http.Client) (IdentityProviders, error) {
+	providers := make(IdentityProviders, len(cfg.Providers))
+	for _, providerCfg := range cfg.Providers {
+		if providerCfg.Name == "" || providerCfg.Issuer == "" || providerCfg.ClientID == "" || providerCfg.RedirectURL == "" {
+			return nil, fmt.Errorf("NewIdentityProviders: provider %q needs a name, issuer, clientId and redirectUrl", providerCfg.Name)
+		}
+		if _, ok := providers[providerCfg.Name]; ok {
+			return nil, fmt.Errorf("NewIdentityProviders: duplicate provider %q", providerCfg.Name)
+		}
+		providers[providerCfg.Name] = NewOidcProvider(providerCfg, client)
+	}
+
+	return providers, nil
+}
+
+func (p IdentityProviders) Get(name string) (IdentityProvider, error) {
+	provider, ok := p[name]
+	if !ok {
+		return nil, ErrUnknownIdentityProvider
+	}
+
+	return provider, nil
+}
+
+// oidcDiscovery is the part of the provider metadata served at
+// /.well-known/openid-configuration that the authorization code flow needs.
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+type idTokenClaims struct {
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	jwt.RegisteredClaims
+}
+
+// oidcProvider implements the authorization code flow with PKCE against a
+// generic OpenID Connect provider.
+type oidcProvider struct {
+	cfg    config.OidcProviderConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]crypto.PublicKey
+}
+
+func NewOidcProvider(cfg config.OidcProviderConfig, client *http.Client) IdentityProvider {
+	return &oidcProvider{cfg: cfg, client: client}
+}
+
+func (p *oidcProvider) Name() string {
+	return p.cfg.Name
+}
+
+func (p *oidcProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("oidcProvider.AuthCodeURL fail parse authorization endpoint: %w", err)
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientID)
+	query.Set("redirect_uri", p.cfg.RedirectURL)
+	query.Set("scope", strings.Join(append([]string{"openid"}, p.cfg.Scopes...), " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+func (p *oidcProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*ExternalIdentity, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("oidcProvider.Exchange fail build token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidcProvider.Exchange fail call token endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("oidcProvider.Exchange fail decode token response: %w", err)
+	}
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("%w: %s %s", ErrIdentityProviderRejected, body.Error, body.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidcProvider.Exchange: token endpoint returned %d", resp.StatusCode)
+	}
+
+	claims, err := p.verifyIDToken(ctx, body.IDToken)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIdentityToken)
+	}
+
+	return &ExternalIdentity{
+		Provider:      p.cfg.Name,
+		Subject:       claims.Subject,
+		Email:         strings.ToLower(strings.TrimSpace(claims.Email)),
+		EmailVerified: claims.EmailVerified,
+		GivenName:     claims.GivenName,
+		FamilyName:    claims.FamilyName,
+	}, nil
+}
+
+// verifyIDToken checks the signature against the provider's published keys
+// and that the token was issued by the provider for this client.
+func (p *oidcProvider) verifyIDToken(ctx context.Context, idToken string) (*idTokenClaims, error) {
+	claims := new(idTokenClaims)
+	_, err := jwt.ParseWithClaims(idToken, claims,
+		func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			return p.publicKey(ctx, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "EdDSA"}),
+		jwt.WithIssuer(p.cfg.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIdentityToken, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: no subject", ErrInvalidIdentityToken)
+	}
+
+	return claims, nil
+}
+
+func (p *oidcProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	discovery := new(oidcDiscovery)
+	wellKnown := strings.TrimSuffix(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, wellKnown, discovery); err != nil {
+		return nil, fmt.Errorf("oidcProvider.discover fail get provider metadata: %w", err)
+	}
+	if discovery.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("oidcProvider.discover: provider claims issuer %q, configured %q", discovery.Issuer, p.cfg.Issuer)
+	}
+
+	p.discovery = discovery
+	return discovery, nil
+}
+
+// publicKey returns the key the provider signs with under kid. The key set is
+// fetched again when kid is unknown, as happens after the provider rotated.
+func (p *oidcProvider) publicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+
+	var set JSONWebKeySet
+	if err := p.getJSON(ctx, discovery.JwksURI, &set); err != nil {
+		return nil, fmt.Errorf("oidcProvider.publicKey fail get key set: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	p.keys = keys
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("no signing key %q", kid)
+}
+
+// lookupKey finds kid in the cached key set. Tokens without a kid can only
+// be matched while the provider publishes a single key.
+func (p *oidcProvider) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *oidcProvider) getJSON(ctx context.Context, endpoint string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %d", endpoint, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// PublicKey decodes an RSA, EC or Ed25519 key of a key set.
+func (k JSONWebKey) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("decode modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("decode exponent: %w", err)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("decode x: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("decode y: %w", err)
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) > size || len(y) > size {
+			return nil, errors.New("coordinate too long")
+		}
+		point := make([]byte, 1+2*size)
+		point[0] = 4
+		copy(point[1+size-len(x):1+size], x)
+		copy(point[1+2*size-len(y):], y)
+		return ecdsa.ParseUncompressedPublicKey(curve, point)
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
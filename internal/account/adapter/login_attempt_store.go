@@ -0,0 +1,224 @@
+package adapter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ali-mahdavi-dev/framework/infrastructure/logging"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LoginAttemptStore counts failed logins per key, e.g. a username or an IP
+// address, and holds the temporary blocks derived from those counts.
+type LoginAttemptStore interface {
+	// RegisterFailure records a failed login and returns the failures of key
+	// within window, counted from the first failure.
+	RegisterFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	// Block rejects logins for key during d.
+	Block(ctx context.Context, key string, d time.Duration) error
+	// BlockedFor returns how long logins for key are still rejected.
+	BlockedFor(ctx context.Context, key string) (time.Duration, error)
+	// Reset forgets the failures and the block of key.
+	Reset(ctx context.Context, key string) error
+}
+
+// LoginBackoff returns how long to block a key after its failures-th failed
+// login. The first free failures are not delayed; every failure after them
+// doubles the delay, starting at base and capped at max.
+func LoginBackoff(failures, free int, base, max time.Duration) time.Duration {
+	if failures <= free || base <= 0 {
+		return 0
+	}
+
+	delay := base
+	for range failures - free - 1 {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+
+	return min(delay, max)
+}
+
+type redisLoginAttemptStore struct {
+	client *redis.Client
+}
+
+func NewRedisLoginAttemptStore(client *redis.Client) LoginAttemptStore {
+	return &redisLoginAttemptStore{client: client}
+}
+
+// registerFailureScript starts the window with the first failure, so later
+// failures do not extend it.
+var registerFailureScript = redis.NewScript(`
+local failures = redis.call("INCR", KEYS[1])
+if failures == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return failures
+`)
+
+func loginFailuresKey(key string) string { return "login:failures:" + key }
+func loginBlockKey(key string) string    { return "login:block:" + key }
+
+func (s *redisLoginAttemptStore) RegisterFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	return registerFailureScript.Run(ctx, s.client, []string{loginFailuresKey(key)}, window.Milliseconds()).Int()
+}
+
+func (s *redisLoginAttemptStore) Block(ctx context.Context, key string, d time.Duration) error {
+	return s.client.Set(ctx, loginBlockKey(key), 1, d).Err()
+}
+
+func (s *redisLoginAttemptStore) BlockedFor(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := s.client.PTTL(ctx, loginBlockKey(key)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// PTTL is negative when the key does not exist or has no expiry
+	return max(ttl, 0), nil
+}
+
+func (s *redisLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	return s.client.Del(ctx, loginFailuresKey(key), loginBlockKey(key)).Err()
+}
+
+type memoryLoginFailures struct {
+	count     int
+	expiresAt time.Time
+}
+
+// memoryLoginAttemptStore keeps the counters in the process. It is meant for
+// tests and single-instance deployments.
+type memoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	failures map[string]*memoryLoginFailures
+	blocks   map[string]time.Time
+}
+
+func NewMemoryLoginAttemptStore() LoginAttemptStore {
+	return &memoryLoginAttemptStore{
+		failures: make(map[string]*memoryLoginFailures),
+		blocks:   make(map[string]time.Time),
+	}
+}
+
+func (s *memoryLoginAttemptStore) RegisterFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	failures, ok := s.failures[key]
+	if !ok || !now.Before(failures.expiresAt) {
+		failures = &memoryLoginFailures{expiresAt: now.Add(window)}
+		s.failures[key] = failures
+	}
+
+	failures.count++
+	return failures.count, nil
+}
+
+func (s *memoryLoginAttemptStore) Block(ctx context.Context, key string, d time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blocks[key] = time.Now().Add(d)
+	return nil
+}
+
+func (s *memoryLoginAttemptStore) BlockedFor(ctx context.Context, key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	until, ok := s.blocks[key]
+	if !ok {
+		return 0, nil
+	}
+
+	remaining := time.Until(until)
+	if remaining <= 0 {
+		delete(s.blocks, key)
+		return 0, nil
+	}
+
+	return remaining, nil
+}
+
+func (s *memoryLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.failures, key)
+	delete(s.blocks, key)
+	return nil
+}
+
+// fallbackLoginAttemptStore keeps login protection running while the primary
+// store is unreachable, at the cost of counting per instance meanwhile.
+type fallbackLoginAttemptStore struct {
+	primary  LoginAttemptStore
+	fallback LoginAttemptStore
+}
+
+// NewFallbackLoginAttemptStore returns a store that uses fallback whenever a
+// call to primary fails.
+func NewFallbackLoginAttemptStore(primary, fallback LoginAttemptStore) LoginAttemptStore {
+	return &fallbackLoginAttemptStore{primary: primary, fallback: fallback}
+}
+
+func (s *fallbackLoginAttemptStore) RegisterFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	failures, err := s.primary.RegisterFailure(ctx, key, window)
+	if err != nil {
+		logPrimaryLoginStoreFailure(err)
+		return s.fallback.RegisterFailure(ctx, key, window)
+	}
+
+	return failures, nil
+}
+
+func (s *fallbackLoginAttemptStore) Block(ctx context.Context, key string, d time.Duration) error {
+	if err := s.primary.Block(ctx, key, d); err != nil {
+		logPrimaryLoginStoreFailure(err)
+		return s.fallback.Block(ctx, key, d)
+	}
+
+	return nil
+}
+
+// BlockedFor honours blocks from both stores, so a block written to the
+// fallback during an outage survives the primary coming back.
+func (s *fallbackLoginAttemptStore) BlockedFor(ctx context.Context, key string) (time.Duration, error) {
+	fallbackBlock, err := s.fallback.BlockedFor(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	primaryBlock, err := s.primary.BlockedFor(ctx, key)
+	if err != nil {
+		logPrimaryLoginStoreFailure(err)
+		return fallbackBlock, nil
+	}
+
+	return max(primaryBlock, fallbackBlock), nil
+}
+
+func (s *fallbackLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	if err := s.fallback.Reset(ctx, key); err != nil {
+		return err
+	}
+
+	if err := s.primary.Reset(ctx, key); err != nil {
+		logPrimaryLoginStoreFailure(err)
+	}
+
+	return nil
+}
+
+func logPrimaryLoginStoreFailure(err error) {
+	logging.Warn("Login attempt store unavailable, using in-memory fallback").
+		WithError(err).
+		Log()
+}
@@ -0,0 +1,71 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"shikposh-backend/config"
+	"github.com/ali-mahdavi-dev/framework/infrastructure/logging"
+)
+
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// MailSender delivers transactional mails such as verification links.
+type MailSender interface {
+	Send(ctx context.Context, mail Mail) error
+}
+
+// NewMailSender returns the sender selected by mail.sender. The log and file
+// senders are meant for local development.
+func NewMailSender(cfg config.MailConfig) (MailSender, error) {
+	switch cfg.Sender {
+	case "", "log":
+		return &logMailSender{from: cfg.From}, nil
+	case "file":
+		if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+			return nil, fmt.Errorf("NewMailSender fail create mail directory: %w", err)
+		}
+		return &fileMailSender{dir: cfg.Dir, from: cfg.From}, nil
+	default:
+		return nil, fmt.Errorf("NewMailSender unknown mail sender %q", cfg.Sender)
+	}
+}
+
+type logMailSender struct {
+	from string
+}
+
+func (s *logMailSender) Send(ctx context.Context, mail Mail) error {
+	logging.Info("Mail sent").
+		WithString("from", s.from).
+		WithString("to", mail.To).
+		WithString("subject", mail.Subject).
+		WithString("body", mail.Body).
+		Log()
+	return nil
+}
+
+// fileMailSender writes every mail as an .eml file into its directory.
+type fileMailSender struct {
+	dir  string
+	from string
+}
+
+func (s *fileMailSender) Send(ctx context.Context, mail Mail) error {
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), strings.NewReplacer("@", "_at_", "/", "_").Replace(mail.To))
+	content := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n", s.from, mail.To, mail.Subject, mail.Body)
+
+	if err := os.WriteFile(filepath.Join(s.dir, name), []byte(content), 0o644); err != nil {
+		return fmt.Errorf("fileMailSender.Send fail write mail: %w", err)
+	}
+
+	return nil
+}
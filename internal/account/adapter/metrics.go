@@ -0,0 +1,30 @@
+package adapter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Login failure reasons reported by LoginFailures.
+const (
+	LoginFailureInvalidCredentials = "invalid_credentials"
+	LoginFailureThrottled          = "throttled"
+	LoginFailureLocked             = "locked"
+)
+
+// Account metrics, exported through the /metrics endpoint.
+var (
+	LoginFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "shikposh",
+		Subsystem: "account",
+		Name:      "login_failures_total",
+		Help:      "Rejected password logins by reason.",
+	}, []string{"reason"})
+
+	AccountLockouts = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "shikposh",
+		Subsystem: "account",
+		Name:      "lockouts_total",
+		Help:      "Accounts locked after too many failed logins.",
+	})
+)
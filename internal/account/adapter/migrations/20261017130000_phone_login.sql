@@ -0,0 +1,11 @@
+-- migrate:up
+-- Users who sign in with a one-time code have no email address
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX idx_users_email ON users(email) WHERE email <> '' AND deleted_at IS NULL;
+
+CREATE UNIQUE INDEX idx_profiles_phone ON profiles(phone) WHERE phone IS NOT NULL AND phone <> '' AND deleted_at IS NULL;
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_profiles_phone;
+DROP INDEX IF EXISTS idx_users_email;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
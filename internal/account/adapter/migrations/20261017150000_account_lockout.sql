@@ -0,0 +1,23 @@
+-- migrate:up
+ALTER TABLE users ADD COLUMN locked_until TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE account_unlock_tokens (
+    id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    user_id BIGINT NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT fk_account_unlock_tokens_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_account_unlock_tokens_deleted_at ON account_unlock_tokens(deleted_at);
+CREATE INDEX idx_account_unlock_tokens_user_id ON account_unlock_tokens(user_id);
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_account_unlock_tokens_user_id;
+DROP INDEX IF EXISTS idx_account_unlock_tokens_deleted_at;
+DROP TABLE IF EXISTS account_unlock_tokens;
+ALTER TABLE users DROP COLUMN IF EXISTS locked_until;
@@ -0,0 +1,6 @@
+-- migrate:up
+-- Phone numbers are stored in E.164 form
+UPDATE profiles SET phone = '+98' || substring(phone from 2) WHERE phone ~ '^09[0-9]{9}$';
+
+-- migrate:down
+UPDATE profiles SET phone = '0' || substring(phone from 4) WHERE phone ~ '^\+989[0-9]{9}$';
@@ -0,0 +1,46 @@
+-- migrate:up
+CREATE TABLE addresses (
+    id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    user_id BIGINT NOT NULL,
+    recipient_name VARCHAR(255) NOT NULL DEFAULT '',
+    phone VARCHAR(32) NOT NULL DEFAULT '',
+    province VARCHAR(255) NOT NULL DEFAULT '',
+    city VARCHAR(255) NOT NULL DEFAULT '',
+    postal_code VARCHAR(10) NOT NULL DEFAULT '',
+    street TEXT NOT NULL DEFAULT '',
+    plate VARCHAR(32) NOT NULL DEFAULT '',
+    unit VARCHAR(32) NOT NULL DEFAULT '',
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT fk_addresses_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_addresses_deleted_at ON addresses(deleted_at);
+CREATE INDEX idx_addresses_user_id ON addresses(user_id);
+CREATE UNIQUE INDEX idx_addresses_default ON addresses(user_id) WHERE is_default AND deleted_at IS NULL;
+
+-- The free-text profile address becomes the default address of its user
+INSERT INTO addresses (user_id, recipient_name, phone, street, is_default)
+SELECT p.user_id, TRIM(CONCAT(u.first_name, ' ', u.last_name)), COALESCE(p.phone, ''), TRIM(p.address), TRUE
+FROM profiles p
+JOIN users u ON u.id = p.user_id
+WHERE p.address IS NOT NULL AND TRIM(p.address) <> '' AND p.deleted_at IS NULL;
+
+ALTER TABLE profiles DROP COLUMN address;
+
+-- migrate:down
+ALTER TABLE profiles ADD COLUMN address TEXT;
+
+UPDATE profiles p
+SET address = CONCAT_WS(', ', NULLIF(a.province, ''), NULLIF(a.city, ''), NULLIF(a.street, ''), NULLIF(a.plate, ''), NULLIF(a.unit, ''), NULLIF(a.postal_code, ''))
+FROM addresses a
+WHERE a.user_id = p.user_id AND a.is_default AND a.deleted_at IS NULL;
+
+DROP INDEX IF EXISTS idx_addresses_default;
+DROP INDEX IF EXISTS idx_addresses_user_id;
+DROP INDEX IF EXISTS idx_addresses_deleted_at;
+DROP TABLE IF EXISTS addresses;
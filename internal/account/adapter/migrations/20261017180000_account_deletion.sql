@@ -0,0 +1,8 @@
+-- migrate:up
+ALTER TABLE users ADD COLUMN purge_after TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_users_purge_after ON users(purge_after) WHERE purge_after IS NOT NULL;
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_users_purge_after;
+ALTER TABLE users DROP COLUMN IF EXISTS purge_after;
@@ -0,0 +1,5 @@
+-- migrate:up
+ALTER TABLE users ADD COLUMN disabled_at TIMESTAMP WITH TIME ZONE;
+
+-- migrate:down
+ALTER TABLE users DROP COLUMN IF EXISTS disabled_at;
@@ -0,0 +1,53 @@
+-- migrate:up
+CREATE TABLE service_accounts (
+    id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    name VARCHAR(64) NOT NULL UNIQUE,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_service_accounts_deleted_at ON service_accounts(deleted_at);
+
+CREATE TABLE service_account_scopes (
+    service_account_id BIGINT NOT NULL,
+    permission_id BIGINT NOT NULL,
+    PRIMARY KEY (service_account_id, permission_id),
+    CONSTRAINT fk_service_account_scopes_service_account FOREIGN KEY (service_account_id) REFERENCES service_accounts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_service_account_scopes_permission FOREIGN KEY (permission_id) REFERENCES permissions(id) ON DELETE CASCADE
+);
+
+CREATE TABLE api_keys (
+    id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    service_account_id BIGINT NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT fk_api_keys_service_account FOREIGN KEY (service_account_id) REFERENCES service_accounts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_api_keys_deleted_at ON api_keys(deleted_at);
+CREATE INDEX idx_api_keys_service_account_id ON api_keys(service_account_id);
+
+INSERT INTO permissions (name, description) VALUES
+    ('service-accounts:write', 'Manage service accounts and their API keys');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'service-accounts:write'
+WHERE r.name = 'admin';
+
+-- migrate:down
+DELETE FROM permissions WHERE name = 'service-accounts:write';
+
+DROP INDEX IF EXISTS idx_api_keys_service_account_id;
+DROP INDEX IF EXISTS idx_api_keys_deleted_at;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS service_account_scopes;
+DROP INDEX IF EXISTS idx_service_accounts_deleted_at;
+DROP TABLE IF EXISTS service_accounts;
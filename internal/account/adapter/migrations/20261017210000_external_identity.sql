@@ -0,0 +1,39 @@
+-- migrate:up
+CREATE TABLE external_identities (
+    id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    user_id BIGINT NOT NULL,
+    provider VARCHAR(64) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT fk_external_identities_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_external_identities_deleted_at ON external_identities(deleted_at);
+CREATE INDEX idx_external_identities_user_id ON external_identities(user_id);
+CREATE UNIQUE INDEX idx_external_identities_provider_subject ON external_identities(provider, subject) WHERE deleted_at IS NULL;
+
+CREATE TABLE oidc_authorizations (
+    id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    provider VARCHAR(64) NOT NULL,
+    state_hash VARCHAR(64) NOT NULL UNIQUE,
+    nonce VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_oidc_authorizations_deleted_at ON oidc_authorizations(deleted_at);
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_oidc_authorizations_deleted_at;
+DROP TABLE IF EXISTS oidc_authorizations;
+DROP INDEX IF EXISTS idx_external_identities_provider_subject;
+DROP INDEX IF EXISTS idx_external_identities_user_id;
+DROP INDEX IF EXISTS idx_external_identities_deleted_at;
+DROP TABLE IF EXISTS external_identities;
@@ -0,0 +1,7 @@
+-- migrate:up
+ALTER TABLE users ADD COLUMN avatar_key VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN avatar_url VARCHAR(500);
+
+-- migrate:down
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_url;
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_key;
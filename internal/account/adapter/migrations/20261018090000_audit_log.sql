@@ -0,0 +1,49 @@
+-- migrate:up
+CREATE TABLE audit_events (
+    id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    occurred_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    actor_type VARCHAR(16),
+    actor_id BIGINT,
+    action VARCHAR(64) NOT NULL,
+    target_type VARCHAR(32),
+    target_id VARCHAR(255),
+    ip VARCHAR(45),
+    user_agent TEXT,
+    request_id VARCHAR(64),
+    outcome VARCHAR(16) NOT NULL,
+    reason VARCHAR(64)
+);
+
+CREATE INDEX idx_audit_events_occurred_at ON audit_events(occurred_at);
+CREATE INDEX idx_audit_events_actor ON audit_events(actor_type, actor_id);
+CREATE INDEX idx_audit_events_target ON audit_events(target_type, target_id);
+CREATE INDEX idx_audit_events_action ON audit_events(action);
+
+-- Entries are never changed; the retention purge is the only thing deleting them.
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_events_append_only
+    BEFORE UPDATE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+INSERT INTO permissions (name, description) VALUES
+    ('audit:read', 'View the security audit log');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'audit:read'
+WHERE r.name = 'admin';
+
+-- migrate:down
+DELETE FROM permissions WHERE name = 'audit:read';
+
+DROP TRIGGER IF EXISTS trg_audit_events_append_only ON audit_events;
+DROP FUNCTION IF EXISTS audit_events_append_only();
+DROP INDEX IF EXISTS idx_audit_events_action;
+DROP INDEX IF EXISTS idx_audit_events_target;
+DROP INDEX IF EXISTS idx_audit_events_actor;
+DROP INDEX IF EXISTS idx_audit_events_occurred_at;
+DROP TABLE IF EXISTS audit_events;
@@ -0,0 +1,243 @@
+package adapter
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrOtpNotFound = errors.New("otp not found")
+
+// OtpStore keeps the hashed one-time codes sent to phone numbers until they
+// expire or are used.
+type OtpStore interface {
+	// Acquire reserves the right to send a new code to the phone for window.
+	// It reports false while an earlier reservation is still active.
+	Acquire(ctx context.Context, phone string, window time.Duration) (bool, error)
+	Save(ctx context.Context, phone, codeHash string, ttl time.Duration) error
+	// Get returns the stored code hash together with the failed attempts so far.
+	Get(ctx context.Context, phone string) (string, int, error)
+	// IncrAttempts records a failed attempt and returns the new count.
+	IncrAttempts(ctx context.Context, phone string) (int, error)
+	// Consume deletes the code of the phone only if it still has codeHash, in
+	// one step. It reports false when another verification consumed it first.
+	Consume(ctx context.Context, phone, codeHash string) (bool, error)
+	Delete(ctx context.Context, phone string) error
+}
+
+// GenerateOtpCode returns a random numeric code with the given number of digits.
+func GenerateOtpCode(digits int) (string, error) {
+	var code strings.Builder
+	for range digits {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", fmt.Errorf("GenerateOtpCode fail read random digit: %w", err)
+		}
+		code.WriteByte(byte('0' + n.Int64()))
+	}
+
+	return code.String(), nil
+}
+
+// HashOtpCode binds the code to the phone it was sent to, so a stored hash
+// cannot be replayed for another number.
+func HashOtpCode(phone, code string) string {
+	sum := sha256.Sum256([]byte(phone + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+type redisOtpStore struct {
+	client *redis.Client
+}
+
+func NewRedisOtpStore(client *redis.Client) OtpStore {
+	return &redisOtpStore{client: client}
+}
+
+// incrAttemptsScript does not recreate a code that expired in the meantime.
+var incrAttemptsScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return -1
+end
+return redis.call("HINCRBY", KEYS[1], "attempts", 1)
+`)
+
+// consumeScript deletes the code only if it was not replaced or consumed
+// since it was read.
+var consumeScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], "hash") ~= ARGV[1] then
+	return 0
+end
+return redis.call("DEL", KEYS[1])
+`)
+
+func otpCodeKey(phone string) string  { return "otp:code:" + phone }
+func otpLimitKey(phone string) string { return "otp:limit:" + phone }
+
+func (s *redisOtpStore) Acquire(ctx context.Context, phone string, window time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, otpLimitKey(phone), 1, window).Result()
+}
+
+func (s *redisOtpStore) Save(ctx context.Context, phone, codeHash string, ttl time.Duration) error {
+	key := otpCodeKey(phone)
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, "hash", codeHash, "attempts", 0)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	return err
+}
+
+func (s *redisOtpStore) Get(ctx context.Context, phone string) (string, int, error) {
+	values, err := s.client.HGetAll(ctx, otpCodeKey(phone)).Result()
+	if err != nil {
+		return "", 0, err
+	}
+	if values["hash"] == "" {
+		return "", 0, ErrOtpNotFound
+	}
+
+	var attempts int
+	if _, err := fmt.Sscan(values["attempts"], &attempts); err != nil {
+		return "", 0, fmt.Errorf("redisOtpStore.Get fail parse attempts: %w", err)
+	}
+
+	return values["hash"], attempts, nil
+}
+
+func (s *redisOtpStore) IncrAttempts(ctx context.Context, phone string) (int, error) {
+	attempts, err := incrAttemptsScript.Run(ctx, s.client, []string{otpCodeKey(phone)}).Int()
+	if err != nil {
+		return 0, err
+	}
+	if attempts < 0 {
+		return 0, ErrOtpNotFound
+	}
+
+	return attempts, nil
+}
+
+func (s *redisOtpStore) Consume(ctx context.Context, phone, codeHash string) (bool, error) {
+	deleted, err := consumeScript.Run(ctx, s.client, []string{otpCodeKey(phone)}, codeHash).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return deleted == 1, nil
+}
+
+func (s *redisOtpStore) Delete(ctx context.Context, phone string) error {
+	return s.client.Del(ctx, otpCodeKey(phone)).Err()
+}
+
+type memoryOtp struct {
+	hash      string
+	attempts  int
+	expiresAt time.Time
+}
+
+// memoryOtpStore keeps codes in the process. It is meant for tests and local
+// development, where codes do not have to survive restarts.
+type memoryOtpStore struct {
+	mu     sync.Mutex
+	codes  map[string]*memoryOtp
+	limits map[string]time.Time
+}
+
+func NewMemoryOtpStore() OtpStore {
+	return &memoryOtpStore{
+		codes:  make(map[string]*memoryOtp),
+		limits: make(map[string]time.Time),
+	}
+}
+
+func (s *memoryOtpStore) Acquire(ctx context.Context, phone string, window time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if until, ok := s.limits[phone]; ok && now.Before(until) {
+		return false, nil
+	}
+
+	s.limits[phone] = now.Add(window)
+	return true, nil
+}
+
+func (s *memoryOtpStore) Save(ctx context.Context, phone, codeHash string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.codes[phone] = &memoryOtp{hash: codeHash, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *memoryOtpStore) Get(ctx context.Context, phone string) (string, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	otp, ok := s.lookup(phone)
+	if !ok {
+		return "", 0, ErrOtpNotFound
+	}
+
+	return otp.hash, otp.attempts, nil
+}
+
+func (s *memoryOtpStore) IncrAttempts(ctx context.Context, phone string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	otp, ok := s.lookup(phone)
+	if !ok {
+		return 0, ErrOtpNotFound
+	}
+
+	otp.attempts++
+	return otp.attempts, nil
+}
+
+func (s *memoryOtpStore) Consume(ctx context.Context, phone, codeHash string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	otp, ok := s.lookup(phone)
+	if !ok || otp.hash != codeHash {
+		return false, nil
+	}
+
+	delete(s.codes, phone)
+	return true, nil
+}
+
+func (s *memoryOtpStore) Delete(ctx context.Context, phone string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.codes, phone)
+	return nil
+}
+
+// lookup must be called with s.mu held.
+func (s *memoryOtpStore) lookup(phone string) (*memoryOtp, bool) {
+	otp, ok := s.codes[phone]
+	if !ok {
+		return nil, false
+	}
+	if !time.Now().Before(otp.expiresAt) {
+		delete(s.codes, phone)
+		return nil, false
+	}
+
+	return otp, true
+}
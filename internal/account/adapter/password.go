@@ -0,0 +1,90 @@
+package adapter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"shikposh-backend/config"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrPasswordMismatch = errors.New("password does not match")
+
+// MaxPasswordBytes is the longest password bcrypt hashes. It is enforced
+// whatever the configured MaxLength, since that counts characters and a
+// character takes up to 4 bytes.
+const MaxPasswordBytes = 72
+
+// HashPassword returns the bcrypt hash that is stored instead of the password.
+func HashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("HashPassword fail hash password: %w", err)
+	}
+
+	return string(hashed), nil
+}
+
+// ComparePassword returns ErrPasswordMismatch when password does not match the hash.
+func ComparePassword(hashedPassword, password string) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password)); err != nil {
+		return ErrPasswordMismatch
+	}
+
+	return nil
+}
+
+// ValidatePassword checks a new password against the configured rules and
+// describes every rule it breaks. Rules left at their zero value are not
+// enforced, except the MaxPasswordBytes limit of bcrypt.
+func ValidatePassword(cfg config.PasswordConfig, password string) error {
+	var (
+		violations                               []string
+		hasDigit, hasUpper, hasLower, hasSpecial bool
+	)
+
+	for _, r := range password {
+		switch {
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSpecial = true
+		}
+	}
+
+	length := len([]rune(password))
+	if cfg.MinLength > 0 && length < cfg.MinLength {
+		violations = append(violations, fmt.Sprintf("be at least %d characters long", cfg.MinLength))
+	}
+	if cfg.MaxLength > 0 && length > cfg.MaxLength {
+		violations = append(violations, fmt.Sprintf("be at most %d characters long", cfg.MaxLength))
+	}
+	if len(password) > MaxPasswordBytes {
+		violations = append(violations, fmt.Sprintf("be at most %d bytes long", MaxPasswordBytes))
+	}
+	if cfg.IncludeDigits && !hasDigit {
+		violations = append(violations, "contain a digit")
+	}
+	if cfg.IncludeUppercase && !hasUpper {
+		violations = append(violations, "contain an uppercase letter")
+	}
+	if cfg.IncludeLowercase && !hasLower {
+		violations = append(violations, "contain a lowercase letter")
+	}
+	if cfg.IncludeChars && !hasSpecial {
+		violations = append(violations, "contain a special character")
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("password must %s", strings.Join(violations, ", "))
+	}
+
+	return nil
+}
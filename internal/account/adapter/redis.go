@@ -0,0 +1,35 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"shikposh-backend/config"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/cast"
+)
+
+// NewRedisClient connects to the configured Redis server. Timeouts are
+// expressed in seconds.
+func NewRedisClient(ctx context.Context, cfg config.RedisConfig) (*redis.Client, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:         net.JoinHostPort(cfg.Host, cfg.Port),
+		Password:     cfg.Password,
+		DB:           cast.ToInt(cfg.Db),
+		DialTimeout:  cfg.DialTimeout * time.Second,
+		ReadTimeout:  cfg.ReadTimeout * time.Second,
+		WriteTimeout: cfg.WriteTimeout * time.Second,
+		PoolSize:     cfg.PoolSize,
+		PoolTimeout:  cfg.PoolTimeout * time.Second,
+	})
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("NewRedisClient fail ping redis: %w", err)
+	}
+
+	return client, nil
+}
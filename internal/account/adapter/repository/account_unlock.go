@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"gorm.io/gorm"
+)
+
+var ErrAccountUnlockTokenNotFound = errors.New("account unlock token not found")
+
+type AccountUnlockRepository interface {
+	adapter.BaseRepository[*entity.AccountUnlockToken]
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entity.AccountUnlockToken, error)
+	MarkUsed(ctx context.Context, token *entity.AccountUnlockToken, now time.Time) (bool, error)
+}
+
+type accountUnlockGormRepository struct {
+	adapter.BaseRepository[*entity.AccountUnlockToken]
+	db *gorm.DB
+}
+
+func NewAccountUnlockRepository(db *gorm.DB) AccountUnlockRepository {
+	return &accountUnlockGormRepository{
+		BaseRepository: adapter.NewGormRepository[*entity.AccountUnlockToken](db),
+		db:             db,
+	}
+}
+
+func (r *accountUnlockGormRepository) Model(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&entity.AccountUnlockToken{})
+}
+
+func (r *accountUnlockGormRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.AccountUnlockToken, error) {
+	token, err := r.FindByField(ctx, "token_hash", tokenHash)
+	if err != nil {
+		if errors.Is(err, adapter.ErrEntityNotFound) {
+			return nil, ErrAccountUnlockTokenNotFound
+		}
+
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// MarkUsed consumes the token only if it is still unused. It reports false
+// when the token was already used.
+func (r *accountUnlockGormRepository) MarkUsed(ctx context.Context, token *entity.AccountUnlockToken, now time.Time) (bool, error) {
+	result := r.Model(ctx).
+		Where("id = ? AND used_at IS NULL", uint64(token.ID)).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	token.MarkUsed(now)
+	return true, nil
+}
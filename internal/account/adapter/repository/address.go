@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"gorm.io/gorm"
+)
+
+var ErrAddressNotFound = errors.New("address not found")
+
+type AddressRepository interface {
+	adapter.BaseRepository[*entity.Address]
+	FindByUserID(ctx context.Context, userID entity.UserID) ([]*entity.Address, error)
+	FindByIDAndUserID(ctx context.Context, id entity.AddressID, userID entity.UserID) (*entity.Address, error)
+	ClearDefault(ctx context.Context, userID entity.UserID) error
+}
+
+type addressGormRepository struct {
+	adapter.BaseRepository[*entity.Address]
+	db *gorm.DB
+}
+
+func NewAddressRepository(db *gorm.DB) AddressRepository {
+	return &addressGormRepository{
+		BaseRepository: adapter.NewGormRepository[*entity.Address](db),
+		db:             db,
+	}
+}
+
+func (a *addressGormRepository) Model(ctx context.Context) *gorm.DB {
+	return a.db.WithContext(ctx).Model(&entity.Address{})
+}
+
+// FindByUserID returns the addresses of the user, the default one first.
+func (a *addressGormRepository) FindByUserID(ctx context.Context, userID entity.UserID) ([]*entity.Address, error) {
+	var addresses []*entity.Address
+	err := a.Model(ctx).
+		Where("user_id = ?", uint64(userID)).
+		Order("is_default DESC, created_at DESC").
+		Find(&addresses).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, address := range addresses {
+		a.SetSeen(address)
+	}
+	return addresses, nil
+}
+
+// FindByIDAndUserID returns the address only if it belongs to the user.
+func (a *addressGormRepository) FindByIDAndUserID(ctx context.Context, id entity.AddressID, userID entity.UserID) (*entity.Address, error) {
+	address := new(entity.Address)
+	err := a.Model(ctx).Where("id = ? AND user_id = ?", uint64(id), uint64(userID)).First(address).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAddressNotFound
+		}
+
+		return nil, err
+	}
+
+	a.SetSeen(address)
+	return address, nil
+}
+
+// ClearDefault unsets the default flag on every address of the user.
+func (a *addressGormRepository) ClearDefault(ctx context.Context, userID entity.UserID) error {
+	return a.Model(ctx).
+		Where("user_id = ? AND is_default", uint64(userID)).
+		Update("is_default", false).Error
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"gorm.io/gorm"
+)
+
+// AuditEventSearch selects entries of the audit log. Zero fields match
+// every entry; From is inclusive and To exclusive.
+type AuditEventSearch struct {
+	ActorType  string
+	ActorID    *uint64
+	Action     string
+	TargetType string
+	TargetID   string
+	Outcome    entity.AuditOutcome
+	IP         string
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+	Skip       int
+	Limit      int
+}
+
+// AuditEventRepository stores the audit log. It is append-only: entries
+// can be added and searched, and only the retention purge deletes them.
+type AuditEventRepository interface {
+	adapter.SeenedRepository
+	Save(ctx context.Context, event *entity.AuditEvent) error
+	Search(ctx context.Context, search AuditEventSearch) ([]*entity.AuditEvent, int64, error)
+	PurgeBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type auditEventGormRepository struct {
+	base adapter.BaseRepository[*entity.AuditEvent]
+	db   *gorm.DB
+}
+
+func NewAuditEventRepository(db *gorm.DB) AuditEventRepository {
+	return &auditEventGormRepository{
+		base: adapter.NewGormRepository[*entity.AuditEvent](db),
+		db:   db,
+	}
+}
+
+func (a *auditEventGormRepository) Model(ctx context.Context) *gorm.DB {
+	return a.db.WithContext(ctx).Model(&entity.AuditEvent{})
+}
+
+func (a *auditEventGormRepository) Seen() []adapter.Entity {
+	return a.base.Seen()
+}
+
+func (a *auditEventGormRepository) SetSeen(model adapter.Entity) {
+	a.base.SetSeen(model)
+}
+
+func (a *auditEventGormRepository) Save(ctx context.Context, event *entity.AuditEvent) error {
+	return a.base.Save(ctx, event)
+}
+
+// Search returns a page of the matching entries, the newest first, along
+// with the number of matching entries.
+func (a *auditEventGormRepository) Search(ctx context.Context, search AuditEventSearch) ([]*entity.AuditEvent, int64, error) {
+	query := a.Model(ctx)
+	if search.ActorType != "" {
+		query = query.Where("actor_type = ?", search.ActorType)
+	}
+	if search.ActorID != nil {
+		query = query.Where("actor_id = ?", *search.ActorID)
+	}
+	if search.Action != "" {
+		query = query.Where("action = ?", search.Action)
+	}
+	if search.TargetType != "" {
+		query = query.Where("target_type = ?", search.TargetType)
+	}
+	if search.TargetID != "" {
+		query = query.Where("target_id = ?", search.TargetID)
+	}
+	if search.Outcome != "" {
+		query = query.Where("outcome = ?", search.Outcome)
+	}
+	if search.IP != "" {
+		query = query.Where("ip = ?", search.IP)
+	}
+	if search.RequestID != "" {
+		query = query.Where("request_id = ?", search.RequestID)
+	}
+	if search.From != nil {
+		query = query.Where("occurred_at >= ?", *search.From)
+	}
+	if search.To != nil {
+		query = query.Where("occurred_at < ?", *search.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []*entity.AuditEvent
+	err := query.
+		Order("occurred_at DESC, id DESC").
+		Offset(search.Skip).
+		Limit(search.Limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+// PurgeBefore deletes the entries that occurred before the given time and
+// returns how many were deleted.
+func (a *auditEventGormRepository) PurgeBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := a.db.WithContext(ctx).
+		Where("occurred_at < ?", before).
+		Delete(&entity.AuditEvent{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
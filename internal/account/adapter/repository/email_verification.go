@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"gorm.io/gorm"
+)
+
+var ErrEmailVerificationTokenNotFound = errors.New("email verification token not found")
+
+type EmailVerificationRepository interface {
+	adapter.BaseRepository[*entity.EmailVerificationToken]
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entity.EmailVerificationToken, error)
+	FindLatestByUserID(ctx context.Context, userID entity.UserID) (*entity.EmailVerificationToken, error)
+	MarkUsed(ctx context.Context, token *entity.EmailVerificationToken, now time.Time) (bool, error)
+}
+
+type emailVerificationGormRepository struct {
+	adapter.BaseRepository[*entity.EmailVerificationToken]
+	db *gorm.DB
+}
+
+func NewEmailVerificationRepository(db *gorm.DB) EmailVerificationRepository {
+	return &emailVerificationGormRepository{
+		BaseRepository: adapter.NewGormRepository[*entity.EmailVerificationToken](db),
+		db:             db,
+	}
+}
+
+func (r *emailVerificationGormRepository) Model(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&entity.EmailVerificationToken{})
+}
+
+func (r *emailVerificationGormRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.EmailVerificationToken, error) {
+	token, err := r.FindByField(ctx, "token_hash", tokenHash)
+	if err != nil {
+		if errors.Is(err, adapter.ErrEntityNotFound) {
+			return nil, ErrEmailVerificationTokenNotFound
+		}
+
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (r *emailVerificationGormRepository) FindLatestByUserID(ctx context.Context, userID entity.UserID) (*entity.EmailVerificationToken, error) {
+	token := new(entity.EmailVerificationToken)
+	err := r.Model(ctx).Where("user_id = ?", uint64(userID)).Order("created_at DESC").First(token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEmailVerificationTokenNotFound
+		}
+
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// MarkUsed consumes the token only if it is still unused, so a token cannot
+// verify an address twice. It reports false when the token was already used.
+func (r *emailVerificationGormRepository) MarkUsed(ctx context.Context, token *entity.EmailVerificationToken, now time.Time) (bool, error) {
+	result := r.Model(ctx).
+		Where("id = ? AND used_at IS NULL", uint64(token.ID)).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	token.MarkUsed(now)
+	return true, nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrExternalIdentityNotFound  = errors.New("external identity not found")
+	ErrOidcAuthorizationNotFound = errors.New("oidc authorization not found")
+)
+
+type ExternalIdentityRepository interface {
+	adapter.BaseRepository[*entity.ExternalIdentity]
+	FindByProviderAndSubject(ctx context.Context, provider, subject string) (*entity.ExternalIdentity, error)
+}
+
+type externalIdentityGormRepository struct {
+	adapter.BaseRepository[*entity.ExternalIdentity]
+	db *gorm.DB
+}
+
+func NewExternalIdentityRepository(db *gorm.DB) ExternalIdentityRepository {
+	return &externalIdentityGormRepository{
+		BaseRepository: adapter.NewGormRepository[*entity.ExternalIdentity](db),
+		db:             db,
+	}
+}
+
+func (r *externalIdentityGormRepository) Model(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&entity.ExternalIdentity{})
+}
+
+func (r *externalIdentityGormRepository) FindByProviderAndSubject(ctx context.Context, provider, subject string) (*entity.ExternalIdentity, error) {
+	var identity entity.ExternalIdentity
+	err := r.Model(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrExternalIdentityNotFound
+		}
+
+		return nil, err
+	}
+
+	r.SetSeen(&identity)
+	return &identity, nil
+}
+
+type OidcAuthorizationRepository interface {
+	adapter.BaseRepository[*entity.OidcAuthorization]
+	FindByStateHash(ctx context.Context, stateHash string) (*entity.OidcAuthorization, error)
+	MarkUsed(ctx context.Context, authorization *entity.OidcAuthorization, now time.Time) (bool, error)
+}
+
+type oidcAuthorizationGormRepository struct {
+	adapter.BaseRepository[*entity.OidcAuthorization]
+	db *gorm.DB
+}
+
+func NewOidcAuthorizationRepository(db *gorm.DB) OidcAuthorizationRepository {
+	return &oidcAuthorizationGormRepository{
+		BaseRepository: adapter.NewGormRepository[*entity.OidcAuthorization](db),
+		db:             db,
+	}
+}
+
+func (r *oidcAuthorizationGormRepository) Model(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&entity.OidcAuthorization{})
+}
+
+func (r *oidcAuthorizationGormRepository) FindByStateHash(ctx context.Context, stateHash string) (*entity.OidcAuthorization, error) {
+	authorization, err := r.FindByField(ctx, "state_hash", stateHash)
+	if err != nil {
+		if errors.Is(err, adapter.ErrEntityNotFound) {
+			return nil, ErrOidcAuthorizationNotFound
+		}
+
+		return nil, err
+	}
+
+	return authorization, nil
+}
+
+// MarkUsed consumes the authorization only if it is still unused, so a code
+// cannot be redeemed twice. It reports false when it was already used.
+func (r *oidcAuthorizationGormRepository) MarkUsed(ctx context.Context, authorization *entity.OidcAuthorization, now time.Time) (bool, error) {
+	result := r.Model(ctx).
+		Where("id = ? AND used_at IS NULL", uint64(authorization.ID)).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	authorization.MarkUsed(now)
+	return true, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"gorm.io/gorm"
+)
+
+var ErrPasswordResetTokenNotFound = errors.New("password reset token not found")
+
+type PasswordResetRepository interface {
+	adapter.BaseRepository[*entity.PasswordResetToken]
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error)
+	MarkUsed(ctx context.Context, token *entity.PasswordResetToken, now time.Time) (bool, error)
+	InvalidateByUserID(ctx context.Context, userID entity.UserID, now time.Time) error
+}
+
+type passwordResetGormRepository struct {
+	adapter.BaseRepository[*entity.PasswordResetToken]
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetGormRepository{
+		BaseRepository: adapter.NewGormRepository[*entity.PasswordResetToken](db),
+		db:             db,
+	}
+}
+
+func (r *passwordResetGormRepository) Model(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&entity.PasswordResetToken{})
+}
+
+func (r *passwordResetGormRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
+	token, err := r.FindByField(ctx, "token_hash", tokenHash)
+	if err != nil {
+		if errors.Is(err, adapter.ErrEntityNotFound) {
+			return nil, ErrPasswordResetTokenNotFound
+		}
+
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// MarkUsed consumes the token only if it is still unused, so a token cannot
+// reset the password twice. It reports false when the token was already used.
+func (r *passwordResetGormRepository) MarkUsed(ctx context.Context, token *entity.PasswordResetToken, now time.Time) (bool, error) {
+	result := r.Model(ctx).
+		Where("id = ? AND used_at IS NULL", uint64(token.ID)).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	token.MarkUsed(now)
+	return true, nil
+}
+
+// InvalidateByUserID consumes every outstanding reset token of the user.
+func (r *passwordResetGormRepository) InvalidateByUserID(ctx context.Context, userID entity.UserID, now time.Time) error {
+	return r.Model(ctx).
+		Where("user_id = ? AND used_at IS NULL", uint64(userID)).
+		Update("used_at", now).Error
+}
@@ -15,6 +15,8 @@ var ErrProfileNotFound = errors.New("profile not found")
 type ProfileRepository interface {
 	adapter.BaseRepository[*entity.Profile]
 	FindByUserID(ctx context.Context, userID entity.UserID) (*entity.Profile, error)
+	FindByPhone(ctx context.Context, phone string) (*entity.Profile, error)
+	FindByUserIDs(ctx context.Context, userIDs []entity.UserID) ([]*entity.Profile, error)
 }
 
 type profileGormRepository struct {
@@ -36,7 +38,7 @@ func (p *profileGormRepository) Model(ctx context.Context) *gorm.DB {
 func (p *profileGormRepository) FindByUserID(ctx context.Context, userID entity.UserID) (*entity.Profile, error) {
 	profile, err := p.FindByField(ctx, "user_id", uint64(userID))
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, adapter.ErrEntityNotFound) {
 			return nil, ErrProfileNotFound
 		}
 
@@ -45,3 +47,34 @@ func (p *profileGormRepository) FindByUserID(ctx context.Context, userID entity.
 
 	return profile, nil
 }
+
+func (p *profileGormRepository) FindByPhone(ctx context.Context, phone string) (*entity.Profile, error) {
+	profile, err := p.FindByField(ctx, "phone", phone)
+	if err != nil {
+		if errors.Is(err, adapter.ErrEntityNotFound) {
+			return nil, ErrProfileNotFound
+		}
+
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// FindByUserIDs returns the profiles of the given users. Users without a
+// profile are left out.
+func (p *profileGormRepository) FindByUserIDs(ctx context.Context, userIDs []entity.UserID) ([]*entity.Profile, error) {
+	var profiles []*entity.Profile
+	if len(userIDs) == 0 {
+		return profiles, nil
+	}
+
+	err := p.Model(ctx).Where("user_id IN ?", userIDs).Find(&profiles).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, profile := range profiles {
+		p.SetSeen(profile)
+	}
+	return profiles, nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"gorm.io/gorm"
+)
+
+var ErrRefreshTokenNotFound = errors.New("refresh token not found")
+
+type RefreshTokenRepository interface {
+	adapter.BaseRepository[*entity.RefreshToken]
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+	MarkUsed(ctx context.Context, token *entity.RefreshToken, now time.Time) (bool, error)
+	RevokeBySessionID(ctx context.Context, sessionID entity.SessionID) error
+	RevokeByUserID(ctx context.Context, userID entity.UserID) error
+}
+
+type refreshTokenGormRepository struct {
+	adapter.BaseRepository[*entity.RefreshToken]
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenGormRepository{
+		BaseRepository: adapter.NewGormRepository[*entity.RefreshToken](db),
+		db:             db,
+	}
+}
+
+func (r *refreshTokenGormRepository) Model(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&entity.RefreshToken{})
+}
+
+func (r *refreshTokenGormRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	token, err := r.FindByField(ctx, "token_hash", tokenHash)
+	if err != nil {
+		if errors.Is(err, adapter.ErrEntityNotFound) {
+			return nil, ErrRefreshTokenNotFound
+		}
+
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// MarkUsed consumes the token only if it is still unused and not revoked, so
+// two concurrent refreshes cannot both exchange it. It reports false when the
+// token was consumed or revoked in the meantime.
+func (r *refreshTokenGormRepository) MarkUsed(ctx context.Context, token *entity.RefreshToken, now time.Time) (bool, error) {
+	result := r.Model(ctx).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", uint64(token.ID)).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	token.MarkUsed(now)
+	return true, nil
+}
+
+// RevokeBySessionID revokes the whole token family of a session.
+func (r *refreshTokenGormRepository) RevokeBySessionID(ctx context.Context, sessionID entity.SessionID) error {
+	return r.Model(ctx).
+		Where("session_id = ? AND revoked_at IS NULL", uint64(sessionID)).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeByUserID revokes the token families of every session of the user.
+func (r *refreshTokenGormRepository) RevokeByUserID(ctx context.Context, userID entity.UserID) error {
+	return r.Model(ctx).
+		Where("user_id = ? AND revoked_at IS NULL", uint64(userID)).
+		Update("revoked_at", time.Now()).Error
+}
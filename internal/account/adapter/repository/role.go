@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"gorm.io/gorm"
+)
+
+var ErrRoleNotFound = errors.New("role not found")
+
+type RoleRepository interface {
+	adapter.BaseRepository[*entity.Role]
+	FindByName(ctx context.Context, name string) (*entity.Role, error)
+	FindPermissionsByNames(ctx context.Context, names []string) ([]*entity.Permission, error)
+}
+
+type roleGormRepository struct {
+	adapter.BaseRepository[*entity.Role]
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleGormRepository{
+		BaseRepository: adapter.NewGormRepository[*entity.Role](db),
+		db:             db,
+	}
+}
+
+func (r *roleGormRepository) Model(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&entity.Role{})
+}
+
+func (r *roleGormRepository) FindByName(ctx context.Context, name string) (*entity.Role, error) {
+	role := new(entity.Role)
+	err := r.Model(ctx).Preload("Permissions").Where("name = ?", name).First(role).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+
+		return nil, err
+	}
+
+	r.SetSeen(role)
+
+	return role, nil
+}
+
+// FindPermissionsByNames returns the permissions with the given names. Names
+// without a permission are left out.
+func (r *roleGormRepository) FindPermissionsByNames(ctx context.Context, names []string) ([]*entity.Permission, error) {
+	var permissions []*entity.Permission
+	err := r.db.WithContext(ctx).Where("name IN ?", names).Find(&permissions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrServiceAccountNotFound = errors.New("service account not found")
+	ErrApiKeyNotFound         = errors.New("api key not found")
+)
+
+type ServiceAccountRepository interface {
+	adapter.BaseRepository[*entity.ServiceAccount]
+	FindByName(ctx context.Context, name string) (*entity.ServiceAccount, error)
+	FindAll(ctx context.Context) ([]*entity.ServiceAccount, error)
+}
+
+type serviceAccountGormRepository struct {
+	adapter.BaseRepository[*entity.ServiceAccount]
+	db *gorm.DB
+}
+
+func NewServiceAccountRepository(db *gorm.DB) ServiceAccountRepository {
+	return &serviceAccountGormRepository{
+		BaseRepository: adapter.NewGormRepository[*entity.ServiceAccount](db),
+		db:             db,
+	}
+}
+
+func (r *serviceAccountGormRepository) Model(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&entity.ServiceAccount{})
+}
+
+func (r *serviceAccountGormRepository) FindByName(ctx context.Context, name string) (*entity.ServiceAccount, error) {
+	account, err := r.FindByField(ctx, "name", name)
+	if err != nil {
+		if errors.Is(err, adapter.ErrEntityNotFound) {
+			return nil, ErrServiceAccountNotFound
+		}
+
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// FindAll returns every service account with its scopes and keys, ordered
+// by name.
+func (r *serviceAccountGormRepository) FindAll(ctx context.Context) ([]*entity.ServiceAccount, error) {
+	var accounts []*entity.ServiceAccount
+	err := r.Model(ctx).
+		Preload("Scopes").
+		Preload("ApiKeys", func(db *gorm.DB) *gorm.DB {
+			return db.Order("api_keys.created_at DESC, api_keys.id DESC")
+		}).
+		Order("name").
+		Find(&accounts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, account := range accounts {
+		r.SetSeen(account)
+	}
+	return accounts, nil
+}
+
+type ApiKeyRepository interface {
+	adapter.BaseRepository[*entity.ApiKey]
+	FindByKeyHash(ctx context.Context, keyHash string) (*entity.ApiKey, error)
+	FindByIDAndServiceAccountID(ctx context.Context, id entity.ApiKeyID, serviceAccountID entity.ServiceAccountID) (*entity.ApiKey, error)
+	MarkUsed(ctx context.Context, key *entity.ApiKey, now time.Time) error
+}
+
+type apiKeyGormRepository struct {
+	adapter.BaseRepository[*entity.ApiKey]
+	db *gorm.DB
+}
+
+func NewApiKeyRepository(db *gorm.DB) ApiKeyRepository {
+	return &apiKeyGormRepository{
+		BaseRepository: adapter.NewGormRepository[*entity.ApiKey](db),
+		db:             db,
+	}
+}
+
+func (r *apiKeyGormRepository) Model(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&entity.ApiKey{})
+}
+
+// FindByKeyHash returns the key together with its service account and the
+// account's scopes. Keys of deleted service accounts are not found.
+func (r *apiKeyGormRepository) FindByKeyHash(ctx context.Context, keyHash string) (*entity.ApiKey, error) {
+	key := new(entity.ApiKey)
+	err := r.Model(ctx).
+		Preload("ServiceAccount.Scopes").
+		Where("key_hash = ?", keyHash).
+		First(key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrApiKeyNotFound
+		}
+
+		return nil, err
+	}
+	if key.ServiceAccount == nil {
+		return nil, ErrApiKeyNotFound
+	}
+
+	r.SetSeen(key)
+
+	return key, nil
+}
+
+func (r *apiKeyGormRepository) FindByIDAndServiceAccountID(ctx context.Context, id entity.ApiKeyID, serviceAccountID entity.ServiceAccountID) (*entity.ApiKey, error) {
+	key := new(entity.ApiKey)
+	err := r.Model(ctx).
+		Where("id = ? AND service_account_id = ?", uint64(id), uint64(serviceAccountID)).
+		First(key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrApiKeyNotFound
+		}
+
+		return nil, err
+	}
+
+	r.SetSeen(key)
+
+	return key, nil
+}
+
+// MarkUsed writes only the last-used time, so recording the use of a key
+// never overwrites a concurrent revocation.
+func (r *apiKeyGormRepository) MarkUsed(ctx context.Context, key *entity.ApiKey, now time.Time) error {
+	err := r.Model(ctx).
+		Where("id = ?", uint64(key.ID)).
+		Update("last_used_at", now).Error
+	if err != nil {
+		return err
+	}
+
+	key.Touch(now)
+	return nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"gorm.io/gorm"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+type SessionRepository interface {
+	adapter.BaseRepository[*entity.Session]
+	FindBySessionID(ctx context.Context, id entity.SessionID) (*entity.Session, error)
+	RevokeByUserID(ctx context.Context, userID entity.UserID, now time.Time) error
+	FindActiveByUserID(ctx context.Context, userID entity.UserID, now time.Time) ([]*entity.Session, error)
+}
+
+type sessionGormRepository struct {
+	adapter.BaseRepository[*entity.Session]
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionGormRepository{
+		BaseRepository: adapter.NewGormRepository[*entity.Session](db),
+		db:             db,
+	}
+}
+
+func (s *sessionGormRepository) Model(ctx context.Context) *gorm.DB {
+	return s.db.WithContext(ctx).Model(&entity.Session{})
+}
+
+func (s *sessionGormRepository) FindBySessionID(ctx context.Context, id entity.SessionID) (*entity.Session, error) {
+	session, err := s.FindByID(ctx, uint64(id))
+	if err != nil {
+		if errors.Is(err, adapter.ErrEntityNotFound) {
+			return nil, ErrSessionNotFound
+		}
+
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// RevokeByUserID revokes every active session of the user on all devices.
+func (s *sessionGormRepository) RevokeByUserID(ctx context.Context, userID entity.UserID, now time.Time) error {
+	return s.Model(ctx).
+		Where("user_id = ? AND revoked_at IS NULL", uint64(userID)).
+		Update("revoked_at", now).Error
+}
+
+// FindActiveByUserID returns the sessions of the user that can still be
+// used, the most recently seen first.
+func (s *sessionGormRepository) FindActiveByUserID(ctx context.Context, userID entity.UserID, now time.Time) ([]*entity.Session, error) {
+	var sessions []*entity.Session
+	err := s.Model(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", uint64(userID), now).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		s.SetSeen(session)
+	}
+	return sessions, nil
+}
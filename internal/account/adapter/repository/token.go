@@ -1,47 +0,0 @@
-package repository
-
-import (
-	"context"
-	"errors"
-
-	"shikposh-backend/internal/account/domain/entity"
-	"github.com/ali-mahdavi-dev/framework/adapter"
-
-	"gorm.io/gorm"
-)
-
-var ErrTokenNotFound = errors.New("Token not found")
-
-type TokenRepository interface {
-	adapter.BaseRepository[*entity.Token]
-	FindByUserID(ctx context.Context, userID entity.UserID) (*entity.Token, error)
-}
-
-type tokenGormRepository struct {
-	adapter.BaseRepository[*entity.Token]
-	db *gorm.DB
-}
-
-func NewTokenRepository(db *gorm.DB) TokenRepository {
-	return &tokenGormRepository{
-		BaseRepository: adapter.NewGormRepository[*entity.Token](db),
-		db:             db,
-	}
-}
-
-func (u *tokenGormRepository) Model(ctx context.Context) *gorm.DB {
-	return u.db.WithContext(ctx).Model(&entity.Token{})
-}
-
-func (u *tokenGormRepository) FindByUserID(ctx context.Context, userID entity.UserID) (*entity.Token, error) {
-	token, err := u.FindByField(ctx, "user_id", uint64(userID))
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrTokenNotFound
-		}
-
-		return nil, err
-	}
-
-	return token, nil
-}
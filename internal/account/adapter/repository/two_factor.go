@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTwoFactorChallengeNotFound = errors.New("two-factor challenge not found")
+	ErrRecoveryCodeNotFound       = errors.New("recovery code not found")
+)
+
+type TwoFactorChallengeRepository interface {
+	adapter.BaseRepository[*entity.TwoFactorChallenge]
+	FindByTokenHash(ctx context.Context, tokenHash string) (*entity.TwoFactorChallenge, error)
+	MarkUsed(ctx context.Context, challenge *entity.TwoFactorChallenge, now time.Time) (bool, error)
+}
+
+type twoFactorChallengeGormRepository struct {
+	adapter.BaseRepository[*entity.TwoFactorChallenge]
+	db *gorm.DB
+}
+
+func NewTwoFactorChallengeRepository(db *gorm.DB) TwoFactorChallengeRepository {
+	return &twoFactorChallengeGormRepository{
+		BaseRepository: adapter.NewGormRepository[*entity.TwoFactorChallenge](db),
+		db:             db,
+	}
+}
+
+func (r *twoFactorChallengeGormRepository) Model(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&entity.TwoFactorChallenge{})
+}
+
+func (r *twoFactorChallengeGormRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.TwoFactorChallenge, error) {
+	challenge, err := r.FindByField(ctx, "token_hash", tokenHash)
+	if err != nil {
+		if errors.Is(err, adapter.ErrEntityNotFound) {
+			return nil, ErrTwoFactorChallengeNotFound
+		}
+
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// MarkUsed consumes the challenge only if it is still unused, so one
+// challenge cannot open two sessions. It reports false when it was already used.
+func (r *twoFactorChallengeGormRepository) MarkUsed(ctx context.Context, challenge *entity.TwoFactorChallenge, now time.Time) (bool, error) {
+	result := r.Model(ctx).
+		Where("id = ? AND used_at IS NULL", uint64(challenge.ID)).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	challenge.MarkUsed(now)
+	return true, nil
+}
+
+type RecoveryCodeRepository interface {
+	adapter.BaseRepository[*entity.RecoveryCode]
+	FindUnusedByHash(ctx context.Context, userID entity.UserID, codeHash string) (*entity.RecoveryCode, error)
+	MarkUsed(ctx context.Context, code *entity.RecoveryCode, now time.Time) (bool, error)
+	DeleteByUserID(ctx context.Context, userID entity.UserID) error
+}
+
+type recoveryCodeGormRepository struct {
+	adapter.BaseRepository[*entity.RecoveryCode]
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) RecoveryCodeRepository {
+	return &recoveryCodeGormRepository{
+		BaseRepository: adapter.NewGormRepository[*entity.RecoveryCode](db),
+		db:             db,
+	}
+}
+
+func (r *recoveryCodeGormRepository) Model(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&entity.RecoveryCode{})
+}
+
+func (r *recoveryCodeGormRepository) FindUnusedByHash(ctx context.Context, userID entity.UserID, codeHash string) (*entity.RecoveryCode, error) {
+	var code entity.RecoveryCode
+	err := r.Model(ctx).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", uint64(userID), codeHash).
+		First(&code).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecoveryCodeNotFound
+		}
+
+		return nil, err
+	}
+
+	r.SetSeen(&code)
+	return &code, nil
+}
+
+// MarkUsed consumes the code only if it is still unused. It reports false
+// when a concurrent login used it first.
+func (r *recoveryCodeGormRepository) MarkUsed(ctx context.Context, code *entity.RecoveryCode, now time.Time) (bool, error) {
+	result := r.Model(ctx).
+		Where("id = ? AND used_at IS NULL", uint64(code.ID)).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	code.MarkUsed(now)
+	return true, nil
+}
+
+// DeleteByUserID removes every recovery code of the user, used or not.
+func (r *recoveryCodeGormRepository) DeleteByUserID(ctx context.Context, userID entity.UserID) error {
+	return r.db.WithContext(ctx).
+		Unscoped().
+		Where("user_id = ?", uint64(userID)).
+		Delete(&entity.RecoveryCode{}).Error
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
 
 	"shikposh-backend/internal/account/domain/entity"
 	"github.com/ali-mahdavi-dev/framework/adapter"
@@ -12,10 +14,28 @@ import (
 
 var ErrUserNotFound = errors.New("user not found")
 
+// UserSearch selects a page of users. Query matches part of the username or
+// email address; Phone, when set, also matches the E.164 phone number of the
+// profile exactly.
+type UserSearch struct {
+	Query string
+	Phone string
+	Skip  int
+	Limit int
+}
+
 type UserRepository interface {
 	adapter.BaseRepository[*entity.User]
 	FindByUserName(ctx context.Context, username string) (*entity.User, error)
+	FindByEmail(ctx context.Context, email string) (*entity.User, error)
 	FindByUsernameExcludingID(ctx context.Context, username string, Id uint) (*entity.User, error)
+	FindPermissions(ctx context.Context, userID entity.UserID) ([]string, error)
+	HasRole(ctx context.Context, userID entity.UserID, roleName string) (bool, error)
+	AssignRole(ctx context.Context, user *entity.User, role *entity.Role) error
+	PurgeDeleted(ctx context.Context, now time.Time) (int64, error)
+	FindByIDWithRoles(ctx context.Context, id entity.UserID) (*entity.User, error)
+	Search(ctx context.Context, search UserSearch) ([]*entity.User, int64, error)
+	ReplaceRoles(ctx context.Context, user *entity.User, roles []*entity.Role) error
 }
 
 type userGormRepository struct {
@@ -60,3 +80,123 @@ func (u *userGormRepository) FindByUserName(ctx context.Context, username string
 
 	return user, nil
 }
+
+func (u *userGormRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	user := new(entity.User)
+	err := u.db.WithContext(ctx).Where("email = ?", email).First(user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+
+		return nil, err
+	}
+
+	u.SetSeen(user)
+	return user, nil
+}
+
+// FindPermissions returns the distinct permission names granted to the user
+// through all of their roles.
+func (u *userGormRepository) FindPermissions(ctx context.Context, userID entity.UserID) ([]string, error) {
+	var permissions []string
+	err := u.db.WithContext(ctx).
+		Table("permissions").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
+		Where("user_roles.user_id = ? AND permissions.deleted_at IS NULL", uint64(userID)).
+		Distinct().
+		Pluck("permissions.name", &permissions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// HasRole reports whether the user holds the role with the given name.
+func (u *userGormRepository) HasRole(ctx context.Context, userID entity.UserID, roleName string) (bool, error) {
+	var count int64
+	err := u.db.WithContext(ctx).
+		Table("user_roles").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("user_roles.user_id = ? AND roles.name = ? AND roles.deleted_at IS NULL", uint64(userID), roleName).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (u *userGormRepository) AssignRole(ctx context.Context, user *entity.User, role *entity.Role) error {
+	return u.db.WithContext(ctx).Model(user).Association("Roles").Append(role)
+}
+
+// PurgeDeleted hard-deletes the closed accounts whose grace period ended
+// before now and returns how many were erased. Every table referencing users
+// does so through a foreign key with ON DELETE CASCADE, so the sessions,
+// tokens, addresses and identities of the users go with them.
+func (u *userGormRepository) PurgeDeleted(ctx context.Context, now time.Time) (int64, error) {
+	result := u.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND purge_after <= ?", now).
+		Delete(&entity.User{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (u *userGormRepository) FindByIDWithRoles(ctx context.Context, id entity.UserID) (*entity.User, error) {
+	user := new(entity.User)
+	err := u.db.WithContext(ctx).Preload("Roles").First(user, uint64(id)).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+
+		return nil, err
+	}
+
+	u.SetSeen(user)
+	return user, nil
+}
+
+// Search returns a page of the users matching the search with their roles,
+// the newest first, along with the number of matching users.
+func (u *userGormRepository) Search(ctx context.Context, search UserSearch) ([]*entity.User, int64, error) {
+	query := u.db.WithContext(ctx).Model(&entity.User{})
+	if search.Query != "" {
+		pattern := "%" + strings.ToLower(search.Query) + "%"
+		condition := u.db.Where("LOWER(users.user_name) LIKE ? OR LOWER(users.email) LIKE ?", pattern, pattern)
+		if search.Phone != "" {
+			phones := u.db.Model(&entity.Profile{}).Select("user_id").Where("phone = ?", search.Phone)
+			condition = condition.Or("users.id IN (?)", phones)
+		}
+		query = query.Where(condition)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []*entity.User
+	err := query.Preload("Roles").
+		Order("users.created_at DESC, users.id DESC").
+		Offset(search.Skip).
+		Limit(search.Limit).
+		Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, user := range users {
+		u.SetSeen(user)
+	}
+	return users, total, nil
+}
+
+func (u *userGormRepository) ReplaceRoles(ctx context.Context, user *entity.User, roles []*entity.Role) error {
+	return u.db.WithContext(ctx).Model(user).Association("Roles").Replace(roles)
+}
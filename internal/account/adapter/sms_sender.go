@@ -0,0 +1,36 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+
+	"shikposh-backend/config"
+	"github.com/ali-mahdavi-dev/framework/infrastructure/logging"
+)
+
+// SmsSender delivers text messages such as one-time login codes.
+type SmsSender interface {
+	Send(ctx context.Context, phone, message string) error
+}
+
+// NewSmsSender returns the sender selected by sms.sender. Only the log sender
+// exists so far; an SMS gateway is plugged in here.
+func NewSmsSender(cfg config.SmsConfig) (SmsSender, error) {
+	switch cfg.Sender {
+	case "", "log":
+		return &logSmsSender{}, nil
+	default:
+		return nil, fmt.Errorf("NewSmsSender unknown sms sender %q", cfg.Sender)
+	}
+}
+
+// logSmsSender writes messages to the log instead of sending them.
+type logSmsSender struct{}
+
+func (s *logSmsSender) Send(ctx context.Context, phone, message string) error {
+	logging.Info("SMS sent").
+		WithString("phone", phone).
+		WithString("message", message).
+		Log()
+	return nil
+}
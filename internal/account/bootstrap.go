@@ -1,10 +1,18 @@
 package account
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
 	"shikposh-backend/config"
 	accountadapter "shikposh-backend/internal/account/adapter"
 	"shikposh-backend/internal/account/entrypoint"
 	"shikposh-backend/internal/account/entrypoint/handler"
+	"shikposh-backend/internal/account/query"
 	"shikposh-backend/internal/account/service_layer/command_handler"
 	"shikposh-backend/internal/account/service_layer/event_handler"
 	"github.com/ali-mahdavi-dev/framework/adapter"
@@ -13,12 +21,23 @@ import (
 	commandmiddleware "github.com/ali-mahdavi-dev/framework/service_layer/command_event_handler/command_middleware"
 	"github.com/ali-mahdavi-dev/framework/service_layer/messagebus"
 	"shikposh-backend/internal/unit_of_work"
+	"shikposh-backend/pkg/middleware"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/static"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
-func Bootstrap(router fiber.Router, db *gorm.DB, cfg *config.Config) error {
+const (
+	// identityProviderTimeout bounds every call to an identity provider.
+	identityProviderTimeout = 10 * time.Second
+	// storedFileMaxAge is how long clients may cache stored files in seconds.
+	// Every upload gets a new key, so a file never changes.
+	storedFileMaxAge = 365 * 24 * 60 * 60
+)
+
+func Bootstrap(router fiber.Router, db *gorm.DB, cfg *config.Config, mw *middleware.Middleware) error {
 	// Create event channel and unit of work for this module
 	eventCh := make(chan adapter.EventWithWaitGroup, 100)
 	uow := unitofwork.New(db, eventCh)
@@ -30,29 +49,261 @@ func Bootstrap(router fiber.Router, db *gorm.DB, cfg *config.Config) error {
 		return err
 	}
 
-	userHandler := command_handler.NewUserHandler(uow, cfg)
-	userEventHandler := event_handler.NewUserEventHandler(uow)
-	userController := handler.NewUserController(bus, ag, userHandler)
+	mailer, err := accountadapter.NewMailSender(cfg.Mail)
+	if err != nil {
+		logging.Error("Failed to initialize mail sender").WithError(err).Log()
+		return err
+	}
+
+	redisClient := newLazyRedisClient(cfg.Redis)
+
+	otpStore, err := newOtpStore(cfg, redisClient)
+	if err != nil {
+		logging.Error("Failed to initialize OTP store").WithError(err).Log()
+		return err
+	}
+
+	loginAttempts, err := newLoginAttemptStore(cfg, redisClient)
+	if err != nil {
+		logging.Error("Failed to initialize login attempt store").WithError(err).Log()
+		return err
+	}
+
+	sms, err := accountadapter.NewSmsSender(cfg.Sms)
+	if err != nil {
+		logging.Error("Failed to initialize SMS sender").WithError(err).Log()
+		return err
+	}
+
+	identityProviders, err := accountadapter.NewIdentityProviders(cfg.Oidc, &http.Client{Timeout: identityProviderTimeout})
+	if err != nil {
+		logging.Error("Failed to initialize identity providers").WithError(err).Log()
+		return err
+	}
+
+	storage, err := accountadapter.NewFileStorage(cfg.Storage)
+	if err != nil {
+		logging.Error("Failed to initialize file storage").WithError(err).Log()
+		return err
+	}
+	if err := serveStoredFiles(router, cfg.Storage); err != nil {
+		logging.Error("Failed to serve stored files").WithError(err).Log()
+		return err
+	}
+
+	userHandler := command_handler.NewUserHandler(uow, cfg, mw.Cfg.AccessTokens, loginAttempts)
+	otpHandler := command_handler.NewOtpHandler(uow, cfg, otpStore, sms, userHandler)
+	addressHandler := command_handler.NewAddressHandler(uow)
+	serviceAccountHandler := command_handler.NewServiceAccountHandler(uow)
+	oidcHandler := command_handler.NewOidcHandler(uow, cfg, identityProviders, userHandler)
+	avatarHandler := command_handler.NewAvatarHandler(uow, cfg, storage)
+	auditHandler := command_handler.NewAuditHandler(uow, cfg)
+	userEventHandler := event_handler.NewUserEventHandler(uow, cfg, mailer)
+	avatarEventHandler := event_handler.NewAvatarEventHandler(storage)
+	auditEventHandler := event_handler.NewAuditEventHandler(uow)
+	userController := handler.NewUserController(bus, ag, userHandler, mw)
+	otpController := handler.NewOtpController(bus, otpHandler, mw)
+	profileController := handler.NewProfileController(bus, query.NewUserQueryHandler(uow), query.NewProfileQueryHandler(uow), query.NewDataExportQueryHandler(uow), mw)
+	addressController := handler.NewAddressController(bus, addressHandler, query.NewAddressQueryHandler(uow), mw)
+	adminUserController := handler.NewAdminUserController(bus, query.NewUserQueryHandler(uow), mw)
+	serviceAccountController := handler.NewServiceAccountController(bus, serviceAccountHandler, query.NewServiceAccountQueryHandler(uow), mw)
 
 	entrypoint.NewAccountRouter(router, entrypoint.UserManagementRouter{
-		User: userController,
+		User:           userController,
+		Otp:            otpController,
+		Profile:        profileController,
+		Address:        addressController,
+		AdminUser:      adminUserController,
+		ServiceAccount: serviceAccountController,
+		Jwks:           handler.NewJwksController(mw.Cfg.AccessTokens),
+		Oidc:           handler.NewOidcController(oidcHandler, mw),
+		Audit:          handler.NewAuditController(query.NewAuditQueryHandler(uow), mw),
 	})
 
 	// register command middlewares
 	bus.AddCommandMiddleware(
 		commandmiddleware.Logging(),
+		mw.CommandAudit(),
+		mw.CommandAuthorization(),
 	)
 
 	// register command handlers
 	bus.AddCommandHandler(
 		commandeventhandler.NewCommandHandler(userHandler.RegisterHandler),
 		commandeventhandler.NewCommandHandler(userHandler.LogoutHandler),
+		commandeventhandler.NewCommandHandler(userHandler.VerifyEmailHandler),
+		commandeventhandler.NewCommandHandler(userHandler.ResendVerificationHandler),
+		commandeventhandler.NewCommandHandler(userHandler.RequestPasswordResetHandler),
+		commandeventhandler.NewCommandHandler(userHandler.ResetPasswordHandler),
+		commandeventhandler.NewCommandHandler(userHandler.ChangePasswordHandler),
+		commandeventhandler.NewCommandHandler(userHandler.UnlockAccountHandler),
+		commandeventhandler.NewCommandHandler(userHandler.UpdateUserHandler),
+		commandeventhandler.NewCommandHandler(userHandler.UpdateProfileHandler),
+		commandeventhandler.NewCommandHandler(userHandler.DeleteAccountHandler),
+		commandeventhandler.NewCommandHandler(userHandler.DisableUserHandler),
+		commandeventhandler.NewCommandHandler(userHandler.EnableUserHandler),
+		commandeventhandler.NewCommandHandler(userHandler.ForceLogoutHandler),
+		commandeventhandler.NewCommandHandler(userHandler.AdminResetPasswordHandler),
+		commandeventhandler.NewCommandHandler(userHandler.ChangeUserRolesHandler),
+		commandeventhandler.NewCommandHandler(avatarHandler.UploadAvatarHandler),
+		commandeventhandler.NewCommandHandler(avatarHandler.RemoveAvatarHandler),
+		commandeventhandler.NewCommandHandler(avatarHandler.RejectAvatarHandler),
+		commandeventhandler.NewCommandHandler(addressHandler.UpdateAddressHandler),
+		commandeventhandler.NewCommandHandler(addressHandler.DeleteAddressHandler),
+		commandeventhandler.NewCommandHandler(addressHandler.SetDefaultAddressHandler),
+		commandeventhandler.NewCommandHandler(serviceAccountHandler.RevokeApiKeyHandler),
+		commandeventhandler.NewCommandHandler(otpHandler.RequestOtpHandler),
 	)
 
 	// register event handlers
 	bus.AddEventHandler(
 		commandeventhandler.NewEventHandler(userEventHandler.RegisterEvent),
+		commandeventhandler.NewEventHandler(userEventHandler.SendVerificationEmail),
+		commandeventhandler.NewEventHandler(userEventHandler.ResendVerificationEmail),
+		commandeventhandler.NewEventHandler(userEventHandler.SendPasswordResetEmail),
+		commandeventhandler.NewEventHandler(userEventHandler.SendUnlockEmail),
+		commandeventhandler.NewEventHandler(userEventHandler.LogProfileUpdate),
+		commandeventhandler.NewEventHandler(userEventHandler.SendAccountDeletedEmail),
+		commandeventhandler.NewEventHandler(userEventHandler.LogUserDisabled),
+		commandeventhandler.NewEventHandler(userEventHandler.LogUserEnabled),
+		commandeventhandler.NewEventHandler(userEventHandler.LogUserLoggedOut),
+		commandeventhandler.NewEventHandler(userEventHandler.LogPasswordResetByAdmin),
+		commandeventhandler.NewEventHandler(userEventHandler.LogUserRolesChanged),
+		commandeventhandler.NewEventHandler(userEventHandler.LogServiceAccountCreated),
+		commandeventhandler.NewEventHandler(userEventHandler.LogApiKeyIssued),
+		commandeventhandler.NewEventHandler(userEventHandler.LogApiKeyRevoked),
+		commandeventhandler.NewEventHandler(userEventHandler.LogExternalIdentityLinked),
+		commandeventhandler.NewEventHandler(userEventHandler.LogAvatarRejected),
+		commandeventhandler.NewEventHandler(avatarEventHandler.DeleteDiscardedAvatar),
+		commandeventhandler.NewEventHandler(auditEventHandler.RecordUserLocked),
+		commandeventhandler.NewEventHandler(auditEventHandler.RecordExternalIdentityLinked),
 	)
 
+	if cfg.Account.PurgeInterval > 0 {
+		go purgeDeletedAccounts(context.Background(), userHandler, cfg.Account.PurgeInterval*time.Hour)
+	}
+	if cfg.Audit.PurgeInterval > 0 {
+		go purgeAuditEvents(context.Background(), auditHandler, cfg.Audit.PurgeInterval*time.Hour)
+	}
+
+	return nil
+}
+
+// purgeDeletedAccounts erases closed accounts whose grace period has passed
+// every interval. The same can be done by hand with `user purge-deleted`.
+func purgeDeletedAccounts(ctx context.Context, userHandler *command_handler.UserHandler, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			purged, err := userHandler.PurgeDeletedAccounts(ctx, now)
+			if err != nil {
+				logging.Error("Failed to purge deleted accounts").WithError(err).Log()
+				continue
+			}
+			if purged > 0 {
+				logging.Info("Deleted accounts purged").WithInt64("count", purged).Log()
+			}
+		}
+	}
+}
+
+// purgeAuditEvents deletes audit log entries past their retention every
+// interval. The same can be done by hand with `audit purge`.
+func purgeAuditEvents(ctx context.Context, auditHandler *command_handler.AuditHandler, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			purged, err := auditHandler.PurgeAuditEvents(ctx, now)
+			if err != nil {
+				logging.Error("Failed to purge audit events").WithError(err).Log()
+				continue
+			}
+			if purged > 0 {
+				logging.Info("Audit events purged").WithInt64("count", purged).Log()
+			}
+		}
+	}
+}
+
+// serveStoredFiles serves the files of the local storage under the path of
+// storage.baseURL. Other storages serve their files themselves.
+func serveStoredFiles(router fiber.Router, cfg config.StorageConfig) error {
+	if cfg.Driver != "" && cfg.Driver != "local" {
+		return nil
+	}
+
+	baseURL, err := url.Parse(cfg.BaseURL)
+	if err != nil {
+		return fmt.Errorf("invalid storage base URL %q: %w", cfg.BaseURL, err)
+	}
+	path := strings.TrimSuffix(baseURL.Path, "/")
+	if path == "" {
+		path = "/media"
+	}
+
+	router.Get(path+"/*", static.New(cfg.Dir, static.Config{MaxAge: storedFileMaxAge}))
 	return nil
 }
+
+// newLazyRedisClient connects on first use, so Redis is only required when
+// a store is configured to use it. Every store shares the one client.
+func newLazyRedisClient(cfg config.RedisConfig) func() (*redis.Client, error) {
+	var (
+		client *redis.Client
+		err    error
+	)
+
+	return func() (*redis.Client, error) {
+		if client == nil && err == nil {
+			client, err = accountadapter.NewRedisClient(context.Background(), cfg)
+		}
+		return client, err
+	}
+}
+
+// newOtpStore returns the store selected by otp.store. Codes live in memory
+// unless Redis is configured, which multi-instance deployments need.
+func newOtpStore(cfg *config.Config, redisClient func() (*redis.Client, error)) (accountadapter.OtpStore, error) {
+	switch cfg.Otp.Store {
+	case "", "memory":
+		return accountadapter.NewMemoryOtpStore(), nil
+	case "redis":
+		client, err := redisClient()
+		if err != nil {
+			return nil, err
+		}
+		return accountadapter.NewRedisOtpStore(client), nil
+	default:
+		return nil, fmt.Errorf("unknown otp store %q", cfg.Otp.Store)
+	}
+}
+
+// newLoginAttemptStore returns the store selected by login.store. The Redis
+// store falls back to counting in memory while Redis is unreachable.
+func newLoginAttemptStore(cfg *config.Config, redisClient func() (*redis.Client, error)) (accountadapter.LoginAttemptStore, error) {
+	switch cfg.Login.Store {
+	case "", "memory":
+		return accountadapter.NewMemoryLoginAttemptStore(), nil
+	case "redis":
+		client, err := redisClient()
+		if err != nil {
+			return nil, err
+		}
+		return accountadapter.NewFallbackLoginAttemptStore(
+			accountadapter.NewRedisLoginAttemptStore(client),
+			accountadapter.NewMemoryLoginAttemptStore(),
+		), nil
+	default:
+		return nil, fmt.Errorf("unknown login store %q", cfg.Login.Store)
+	}
+}
@@ -0,0 +1,38 @@
+package commands
+
+// The commands below are issued by an administrator, ActorID, on the account
+// of UserID.
+
+type DisableUser struct {
+	UserID  uint64 `json:"-"`
+	ActorID uint64 `json:"-"`
+}
+
+type EnableUser struct {
+	UserID  uint64 `json:"-"`
+	ActorID uint64 `json:"-"`
+}
+
+type ForceLogoutUser struct {
+	UserID  uint64 `json:"-"`
+	ActorID uint64 `json:"-"`
+}
+
+type AdminResetPassword struct {
+	UserID  uint64 `json:"-"`
+	ActorID uint64 `json:"-"`
+}
+
+// ChangeUserRoles replaces every role of the user with Roles.
+type ChangeUserRoles struct {
+	UserID  uint64   `json:"-"`
+	ActorID uint64   `json:"-"`
+	Roles   []string `json:"roles" validate:"dive,required"`
+}
+
+// RejectAvatar removes an uploaded avatar that breaks the content rules. The
+// user gets their generated avatar back.
+type RejectAvatar struct {
+	UserID  uint64 `json:"-"`
+	ActorID uint64 `json:"-"`
+}
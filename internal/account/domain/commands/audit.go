@@ -0,0 +1,109 @@
+package commands
+
+import "strconv"
+
+// Commands implementing AuditAction are recorded in the security audit log,
+// with the target returned by AuditTarget when they have one. Actions are
+// named after what was acted on, the admin ones prefixed with "admin.".
+
+const (
+	auditTargetUser           = "user"
+	auditTargetUserName       = "user_name"
+	auditTargetEmail          = "email"
+	auditTargetPhone          = "phone"
+	auditTargetProvider       = "identity_provider"
+	auditTargetServiceAccount = "service_account"
+	auditTargetApiKey         = "api_key"
+)
+
+func userTarget(id uint64) (string, string) {
+	return auditTargetUser, strconv.FormatUint(id, 10)
+}
+
+func (RegisterUser) AuditAction() string { return "user.register" }
+
+func (c RegisterUser) AuditTarget() (string, string) { return auditTargetUserName, c.UserName }
+
+func (LoginUser) AuditAction() string { return "user.login" }
+
+func (c LoginUser) AuditTarget() (string, string) { return auditTargetUserName, c.UserName }
+
+func (VerifyTwoFactor) AuditAction() string { return "user.login.two_factor" }
+
+func (VerifyOtp) AuditAction() string { return "user.login.otp" }
+
+func (c VerifyOtp) AuditTarget() (string, string) { return auditTargetPhone, c.Phone }
+
+func (CompleteOidcLogin) AuditAction() string { return "user.login.oidc" }
+
+func (c CompleteOidcLogin) AuditTarget() (string, string) { return auditTargetProvider, c.Provider }
+
+func (Logout) AuditAction() string { return "user.logout" }
+
+func (c Logout) AuditTarget() (string, string) { return userTarget(c.UserID) }
+
+func (VerifyEmail) AuditAction() string { return "user.email.verify" }
+
+func (RequestPasswordReset) AuditAction() string { return "user.password.reset_request" }
+
+func (c RequestPasswordReset) AuditTarget() (string, string) { return auditTargetEmail, c.Email }
+
+func (ResetPassword) AuditAction() string { return "user.password.reset" }
+
+func (ChangePassword) AuditAction() string { return "user.password.change" }
+
+func (c ChangePassword) AuditTarget() (string, string) { return userTarget(c.UserID) }
+
+func (UnlockAccount) AuditAction() string { return "user.unlock" }
+
+func (UpdateUser) AuditAction() string { return "user.update" }
+
+func (c UpdateUser) AuditTarget() (string, string) { return userTarget(c.UserID) }
+
+func (DeleteAccount) AuditAction() string { return "user.delete" }
+
+func (c DeleteAccount) AuditTarget() (string, string) { return userTarget(c.UserID) }
+
+func (ConfirmTotp) AuditAction() string { return "user.two_factor.enable" }
+
+func (c ConfirmTotp) AuditTarget() (string, string) { return userTarget(c.UserID) }
+
+func (DisableUser) AuditAction() string { return "admin.user.disable" }
+
+func (c DisableUser) AuditTarget() (string, string) { return userTarget(c.UserID) }
+
+func (EnableUser) AuditAction() string { return "admin.user.enable" }
+
+func (c EnableUser) AuditTarget() (string, string) { return userTarget(c.UserID) }
+
+func (ForceLogoutUser) AuditAction() string { return "admin.user.logout" }
+
+func (c ForceLogoutUser) AuditTarget() (string, string) { return userTarget(c.UserID) }
+
+func (AdminResetPassword) AuditAction() string { return "admin.user.password_reset" }
+
+func (c AdminResetPassword) AuditTarget() (string, string) { return userTarget(c.UserID) }
+
+func (ChangeUserRoles) AuditAction() string { return "admin.user.roles.change" }
+
+func (c ChangeUserRoles) AuditTarget() (string, string) { return userTarget(c.UserID) }
+
+func (RejectAvatar) AuditAction() string { return "admin.user.avatar.reject" }
+
+func (c RejectAvatar) AuditTarget() (string, string) { return userTarget(c.UserID) }
+
+func (CreateServiceAccount) AuditAction() string { return "admin.service_account.create" }
+
+func (c CreateServiceAccount) AuditTarget() (string, string) { return auditTargetServiceAccount, c.Name }
+
+func (IssueApiKey) AuditAction() string { return "admin.api_key.issue" }
+
+func (c IssueApiKey) AuditTarget() (string, string) {
+	return auditTargetServiceAccount, strconv.FormatUint(c.ServiceAccountID, 10)
+}
+
+func (RevokeApiKey) AuditAction() string { return "admin.api_key.revoke" }
+
+func (c RevokeApiKey) AuditTarget() (string, string) {
+	return auditTargetApiKey, strconv.FormatUint(c.ApiKeyID, 10)
+}
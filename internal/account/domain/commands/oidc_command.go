@@ -0,0 +1,16 @@
+package commands
+
+// StartOidcLogin sends the user to the identity provider named Provider.
+type StartOidcLogin struct {
+	Provider string `json:"-"`
+}
+
+// CompleteOidcLogin redeems the code and state the provider sent the user
+// back with.
+type CompleteOidcLogin struct {
+	Provider  string `json:"-"`
+	Code      string `json:"code" validate:"required"`
+	State     string `json:"state" validate:"required"`
+	UserAgent string `json:"-"`
+	IP        string `json:"-"`
+}
@@ -0,0 +1,44 @@
+package commands
+
+import "slices"
+
+const (
+	// UsersReadPermission is required to list and view user accounts.
+	UsersReadPermission = "users:read"
+	// UsersWritePermission is required to manage user accounts and their roles.
+	UsersWritePermission = "users:write"
+	// ServiceAccountsWritePermission is required to manage service accounts
+	// and their API keys.
+	ServiceAccountsWritePermission = "service-accounts:write"
+	// AuditReadPermission is required to view the security audit log.
+	AuditReadPermission = "audit:read"
+)
+
+// accountAdministrationPermissions are never granted to service accounts.
+// The self-management guards and the audit log need a user behind every
+// change of an account.
+var accountAdministrationPermissions = []string{UsersWritePermission, ServiceAccountsWritePermission}
+
+// IsAssignableScope reports whether a service account may be granted the
+// permission.
+func IsAssignableScope(permission string) bool {
+	return !slices.Contains(accountAdministrationPermissions, permission)
+}
+
+func (DisableUser) RequiredPermission() string { return UsersWritePermission }
+
+func (EnableUser) RequiredPermission() string { return UsersWritePermission }
+
+func (ForceLogoutUser) RequiredPermission() string { return UsersWritePermission }
+
+func (AdminResetPassword) RequiredPermission() string { return UsersWritePermission }
+
+func (ChangeUserRoles) RequiredPermission() string { return UsersWritePermission }
+
+func (RejectAvatar) RequiredPermission() string { return UsersWritePermission }
+
+func (CreateServiceAccount) RequiredPermission() string { return ServiceAccountsWritePermission }
+
+func (IssueApiKey) RequiredPermission() string { return ServiceAccountsWritePermission }
+
+func (RevokeApiKey) RequiredPermission() string { return ServiceAccountsWritePermission }
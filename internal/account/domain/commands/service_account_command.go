@@ -0,0 +1,28 @@
+package commands
+
+import "time"
+
+// The commands below are issued by an administrator, ActorID.
+
+// CreateServiceAccount creates a service account granted the permissions
+// named in Scopes.
+type CreateServiceAccount struct {
+	Name        string   `json:"name" validate:"required,max=64"`
+	Description string   `json:"description"`
+	Scopes      []string `json:"scopes" validate:"required,min=1,dive,required"`
+	ActorID     uint64   `json:"-"`
+}
+
+// IssueApiKey issues a new key of the service account. A key without
+// ExpiresAt never expires.
+type IssueApiKey struct {
+	ServiceAccountID uint64     `json:"-"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+	ActorID          uint64     `json:"-"`
+}
+
+type RevokeApiKey struct {
+	ServiceAccountID uint64 `json:"-"`
+	ApiKeyID         uint64 `json:"-"`
+	ActorID          uint64 `json:"-"`
+}
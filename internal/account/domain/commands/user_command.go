@@ -11,10 +11,153 @@ type RegisterUser struct {
 }
 
 type LoginUser struct {
-	UserName string `json:"user_name" validate:"required"`
-	Password string `json:"password" validate:"required"`
+	UserName  string `json:"user_name" validate:"required"`
+	Password  string `json:"password" validate:"required"`
+	UserAgent string `json:"-"`
+	IP        string `json:"-"`
+}
+
+type RefreshToken struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+	UserAgent    string `json:"-"`
+	IP           string `json:"-"`
 }
 
 type Logout struct {
-	UserID uint64 `json:"user_id" validate:"required"`
+	UserID    uint64 `json:"user_id" validate:"required"`
+	SessionID uint64 `json:"session_id" validate:"required"`
+}
+
+type AssignRole struct {
+	UserName string `json:"user_name" validate:"required"`
+	Role     string `json:"role" validate:"required"`
+}
+
+type VerifyEmail struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResendVerificationEmail struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type RequestPasswordReset struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPassword struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+type ChangePassword struct {
+	UserID          uint64 `json:"-"`
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+// DeleteAccount closes the account of a user. Password is only required from
+// users who have one; users who sign in by OTP alone leave it empty.
+type DeleteAccount struct {
+	UserID   uint64 `json:"-"`
+	Password string `json:"password"`
+}
+
+type RequestOtp struct {
+	Phone string `json:"phone" validate:"required"`
+}
+
+type VerifyOtp struct {
+	Phone     string `json:"phone" validate:"required"`
+	Code      string `json:"code" validate:"required,numeric"`
+	UserAgent string `json:"-"`
+	IP        string `json:"-"`
+}
+
+type EnrollTotp struct {
+	UserID uint64 `json:"-"`
+}
+
+type ConfirmTotp struct {
+	UserID uint64 `json:"-"`
+	Code   string `json:"code" validate:"required,numeric,len=6"`
+}
+
+// VerifyTwoFactor completes a login that returned a two-factor challenge.
+// Either a TOTP code or one of the recovery codes is accepted.
+type VerifyTwoFactor struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+	UserAgent      string `json:"-"`
+	IP             string `json:"-"`
+}
+
+type UnlockAccount struct {
+	Token string `json:"token" validate:"required"`
+}
+
+
+// UpdateUser edits the account details of the authenticated user. Omitted
+// fields are left unchanged.
+type UpdateUser struct {
+	UserID    uint64  `json:"-"`
+	FirstName *string `json:"first_name" validate:"omitnil,min=3"`
+	LastName  *string `json:"last_name" validate:"omitnil,min=3"`
+	Email     *string `json:"email" validate:"omitnil,email"`
+}
+
+// UpdateProfile edits the profile of the authenticated user. Omitted fields
+// are left unchanged and an empty phone removes the number.
+type UpdateProfile struct {
+	UserID uint64  `json:"-"`
+	Bio    *string `json:"bio" validate:"omitnil,max=500"`
+	Phone  *string `json:"phone"`
+}
+
+// SaveAddress holds the fields of an address in the address book. Phone and
+// postal code are normalised before they are stored.
+type SaveAddress struct {
+	RecipientName string   `json:"recipient_name" validate:"required,max=255"`
+	Phone         string   `json:"phone" validate:"required"`
+	Province      string   `json:"province" validate:"required,max=255"`
+	City          string   `json:"city" validate:"required,max=255"`
+	PostalCode    string   `json:"postal_code" validate:"required"`
+	Street        string   `json:"street" validate:"required,max=1000"`
+	Plate         string   `json:"plate" validate:"required,max=32"`
+	Unit          string   `json:"unit" validate:"max=32"`
+	Latitude      *float64 `json:"latitude" validate:"omitnil,min=-90,max=90"`
+	Longitude     *float64 `json:"longitude" validate:"omitnil,min=-180,max=180"`
+	IsDefault     bool     `json:"is_default"`
+}
+
+type CreateAddress struct {
+	SaveAddress
+	UserID uint64 `json:"-"`
+}
+
+type UpdateAddress struct {
+	SaveAddress
+	UserID    uint64 `json:"-"`
+	AddressID uint64 `json:"-"`
+}
+
+type DeleteAddress struct {
+	UserID    uint64 `json:"-"`
+	AddressID uint64 `json:"-"`
+}
+
+type SetDefaultAddress struct {
+	UserID    uint64 `json:"-"`
+	AddressID uint64 `json:"-"`
+}
+
+// UploadAvatar replaces the avatar of the user with the uploaded image.
+type UploadAvatar struct {
+	UserID  uint64 `json:"-"`
+	Content []byte `json:"-"`
+}
+
+// RemoveAvatar brings back the generated avatar of the user.
+type RemoveAvatar struct {
+	UserID uint64 `json:"-"`
 }
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"gorm.io/gorm"
+)
+
+type AccountUnlockTokenID uint64
+
+// AccountUnlockToken lets the owner of a locked account unlock it from the
+// link mailed on lockout. Only the hash of the token is stored.
+type AccountUnlockToken struct {
+	adapter.BaseEntity
+	ID        AccountUnlockTokenID `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	UserID    UserID         `json:"user_id" gorm:"user_id"`
+	TokenHash string         `json:"-" gorm:"token_hash"`
+	ExpiresAt time.Time      `json:"expires_at" gorm:"expires_at"`
+	UsedAt    *time.Time     `json:"used_at" gorm:"used_at"`
+}
+
+// NewAccountUnlockToken generates an unlock token for the user and returns
+// the entity together with the plain token value.
+func NewAccountUnlockToken(userID UserID, expiresAt time.Time) (*AccountUnlockToken, string, error) {
+	plain, err := newOpaqueToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("NewAccountUnlockToken fail generate random token: %w", err)
+	}
+
+	return &AccountUnlockToken{
+		UserID:    userID,
+		TokenHash: HashToken(plain),
+		ExpiresAt: expiresAt,
+	}, plain, nil
+}
+
+// IsUsable reports whether the token can still unlock the account.
+func (t *AccountUnlockToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
+
+func (t *AccountUnlockToken) MarkUsed(now time.Time) {
+	t.UsedAt = &now
+}
@@ -0,0 +1,113 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidPostalCode = errors.New("invalid postal code")
+
+type AddressID uint64
+
+// Address is a shipping address in the address book of a user. At most one
+// address of a user is the default one.
+type Address struct {
+	adapter.BaseEntity
+	ID            AddressID `gorm:"primaryKey"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
+	UserID        UserID         `json:"user_id" gorm:"user_id;index"`
+	RecipientName string         `json:"recipient_name" gorm:"recipient_name"`
+	Phone         string         `json:"phone" gorm:"phone"`
+	Province      string         `json:"province" gorm:"province"`
+	City          string         `json:"city" gorm:"city"`
+	PostalCode    string         `json:"postal_code" gorm:"postal_code"`
+	Street        string         `json:"street" gorm:"street"`
+	Plate         string         `json:"plate" gorm:"plate"`
+	Unit          string         `json:"unit" gorm:"unit"`
+	Latitude      *float64       `json:"latitude,omitempty" gorm:"latitude"`
+	Longitude     *float64       `json:"longitude,omitempty" gorm:"longitude"`
+	IsDefault     bool           `json:"is_default" gorm:"is_default"`
+}
+
+// AddressFields holds the fields of an address that its owner can edit.
+// Phone and PostalCode are expected in the forms returned by NormalizePhone
+// and NormalizePostalCode.
+type AddressFields struct {
+	RecipientName string
+	Phone         string
+	Province      string
+	City          string
+	PostalCode    string
+	Street        string
+	Plate         string
+	Unit          string
+	Latitude      *float64
+	Longitude     *float64
+}
+
+func NewAddress(userID UserID, fields AddressFields) *Address {
+	address := &Address{UserID: userID}
+	address.Update(fields)
+	return address
+}
+
+// Update replaces the editable fields of the address.
+func (a *Address) Update(fields AddressFields) {
+	a.RecipientName = fields.RecipientName
+	a.Phone = fields.Phone
+	a.Province = fields.Province
+	a.City = fields.City
+	a.PostalCode = fields.PostalCode
+	a.Street = fields.Street
+	a.Plate = fields.Plate
+	a.Unit = fields.Unit
+	a.Latitude = fields.Latitude
+	a.Longitude = fields.Longitude
+}
+
+// NormalizePostalCode converts a postal code written with Latin, Persian or
+// Arabic digits, optionally split by a dash or spaces, into its 10 digits.
+// Iranian postal codes never contain 0 or 2 in the first four digits, never
+// contain 2 after them, never have 0, 2 or 5 as the fifth digit and never
+// start with the same digit four times.
+func NormalizePostalCode(code string) (string, error) {
+	digits := make([]byte, 0, 10)
+	for _, r := range code {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = append(digits, byte(r))
+		case r >= '۰' && r <= '۹':
+			digits = append(digits, byte('0'+r-'۰'))
+		case r >= '٠' && r <= '٩':
+			digits = append(digits, byte('0'+r-'٠'))
+		case r == ' ' || r == '-':
+		default:
+			return "", ErrInvalidPostalCode
+		}
+	}
+
+	if len(digits) != 10 {
+		return "", ErrInvalidPostalCode
+	}
+	if digits[0] == digits[1] && digits[1] == digits[2] && digits[2] == digits[3] {
+		return "", ErrInvalidPostalCode
+	}
+	for i, d := range digits {
+		switch {
+		case d == '2':
+			return "", ErrInvalidPostalCode
+		case i < 4 && d == '0':
+			return "", ErrInvalidPostalCode
+		case i == 4 && (d == '0' || d == '5'):
+			return "", ErrInvalidPostalCode
+		}
+	}
+
+	return string(digits), nil
+}
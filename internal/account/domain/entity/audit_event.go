@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/ali-mahdavi-dev/framework/adapter"
+)
+
+type AuditEventID uint64
+
+// AuditOutcome tells whether an audited action went through. Denied actions
+// were refused for lack of credentials or permission; failed ones were
+// rejected for any other reason.
+type AuditOutcome string
+
+const (
+	AuditSuccess AuditOutcome = "success"
+	AuditDenied  AuditOutcome = "denied"
+	AuditFailure AuditOutcome = "failure"
+)
+
+// AuditEvent is an entry of the security audit log. Entries are never
+// changed; they are only deleted once older than audit.retentionDays.
+//
+// The actor is who acted, "user" or "service" with its ID, and is empty for
+// anonymous callers. The target is what was acted on, e.g. "user" and its ID.
+// IP, UserAgent and RequestID are empty for entries recorded outside a request.
+type AuditEvent struct {
+	adapter.BaseEntity
+	ID         AuditEventID `json:"id" gorm:"primaryKey"`
+	OccurredAt time.Time    `json:"occurred_at" gorm:"occurred_at"`
+	ActorType  string       `json:"actor_type,omitempty" gorm:"actor_type"`
+	ActorID    *uint64      `json:"actor_id,omitempty" gorm:"actor_id"`
+	Action     string       `json:"action" gorm:"action"`
+	TargetType string       `json:"target_type,omitempty" gorm:"target_type"`
+	TargetID   string       `json:"target_id,omitempty" gorm:"target_id"`
+	IP         string       `json:"ip,omitempty" gorm:"ip"`
+	UserAgent  string       `json:"user_agent,omitempty" gorm:"user_agent"`
+	RequestID  string       `json:"request_id,omitempty" gorm:"request_id"`
+	Outcome    AuditOutcome `json:"outcome" gorm:"outcome"`
+	// Reason is the error type of denied and failed actions.
+	Reason string `json:"reason,omitempty" gorm:"reason"`
+}
+
+func NewAuditEvent(action string, outcome AuditOutcome, now time.Time) *AuditEvent {
+	return &AuditEvent{
+		Action:     action,
+		Outcome:    outcome,
+		OccurredAt: now,
+	}
+}
+
+// ActedBy records the actor of the entry.
+func (e *AuditEvent) ActedBy(actorType string, actorID uint64) *AuditEvent {
+	e.ActorType = actorType
+	e.ActorID = &actorID
+	return e
+}
+
+// On records the target of the entry.
+func (e *AuditEvent) On(targetType, targetID string) *AuditEvent {
+	e.TargetType = targetType
+	e.TargetID = targetID
+	return e
+}
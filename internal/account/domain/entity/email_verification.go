@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"gorm.io/gorm"
+)
+
+type EmailVerificationTokenID uint64
+
+// EmailVerificationToken proves the ownership of the email address it was
+// sent to. Only the hash of the token is stored.
+type EmailVerificationToken struct {
+	adapter.BaseEntity
+	ID        EmailVerificationTokenID `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	UserID    UserID         `json:"user_id" gorm:"user_id"`
+	Email     string         `json:"email" gorm:"email"`
+	TokenHash string         `json:"-" gorm:"token_hash"`
+	ExpiresAt time.Time      `json:"expires_at" gorm:"expires_at"`
+	UsedAt    *time.Time     `json:"used_at" gorm:"used_at"`
+}
+
+// NewEmailVerificationToken generates a token for the given address and
+// returns the entity together with the plain token value.
+func NewEmailVerificationToken(userID UserID, email string, expiresAt time.Time) (*EmailVerificationToken, string, error) {
+	plain, err := newOpaqueToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("NewEmailVerificationToken fail generate random token: %w", err)
+	}
+
+	return &EmailVerificationToken{
+		UserID:    userID,
+		Email:     email,
+		TokenHash: HashToken(plain),
+		ExpiresAt: expiresAt,
+	}, plain, nil
+}
+
+// IsUsable reports whether the token can still verify its email address.
+func (t *EmailVerificationToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
+
+func (t *EmailVerificationToken) MarkUsed(now time.Time) {
+	t.UsedAt = &now
+}
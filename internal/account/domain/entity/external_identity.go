@@ -0,0 +1,104 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"shikposh-backend/internal/account/domain/events"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"gorm.io/gorm"
+)
+
+type ExternalIdentityID uint64
+type OidcAuthorizationID uint64
+
+// ExternalIdentity links a user to their account at an identity provider.
+// Subject is the provider's ID of that account; the email is kept only to
+// show which account is linked.
+type ExternalIdentity struct {
+	adapter.BaseEntity
+	ID        ExternalIdentityID `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	UserID    UserID         `json:"user_id" gorm:"user_id"`
+	Provider  string         `json:"provider" gorm:"provider"`
+	Subject   string         `json:"subject" gorm:"subject"`
+	Email     string         `json:"email" gorm:"email"`
+}
+
+func NewExternalIdentity(userID UserID, provider, subject, email string) *ExternalIdentity {
+	identity := &ExternalIdentity{
+		UserID:   userID,
+		Provider: provider,
+		Subject:  subject,
+		Email:    email,
+	}
+
+	identity.AddEvent(&events.ExternalIdentityLinkedEvent{
+		UserID:   uint64(userID),
+		Provider: provider,
+	})
+
+	return identity
+}
+
+// OidcAuthorization remembers a sign-in that was sent to an identity provider
+// until the user comes back with a code. Only the hash of the state is
+// stored; the nonce and the PKCE code verifier never leave the server.
+type OidcAuthorization struct {
+	adapter.BaseEntity
+	ID           OidcAuthorizationID `gorm:"primaryKey"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	Provider     string         `json:"provider" gorm:"provider"`
+	StateHash    string         `json:"-" gorm:"state_hash"`
+	Nonce        string         `json:"-" gorm:"nonce"`
+	CodeVerifier string         `json:"-" gorm:"code_verifier"`
+	ExpiresAt    time.Time      `json:"expires_at" gorm:"expires_at"`
+	UsedAt       *time.Time     `json:"used_at" gorm:"used_at"`
+}
+
+// NewOidcAuthorization returns the authorization together with its plain
+// state, which travels to the provider and back.
+func NewOidcAuthorization(provider string, expiresAt time.Time) (*OidcAuthorization, string, error) {
+	state, err := newOpaqueToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("NewOidcAuthorization fail generate state: %w", err)
+	}
+	nonce, err := newOpaqueToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("NewOidcAuthorization fail generate nonce: %w", err)
+	}
+	verifier, err := newOpaqueToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("NewOidcAuthorization fail generate code verifier: %w", err)
+	}
+
+	return &OidcAuthorization{
+		Provider:     provider,
+		StateHash:    HashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    expiresAt,
+	}, state, nil
+}
+
+// CodeChallenge is the S256 PKCE challenge of the code verifier.
+func (a *OidcAuthorization) CodeChallenge() string {
+	sum := sha256.Sum256([]byte(a.CodeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// IsUsable reports whether the user can still come back from the provider.
+func (a *OidcAuthorization) IsUsable(now time.Time) bool {
+	return a.UsedAt == nil && now.Before(a.ExpiresAt)
+}
+
+func (a *OidcAuthorization) MarkUsed(now time.Time) {
+	a.UsedAt = &now
+}
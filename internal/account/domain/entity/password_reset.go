@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"gorm.io/gorm"
+)
+
+type PasswordResetTokenID uint64
+
+// PasswordResetToken lets the owner of an email address choose a new password
+// once. Only the hash of the token is stored.
+type PasswordResetToken struct {
+	adapter.BaseEntity
+	ID        PasswordResetTokenID `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	UserID    UserID         `json:"user_id" gorm:"user_id"`
+	TokenHash string         `json:"-" gorm:"token_hash"`
+	ExpiresAt time.Time      `json:"expires_at" gorm:"expires_at"`
+	UsedAt    *time.Time     `json:"used_at" gorm:"used_at"`
+}
+
+// NewPasswordResetToken generates a reset token for the user and returns the
+// entity together with the plain token value.
+func NewPasswordResetToken(userID UserID, expiresAt time.Time) (*PasswordResetToken, string, error) {
+	plain, err := newOpaqueToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("NewPasswordResetToken fail generate random token: %w", err)
+	}
+
+	return &PasswordResetToken{
+		UserID:    userID,
+		TokenHash: HashToken(plain),
+		ExpiresAt: expiresAt,
+	}, plain, nil
+}
+
+// IsUsable reports whether the token can still reset the password.
+func (t *PasswordResetToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
+
+func (t *PasswordResetToken) MarkUsed(now time.Time) {
+	t.UsedAt = &now
+}
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidPhone = errors.New("invalid mobile phone number")
+
+// NormalizePhone converts an Iranian mobile number written as 0912..., +98912...,
+// 0098912... or 98912..., with Latin, Persian or Arabic digits, into the
+// E.164 form +989xxxxxxxxx used to store and look up phone numbers.
+func NormalizePhone(phone string) (string, error) {
+	var digits strings.Builder
+	for _, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r >= '۰' && r <= '۹':
+			digits.WriteRune('0' + r - '۰')
+		case r >= '٠' && r <= '٩':
+			digits.WriteRune('0' + r - '٠')
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '+':
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+
+	normalized := digits.String()
+	switch {
+	case strings.HasPrefix(normalized, "0098"):
+		normalized = "0" + normalized[4:]
+	case strings.HasPrefix(normalized, "98"):
+		normalized = "0" + normalized[2:]
+	case strings.HasPrefix(normalized, "9"):
+		normalized = "0" + normalized
+	}
+
+	if len(normalized) != 11 || !strings.HasPrefix(normalized, "09") {
+		return "", ErrInvalidPhone
+	}
+
+	return "+98" + normalized[1:], nil
+}
@@ -3,6 +3,7 @@ package entity
 import (
 	"time"
 
+	"shikposh-backend/internal/account/domain/events"
 	"github.com/ali-mahdavi-dev/framework/adapter"
 
 	"gorm.io/gorm"
@@ -19,7 +20,6 @@ type Profile struct {
 	UserID    UserID         `json:"user_id" gorm:"user_id;uniqueIndex"`
 	Bio       string         `json:"bio" gorm:"bio"`
 	Phone     string         `json:"phone" gorm:"phone"`
-	Address   string         `json:"address" gorm:"address"`
 }
 
 func NewProfile(userID UserID) *Profile {
@@ -27,3 +27,25 @@ func NewProfile(userID UserID) *Profile {
 		UserID: userID,
 	}
 }
+
+// Update applies the given changes; nil leaves a field as it is. The phone
+// number is expected in the form returned by NormalizePhone.
+func (p *Profile) Update(bio, phone *string) {
+	var fields []string
+
+	if bio != nil && *bio != p.Bio {
+		p.Bio = *bio
+		fields = append(fields, "bio")
+	}
+	if phone != nil && *phone != p.Phone {
+		p.Phone = *phone
+		fields = append(fields, "phone")
+	}
+
+	if len(fields) > 0 {
+		p.AddEvent(&events.ProfileUpdatedEvent{
+			UserID: uint64(p.UserID),
+			Fields: fields,
+		})
+	}
+}
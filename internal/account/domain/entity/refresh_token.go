@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"gorm.io/gorm"
+)
+
+type RefreshTokenID uint64
+
+// RefreshToken is a single-use token of a session's token family. Only the
+// SHA-256 hash of the token is stored; the plain value is handed to the client
+// once and exchanged for a new pair on every refresh.
+type RefreshToken struct {
+	adapter.BaseEntity
+	ID        RefreshTokenID `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	SessionID SessionID      `json:"session_id" gorm:"session_id"`
+	UserID    UserID         `json:"user_id" gorm:"user_id"`
+	TokenHash string         `json:"-" gorm:"token_hash"`
+	ExpiresAt time.Time      `json:"expires_at" gorm:"expires_at"`
+	UsedAt    *time.Time     `json:"used_at" gorm:"used_at"`
+	RevokedAt *time.Time     `json:"revoked_at" gorm:"revoked_at"`
+}
+
+// NewRefreshToken generates a random refresh token for the given session and
+// returns the entity together with the plain token value.
+func NewRefreshToken(sessionID SessionID, userID UserID, expiresAt time.Time) (*RefreshToken, string, error) {
+	plain, err := newOpaqueToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("NewRefreshToken fail generate random token: %w", err)
+	}
+
+	return &RefreshToken{
+		SessionID: sessionID,
+		UserID:    userID,
+		TokenHash: HashToken(plain),
+		ExpiresAt: expiresAt,
+	}, plain, nil
+}
+
+// IsUsable reports whether the token can be exchanged for a new pair.
+func (t *RefreshToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// IsReused reports whether an already rotated token is presented again. A token
+// revoked by logout is merely unusable, not reused.
+func (t *RefreshToken) IsReused() bool {
+	return t.UsedAt != nil
+}
+
+func (t *RefreshToken) MarkUsed(now time.Time) {
+	t.UsedAt = &now
+}
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"gorm.io/gorm"
+)
+
+type RoleID uint64
+type PermissionID uint64
+
+// Built-in roles seeded by the account migrations.
+const (
+	RoleAdmin          = "admin"
+	RoleCatalogManager = "catalog-manager"
+	RoleSupport        = "support"
+)
+
+type Role struct {
+	adapter.BaseEntity
+	ID          RoleID `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	Name        string         `json:"name" gorm:"name"`
+	Description string         `json:"description" gorm:"description"`
+	Permissions []*Permission  `json:"permissions,omitempty" gorm:"many2many:role_permissions"`
+}
+
+// Permission is a single capability in the form "<resource>:<action>",
+// e.g. "products:write".
+type Permission struct {
+	adapter.BaseEntity
+	ID          PermissionID `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	Name        string         `json:"name" gorm:"name"`
+	Description string         `json:"description" gorm:"description"`
+}
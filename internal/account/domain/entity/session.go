@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"gorm.io/gorm"
+)
+
+type SessionID uint64
+
+// Session represents a single logged-in device. Every successful login opens a
+// new session; access and refresh tokens are always bound to one of them.
+type Session struct {
+	adapter.BaseEntity
+	ID         SessionID `gorm:"primaryKey"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  gorm.DeletedAt `gorm:"index"`
+	UserID     UserID         `json:"user_id" gorm:"user_id"`
+	UserAgent  string         `json:"user_agent" gorm:"user_agent"`
+	IP         string         `json:"ip" gorm:"ip"`
+	LastSeenAt time.Time      `json:"last_seen_at" gorm:"last_seen_at"`
+	ExpiresAt  time.Time      `json:"expires_at" gorm:"expires_at"`
+	RevokedAt  *time.Time     `json:"revoked_at" gorm:"revoked_at"`
+}
+
+func NewSession(userID UserID, userAgent, ip string, expiresAt time.Time) *Session {
+	return &Session{
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IP:         ip,
+		LastSeenAt: time.Now(),
+		ExpiresAt:  expiresAt,
+	}
+}
+
+// IsActive reports whether the session can still be used to authenticate.
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// Touch records activity from the device that owns the session.
+func (s *Session) Touch(userAgent, ip string, now time.Time) {
+	if userAgent != "" {
+		s.UserAgent = userAgent
+	}
+	if ip != "" {
+		s.IP = ip
+	}
+	s.LastSeenAt = now
+}
+
+func (s *Session) Revoke(now time.Time) {
+	if s.RevokedAt == nil {
+		s.RevokedAt = &now
+	}
+}
@@ -0,0 +1,110 @@
+package entity
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactorChallengeID uint64
+type RecoveryCodeID uint64
+
+// MaxTwoFactorAttempts is the number of wrong codes a challenge accepts
+// before the user has to log in with their password again.
+const MaxTwoFactorAttempts = 5
+
+// recoveryCodeCount is the number of recovery codes issued on enrollment.
+const recoveryCodeCount = 10
+
+// TwoFactorChallenge is handed out by a password login of a user with
+// two-factor authentication enabled. It is exchanged for a session once the
+// second factor is verified. Only the hash of the token is stored.
+type TwoFactorChallenge struct {
+	adapter.BaseEntity
+	ID        TwoFactorChallengeID `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	UserID    UserID         `json:"user_id" gorm:"user_id"`
+	TokenHash string         `json:"-" gorm:"token_hash"`
+	Attempts  int            `json:"attempts" gorm:"attempts"`
+	ExpiresAt time.Time      `json:"expires_at" gorm:"expires_at"`
+	UsedAt    *time.Time     `json:"used_at" gorm:"used_at"`
+}
+
+// NewTwoFactorChallenge returns the challenge together with its plain token.
+func NewTwoFactorChallenge(userID UserID, expiresAt time.Time) (*TwoFactorChallenge, string, error) {
+	plain, err := newOpaqueToken()
+	if err != nil {
+		return nil, "", fmt.Errorf("NewTwoFactorChallenge fail generate random token: %w", err)
+	}
+
+	return &TwoFactorChallenge{
+		UserID:    userID,
+		TokenHash: HashToken(plain),
+		ExpiresAt: expiresAt,
+	}, plain, nil
+}
+
+// IsUsable reports whether the challenge still accepts a second factor.
+func (c *TwoFactorChallenge) IsUsable(now time.Time) bool {
+	return c.UsedAt == nil && c.Attempts < MaxTwoFactorAttempts && now.Before(c.ExpiresAt)
+}
+
+func (c *TwoFactorChallenge) RecordFailedAttempt() {
+	c.Attempts++
+}
+
+func (c *TwoFactorChallenge) MarkUsed(now time.Time) {
+	c.UsedAt = &now
+}
+
+// RecoveryCode replaces the authenticator app once, e.g. after losing the
+// phone. Only the hash of the code is stored.
+type RecoveryCode struct {
+	adapter.BaseEntity
+	ID        RecoveryCodeID `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	UserID    UserID         `json:"user_id" gorm:"user_id"`
+	CodeHash  string         `json:"-" gorm:"code_hash"`
+	UsedAt    *time.Time     `json:"used_at" gorm:"used_at"`
+}
+
+// NewRecoveryCodes generates a fresh set of recovery codes and returns the
+// entities together with the plain codes, which are shown to the user once.
+func NewRecoveryCodes(userID UserID) ([]*RecoveryCode, []string, error) {
+	codes := make([]*RecoveryCode, 0, recoveryCodeCount)
+	plains := make([]string, 0, recoveryCodeCount)
+
+	for range recoveryCodeCount {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("NewRecoveryCodes fail read random bytes: %w", err)
+		}
+
+		encoded := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+		plain := encoded[:4] + "-" + encoded[4:]
+
+		codes = append(codes, &RecoveryCode{UserID: userID, CodeHash: HashRecoveryCode(plain)})
+		plains = append(plains, plain)
+	}
+
+	return codes, plains, nil
+}
+
+// HashRecoveryCode ignores case and dashes, so codes can be typed loosely.
+func HashRecoveryCode(code string) string {
+	return HashToken(strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", "")))
+}
+
+func (c *RecoveryCode) MarkUsed(now time.Time) {
+	c.UsedAt = &now
+}
@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"net/url"
 	"time"
 
 	"shikposh-backend/internal/account/domain/events"
@@ -18,11 +19,21 @@ type User struct {
 	UpdatedAt        time.Time
 	DeletedAt        gorm.DeletedAt `gorm:"index"`
 	AvatarIdentifier string         `json:"avatar_identifier" gorm:"avatar_identifier"`
+	AvatarKey        string         `json:"-" gorm:"avatar_key"`
+	AvatarURL        *string        `json:"avatar_url,omitempty" gorm:"avatar_url"`
 	UserName         string         `json:"user_name" gorm:"user_name"`
 	FirstName        string         `json:"first_name" gorm:"first_name"`
 	LastName         string         `json:"last_name" gorm:"last_name"`
 	Email            string         `json:"email" gorm:"email"`
 	Password         string         `json:"password" gorm:"password"`
+	EmailVerifiedAt  *time.Time     `json:"email_verified_at,omitempty" gorm:"email_verified_at"`
+	TotpSecret       string         `json:"-" gorm:"totp_secret"`
+	TotpEnabledAt    *time.Time     `json:"totp_enabled_at,omitempty" gorm:"totp_enabled_at"`
+	TotpLastStep     int64          `json:"-" gorm:"totp_last_step"`
+	LockedUntil      *time.Time     `json:"locked_until,omitempty" gorm:"locked_until"`
+	PurgeAfter       *time.Time     `json:"-" gorm:"purge_after"`
+	DisabledAt       *time.Time     `json:"disabled_at,omitempty" gorm:"disabled_at"`
+	Roles            []*Role        `json:"roles,omitempty" gorm:"many2many:user_roles"`
 }
 
 func NewUser(
@@ -43,9 +54,8 @@ func NewUser(
 	}
 
 	// Add register event with pointer to user.ID so it updates when ID is set
-	userID := uint64(user.ID)
 	user.AddEvent(&events.RegisterUserEvent{
-		UserID:           &userID,
+		UserID:           (*uint64)(&user.ID),
 		AvatarIdentifier: user.AvatarIdentifier,
 		UserName:         user.UserName,
 		FirstName:        user.FirstName,
@@ -55,3 +65,287 @@ func NewUser(
 
 	return user
 }
+
+// NewPhoneUser creates the account of someone who signed in with a one-time
+// code. The phone number doubles as username and avatar identifier; email and
+// password stay empty, so password login is impossible until they are set.
+func NewPhoneUser(phone string) *User {
+	user := &User{
+		AvatarIdentifier: phone,
+		UserName:         phone,
+	}
+
+	user.AddEvent(&events.RegisterUserEvent{
+		UserID:           (*uint64)(&user.ID),
+		AvatarIdentifier: user.AvatarIdentifier,
+		UserName:         user.UserName,
+	})
+
+	return user
+}
+
+// NewExternalUser creates the account of someone who signed in with an
+// identity provider for the first time. The username is derived from the
+// provider account, and without a password only the provider can sign in.
+// An email the provider verified counts as verified here too.
+func NewExternalUser(provider, subject, firstName, lastName, email string, emailVerified bool, now time.Time) *User {
+	userName := provider + "_" + subject
+	user := &User{
+		AvatarIdentifier: userName,
+		UserName:         userName,
+		FirstName:        firstName,
+		LastName:         lastName,
+		Email:            email,
+	}
+	if email != "" && emailVerified {
+		user.EmailVerifiedAt = &now
+	}
+
+	user.AddEvent(&events.RegisterUserEvent{
+		UserID:           (*uint64)(&user.ID),
+		AvatarIdentifier: user.AvatarIdentifier,
+		UserName:         user.UserName,
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Email:            user.Email,
+		EmailVerified:    user.IsEmailVerified(),
+	})
+
+	return user
+}
+
+// IsEmailVerified reports whether the user confirmed the ownership of their email address.
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+func (u *User) VerifyEmail(now time.Time) {
+	if u.EmailVerifiedAt == nil {
+		u.EmailVerifiedAt = &now
+	}
+}
+
+// RequestEmailVerification asks for a new verification email to be sent to
+// the current address of the user.
+func (u *User) RequestEmailVerification() {
+	u.AddEvent(&events.VerificationEmailRequestedEvent{
+		UserID: uint64(u.ID),
+		Email:  u.Email,
+	})
+}
+
+// UpdateDetails applies the given changes; nil leaves a field as it is. A new
+// email address has to be verified again, so a verification email is requested.
+func (u *User) UpdateDetails(firstName, lastName, email *string) {
+	var fields []string
+
+	if firstName != nil && *firstName != u.FirstName {
+		u.FirstName = *firstName
+		fields = append(fields, "first_name")
+	}
+	if lastName != nil && *lastName != u.LastName {
+		u.LastName = *lastName
+		fields = append(fields, "last_name")
+	}
+	if email != nil && *email != u.Email {
+		u.Email = *email
+		u.EmailVerifiedAt = nil
+		fields = append(fields, "email")
+		u.RequestEmailVerification()
+	}
+
+	if len(fields) > 0 {
+		u.AddEvent(&events.ProfileUpdatedEvent{
+			UserID: uint64(u.ID),
+			Fields: fields,
+		})
+	}
+}
+
+// RequestPasswordReset asks for a password reset link to be mailed to the user.
+func (u *User) RequestPasswordReset() {
+	u.AddEvent(&events.PasswordResetRequestedEvent{
+		UserID: uint64(u.ID),
+		Email:  u.Email,
+	})
+}
+
+// ChangePassword replaces the stored password hash.
+func (u *User) ChangePassword(hashedPassword string) {
+	u.Password = hashedPassword
+}
+
+// IsTwoFactorEnabled reports whether login asks for a TOTP code after the password.
+func (u *User) IsTwoFactorEnabled() bool {
+	return u.TotpEnabledAt != nil
+}
+
+// StartTotpEnrollment stores a new secret that becomes active only once
+// ConfirmTotpEnrollment proves the authenticator app was set up.
+func (u *User) StartTotpEnrollment(secret string) {
+	u.TotpSecret = secret
+	u.TotpEnabledAt = nil
+	u.TotpLastStep = 0
+}
+
+func (u *User) ConfirmTotpEnrollment(now time.Time) {
+	u.TotpEnabledAt = &now
+}
+
+// AcceptTotpStep records the time step of a valid code and refuses steps
+// that were already used, so an observed code cannot be replayed.
+func (u *User) AcceptTotpStep(step int64) bool {
+	if step <= u.TotpLastStep {
+		return false
+	}
+
+	u.TotpLastStep = step
+	return true
+}
+
+// IsLocked reports whether logins are refused after too many password failures.
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// Lock refuses password logins until the given time and asks for an unlock
+// link to be mailed to the user.
+func (u *User) Lock(until time.Time) {
+	u.LockedUntil = &until
+	u.AddEvent(&events.UserLockedEvent{
+		UserID:      uint64(u.ID),
+		Email:       u.Email,
+		LockedUntil: until,
+	})
+}
+
+func (u *User) Unlock() {
+	u.LockedUntil = nil
+}
+
+// ScheduleDeletion marks the account for erasure once purgeAfter has passed.
+// The caller soft-deletes the user, so it can no longer sign in meanwhile.
+func (u *User) ScheduleDeletion(purgeAfter time.Time) {
+	u.PurgeAfter = &purgeAfter
+	u.AddEvent(&events.UserDeletedEvent{
+		UserID:     uint64(u.ID),
+		Email:      u.Email,
+		PurgeAfter: purgeAfter,
+	})
+}
+
+// IsDisabled reports whether an administrator has disabled the account.
+// Disabled users cannot sign in by any means.
+func (u *User) IsDisabled() bool {
+	return u.DisabledAt != nil
+}
+
+// Disable refuses every login of the user until Enable is called. The caller
+// revokes the sessions the user already has.
+func (u *User) Disable(actorID UserID, now time.Time) {
+	if u.DisabledAt == nil {
+		u.DisabledAt = &now
+	}
+	u.AddEvent(&events.UserDisabledEvent{
+		UserID:  uint64(u.ID),
+		ActorID: uint64(actorID),
+	})
+}
+
+func (u *User) Enable(actorID UserID) {
+	u.DisabledAt = nil
+	u.AddEvent(&events.UserEnabledEvent{
+		UserID:  uint64(u.ID),
+		ActorID: uint64(actorID),
+	})
+}
+
+// ForceLogout records that an administrator signed the user out on every
+// device. The caller revokes the sessions.
+func (u *User) ForceLogout(actorID UserID) {
+	u.AddEvent(&events.UserLoggedOutEvent{
+		UserID:  uint64(u.ID),
+		ActorID: uint64(actorID),
+	})
+}
+
+// ResetPasswordByAdmin drops the current password, so only a password reset
+// link, which is mailed to the user, can set a new one.
+func (u *User) ResetPasswordByAdmin(actorID UserID) {
+	u.Password = ""
+	u.RequestPasswordReset()
+	u.AddEvent(&events.PasswordResetByAdminEvent{
+		UserID:  uint64(u.ID),
+		ActorID: uint64(actorID),
+	})
+}
+
+// ReplaceRoles sets the roles of the user. The caller persists the change,
+// since the roles live in a join table.
+func (u *User) ReplaceRoles(roles []*Role, actorID UserID) {
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = role.Name
+	}
+
+	u.Roles = roles
+	u.AddEvent(&events.UserRolesChangedEvent{
+		UserID:  uint64(u.ID),
+		ActorID: uint64(actorID),
+		Roles:   names,
+	})
+}
+
+// generatedAvatarPath serves the avatar generated from an identifier.
+const generatedAvatarPath = "/api/v1/public/avatar/"
+
+// AvatarLink is the uploaded avatar of the user, or their generated avatar
+// while they have none.
+func (u *User) AvatarLink() string {
+	if u.AvatarURL != nil {
+		return *u.AvatarURL
+	}
+	return generatedAvatarPath + url.PathEscape(u.AvatarIdentifier)
+}
+
+// SetUploadedAvatar replaces the avatar of the user with the stored file of
+// key. A previously uploaded file is deleted once the change is saved.
+func (u *User) SetUploadedAvatar(key, avatarURL string) {
+	u.discardUploadedAvatar()
+	u.AvatarKey = key
+	u.AvatarURL = &avatarURL
+}
+
+// RemoveUploadedAvatar brings back the generated avatar and reports whether
+// the user had uploaded one.
+func (u *User) RemoveUploadedAvatar() bool {
+	if u.AvatarKey == "" {
+		return false
+	}
+	u.discardUploadedAvatar()
+	u.AvatarKey = ""
+	u.AvatarURL = nil
+	return true
+}
+
+// RejectUploadedAvatar removes an upload a moderator found inappropriate.
+func (u *User) RejectUploadedAvatar(actorID UserID) bool {
+	if !u.RemoveUploadedAvatar() {
+		return false
+	}
+	u.AddEvent(&events.AvatarRejectedEvent{
+		UserID:  uint64(u.ID),
+		ActorID: uint64(actorID),
+	})
+	return true
+}
+
+func (u *User) discardUploadedAvatar() {
+	if u.AvatarKey == "" {
+		return
+	}
+	u.AddEvent(&events.AvatarDiscardedEvent{
+		UserID: uint64(u.ID),
+		Key:    u.AvatarKey,
+	})
+}
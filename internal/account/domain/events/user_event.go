@@ -1,6 +1,8 @@
 package events
 
-// user
+import "time"
+
+// user, EmailVerified is set when an identity provider vouched for the email
 type RegisterUserEvent struct {
 	UserID           *uint64 `json:"user_id"`
 	AvatarIdentifier string  `json:"avatar_identifier"`
@@ -8,4 +10,105 @@ type RegisterUserEvent struct {
 	FirstName        string  `json:"first_name"`
 	LastName         string  `json:"last_name"`
 	Email            string  `json:"email"`
+	EmailVerified    bool    `json:"email_verified"`
+}
+
+type VerificationEmailRequestedEvent struct {
+	UserID uint64 `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+type PasswordResetRequestedEvent struct {
+	UserID uint64 `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+// UserLockedEvent is raised when an account is locked after too many failed logins.
+type UserLockedEvent struct {
+	UserID      uint64    `json:"user_id"`
+	Email       string    `json:"email"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+// ProfileUpdatedEvent is raised when a user edits their account details or
+// profile. Fields holds the JSON names of the fields that changed.
+type ProfileUpdatedEvent struct {
+	UserID uint64   `json:"user_id"`
+	Fields []string `json:"fields"`
+}
+
+// UserDeletedEvent is raised when a user closes their account. Their personal
+// data is erased after PurgeAfter.
+type UserDeletedEvent struct {
+	UserID     uint64    `json:"user_id"`
+	Email      string    `json:"email"`
+	PurgeAfter time.Time `json:"purge_after"`
+}
+
+// admin actions, ActorID is the administrator who acted on the account
+type UserDisabledEvent struct {
+	UserID  uint64 `json:"user_id"`
+	ActorID uint64 `json:"actor_id"`
+}
+
+type UserEnabledEvent struct {
+	UserID  uint64 `json:"user_id"`
+	ActorID uint64 `json:"actor_id"`
+}
+
+// UserLoggedOutEvent is raised when every session of the user is revoked.
+type UserLoggedOutEvent struct {
+	UserID  uint64 `json:"user_id"`
+	ActorID uint64 `json:"actor_id"`
+}
+
+type PasswordResetByAdminEvent struct {
+	UserID  uint64 `json:"user_id"`
+	ActorID uint64 `json:"actor_id"`
+}
+
+// UserRolesChangedEvent holds the names of the roles the user has afterwards.
+type UserRolesChangedEvent struct {
+	UserID  uint64   `json:"user_id"`
+	ActorID uint64   `json:"actor_id"`
+	Roles   []string `json:"roles"`
+}
+
+// service accounts, ActorID is the administrator who acted on the account
+type ServiceAccountCreatedEvent struct {
+	ServiceAccountID *uint64  `json:"service_account_id"`
+	Name             string   `json:"name"`
+	Scopes           []string `json:"scopes"`
+	ActorID          uint64   `json:"actor_id"`
+}
+
+type ApiKeyIssuedEvent struct {
+	ServiceAccountID uint64  `json:"service_account_id"`
+	ApiKeyID         *uint64 `json:"api_key_id"`
+	ActorID          uint64  `json:"actor_id"`
+}
+
+type ApiKeyRevokedEvent struct {
+	ServiceAccountID uint64 `json:"service_account_id"`
+	ApiKeyID         uint64 `json:"api_key_id"`
+	ActorID          uint64 `json:"actor_id"`
+}
+
+// ExternalIdentityLinkedEvent is raised when a user signs in with an identity
+// provider account for the first time.
+type ExternalIdentityLinkedEvent struct {
+	UserID   uint64 `json:"user_id"`
+	Provider string `json:"provider"`
+}
+
+// AvatarDiscardedEvent is raised when an uploaded avatar is replaced or
+// removed; Key is the stored file that is no longer used.
+type AvatarDiscardedEvent struct {
+	UserID uint64 `json:"user_id"`
+	Key    string `json:"key"`
+}
+
+type AvatarRejectedEvent struct {
+	UserID  uint64 `json:"user_id"`
+	ActorID uint64 `json:"actor_id"`
 }
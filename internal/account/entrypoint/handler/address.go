@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"strconv"
+
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	"shikposh-backend/internal/account/query"
+	"shikposh-backend/internal/account/service_layer/command_handler"
+	"shikposh-backend/pkg/middleware"
+	appphrases "shikposh-backend/pkg/phrases"
+	httpapi "github.com/ali-mahdavi-dev/framework/api/http"
+	"github.com/ali-mahdavi-dev/framework/errors"
+	"github.com/ali-mahdavi-dev/framework/errors/phrases"
+	"github.com/ali-mahdavi-dev/framework/service_layer/messagebus"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type AddressController struct {
+	bus                 messagebus.MessageBus
+	addressHandler      *command_handler.AddressHandler
+	addressQueryHandler *query.AddressQueryHandler
+	mw                  *middleware.Middleware
+}
+
+func NewAddressController(
+	bus messagebus.MessageBus,
+	addressHandler *command_handler.AddressHandler,
+	addressQueryHandler *query.AddressQueryHandler,
+	mw *middleware.Middleware,
+) *AddressController {
+	return &AddressController{
+		bus:                 bus,
+		addressHandler:      addressHandler,
+		addressQueryHandler: addressQueryHandler,
+		mw:                  mw,
+	}
+}
+
+func (a *AddressController) RegisterRoutes(r fiber.Router) {
+	addressRoute := r.Group("/api/v1/me/addresses", a.mw.AuthMiddleware())
+	{
+		addressRoute.Get("", a.GetAddresses)
+		addressRoute.Post("", a.CreateAddress)
+		addressRoute.Get("/:id", a.GetAddress)
+		addressRoute.Put("/:id", a.UpdateAddress)
+		addressRoute.Delete("/:id", a.DeleteAddress)
+		addressRoute.Post("/:id/default", a.SetDefaultAddress)
+	}
+}
+
+// GetAddresses godoc
+//
+//	@Summary		List addresses
+//	@Description	Returns the address book of the authenticated user, the default address first.
+//	@Tags			addresses
+//	@Produce		json
+//	@Success		200	{array}		query.AddressDetails	"Addresses"
+//	@Failure		401	{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		500	{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/me/addresses [get]
+func (a *AddressController) GetAddresses(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	result, err := a.addressQueryHandler.GetAddressesByUserID(ctx, entity.UserID(identity.UserID))
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return httpapi.ResSuccess(c, result)
+}
+
+// CreateAddress godoc
+//
+//	@Summary		Add an address
+//	@Description	Adds an address to the address book of the authenticated user. The first address becomes the default one.
+//	@Description	The phone must be an Iranian mobile number and the postal code a valid 10-digit Iranian postal code.
+//	@Tags			addresses
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		commands.SaveAddress	true	"Address"
+//	@Success		200		{object}	query.AddressDetails	"Created address"
+//	@Failure		400		{object}	httpapi.ResponseResult	"Invalid request body, phone number or postal code"
+//	@Failure		401		{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		500		{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/me/addresses [post]
+func (a *AddressController) CreateAddress(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	cmd := new(commands.CreateAddress)
+	if err := httpapi.ParseJSON(c, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+	cmd.UserID = identity.UserID
+
+	address, err := a.addressHandler.CreateAddressHandler(ctx, cmd)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return httpapi.ResSuccess(c, query.NewAddressDetails(address))
+}
+
+// GetAddress godoc
+//
+//	@Summary		Get an address
+//	@Description	Returns an address of the authenticated user.
+//	@Tags			addresses
+//	@Produce		json
+//	@Param			id	path		int						true	"Address ID"
+//	@Success		200	{object}	query.AddressDetails	"Address"
+//	@Failure		401	{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		404	{object}	httpapi.ResponseResult	"Address not found"
+//	@Failure		500	{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/me/addresses/{id} [get]
+func (a *AddressController) GetAddress(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	addressID, err := parseAddressID(c)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	result, err := a.addressQueryHandler.GetAddress(ctx, entity.AddressID(addressID), entity.UserID(identity.UserID))
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return httpapi.ResSuccess(c, result)
+}
+
+// UpdateAddress godoc
+//
+//	@Summary		Replace an address
+//	@Description	Replaces every field of an address of the authenticated user. Setting is_default makes it the default address.
+//	@Tags			addresses
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"Address ID"
+//	@Param			request	body		commands.SaveAddress	true	"Address"
+//	@Success		200		{object}	query.AddressDetails	"Updated address"
+//	@Failure		400		{object}	httpapi.ResponseResult	"Invalid request body, phone number or postal code"
+//	@Failure		401		{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		404		{object}	httpapi.ResponseResult	"Address not found"
+//	@Failure		500		{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/me/addresses/{id} [put]
+func (a *AddressController) UpdateAddress(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	addressID, err := parseAddressID(c)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	cmd := new(commands.UpdateAddress)
+	if err := httpapi.ParseJSON(c, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+	cmd.UserID = identity.UserID
+	cmd.AddressID = addressID
+
+	if err := a.bus.Handle(ctx, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	result, err := a.addressQueryHandler.GetAddress(ctx, entity.AddressID(addressID), entity.UserID(identity.UserID))
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return httpapi.ResSuccess(c, result)
+}
+
+// DeleteAddress godoc
+//
+//	@Summary		Delete an address
+//	@Description	Removes an address from the address book of the authenticated user. Deleting the default address makes the most recent remaining one the default.
+//	@Tags			addresses
+//	@Param			id	path	int	true	"Address ID"
+//	@Success		204	"Address deleted"
+//	@Failure		401	{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		404	{object}	httpapi.ResponseResult	"Address not found"
+//	@Failure		500	{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/me/addresses/{id} [delete]
+func (a *AddressController) DeleteAddress(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	addressID, err := parseAddressID(c)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	cmd := &commands.DeleteAddress{UserID: identity.UserID, AddressID: addressID}
+	if err := a.bus.Handle(ctx, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// SetDefaultAddress godoc
+//
+//	@Summary		Set the default address
+//	@Description	Makes an address of the authenticated user their default address.
+//	@Tags			addresses
+//	@Param			id	path	int	true	"Address ID"
+//	@Success		204	"Default address set"
+//	@Failure		401	{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		404	{object}	httpapi.ResponseResult	"Address not found"
+//	@Failure		500	{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/me/addresses/{id}/default [post]
+func (a *AddressController) SetDefaultAddress(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	addressID, err := parseAddressID(c)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	cmd := &commands.SetDefaultAddress{UserID: identity.UserID, AddressID: addressID}
+	if err := a.bus.Handle(ctx, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// parseAddressID reads the :id route parameter. An ID that is not a number
+// cannot name an address, so it is reported as not found.
+func parseAddressID(c fiber.Ctx) (uint64, error) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return 0, errors.NotFound(appphrases.AddressNotFound, "Address not found")
+	}
+
+	return id, nil
+}
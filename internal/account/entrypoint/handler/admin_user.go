@@ -0,0 +1,287 @@
+package handler
+
+import (
+	"strconv"
+
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	"shikposh-backend/internal/account/query"
+	"shikposh-backend/pkg/middleware"
+	httpapi "github.com/ali-mahdavi-dev/framework/api/http"
+	"github.com/ali-mahdavi-dev/framework/errors"
+	"github.com/ali-mahdavi-dev/framework/errors/phrases"
+	"github.com/ali-mahdavi-dev/framework/service_layer/messagebus"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// AdminUserController lets support staff find and manage user accounts.
+// Viewing requires users:read and every change requires users:write.
+type AdminUserController struct {
+	bus              messagebus.MessageBus
+	userQueryHandler *query.UserQueryHandler
+	mw               *middleware.Middleware
+}
+
+func NewAdminUserController(
+	bus messagebus.MessageBus,
+	userQueryHandler *query.UserQueryHandler,
+	mw *middleware.Middleware,
+) *AdminUserController {
+	return &AdminUserController{
+		bus:              bus,
+		userQueryHandler: userQueryHandler,
+		mw:               mw,
+	}
+}
+
+func (a *AdminUserController) RegisterRoutes(r fiber.Router) {
+	adminRoute := r.Group("/api/v1/admin/users",
+		a.mw.AuthMiddleware(),
+		a.mw.RequireTwoFactorEnrollment(),
+		a.mw.RequirePermission(commands.UsersReadPermission),
+	)
+	{
+		adminRoute.Get("", a.ListUsers)
+		adminRoute.Get("/:id", a.GetUser)
+
+		requireWrite := a.mw.RequirePermission(commands.UsersWritePermission)
+		adminRoute.Post("/:id/disable", requireWrite, a.DisableUser)
+		adminRoute.Post("/:id/enable", requireWrite, a.EnableUser)
+		adminRoute.Post("/:id/logout", requireWrite, a.ForceLogout)
+		adminRoute.Post("/:id/password-reset", requireWrite, a.ResetPassword)
+		adminRoute.Put("/:id/roles", requireWrite, a.ChangeRoles)
+		adminRoute.Delete("/:id/avatar", requireWrite, a.RejectAvatar)
+	}
+}
+
+// ListUsers godoc
+//
+//	@Summary		List users
+//	@Description	Returns a page of user accounts, the newest first. q matches part of the username or email address, or a phone number.
+//	@Tags			admin-users
+//	@Produce		json
+//	@Param			q		query		string	false	"Username, email or phone number"
+//	@Param			skip	query		int		false	"Number of users to skip"
+//	@Param			limit	query		int		false	"Page size, 20 by default and at most 100"
+//	@Success		200		{array}		query.UserSummary		"Users"
+//	@Failure		401		{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		403		{object}	httpapi.ResponseResult	"Permission denied"
+//	@Failure		500		{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/admin/users [get]
+func (a *AdminUserController) ListUsers(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	filter := query.UserListFilter{
+		Search: c.Query("q"),
+		Skip:   fiber.Query[int](c, "skip"),
+		Limit:  fiber.Query[int](c, "limit"),
+	}
+
+	users, total, err := a.userQueryHandler.ListUsers(ctx, filter)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return httpapi.ResPage(c, users, &httpapi.PaginationResult{
+		Total: total,
+		Skip:  int64(filter.Skip),
+		Limit: int64(len(users)),
+	})
+}
+
+// GetUser godoc
+//
+//	@Summary		Get a user
+//	@Description	Returns a user account with its profile, roles and active sessions.
+//	@Tags			admin-users
+//	@Produce		json
+//	@Param			id	path		int						true	"User ID"
+//	@Success		200	{object}	query.AdminUserDetails	"User"
+//	@Failure		401	{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		403	{object}	httpapi.ResponseResult	"Permission denied"
+//	@Failure		404	{object}	httpapi.ResponseResult	"User not found"
+//	@Failure		500	{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/admin/users/{id} [get]
+func (a *AdminUserController) GetUser(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	userID, err := parseUserID(c)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	result, err := a.userQueryHandler.GetAdminUserDetails(ctx, entity.UserID(userID))
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return httpapi.ResSuccess(c, result)
+}
+
+// DisableUser godoc
+//
+//	@Summary		Disable a user
+//	@Description	Refuses every further login of the user and signs them out on every device.
+//	@Tags			admin-users
+//	@Param			id	path	int	true	"User ID"
+//	@Success		204	"User disabled"
+//	@Failure		400	{object}	httpapi.ResponseResult	"Own account"
+//	@Failure		401	{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		403	{object}	httpapi.ResponseResult	"Permission denied"
+//	@Failure		404	{object}	httpapi.ResponseResult	"User not found"
+//	@Failure		500	{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/admin/users/{id}/disable [post]
+func (a *AdminUserController) DisableUser(c fiber.Ctx) error {
+	return a.handleUserCommand(c, func(userID, actorID uint64) any {
+		return &commands.DisableUser{UserID: userID, ActorID: actorID}
+	})
+}
+
+// EnableUser godoc
+//
+//	@Summary		Enable a user
+//	@Description	Lets a disabled user sign in again.
+//	@Tags			admin-users
+//	@Param			id	path	int	true	"User ID"
+//	@Success		204	"User enabled"
+//	@Failure		401	{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		403	{object}	httpapi.ResponseResult	"Permission denied"
+//	@Failure		404	{object}	httpapi.ResponseResult	"User not found"
+//	@Failure		500	{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/admin/users/{id}/enable [post]
+func (a *AdminUserController) EnableUser(c fiber.Ctx) error {
+	return a.handleUserCommand(c, func(userID, actorID uint64) any {
+		return &commands.EnableUser{UserID: userID, ActorID: actorID}
+	})
+}
+
+// ForceLogout godoc
+//
+//	@Summary		Sign a user out
+//	@Description	Revokes every session of the user, so each of their devices has to sign in again.
+//	@Tags			admin-users
+//	@Param			id	path	int	true	"User ID"
+//	@Success		204	"Sessions revoked"
+//	@Failure		401	{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		403	{object}	httpapi.ResponseResult	"Permission denied"
+//	@Failure		404	{object}	httpapi.ResponseResult	"User not found"
+//	@Failure		500	{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/admin/users/{id}/logout [post]
+func (a *AdminUserController) ForceLogout(c fiber.Ctx) error {
+	return a.handleUserCommand(c, func(userID, actorID uint64) any {
+		return &commands.ForceLogoutUser{UserID: userID, ActorID: actorID}
+	})
+}
+
+// ResetPassword godoc
+//
+//	@Summary		Reset the password of a user
+//	@Description	Invalidates the password of the user, signs them out and mails them a password reset link.
+//	@Tags			admin-users
+//	@Param			id	path	int	true	"User ID"
+//	@Success		204	"Password reset link sent"
+//	@Failure		400	{object}	httpapi.ResponseResult	"User has no email address"
+//	@Failure		401	{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		403	{object}	httpapi.ResponseResult	"Permission denied"
+//	@Failure		404	{object}	httpapi.ResponseResult	"User not found"
+//	@Failure		500	{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/admin/users/{id}/password-reset [post]
+func (a *AdminUserController) ResetPassword(c fiber.Ctx) error {
+	return a.handleUserCommand(c, func(userID, actorID uint64) any {
+		return &commands.AdminResetPassword{UserID: userID, ActorID: actorID}
+	})
+}
+
+// RejectAvatar godoc
+//
+//	@Summary		Reject the avatar of a user
+//	@Description	Deletes the uploaded avatar of the user, for instance because it breaks the content rules. The user gets their generated avatar back.
+//	@Tags			admin-users
+//	@Param			id	path	int	true	"User ID"
+//	@Success		204	"Avatar rejected"
+//	@Failure		401	{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		403	{object}	httpapi.ResponseResult	"Permission denied"
+//	@Failure		404	{object}	httpapi.ResponseResult	"User not found"
+//	@Failure		500	{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/admin/users/{id}/avatar [delete]
+func (a *AdminUserController) RejectAvatar(c fiber.Ctx) error {
+	return a.handleUserCommand(c, func(userID, actorID uint64) any {
+		return &commands.RejectAvatar{UserID: userID, ActorID: actorID}
+	})
+}
+
+// ChangeRoles godoc
+//
+//	@Summary		Change the roles of a user
+//	@Description	Replaces every role of the user with the given ones. An empty list removes all roles.
+//	@Tags			admin-users
+//	@Accept			json
+//	@Param			id		path	int							true	"User ID"
+//	@Param			request	body	commands.ChangeUserRoles	true	"ChangeUserRoles request"
+//	@Success		204		"Roles changed"
+//	@Failure		400		{object}	httpapi.ResponseResult	"Invalid request body or own account"
+//	@Failure		401		{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		403		{object}	httpapi.ResponseResult	"Permission denied"
+//	@Failure		404		{object}	httpapi.ResponseResult	"User or role not found"
+//	@Failure		500		{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/admin/users/{id}/roles [put]
+func (a *AdminUserController) ChangeRoles(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	userID, err := parseUserID(c)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	cmd := new(commands.ChangeUserRoles)
+	if err := httpapi.ParseJSON(c, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+	cmd.UserID = userID
+	cmd.ActorID = identity.UserID
+
+	if err := a.bus.Handle(ctx, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// handleUserCommand sends the command built for the user in the :id route
+// parameter on behalf of the authenticated administrator.
+func (a *AdminUserController) handleUserCommand(c fiber.Ctx, newCommand func(userID, actorID uint64) any) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	userID, err := parseUserID(c)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	if err := a.bus.Handle(ctx, newCommand(userID, identity.UserID)); err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// parseUserID reads the :id route parameter. An ID that is not a number
+// cannot name a user, so it is reported as not found.
+func parseUserID(c fiber.Ctx) (uint64, error) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return 0, errors.NotFound(phrases.UserNotFound)
+	}
+
+	return id, nil
+}
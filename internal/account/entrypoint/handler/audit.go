@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/query"
+	"shikposh-backend/pkg/middleware"
+	appphrases "shikposh-backend/pkg/phrases"
+	httpapi "github.com/ali-mahdavi-dev/framework/api/http"
+	"github.com/ali-mahdavi-dev/framework/errors"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// AuditController lets administrators search the security audit log, which
+// requires audit:read.
+type AuditController struct {
+	auditQueryHandler *query.AuditQueryHandler
+	mw                *middleware.Middleware
+}
+
+func NewAuditController(auditQueryHandler *query.AuditQueryHandler, mw *middleware.Middleware) *AuditController {
+	return &AuditController{
+		auditQueryHandler: auditQueryHandler,
+		mw:                mw,
+	}
+}
+
+func (a *AuditController) RegisterRoutes(r fiber.Router) {
+	adminRoute := r.Group("/api/v1/admin/audit",
+		a.mw.AuthMiddleware(),
+		a.mw.RequireTwoFactorEnrollment(),
+		a.mw.RequirePermission(commands.AuditReadPermission),
+	)
+	{
+		adminRoute.Get("", a.ListAuditEvents)
+	}
+}
+
+// ListAuditEvents godoc
+//
+//	@Summary		Search the audit log
+//	@Description	Returns a page of audit log entries, the newest first. Every filter is optional and they combine.
+//	@Tags			admin-audit
+//	@Produce		json
+//	@Param			actor_type	query		string	false	"user or service"
+//	@Param			actor_id	query		int		false	"ID of the user or service account that acted"
+//	@Param			action		query		string	false	"Action, e.g. user.login"
+//	@Param			target_type	query		string	false	"Kind of target, e.g. user"
+//	@Param			target_id	query		string	false	"ID of the target"
+//	@Param			outcome		query		string	false	"success, denied or failure"
+//	@Param			ip			query		string	false	"Client IP address"
+//	@Param			request_id	query		string	false	"Request ID"
+//	@Param			from		query		string	false	"Earliest time, RFC 3339, inclusive"
+//	@Param			to			query		string	false	"Latest time, RFC 3339, exclusive"
+//	@Param			skip		query		int		false	"Number of entries to skip"
+//	@Param			limit		query		int		false	"Page size, 50 by default and at most 200"
+//	@Success		200			{array}		query.AuditEventDetails	"Audit log entries"
+//	@Failure		400			{object}	httpapi.ResponseResult	"Invalid filter"
+//	@Failure		401			{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		403			{object}	httpapi.ResponseResult	"Permission denied"
+//	@Failure		500			{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/admin/audit [get]
+func (a *AuditController) ListAuditEvents(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	filter, err := parseAuditEventFilter(c)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	events, total, err := a.auditQueryHandler.ListAuditEvents(ctx, filter)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return httpapi.ResPage(c, events, &httpapi.PaginationResult{
+		Total: total,
+		Skip:  int64(filter.Skip),
+		Limit: int64(len(events)),
+	})
+}
+
+func parseAuditEventFilter(c fiber.Ctx) (query.AuditEventFilter, error) {
+	filter := query.AuditEventFilter{
+		ActorType:  c.Query("actor_type"),
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		Outcome:    c.Query("outcome"),
+		IP:         c.Query("ip"),
+		RequestID:  c.Query("request_id"),
+		Skip:       fiber.Query[int](c, "skip"),
+		Limit:      fiber.Query[int](c, "limit"),
+	}
+
+	if actorID := c.Query("actor_id"); actorID != "" {
+		id, err := strconv.ParseUint(actorID, 10, 64)
+		if err != nil {
+			return filter, errors.Validation(appphrases.InvalidAuditFilter, "actor_id must be a number")
+		}
+		filter.ActorID = &id
+	}
+
+	for name, field := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, errors.Validation(appphrases.InvalidAuditFilter, name+" must be an RFC 3339 time")
+		}
+		*field = &t
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	accountadapter "shikposh-backend/internal/account/adapter"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// jwksMaxAge is how long verifiers may cache the key set. A key added for a
+// rotation should be published at least this long before it starts signing.
+const jwksMaxAge = "public, max-age=3600"
+
+// JwksController publishes the public keys access tokens are signed with, so
+// other services can verify them without calling back.
+type JwksController struct {
+	accessTokens *accountadapter.AccessTokenKeys
+}
+
+func NewJwksController(accessTokens *accountadapter.AccessTokenKeys) *JwksController {
+	return &JwksController{accessTokens: accessTokens}
+}
+
+func (j *JwksController) RegisterRoutes(r fiber.Router) {
+	r.Get("/.well-known/jwks.json", j.GetJwks)
+}
+
+// GetJwks godoc
+//
+//	@Summary		Get the access token signing keys
+//	@Description	Returns the JSON Web Key Set of the keys access tokens are signed with, including keys kept only to verify tokens issued before a rotation.
+//	@Description	The set is not wrapped in the usual response envelope, so standard JWT libraries can read it.
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{object}	accountadapter.JSONWebKeySet	"Key set"
+//	@Router			/.well-known/jwks.json [get]
+func (j *JwksController) GetJwks(c fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, jwksMaxAge)
+	return c.JSON(j.accessTokens.JWKS())
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/service_layer/command_handler"
+	"shikposh-backend/pkg/middleware"
+	httpapi "github.com/ali-mahdavi-dev/framework/api/http"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// OidcController signs users in with the identity providers of oidc.providers.
+type OidcController struct {
+	oidcHandler *command_handler.OidcHandler
+	mw          *middleware.Middleware
+}
+
+func NewOidcController(oidcHandler *command_handler.OidcHandler, mw *middleware.Middleware) *OidcController {
+	return &OidcController{oidcHandler: oidcHandler, mw: mw}
+}
+
+func (o *OidcController) RegisterRoutes(r fiber.Router) {
+	publicRoute := r.Group("/api/v1/public/oidc")
+	{
+		publicRoute.Get("/:provider/authorize", o.StartOidcLogin)
+		publicRoute.Post("/:provider/callback", o.CompleteOidcLogin)
+	}
+}
+
+// StartOidcLogin godoc
+//
+//	@Summary		Start a login with an identity provider
+//	@Description	Returns the provider URL the user has to be sent to. The provider sends them back to its configured redirect URL with a code and a state, which are posted to the callback.
+//	@Tags			users
+//	@Produce		json
+//	@Param			provider	path		string							true	"Provider name, e.g. google"
+//	@Success		200			{object}	command_handler.OidcLoginStart	"Authorization URL"
+//	@Failure		400			{object}	httpapi.ResponseResult			"Unknown provider"
+//	@Failure		500			{object}	httpapi.ResponseResult			"Internal server error"
+//	@Router			/api/v1/public/oidc/{provider}/authorize [get]
+func (o *OidcController) StartOidcLogin(c fiber.Ctx) error {
+	cmd := &commands.StartOidcLogin{Provider: c.Params("provider")}
+
+	result, err := o.oidcHandler.StartOidcLoginHandler(c.Context(), cmd)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return httpapi.ResSuccess(c, result)
+}
+
+// CompleteOidcLogin godoc
+//
+//	@Summary		Complete a login with an identity provider
+//	@Description	Exchanges the code and state the provider returned for an access and a refresh token. The first login of an unknown provider account creates its user, or links it to the user with the same email when both sides verified it.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			provider	path		string							true	"Provider name, e.g. google"
+//	@Param			request		body		commands.CompleteOidcLogin		true	"CompleteOidcLogin request"
+//	@Success		200			{object}	command_handler.LoginResult	"Access and refresh tokens"
+//	@Failure		400			{object}	httpapi.ResponseResult			"Invalid request body or unknown provider"
+//	@Failure		401			{object}	httpapi.ResponseResult			"Invalid or expired state, or the provider rejected the code"
+//	@Failure		403			{object}	httpapi.ResponseResult			"Account disabled"
+//	@Failure		409			{object}	httpapi.ResponseResult			"Email already used by another account"
+//	@Failure		500			{object}	httpapi.ResponseResult			"Internal server error"
+//	@Router			/api/v1/public/oidc/{provider}/callback [post]
+func (o *OidcController) CompleteOidcLogin(c fiber.Ctx) error {
+	cmd := new(commands.CompleteOidcLogin)
+	if err := httpapi.ParseJSON(c, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+	cmd.Provider = c.Params("provider")
+	cmd.UserAgent = c.Get(fiber.HeaderUserAgent)
+	cmd.IP = c.IP()
+
+	result, err := o.oidcHandler.CompleteOidcLoginHandler(c.Context(), cmd)
+	o.mw.Audit(c.Context(), cmd, err)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	if result.Access != "" {
+		c.Set("Authorization", "Bearer "+result.Access)
+	}
+
+	return httpapi.ResSuccess(c, result)
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/service_layer/command_handler"
+	"shikposh-backend/pkg/middleware"
+	httpapi "github.com/ali-mahdavi-dev/framework/api/http"
+	"github.com/ali-mahdavi-dev/framework/service_layer/messagebus"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type OtpController struct {
+	bus        messagebus.MessageBus
+	otpHandler *command_handler.OtpHandler
+	mw         *middleware.Middleware
+}
+
+func NewOtpController(bus messagebus.MessageBus, otpHandler *command_handler.OtpHandler, mw *middleware.Middleware) *OtpController {
+	return &OtpController{
+		bus:        bus,
+		otpHandler: otpHandler,
+		mw:         mw,
+	}
+}
+
+func (o *OtpController) RegisterRoutes(r fiber.Router) {
+	publicRoute := r.Group("/api/v1/public/otp", o.mw.OptionalAuthMiddleware())
+	{
+		publicRoute.Post("/request", o.RequestOtp)
+		publicRoute.Post("/verify", o.VerifyOtp)
+	}
+}
+
+// RequestOtp godoc
+//
+//	@Summary		Request a login code
+//	@Description	Texts a one-time login code to an Iranian mobile number. A new code can be requested once per otp.limiter.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		commands.RequestOtp	true	"RequestOtp request"
+//	@Success		204		"Code sent"
+//	@Failure		400		{object}	httpapi.ResponseResult	"Invalid mobile phone number"
+//	@Failure		429		{object}	httpapi.ResponseResult	"Code requested too often"
+//	@Failure		500		{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/public/otp/request [post]
+func (o *OtpController) RequestOtp(c fiber.Ctx) error {
+	ctx := c.Context()
+	cmd := new(commands.RequestOtp)
+
+	if err := httpapi.ParseJSON(c, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	err := o.bus.Handle(ctx, cmd)
+	if err != nil {
+		return resError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// VerifyOtp godoc
+//
+//	@Summary		Login with a code
+//	@Description	Exchanges a one-time code for an access and a refresh token. The first login of an unknown number creates its account.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		commands.VerifyOtp				true	"VerifyOtp request"
+//	@Success		200		{object}	command_handler.LoginResult	"Access and refresh tokens"
+//	@Failure		400		{object}	httpapi.ResponseResult			"Invalid mobile phone number"
+//	@Failure		401		{object}	httpapi.ResponseResult			"Invalid or expired code"
+//	@Failure		500		{object}	httpapi.ResponseResult			"Internal server error"
+//	@Router			/api/v1/public/otp/verify [post]
+func (o *OtpController) VerifyOtp(c fiber.Ctx) error {
+	ctx := c.Context()
+	cmd := new(commands.VerifyOtp)
+
+	if err := httpapi.ParseJSON(c, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+	cmd.UserAgent = c.Get(fiber.HeaderUserAgent)
+	cmd.IP = c.IP()
+
+	result, err := o.otpHandler.VerifyOtpHandler(ctx, cmd)
+	o.mw.Audit(ctx, cmd, err)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	if result.Access != "" {
+		c.Set("Authorization", "Bearer "+result.Access)
+	}
+
+	return httpapi.ResSuccess(c, result)
+}
@@ -0,0 +1,325 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	"shikposh-backend/internal/account/query"
+	"shikposh-backend/pkg/middleware"
+	appphrases "shikposh-backend/pkg/phrases"
+	httpapi "github.com/ali-mahdavi-dev/framework/api/http"
+	"github.com/ali-mahdavi-dev/framework/errors"
+	"github.com/ali-mahdavi-dev/framework/errors/phrases"
+	"github.com/ali-mahdavi-dev/framework/service_layer/messagebus"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type ProfileController struct {
+	bus                 messagebus.MessageBus
+	userQueryHandler    *query.UserQueryHandler
+	profileQueryHandler *query.ProfileQueryHandler
+	exportQueryHandler  *query.DataExportQueryHandler
+	mw                  *middleware.Middleware
+}
+
+func NewProfileController(
+	bus messagebus.MessageBus,
+	userQueryHandler *query.UserQueryHandler,
+	profileQueryHandler *query.ProfileQueryHandler,
+	exportQueryHandler *query.DataExportQueryHandler,
+	mw *middleware.Middleware,
+) *ProfileController {
+	return &ProfileController{
+		bus:                 bus,
+		userQueryHandler:    userQueryHandler,
+		profileQueryHandler: profileQueryHandler,
+		exportQueryHandler:  exportQueryHandler,
+		mw:                  mw,
+	}
+}
+
+func (p *ProfileController) RegisterRoutes(r fiber.Router) {
+	meRoute := r.Group("/api/v1/me", p.mw.AuthMiddleware())
+	{
+		meRoute.Get("", p.GetMe)
+		meRoute.Patch("", p.UpdateMe)
+		meRoute.Delete("", p.DeleteMe)
+		meRoute.Get("/export", p.ExportMyData)
+		meRoute.Get("/profile", p.GetProfile)
+		meRoute.Patch("/profile", p.UpdateProfile)
+		meRoute.Put("/avatar", p.UploadAvatar)
+		meRoute.Delete("/avatar", p.RemoveAvatar)
+	}
+}
+
+// GetMe godoc
+//
+//	@Summary		Get the current user
+//	@Description	Returns the account details of the authenticated user.
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{object}	query.UserDetails		"Account details"
+//	@Failure		401	{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		500	{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/me [get]
+func (p *ProfileController) GetMe(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	result, err := p.userQueryHandler.GetUserDetails(ctx, entity.UserID(identity.UserID))
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return httpapi.ResSuccess(c, result)
+}
+
+// UpdateMe godoc
+//
+//	@Summary		Update the current user
+//	@Description	Changes the name or email address of the authenticated user. Omitted fields are left unchanged.
+//	@Description	A new email address has to be verified again through the link mailed to it.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		commands.UpdateUser		true	"UpdateUser request"
+//	@Success		200		{object}	query.UserDetails		"Updated account details"
+//	@Failure		400		{object}	httpapi.ResponseResult	"Invalid request body"
+//	@Failure		401		{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		409		{object}	httpapi.ResponseResult	"Email address is already in use"
+//	@Failure		500		{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/me [patch]
+func (p *ProfileController) UpdateMe(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	cmd := new(commands.UpdateUser)
+	if err := httpapi.ParseJSON(c, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+	cmd.UserID = identity.UserID
+
+	if err := p.bus.Handle(ctx, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	result, err := p.userQueryHandler.GetUserDetails(ctx, entity.UserID(identity.UserID))
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return httpapi.ResSuccess(c, result)
+}
+
+// DeleteMe godoc
+//
+//	@Summary		Close the current account
+//	@Description	Closes the account of the authenticated user and signs them out on every device. Their reviews stay, attributed to a deleted user.
+//	@Description	The remaining personal data is erased after a grace period. Users who have a password must confirm it; others send an empty object.
+//	@Tags			users
+//	@Accept			json
+//	@Param			request	body	commands.DeleteAccount	true	"DeleteAccount request"
+//	@Success		204		"Account closed"
+//	@Failure		400		{object}	httpapi.ResponseResult	"Invalid request body or incorrect password"
+//	@Failure		401		{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		500		{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/me [delete]
+func (p *ProfileController) DeleteMe(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	cmd := new(commands.DeleteAccount)
+	if err := httpapi.ParseJSON(c, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+	cmd.UserID = identity.UserID
+
+	if err := p.bus.Handle(ctx, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// UploadAvatar godoc
+//
+//	@Summary		Upload an avatar
+//	@Description	Replaces the avatar of the authenticated user with a PNG, JPEG, GIF or WebP image. The image is cropped to its centre square and scaled to 256x256.
+//	@Description	The new avatar is shown on the reviews of the user as well.
+//	@Tags			users
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			avatar	formData	file					true	"Avatar image"
+//	@Success		200		{object}	query.UserDetails		"Account details with the new avatar URL"
+//	@Failure		400		{object}	httpapi.ResponseResult	"Missing, unsupported or too large image"
+//	@Failure		401		{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		500		{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/me/avatar [put]
+func (p *ProfileController) UploadAvatar(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	fileHeader, err := c.FormFile("avatar")
+	if err != nil {
+		return httpapi.ResError(c, errors.Validation(appphrases.InvalidAvatarUpload, "The avatar file is required"))
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	if err := p.bus.Handle(ctx, &commands.UploadAvatar{UserID: identity.UserID, Content: content}); err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	result, err := p.userQueryHandler.GetUserDetails(ctx, entity.UserID(identity.UserID))
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return httpapi.ResSuccess(c, result)
+}
+
+// RemoveAvatar godoc
+//
+//	@Summary		Remove the uploaded avatar
+//	@Description	Deletes the uploaded avatar of the authenticated user, who gets their generated avatar back.
+//	@Tags			users
+//	@Success		204	"Avatar removed"
+//	@Failure		401	{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		500	{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/me/avatar [delete]
+func (p *ProfileController) RemoveAvatar(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	if err := p.bus.Handle(ctx, &commands.RemoveAvatar{UserID: identity.UserID}); err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ExportMyData godoc
+//
+//	@Summary		Export personal data
+//	@Description	Returns everything stored about the authenticated user as a downloadable JSON archive: account, profile, addresses and reviews.
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{object}	query.PersonalDataExport	"Personal data archive"
+//	@Failure		401	{object}	httpapi.ResponseResult		"User not authenticated"
+//	@Failure		500	{object}	httpapi.ResponseResult		"Internal server error"
+//	@Router			/api/v1/me/export [get]
+func (p *ProfileController) ExportMyData(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	result, err := p.exportQueryHandler.ExportPersonalData(ctx, entity.UserID(identity.UserID))
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	filename := fmt.Sprintf("shikposh-data-%d-%s.json", identity.UserID, result.ExportedAt.Format("20060102"))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.JSON(result)
+}
+
+// GetProfile godoc
+//
+//	@Summary		Get the current profile
+//	@Description	Returns the bio and phone number of the authenticated user. Shipping addresses live under /api/v1/me/addresses.
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{object}	query.ProfileDetails	"Profile"
+//	@Failure		401	{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		500	{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/me/profile [get]
+func (p *ProfileController) GetProfile(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	result, err := p.profileQueryHandler.GetProfileDetails(ctx, entity.UserID(identity.UserID))
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return httpapi.ResSuccess(c, result)
+}
+
+// UpdateProfile godoc
+//
+//	@Summary		Update the current profile
+//	@Description	Changes the bio or phone number of the authenticated user. Omitted fields are left unchanged.
+//	@Description	Iranian mobile numbers are stored in E.164 form (+989xxxxxxxxx) and an empty phone removes the number.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		commands.UpdateProfile	true	"UpdateProfile request"
+//	@Success		200		{object}	query.ProfileDetails	"Updated profile"
+//	@Failure		400		{object}	httpapi.ResponseResult	"Invalid request body or mobile phone number"
+//	@Failure		401		{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		409		{object}	httpapi.ResponseResult	"Phone number is already in use"
+//	@Failure		500		{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/me/profile [patch]
+func (p *ProfileController) UpdateProfile(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	cmd := new(commands.UpdateProfile)
+	if err := httpapi.ParseJSON(c, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+	cmd.UserID = identity.UserID
+
+	if err := p.bus.Handle(ctx, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	result, err := p.profileQueryHandler.GetProfileDetails(ctx, entity.UserID(identity.UserID))
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return httpapi.ResSuccess(c, result)
+}
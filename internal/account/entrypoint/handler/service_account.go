@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"strconv"
+
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/query"
+	"shikposh-backend/internal/account/service_layer/command_handler"
+	"shikposh-backend/pkg/middleware"
+	appphrases "shikposh-backend/pkg/phrases"
+	httpapi "github.com/ali-mahdavi-dev/framework/api/http"
+	"github.com/ali-mahdavi-dev/framework/errors"
+	"github.com/ali-mahdavi-dev/framework/errors/phrases"
+	"github.com/ali-mahdavi-dev/framework/service_layer/messagebus"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// ServiceAccountController lets administrators manage service accounts and
+// their API keys. Every route requires service-accounts:write.
+type ServiceAccountController struct {
+	bus                        messagebus.MessageBus
+	serviceAccountHandler      *command_handler.ServiceAccountHandler
+	serviceAccountQueryHandler *query.ServiceAccountQueryHandler
+	mw                         *middleware.Middleware
+}
+
+func NewServiceAccountController(
+	bus messagebus.MessageBus,
+	serviceAccountHandler *command_handler.ServiceAccountHandler,
+	serviceAccountQueryHandler *query.ServiceAccountQueryHandler,
+	mw *middleware.Middleware,
+) *ServiceAccountController {
+	return &ServiceAccountController{
+		bus:                        bus,
+		serviceAccountHandler:      serviceAccountHandler,
+		serviceAccountQueryHandler: serviceAccountQueryHandler,
+		mw:                         mw,
+	}
+}
+
+func (s *ServiceAccountController) RegisterRoutes(r fiber.Router) {
+	adminRoute := r.Group("/api/v1/admin/service-accounts",
+		s.mw.AuthMiddleware(),
+		s.mw.RequireTwoFactorEnrollment(),
+		s.mw.RequirePermission(commands.ServiceAccountsWritePermission),
+	)
+	{
+		adminRoute.Get("", s.ListServiceAccounts)
+		adminRoute.Post("", s.CreateServiceAccount)
+		adminRoute.Post("/:id/keys", s.IssueApiKey)
+		adminRoute.Delete("/:id/keys/:keyId", s.RevokeApiKey)
+	}
+}
+
+// ListServiceAccounts godoc
+//
+//	@Summary		List service accounts
+//	@Description	Returns every service account with its scopes and API keys. The keys themselves are never shown again after they are issued.
+//	@Tags			service-accounts
+//	@Produce		json
+//	@Success		200	{array}		query.ServiceAccountDetails	"Service accounts"
+//	@Failure		401	{object}	httpapi.ResponseResult		"User not authenticated"
+//	@Failure		403	{object}	httpapi.ResponseResult		"Permission denied"
+//	@Failure		500	{object}	httpapi.ResponseResult		"Internal server error"
+//	@Router			/api/v1/admin/service-accounts [get]
+func (s *ServiceAccountController) ListServiceAccounts(c fiber.Ctx) error {
+	result, err := s.serviceAccountQueryHandler.ListServiceAccounts(c.Context())
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return httpapi.ResSuccess(c, result)
+}
+
+// CreateServiceAccount godoc
+//
+//	@Summary		Create a service account
+//	@Description	Creates a service account for an internal job. Its scopes are the permissions its API keys grant, e.g. products:write.
+//	@Tags			service-accounts
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		commands.CreateServiceAccount	true	"CreateServiceAccount request"
+//	@Success		200		{object}	query.ServiceAccountDetails		"Created service account"
+//	@Failure		400		{object}	httpapi.ResponseResult			"Invalid request body or unknown scope"
+//	@Failure		401		{object}	httpapi.ResponseResult			"User not authenticated"
+//	@Failure		403		{object}	httpapi.ResponseResult			"Permission denied"
+//	@Failure		409		{object}	httpapi.ResponseResult			"Name already taken"
+//	@Failure		500		{object}	httpapi.ResponseResult			"Internal server error"
+//	@Router			/api/v1/admin/service-accounts [post]
+func (s *ServiceAccountController) CreateServiceAccount(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	cmd := new(commands.CreateServiceAccount)
+	if err := httpapi.ParseJSON(c, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+	cmd.ActorID = identity.UserID
+
+	account, err := s.serviceAccountHandler.CreateServiceAccountHandler(ctx, cmd)
+	s.mw.Audit(ctx, cmd, err)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return httpapi.ResSuccess(c, query.NewServiceAccountDetails(account))
+}
+
+// IssueApiKey godoc
+//
+//	@Summary		Issue an API key
+//	@Description	Issues a new API key of the service account. The key is part of this response only; store it right away.
+//	@Description	Send it as "Authorization: ApiKey <key>". Without expires_at the key never expires.
+//	@Tags			service-accounts
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"Service account ID"
+//	@Param			request	body		commands.IssueApiKey	false	"IssueApiKey request"
+//	@Success		200		{object}	query.IssuedApiKey		"Issued key"
+//	@Failure		400		{object}	httpapi.ResponseResult	"Invalid request body or expiry in the past"
+//	@Failure		401		{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		403		{object}	httpapi.ResponseResult	"Permission denied"
+//	@Failure		404		{object}	httpapi.ResponseResult	"Service account not found"
+//	@Failure		500		{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/admin/service-accounts/{id}/keys [post]
+func (s *ServiceAccountController) IssueApiKey(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	serviceAccountID, err := parseServiceAccountID(c)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	cmd := new(commands.IssueApiKey)
+	if len(c.Body()) > 0 {
+		if err := httpapi.ParseJSON(c, cmd); err != nil {
+			return httpapi.ResError(c, err)
+		}
+	}
+	cmd.ServiceAccountID = serviceAccountID
+	cmd.ActorID = identity.UserID
+
+	key, plain, err := s.serviceAccountHandler.IssueApiKeyHandler(ctx, cmd)
+	s.mw.Audit(ctx, cmd, err)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return httpapi.ResSuccess(c, &query.IssuedApiKey{ApiKeyDetails: *query.NewApiKeyDetails(key), Key: plain})
+}
+
+// RevokeApiKey godoc
+//
+//	@Summary		Revoke an API key
+//	@Description	Revokes an API key of the service account. Requests carrying it are rejected from then on.
+//	@Tags			service-accounts
+//	@Param			id		path	int	true	"Service account ID"
+//	@Param			keyId	path	int	true	"API key ID"
+//	@Success		204		"Key revoked"
+//	@Failure		401		{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		403		{object}	httpapi.ResponseResult	"Permission denied"
+//	@Failure		404		{object}	httpapi.ResponseResult	"API key not found"
+//	@Failure		500		{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/admin/service-accounts/{id}/keys/{keyId} [delete]
+func (s *ServiceAccountController) RevokeApiKey(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	serviceAccountID, err := parseServiceAccountID(c)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	apiKeyID, err := strconv.ParseUint(c.Params("keyId"), 10, 64)
+	if err != nil {
+		return httpapi.ResError(c, errors.NotFound(appphrases.ApiKeyNotFound, "API key not found"))
+	}
+
+	cmd := &commands.RevokeApiKey{ServiceAccountID: serviceAccountID, ApiKeyID: apiKeyID, ActorID: identity.UserID}
+	if err := s.bus.Handle(ctx, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// parseServiceAccountID reads the :id route parameter. An ID that is not a
+// number cannot name a service account, so it is reported as not found.
+func parseServiceAccountID(c fiber.Ctx) (uint64, error) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return 0, errors.NotFound(appphrases.ServiceAccountNotFound, "Service account not found")
+	}
+
+	return id, nil
+}
@@ -1,61 +1,102 @@
 package handler
 
 import (
-	"image/png"
+	stderrors "errors"
 
 	"shikposh-backend/internal/account/adapter"
 	"shikposh-backend/internal/account/domain/commands"
 	"shikposh-backend/internal/account/service_layer/command_handler"
+	"shikposh-backend/pkg/middleware"
+	appphrases "shikposh-backend/pkg/phrases"
 	httpapi "github.com/ali-mahdavi-dev/framework/api/http"
 	"github.com/ali-mahdavi-dev/framework/errors"
 	"github.com/ali-mahdavi-dev/framework/errors/phrases"
 	"github.com/ali-mahdavi-dev/framework/service_layer/messagebus"
 
 	"github.com/gofiber/fiber/v3"
-	"github.com/spf13/cast"
 )
 
+// avatarMaxAge lets browsers and CDNs keep avatars for a year; an avatar
+// never changes for the same identifier and options.
+const avatarMaxAge = "public, max-age=31536000, immutable"
+
 type UserController struct {
 	bus         messagebus.MessageBus
 	ag          *adapter.AvatarGenerator
 	userHandler *command_handler.UserHandler
+	mw          *middleware.Middleware
 }
 
-func NewUserController(bus messagebus.MessageBus, ag *adapter.AvatarGenerator, userHandler *command_handler.UserHandler) *UserController {
+func NewUserController(bus messagebus.MessageBus, ag *adapter.AvatarGenerator, userHandler *command_handler.UserHandler, mw *middleware.Middleware) *UserController {
 	return &UserController{
 		bus:         bus,
 		ag:          ag,
 		userHandler: userHandler,
+		mw:          mw,
 	}
 }
 
 func (u *UserController) RegisterRoutes(r fiber.Router) {
-	publicRoute := r.Group("/api/v1/public")
+	publicRoute := r.Group("/api/v1/public", u.mw.OptionalAuthMiddleware())
 	{
-		publicRoute.Post("/avatar/:id", u.GenerateAvatarHandler)
+		publicRoute.Get("/avatar/:id", u.GenerateAvatarHandler)
 		publicRoute.Post("/register", u.Register)
 		publicRoute.Post("/login", u.Login)
-		publicRoute.Post("/logout", u.Logout)
+		publicRoute.Post("/login/2fa", u.VerifyTwoFactor)
+		publicRoute.Post("/token/refresh", u.RefreshToken)
+		publicRoute.Post("/verify-email", u.VerifyEmail)
+		publicRoute.Post("/verify-email/resend", u.ResendVerificationEmail)
+		publicRoute.Post("/password/forgot", u.RequestPasswordReset)
+		publicRoute.Post("/password/reset", u.ResetPassword)
+		publicRoute.Post("/account/unlock", u.UnlockAccount)
+
+		// Private routes
+		publicRoute.Post("/logout", u.mw.AuthMiddleware(), u.Logout)
+		publicRoute.Post("/password/change", u.mw.AuthMiddleware(), u.ChangePassword)
+		publicRoute.Post("/2fa/enroll", u.mw.AuthMiddleware(), u.EnrollTotp)
+		publicRoute.Post("/2fa/confirm", u.mw.AuthMiddleware(), u.ConfirmTotp)
 	}
 }
 
+// GenerateAvatarHandler godoc
+//
+//	@Summary		Get the avatar of an identifier
+//	@Description	Returns the generated avatar of the identifier. The same identifier and options always give the same image, so responses carry an ETag and may be cached for a year.
+//	@Tags			users
+//	@Produce		png
+//	@Produce		jpeg
+//	@Produce		image/webp
+//	@Param			id			path		string					true	"Avatar identifier"
+//	@Param			size		query		int						false	"Width and height in pixels, 16 to 512, 256 by default"
+//	@Param			format		query		string					false	"png (default), webp or jpeg"
+//	@Param			background	query		string					false	"Background colour as hex, e.g. ffcc00; transparent by default, white for jpeg"
+//	@Success		200			{file}		binary					"Avatar image"
+//	@Success		304			"Not modified"
+//	@Failure		400			{object}	httpapi.ResponseResult	"Invalid size, format or background"
+//	@Failure		500			{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/public/avatar/{id} [get]
 func (u *UserController) GenerateAvatarHandler(c fiber.Ctx) error {
 	identifier := c.Params("id")
 
-	img, err := u.ag.Generate(identifier)
+	opts, err := adapter.ParseAvatarOptions(c.Query("size"), c.Query("format"), c.Query("background"))
 	if err != nil {
-		return httpapi.ResError(c, err)
+		return httpapi.ResError(c, errors.Validation(appphrases.InvalidAvatarOptions, err.Error()))
 	}
 
-	// Set headers for PNG response
-	c.Set("Content-Type", "image/png")
+	etag := u.ag.ETag(identifier, opts)
+	c.Set(fiber.HeaderCacheControl, avatarMaxAge)
+	c.Set(fiber.HeaderETag, etag)
+	if c.Get(fiber.HeaderIfNoneMatch) == etag {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
 
-	// Encode image directly to response
-	if err := png.Encode(c.Response().BodyWriter(), img); err != nil {
+	avatar, err := u.ag.Render(identifier, opts)
+	if err != nil {
 		return httpapi.ResError(c, err)
 	}
 
-	return nil
+	c.Set(fiber.HeaderContentType, avatar.ContentType)
+	return c.Send(avatar.Body)
 }
 
 // Register godoc
@@ -91,16 +132,19 @@ func (u *UserController) Register(c fiber.Ctx) error {
 // Login godoc
 //
 //	@Summary		Login user
-//	@Description	Authenticates a user and returns an access token.
+//	@Description	Authenticates a user, opens a session for the calling device and returns an access and a refresh token.
+//	@Description	Users with two-factor authentication get a challenge token for /api/v1/public/login/2fa instead.
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
-//	@Param			request	body		commands.LoginUser		true	"LoginUser"
-//	@Success		200		{object}	map[string]string		"Access token"
-//	@Failure		400		{object}	httpapi.ResponseResult	"Invalid request body or unknown provider"
-//	@Failure		401		{object}	httpapi.ResponseResult	"Authentication failed"
-//	@Failure		422		{object}	httpapi.ResponseResult	"Unprocessable input (validation failed)"
-//	@Failure		500		{object}	httpapi.ResponseResult	"Internal server error"
+//	@Param			request	body		commands.LoginUser				true	"LoginUser"
+//	@Success		200		{object}	command_handler.LoginResult	"Access and refresh tokens"
+//	@Failure		400		{object}	httpapi.ResponseResult			"Invalid request body or unknown provider"
+//	@Failure		401		{object}	httpapi.ResponseResult			"Authentication failed"
+//	@Failure		403		{object}	httpapi.ResponseResult			"Account locked after too many failed logins"
+//	@Failure		422		{object}	httpapi.ResponseResult			"Unprocessable input (validation failed)"
+//	@Failure		429		{object}	httpapi.ResponseResult			"Too many failed logins, try again later"
+//	@Failure		500		{object}	httpapi.ResponseResult			"Internal server error"
 //	@Router			/api/v1/public/login [post]
 func (u *UserController) Login(c fiber.Ctx) error {
 	ctx := c.Context()
@@ -109,22 +153,61 @@ func (u *UserController) Login(c fiber.Ctx) error {
 	if err := httpapi.ParseJSON(c, cmd); err != nil {
 		return httpapi.ResError(c, err)
 	}
+	cmd.UserAgent = c.Get(fiber.HeaderUserAgent)
+	cmd.IP = c.IP()
 
-	accessToken, err := u.userHandler.LoginHandler(ctx, cmd)
+	result, err := u.userHandler.LoginHandler(ctx, cmd)
+	u.mw.Audit(ctx, cmd, err)
 	if err != nil {
-		return httpapi.ResError(c, err)
+		return resError(c, err)
 	}
 
 	// Set token in response header
-	c.Set("Authorization", "Bearer "+accessToken)
+	if result.Access != "" {
+		c.Set("Authorization", "Bearer "+result.Access)
+	}
 
-	return httpapi.ResSuccess(c, map[string]string{"access": accessToken})
+	return httpapi.ResSuccess(c, result)
+}
+
+// RefreshToken godoc
+//
+//	@Summary		Refresh tokens
+//	@Description	Exchanges a refresh token for a new access and refresh token. Refresh tokens are single-use; reusing one revokes the session.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		commands.RefreshToken			true	"RefreshToken"
+//	@Success		200		{object}	command_handler.LoginResult	"Access and refresh tokens"
+//	@Failure		400		{object}	httpapi.ResponseResult			"Invalid request body"
+//	@Failure		401		{object}	httpapi.ResponseResult			"Invalid, expired or reused refresh token"
+//	@Failure		422		{object}	httpapi.ResponseResult			"Unprocessable input (validation failed)"
+//	@Failure		500		{object}	httpapi.ResponseResult			"Internal server error"
+//	@Router			/api/v1/public/token/refresh [post]
+func (u *UserController) RefreshToken(c fiber.Ctx) error {
+	ctx := c.Context()
+	cmd := new(commands.RefreshToken)
+
+	if err := httpapi.ParseJSON(c, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+	cmd.UserAgent = c.Get(fiber.HeaderUserAgent)
+	cmd.IP = c.IP()
+
+	result, err := u.userHandler.RefreshHandler(ctx, cmd)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	c.Set("Authorization", "Bearer "+result.Access)
+
+	return httpapi.ResSuccess(c, result)
 }
 
 // Logout godoc
 //
 //	@Summary		Logout user
-//	@Description	Logs out the authenticated user.
+//	@Description	Logs out the current session of the authenticated user. Other devices stay logged in.
 //	@Description	Example success response: {"success": true}
 //	@Description	Example error response: {"success": false, "error": {"code": "USER_NOT_FOUND", "message": "User not found", "status": "Not Found"}}
 //	@Tags			users
@@ -140,13 +223,14 @@ func (u *UserController) Login(c fiber.Ctx) error {
 func (u *UserController) Logout(c fiber.Ctx) error {
 	ctx := c.Context()
 
-	userID := c.Get("user_id")
-	if userID == "" {
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
 		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
 	}
 
 	cmd := new(commands.Logout)
-	cmd.UserID = cast.ToUint64(userID)
+	cmd.UserID = identity.UserID
+	cmd.SessionID = identity.SessionID
 
 	err := u.bus.Handle(ctx, cmd)
 	if err != nil {
@@ -155,3 +239,290 @@ func (u *UserController) Logout(c fiber.Ctx) error {
 
 	return c.SendStatus(fiber.StatusNoContent)
 }
+
+// VerifyEmail godoc
+//
+//	@Summary		Verify email address
+//	@Description	Confirms the email address a verification token was sent to. Each token can be used once.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		commands.VerifyEmail	true	"VerifyEmail request"
+//	@Success		204		"Email verified"
+//	@Failure		400		{object}	httpapi.ResponseResult	"Invalid or expired verification token"
+//	@Failure		500		{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/public/verify-email [post]
+func (u *UserController) VerifyEmail(c fiber.Ctx) error {
+	ctx := c.Context()
+	cmd := new(commands.VerifyEmail)
+
+	if err := httpapi.ParseJSON(c, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	err := u.bus.Handle(ctx, cmd)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ResendVerificationEmail godoc
+//
+//	@Summary		Resend verification email
+//	@Description	Sends a new verification link to an unverified email address. The response does not reveal whether the address is registered.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		commands.ResendVerificationEmail	true	"ResendVerificationEmail request"
+//	@Success		204		"Verification email sent"
+//	@Failure		400		{object}	httpapi.ResponseResult	"Invalid request body"
+//	@Failure		429		{object}	httpapi.ResponseResult	"Verification email requested too often"
+//	@Failure		500		{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/public/verify-email/resend [post]
+func (u *UserController) ResendVerificationEmail(c fiber.Ctx) error {
+	ctx := c.Context()
+	cmd := new(commands.ResendVerificationEmail)
+
+	if err := httpapi.ParseJSON(c, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	err := u.bus.Handle(ctx, cmd)
+	if err != nil {
+		return resError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RequestPasswordReset godoc
+//
+//	@Summary		Request a password reset
+//	@Description	Mails a single-use password reset link. The response does not reveal whether the address is registered.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		commands.RequestPasswordReset	true	"RequestPasswordReset request"
+//	@Success		204		"Reset link sent if the address is registered"
+//	@Failure		400		{object}	httpapi.ResponseResult	"Invalid request body"
+//	@Failure		500		{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/public/password/forgot [post]
+func (u *UserController) RequestPasswordReset(c fiber.Ctx) error {
+	ctx := c.Context()
+	cmd := new(commands.RequestPasswordReset)
+
+	if err := httpapi.ParseJSON(c, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	err := u.bus.Handle(ctx, cmd)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ResetPassword godoc
+//
+//	@Summary		Reset password
+//	@Description	Sets a new password using a reset token and logs the user out of every device.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		commands.ResetPassword	true	"ResetPassword request"
+//	@Success		204		"Password changed"
+//	@Failure		400		{object}	httpapi.ResponseResult	"Invalid or expired reset token, or the password breaks the password rules"
+//	@Failure		500		{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/public/password/reset [post]
+func (u *UserController) ResetPassword(c fiber.Ctx) error {
+	ctx := c.Context()
+	cmd := new(commands.ResetPassword)
+
+	if err := httpapi.ParseJSON(c, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	err := u.bus.Handle(ctx, cmd)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// UnlockAccount godoc
+//
+//	@Summary		Unlock account
+//	@Description	Lifts a lockout caused by failed logins using the single-use token mailed when the account was locked.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		commands.UnlockAccount	true	"UnlockAccount request"
+//	@Success		204		"Account unlocked"
+//	@Failure		400		{object}	httpapi.ResponseResult	"Invalid or expired unlock token"
+//	@Failure		500		{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/public/account/unlock [post]
+func (u *UserController) UnlockAccount(c fiber.Ctx) error {
+	ctx := c.Context()
+	cmd := new(commands.UnlockAccount)
+
+	if err := httpapi.ParseJSON(c, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	err := u.bus.Handle(ctx, cmd)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ChangePassword godoc
+//
+//	@Summary		Change password
+//	@Description	Replaces the password of the authenticated user. Every session is revoked, so the user has to log in again.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		commands.ChangePassword	true	"ChangePassword request"
+//	@Success		204		"Password changed"
+//	@Failure		400		{object}	httpapi.ResponseResult	"Current password is incorrect or the new password breaks the password rules"
+//	@Failure		401		{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		500		{object}	httpapi.ResponseResult	"Internal server error"
+//	@Router			/api/v1/public/password/change [post]
+func (u *UserController) ChangePassword(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	cmd := new(commands.ChangePassword)
+	if err := httpapi.ParseJSON(c, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+	cmd.UserID = identity.UserID
+
+	err := u.bus.Handle(ctx, cmd)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// VerifyTwoFactor godoc
+//
+//	@Summary		Complete a two-factor login
+//	@Description	Exchanges the challenge token of a login and a TOTP or recovery code for an access and a refresh token.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		commands.VerifyTwoFactor		true	"VerifyTwoFactor request"
+//	@Success		200		{object}	command_handler.LoginResult	"Access and refresh tokens"
+//	@Failure		400		{object}	httpapi.ResponseResult			"Invalid request body"
+//	@Failure		401		{object}	httpapi.ResponseResult			"Invalid code, or invalid or expired challenge"
+//	@Failure		500		{object}	httpapi.ResponseResult			"Internal server error"
+//	@Router			/api/v1/public/login/2fa [post]
+func (u *UserController) VerifyTwoFactor(c fiber.Ctx) error {
+	ctx := c.Context()
+	cmd := new(commands.VerifyTwoFactor)
+
+	if err := httpapi.ParseJSON(c, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+	cmd.UserAgent = c.Get(fiber.HeaderUserAgent)
+	cmd.IP = c.IP()
+
+	result, err := u.userHandler.VerifyTwoFactorHandler(ctx, cmd)
+	u.mw.Audit(ctx, cmd, err)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	c.Set("Authorization", "Bearer "+result.Access)
+
+	return httpapi.ResSuccess(c, result)
+}
+
+// EnrollTotp godoc
+//
+//	@Summary		Start two-factor enrollment
+//	@Description	Generates a TOTP secret and its otpauth:// provisioning URI for a QR code. Two-factor authentication stays off until it is confirmed.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	command_handler.TotpEnrollmentResult	"TOTP secret and provisioning URI"
+//	@Failure		401	{object}	httpapi.ResponseResult					"User not authenticated"
+//	@Failure		409	{object}	httpapi.ResponseResult					"Two-factor authentication is already enabled"
+//	@Failure		500	{object}	httpapi.ResponseResult					"Internal server error"
+//	@Router			/api/v1/public/2fa/enroll [post]
+func (u *UserController) EnrollTotp(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	cmd := &commands.EnrollTotp{UserID: identity.UserID}
+
+	result, err := u.userHandler.EnrollTotpHandler(ctx, cmd)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return httpapi.ResSuccess(c, result)
+}
+
+// ConfirmTotp godoc
+//
+//	@Summary		Confirm two-factor enrollment
+//	@Description	Enables two-factor authentication with a code from the authenticator app and returns single-use recovery codes. They are shown only once.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		commands.ConfirmTotp					true	"ConfirmTotp request"
+//	@Success		200		{object}	command_handler.RecoveryCodesResult	"Recovery codes"
+//	@Failure		400		{object}	httpapi.ResponseResult					"Invalid code or enrollment not started"
+//	@Failure		401		{object}	httpapi.ResponseResult					"User not authenticated"
+//	@Failure		409		{object}	httpapi.ResponseResult					"Two-factor authentication is already enabled"
+//	@Failure		500		{object}	httpapi.ResponseResult					"Internal server error"
+//	@Router			/api/v1/public/2fa/confirm [post]
+func (u *UserController) ConfirmTotp(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, errors.NotFound(phrases.UserNotFound))
+	}
+
+	cmd := new(commands.ConfirmTotp)
+	if err := httpapi.ParseJSON(c, cmd); err != nil {
+		return httpapi.ResError(c, err)
+	}
+	cmd.UserID = identity.UserID
+
+	result, err := u.userHandler.ConfirmTotpHandler(ctx, cmd)
+	u.mw.Audit(ctx, cmd, err)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return httpapi.ResSuccess(c, result)
+}
+
+// resError answers rate-limited requests with 429 and leaves every other
+// error to httpapi.ResError.
+func resError(c fiber.Ctx, err error) error {
+	if stderrors.Is(err, command_handler.ErrTooManyRequests) {
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": command_handler.ErrTooManyRequests.Error()})
+	}
+
+	return httpapi.ResError(c, err)
+}
@@ -7,9 +7,25 @@ import (
 )
 
 type UserManagementRouter struct {
-	User *handler.UserController
+	User           *handler.UserController
+	Otp            *handler.OtpController
+	Profile        *handler.ProfileController
+	Address        *handler.AddressController
+	AdminUser      *handler.AdminUserController
+	ServiceAccount *handler.ServiceAccountController
+	Jwks           *handler.JwksController
+	Oidc           *handler.OidcController
+	Audit          *handler.AuditController
 }
 
 func NewAccountRouter(router fiber.Router, controller UserManagementRouter) {
 	controller.User.RegisterRoutes(router)
+	controller.Otp.RegisterRoutes(router)
+	controller.Profile.RegisterRoutes(router)
+	controller.Address.RegisterRoutes(router)
+	controller.AdminUser.RegisterRoutes(router)
+	controller.ServiceAccount.RegisterRoutes(router)
+	controller.Jwks.RegisterRoutes(router)
+	controller.Oidc.RegisterRoutes(router)
+	controller.Audit.RegisterRoutes(router)
 }
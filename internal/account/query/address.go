@@ -0,0 +1,101 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/entity"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"shikposh-backend/internal/unit_of_work"
+	"shikposh-backend/pkg/phrases"
+)
+
+// AddressDetails is an address of the authenticated user as returned by /api/v1/me/addresses.
+type AddressDetails struct {
+	ID            uint64    `json:"id"`
+	RecipientName string    `json:"recipient_name"`
+	Phone         string    `json:"phone"`
+	Province      string    `json:"province"`
+	City          string    `json:"city"`
+	PostalCode    string    `json:"postal_code"`
+	Street        string    `json:"street"`
+	Plate         string    `json:"plate"`
+	Unit          string    `json:"unit"`
+	Latitude      *float64  `json:"latitude,omitempty"`
+	Longitude     *float64  `json:"longitude,omitempty"`
+	IsDefault     bool      `json:"is_default"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func NewAddressDetails(address *entity.Address) *AddressDetails {
+	return &AddressDetails{
+		ID:            uint64(address.ID),
+		RecipientName: address.RecipientName,
+		Phone:         address.Phone,
+		Province:      address.Province,
+		City:          address.City,
+		PostalCode:    address.PostalCode,
+		Street:        address.Street,
+		Plate:         address.Plate,
+		Unit:          address.Unit,
+		Latitude:      address.Latitude,
+		Longitude:     address.Longitude,
+		IsDefault:     address.IsDefault,
+		CreatedAt:     address.CreatedAt,
+		UpdatedAt:     address.UpdatedAt,
+	}
+}
+
+type AddressQueryHandler struct {
+	uow unitofwork.PGUnitOfWork
+}
+
+func NewAddressQueryHandler(uow unitofwork.PGUnitOfWork) *AddressQueryHandler {
+	return &AddressQueryHandler{uow: uow}
+}
+
+// GetAddressesByUserID returns the address book of a user, the default address first.
+func (h *AddressQueryHandler) GetAddressesByUserID(ctx context.Context, userID entity.UserID) ([]*AddressDetails, error) {
+	var addresses []*entity.Address
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		addresses, err = h.uow.Address(ctx).FindByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*AddressDetails, len(addresses))
+	for i, address := range addresses {
+		result[i] = NewAddressDetails(address)
+	}
+	return result, nil
+}
+
+// GetAddress returns an address of a user. Addresses of other users are not found.
+func (h *AddressQueryHandler) GetAddress(ctx context.Context, id entity.AddressID, userID entity.UserID) (*AddressDetails, error) {
+	var address *entity.Address
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		address, err = h.uow.Address(ctx).FindByIDAndUserID(ctx, id, userID)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrAddressNotFound) {
+			return nil, apperrors.NotFound(phrases.AddressNotFound, "Address not found")
+		}
+		return nil, err
+	}
+
+	return NewAddressDetails(address), nil
+}
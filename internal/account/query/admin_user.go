@@ -0,0 +1,199 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/entity"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"github.com/ali-mahdavi-dev/framework/errors/phrases"
+)
+
+const (
+	defaultUserListLimit = 20
+	maxUserListLimit     = 100
+)
+
+// UserListFilter selects a page of users for /api/v1/admin/users. Search
+// matches part of the username or email address, or a phone number.
+type UserListFilter struct {
+	Search string
+	Skip   int
+	Limit  int
+}
+
+// UserSummary is a user account as listed by /api/v1/admin/users.
+type UserSummary struct {
+	ID               uint64     `json:"id"`
+	UserName         string     `json:"user_name"`
+	FirstName        string     `json:"first_name"`
+	LastName         string     `json:"last_name"`
+	Email            string     `json:"email"`
+	EmailVerified    bool       `json:"email_verified"`
+	Phone            string     `json:"phone"`
+	AvatarURL        string     `json:"avatar_url"`
+	Roles            []string   `json:"roles"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+	Disabled         bool       `json:"disabled"`
+	LockedUntil      *time.Time `json:"locked_until,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// SessionDetails is a signed-in device of a user.
+type SessionDetails struct {
+	ID         uint64    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// AdminUserDetails is a user account as returned by /api/v1/admin/users/{id}.
+type AdminUserDetails struct {
+	UserSummary
+	Profile  *ProfileDetails   `json:"profile"`
+	Sessions []*SessionDetails `json:"sessions"`
+}
+
+func newUserSummary(user *entity.User, phone string) *UserSummary {
+	roles := make([]string, len(user.Roles))
+	for i, role := range user.Roles {
+		roles[i] = role.Name
+	}
+
+	return &UserSummary{
+		ID:               uint64(user.ID),
+		UserName:         user.UserName,
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Email:            user.Email,
+		EmailVerified:    user.IsEmailVerified(),
+		Phone:            phone,
+		AvatarURL:        user.AvatarLink(),
+		Roles:            roles,
+		TwoFactorEnabled: user.IsTwoFactorEnabled(),
+		Disabled:         user.IsDisabled(),
+		LockedUntil:      user.LockedUntil,
+		CreatedAt:        user.CreatedAt,
+	}
+}
+
+// ListUsers returns a page of the users matching the filter, the newest
+// first, along with the number of matching users.
+func (h *UserQueryHandler) ListUsers(ctx context.Context, filter UserListFilter) ([]*UserSummary, int64, error) {
+	search := repository.UserSearch{
+		Query: strings.TrimSpace(filter.Search),
+		Skip:  filter.Skip,
+		Limit: filter.Limit,
+	}
+	if search.Skip < 0 {
+		search.Skip = 0
+	}
+	if search.Limit <= 0 {
+		search.Limit = defaultUserListLimit
+	}
+	if search.Limit > maxUserListLimit {
+		search.Limit = maxUserListLimit
+	}
+	if phone, err := entity.NormalizePhone(search.Query); err == nil {
+		search.Phone = phone
+	}
+
+	var (
+		users    []*entity.User
+		profiles []*entity.Profile
+		total    int64
+	)
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		users, total, err = h.uow.User(ctx).Search(ctx, search)
+		if err != nil {
+			return err
+		}
+
+		userIDs := make([]entity.UserID, len(users))
+		for i, user := range users {
+			userIDs[i] = user.ID
+		}
+		profiles, err = h.uow.Profile(ctx).FindByUserIDs(ctx, userIDs)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	phones := make(map[entity.UserID]string, len(profiles))
+	for _, profile := range profiles {
+		phones[profile.UserID] = profile.Phone
+	}
+
+	result := make([]*UserSummary, len(users))
+	for i, user := range users {
+		result[i] = newUserSummary(user, phones[user.ID])
+	}
+	return result, total, nil
+}
+
+// GetAdminUserDetails returns a user account with its profile and the
+// sessions that are still active.
+func (h *UserQueryHandler) GetAdminUserDetails(ctx context.Context, id entity.UserID) (*AdminUserDetails, error) {
+	var (
+		user     *entity.User
+		profile  *entity.Profile
+		sessions []*entity.Session
+	)
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = h.uow.User(ctx).FindByIDWithRoles(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		profile, err = h.uow.Profile(ctx).FindByUserID(ctx, id)
+		if err != nil && !errors.Is(err, repository.ErrProfileNotFound) {
+			return err
+		}
+
+		sessions, err = h.uow.Session(ctx).FindActiveByUserID(ctx, id, time.Now())
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, apperrors.NotFound(phrases.UserNotFound)
+		}
+		return nil, err
+	}
+
+	details := &AdminUserDetails{
+		Profile:  &ProfileDetails{UserID: uint64(id)},
+		Sessions: make([]*SessionDetails, len(sessions)),
+	}
+	phone := ""
+	if profile != nil {
+		phone = profile.Phone
+		details.Profile.Bio = profile.Bio
+		details.Profile.Phone = profile.Phone
+	}
+	details.UserSummary = *newUserSummary(user, phone)
+	for i, session := range sessions {
+		details.Sessions[i] = &SessionDetails{
+			ID:         uint64(session.ID),
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+		}
+	}
+	return details, nil
+}
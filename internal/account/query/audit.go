@@ -0,0 +1,119 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/entity"
+	"shikposh-backend/internal/unit_of_work"
+)
+
+const (
+	defaultAuditListLimit = 50
+	maxAuditListLimit     = 200
+)
+
+// AuditEventFilter selects a page of the audit log for /api/v1/admin/audit.
+// Empty fields match every entry; From is inclusive and To exclusive.
+type AuditEventFilter struct {
+	ActorType  string
+	ActorID    *uint64
+	Action     string
+	TargetType string
+	TargetID   string
+	Outcome    string
+	IP         string
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+	Skip       int
+	Limit      int
+}
+
+// AuditEventDetails is an entry of the audit log.
+type AuditEventDetails struct {
+	ID         uint64    `json:"id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	ActorType  string    `json:"actor_type,omitempty"`
+	ActorID    *uint64   `json:"actor_id,omitempty"`
+	Action     string    `json:"action"`
+	TargetType string    `json:"target_type,omitempty"`
+	TargetID   string    `json:"target_id,omitempty"`
+	IP         string    `json:"ip,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	RequestID  string    `json:"request_id,omitempty"`
+	Outcome    string    `json:"outcome"`
+	Reason     string    `json:"reason,omitempty"`
+}
+
+type AuditQueryHandler struct {
+	uow unitofwork.PGUnitOfWork
+}
+
+func NewAuditQueryHandler(uow unitofwork.PGUnitOfWork) *AuditQueryHandler {
+	return &AuditQueryHandler{uow: uow}
+}
+
+// ListAuditEvents returns a page of the entries matching the filter, the
+// newest first, along with the number of matching entries.
+func (h *AuditQueryHandler) ListAuditEvents(ctx context.Context, filter AuditEventFilter) ([]*AuditEventDetails, int64, error) {
+	search := repository.AuditEventSearch{
+		ActorType:  filter.ActorType,
+		ActorID:    filter.ActorID,
+		Action:     filter.Action,
+		TargetType: filter.TargetType,
+		TargetID:   filter.TargetID,
+		Outcome:    entity.AuditOutcome(filter.Outcome),
+		IP:         filter.IP,
+		RequestID:  filter.RequestID,
+		From:       filter.From,
+		To:         filter.To,
+		Skip:       filter.Skip,
+		Limit:      filter.Limit,
+	}
+	if search.Skip < 0 {
+		search.Skip = 0
+	}
+	if search.Limit <= 0 {
+		search.Limit = defaultAuditListLimit
+	}
+	if search.Limit > maxAuditListLimit {
+		search.Limit = maxAuditListLimit
+	}
+
+	var (
+		events []*entity.AuditEvent
+		total  int64
+	)
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		events, total, err = h.uow.AuditEvent(ctx).Search(ctx, search)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]*AuditEventDetails, len(events))
+	for i, event := range events {
+		result[i] = &AuditEventDetails{
+			ID:         uint64(event.ID),
+			OccurredAt: event.OccurredAt,
+			ActorType:  event.ActorType,
+			ActorID:    event.ActorID,
+			Action:     event.Action,
+			TargetType: event.TargetType,
+			TargetID:   event.TargetID,
+			IP:         event.IP,
+			UserAgent:  event.UserAgent,
+			RequestID:  event.RequestID,
+			Outcome:    string(event.Outcome),
+			Reason:     event.Reason,
+		}
+	}
+	return result, total, nil
+}
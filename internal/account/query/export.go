@@ -0,0 +1,112 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/internal/account/domain/entity"
+	productentity "shikposh-backend/internal/products/domain/entity"
+	"shikposh-backend/internal/unit_of_work"
+)
+
+// PersonalDataExport is the archive of everything stored about a user, as
+// returned by /api/v1/me/export. Data kept by other modules, such as orders,
+// gets its own field here.
+type PersonalDataExport struct {
+	ExportedAt time.Time         `json:"exported_at"`
+	User       *UserDetails      `json:"user"`
+	Profile    *ProfileDetails   `json:"profile"`
+	Addresses  []*AddressDetails `json:"addresses"`
+	Reviews    []*ReviewExport   `json:"reviews"`
+}
+
+// ReviewExport is a review written by the user.
+type ReviewExport struct {
+	ID          uint64    `json:"id"`
+	ProductID   uint64    `json:"product_id"`
+	ProductName string    `json:"product_name,omitempty"`
+	UserName    string    `json:"user_name"`
+	Rating      int       `json:"rating"`
+	Comment     string    `json:"comment"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type DataExportQueryHandler struct {
+	uow       unitofwork.PGUnitOfWork
+	users     *UserQueryHandler
+	profiles  *ProfileQueryHandler
+	addresses *AddressQueryHandler
+}
+
+func NewDataExportQueryHandler(uow unitofwork.PGUnitOfWork) *DataExportQueryHandler {
+	return &DataExportQueryHandler{
+		uow:       uow,
+		users:     NewUserQueryHandler(uow),
+		profiles:  NewProfileQueryHandler(uow),
+		addresses: NewAddressQueryHandler(uow),
+	}
+}
+
+// ExportPersonalData assembles the personal data export of a user.
+func (h *DataExportQueryHandler) ExportPersonalData(ctx context.Context, userID entity.UserID) (*PersonalDataExport, error) {
+	user, err := h.users.GetUserDetails(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := h.profiles.GetProfileDetails(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, err := h.addresses.GetAddressesByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews, err := h.getReviews(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PersonalDataExport{
+		ExportedAt: time.Now().UTC(),
+		User:       user,
+		Profile:    profile,
+		Addresses:  addresses,
+		Reviews:    reviews,
+	}, nil
+}
+
+func (h *DataExportQueryHandler) getReviews(ctx context.Context, userID entity.UserID) ([]*ReviewExport, error) {
+	var reviews []*productentity.Review
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		reviews, err = h.uow.Review(ctx).FindByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*ReviewExport, len(reviews))
+	for i, review := range reviews {
+		result[i] = &ReviewExport{
+			ID:        uint64(review.ID),
+			ProductID: uint64(review.ProductID),
+			UserName:  review.UserName,
+			Rating:    review.Rating,
+			Comment:   review.Comment,
+			CreatedAt: review.CreatedAt,
+			UpdatedAt: review.UpdatedAt,
+		}
+		if review.Product != nil {
+			result[i].ProductName = review.Product.Name
+		}
+	}
+	return result, nil
+}
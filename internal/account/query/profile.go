@@ -2,11 +2,20 @@ package query
 
 import (
 	"context"
+	"errors"
 
+	"shikposh-backend/internal/account/adapter/repository"
 	"shikposh-backend/internal/account/domain/entity"
 	"shikposh-backend/internal/unit_of_work"
 )
 
+// ProfileDetails is the profile of the authenticated user as returned by /api/v1/me/profile.
+type ProfileDetails struct {
+	UserID uint64 `json:"user_id"`
+	Bio    string `json:"bio"`
+	Phone  string `json:"phone"`
+}
+
 type ProfileQueryHandler struct {
 	uow unitofwork.PGUnitOfWork
 }
@@ -40,3 +49,21 @@ func (h *ProfileQueryHandler) GetProfileByUserID(ctx context.Context, userID ent
 	})
 	return profile, err
 }
+
+// GetProfileDetails returns the profile of a user. A profile that was not
+// created yet is returned empty.
+func (h *ProfileQueryHandler) GetProfileDetails(ctx context.Context, userID entity.UserID) (*ProfileDetails, error) {
+	profile, err := h.GetProfileByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrProfileNotFound) {
+			return &ProfileDetails{UserID: uint64(userID)}, nil
+		}
+		return nil, err
+	}
+
+	return &ProfileDetails{
+		UserID: uint64(profile.UserID),
+		Bio:    profile.Bio,
+		Phone:  profile.Phone,
+	}, nil
+}
@@ -0,0 +1,95 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/internal/account/domain/entity"
+	"shikposh-backend/internal/unit_of_work"
+)
+
+// ApiKeyDetails describes an API key without revealing it; Prefix is the
+// beginning of the key, enough to tell the keys of an account apart.
+type ApiKeyDetails struct {
+	ID         uint64     `json:"id"`
+	Prefix     string     `json:"prefix"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// IssuedApiKey is returned once, when the key is issued. Key cannot be
+// retrieved again.
+type IssuedApiKey struct {
+	ApiKeyDetails
+	Key string `json:"key"`
+}
+
+// ServiceAccountDetails is a service account as returned by
+// /api/v1/admin/service-accounts.
+type ServiceAccountDetails struct {
+	ID          uint64           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Scopes      []string         `json:"scopes"`
+	ApiKeys     []*ApiKeyDetails `json:"api_keys"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+func NewApiKeyDetails(key *entity.ApiKey) *ApiKeyDetails {
+	return &ApiKeyDetails{
+		ID:         uint64(key.ID),
+		Prefix:     key.Prefix,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+func NewServiceAccountDetails(account *entity.ServiceAccount) *ServiceAccountDetails {
+	keys := make([]*ApiKeyDetails, len(account.ApiKeys))
+	for i, key := range account.ApiKeys {
+		keys[i] = NewApiKeyDetails(key)
+	}
+
+	return &ServiceAccountDetails{
+		ID:          uint64(account.ID),
+		Name:        account.Name,
+		Description: account.Description,
+		Scopes:      account.ScopeNames(),
+		ApiKeys:     keys,
+		CreatedAt:   account.CreatedAt,
+	}
+}
+
+type ServiceAccountQueryHandler struct {
+	uow unitofwork.PGUnitOfWork
+}
+
+func NewServiceAccountQueryHandler(uow unitofwork.PGUnitOfWork) *ServiceAccountQueryHandler {
+	return &ServiceAccountQueryHandler{uow: uow}
+}
+
+// ListServiceAccounts returns every service account with its scopes and keys.
+func (h *ServiceAccountQueryHandler) ListServiceAccounts(ctx context.Context) ([]*ServiceAccountDetails, error) {
+	var accounts []*entity.ServiceAccount
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		accounts, err = h.uow.ServiceAccount(ctx).FindAll(ctx)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*ServiceAccountDetails, len(accounts))
+	for i, account := range accounts {
+		result[i] = NewServiceAccountDetails(account)
+	}
+	return result, nil
+}
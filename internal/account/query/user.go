@@ -2,11 +2,30 @@ package query
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"github.com/ali-mahdavi-dev/framework/errors/phrases"
 	"shikposh-backend/internal/unit_of_work"
 )
 
+// UserDetails is the account of the authenticated user as returned by /api/v1/me.
+type UserDetails struct {
+	ID               uint64    `json:"id"`
+	UserName         string    `json:"user_name"`
+	FirstName        string    `json:"first_name"`
+	LastName         string    `json:"last_name"`
+	Email            string    `json:"email"`
+	EmailVerified    bool      `json:"email_verified"`
+	AvatarIdentifier string    `json:"avatar_identifier"`
+	AvatarURL        string    `json:"avatar_url"`
+	TwoFactorEnabled bool      `json:"two_factor_enabled"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
 type UserQueryHandler struct {
 	uow unitofwork.PGUnitOfWork
 }
@@ -40,3 +59,28 @@ func (h *UserQueryHandler) GetUserByUserName(ctx context.Context, username strin
 	})
 	return user, err
 }
+
+// GetUserDetails returns the account details of a user without secrets such
+// as the password hash.
+func (h *UserQueryHandler) GetUserDetails(ctx context.Context, id entity.UserID) (*UserDetails, error) {
+	user, err := h.GetUserByID(ctx, uint64(id))
+	if err != nil {
+		if errors.Is(err, adapter.ErrEntityNotFound) {
+			return nil, apperrors.NotFound(phrases.UserNotFound)
+		}
+		return nil, err
+	}
+
+	return &UserDetails{
+		ID:               uint64(user.ID),
+		UserName:         user.UserName,
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Email:            user.Email,
+		EmailVerified:    user.IsEmailVerified(),
+		AvatarIdentifier: user.AvatarIdentifier,
+		AvatarURL:        user.AvatarLink(),
+		TwoFactorEnabled: user.IsTwoFactorEnabled(),
+		CreatedAt:        user.CreatedAt,
+	}, nil
+}
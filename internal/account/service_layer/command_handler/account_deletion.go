@@ -0,0 +1,114 @@
+package command_handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"shikposh-backend/internal/account/adapter"
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/commands"
+	appadapter "github.com/ali-mahdavi-dev/framework/adapter"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"github.com/ali-mahdavi-dev/framework/errors/phrases"
+	appphrases "shikposh-backend/pkg/phrases"
+
+	"github.com/pkg/errors"
+)
+
+// defaultDeletionGracePeriod is used when account.deletionGracePeriod is not configured.
+const defaultDeletionGracePeriod = 30 * 24 * time.Hour
+
+// DeletedUserName replaces the author name on the reviews of a closed account.
+const DeletedUserName = "Deleted user"
+
+// DeleteAccountHandler closes the account right away: the user is
+// soft-deleted, every session and refresh token is revoked, their reviews are
+// anonymised, their uploaded avatar is deleted and their profile is removed so
+// the phone number can be reused.
+// The rest of their data is erased by PurgeDeletedAccounts once the grace
+// period has passed.
+func (h *UserHandler) DeleteAccountHandler(ctx context.Context, cmd *commands.DeleteAccount) error {
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := h.uow.User(ctx).FindByID(ctx, cmd.UserID)
+		if err != nil {
+			if errors.Is(err, appadapter.ErrEntityNotFound) {
+				return apperrors.NotFound(phrases.UserNotFound)
+			}
+			return fmt.Errorf("UserHandler.DeleteAccountHandler fail get user: %w", err)
+		}
+
+		if user.Password != "" {
+			if err := adapter.ComparePassword(user.Password, cmd.Password); err != nil {
+				return apperrors.Validation(appphrases.InvalidCurrentPassword, "Current password is incorrect")
+			}
+		}
+
+		now := time.Now()
+		if err := h.uow.Session(ctx).RevokeByUserID(ctx, user.ID, now); err != nil {
+			return fmt.Errorf("UserHandler.DeleteAccountHandler fail revoke sessions: %w", err)
+		}
+		if err := h.uow.RefreshToken(ctx).RevokeByUserID(ctx, user.ID); err != nil {
+			return fmt.Errorf("UserHandler.DeleteAccountHandler fail revoke refresh tokens: %w", err)
+		}
+
+		if err := h.uow.Review(ctx).AnonymizeByUserID(ctx, user.ID, DeletedUserName); err != nil {
+			return fmt.Errorf("UserHandler.DeleteAccountHandler fail anonymize reviews: %w", err)
+		}
+
+		profile, err := h.uow.Profile(ctx).FindByUserID(ctx, user.ID)
+		switch {
+		case err == nil:
+			if err := h.uow.Profile(ctx).Remove(ctx, profile, true); err != nil {
+				return fmt.Errorf("UserHandler.DeleteAccountHandler fail delete profile: %w", err)
+			}
+		case !errors.Is(err, repository.ErrProfileNotFound):
+			return fmt.Errorf("UserHandler.DeleteAccountHandler fail get profile: %w", err)
+		}
+
+		user.RemoveUploadedAvatar()
+		user.ScheduleDeletion(now.Add(h.deletionGracePeriod()))
+		if err := h.uow.User(ctx).Modify(ctx, user); err != nil {
+			return fmt.Errorf("UserHandler.DeleteAccountHandler fail update user: %w", err)
+		}
+		if err := h.uow.User(ctx).Remove(ctx, user, true); err != nil {
+			return fmt.Errorf("UserHandler.DeleteAccountHandler fail delete user: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PurgeDeletedAccounts erases the closed accounts whose grace period ended
+// before now and returns how many were erased.
+func (h *UserHandler) PurgeDeletedAccounts(ctx context.Context, now time.Time) (int64, error) {
+	var purged int64
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		purged, err = h.uow.User(ctx).PurgeDeleted(ctx, now)
+		if err != nil {
+			return fmt.Errorf("UserHandler.PurgeDeletedAccounts fail purge users: %w", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+func (h *UserHandler) deletionGracePeriod() time.Duration {
+	if h.cfg.Account.DeletionGracePeriod <= 0 {
+		return defaultDeletionGracePeriod
+	}
+
+	return h.cfg.Account.DeletionGracePeriod * time.Hour
+}
@@ -0,0 +1,208 @@
+package command_handler
+
+import (
+	"context"
+	"fmt"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"shikposh-backend/internal/unit_of_work"
+	appphrases "shikposh-backend/pkg/phrases"
+
+	"github.com/pkg/errors"
+)
+
+// AddressHandler manages the address book of a user. The first address of a
+// user becomes the default one, and deleting the default address promotes
+// the most recent remaining one.
+type AddressHandler struct {
+	uow unitofwork.PGUnitOfWork
+}
+
+func NewAddressHandler(uow unitofwork.PGUnitOfWork) *AddressHandler {
+	return &AddressHandler{uow: uow}
+}
+
+func (h *AddressHandler) CreateAddressHandler(ctx context.Context, cmd *commands.CreateAddress) (*entity.Address, error) {
+	fields, err := addressFields(cmd.SaveAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	var address *entity.Address
+	err = h.uow.Do(ctx, func(ctx context.Context) error {
+		userID := entity.UserID(cmd.UserID)
+
+		existing, err := h.uow.Address(ctx).FindByUserID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("AddressHandler.CreateAddressHandler fail get addresses: %w", err)
+		}
+
+		address = entity.NewAddress(userID, fields)
+		address.IsDefault = cmd.IsDefault || len(existing) == 0
+
+		if address.IsDefault && len(existing) > 0 {
+			if err := h.uow.Address(ctx).ClearDefault(ctx, userID); err != nil {
+				return fmt.Errorf("AddressHandler.CreateAddressHandler fail clear default address: %w", err)
+			}
+		}
+
+		if err := h.uow.Address(ctx).Save(ctx, address); err != nil {
+			return fmt.Errorf("AddressHandler.CreateAddressHandler fail save address: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return address, nil
+}
+
+// UpdateAddressHandler replaces an address. Setting is_default makes it the
+// default address; the default address cannot be unset this way.
+func (h *AddressHandler) UpdateAddressHandler(ctx context.Context, cmd *commands.UpdateAddress) error {
+	fields, err := addressFields(cmd.SaveAddress)
+	if err != nil {
+		return err
+	}
+
+	err = h.uow.Do(ctx, func(ctx context.Context) error {
+		address, err := h.findAddress(ctx, cmd.AddressID, cmd.UserID)
+		if err != nil {
+			return err
+		}
+
+		address.Update(fields)
+		if cmd.IsDefault && !address.IsDefault {
+			if err := h.uow.Address(ctx).ClearDefault(ctx, address.UserID); err != nil {
+				return fmt.Errorf("AddressHandler.UpdateAddressHandler fail clear default address: %w", err)
+			}
+			address.IsDefault = true
+		}
+
+		if err := h.uow.Address(ctx).Modify(ctx, address); err != nil {
+			return fmt.Errorf("AddressHandler.UpdateAddressHandler fail update address: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (h *AddressHandler) DeleteAddressHandler(ctx context.Context, cmd *commands.DeleteAddress) error {
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		address, err := h.findAddress(ctx, cmd.AddressID, cmd.UserID)
+		if err != nil {
+			return err
+		}
+
+		if err := h.uow.Address(ctx).Remove(ctx, address, true); err != nil {
+			return fmt.Errorf("AddressHandler.DeleteAddressHandler fail delete address: %w", err)
+		}
+
+		if !address.IsDefault {
+			return nil
+		}
+
+		remaining, err := h.uow.Address(ctx).FindByUserID(ctx, address.UserID)
+		if err != nil {
+			return fmt.Errorf("AddressHandler.DeleteAddressHandler fail get addresses: %w", err)
+		}
+		if len(remaining) == 0 {
+			return nil
+		}
+
+		remaining[0].IsDefault = true
+		if err := h.uow.Address(ctx).Modify(ctx, remaining[0]); err != nil {
+			return fmt.Errorf("AddressHandler.DeleteAddressHandler fail promote default address: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (h *AddressHandler) SetDefaultAddressHandler(ctx context.Context, cmd *commands.SetDefaultAddress) error {
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		address, err := h.findAddress(ctx, cmd.AddressID, cmd.UserID)
+		if err != nil {
+			return err
+		}
+
+		if address.IsDefault {
+			return nil
+		}
+
+		if err := h.uow.Address(ctx).ClearDefault(ctx, address.UserID); err != nil {
+			return fmt.Errorf("AddressHandler.SetDefaultAddressHandler fail clear default address: %w", err)
+		}
+
+		address.IsDefault = true
+		if err := h.uow.Address(ctx).Modify(ctx, address); err != nil {
+			return fmt.Errorf("AddressHandler.SetDefaultAddressHandler fail update address: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// findAddress returns the address only if it belongs to the user, so one
+// user cannot tell the addresses of another apart from missing ones.
+func (h *AddressHandler) findAddress(ctx context.Context, addressID, userID uint64) (*entity.Address, error) {
+	address, err := h.uow.Address(ctx).FindByIDAndUserID(ctx, entity.AddressID(addressID), entity.UserID(userID))
+	if err != nil {
+		if errors.Is(err, repository.ErrAddressNotFound) {
+			return nil, apperrors.NotFound(appphrases.AddressNotFound, "Address not found")
+		}
+		return nil, fmt.Errorf("AddressHandler.findAddress fail get address: %w", err)
+	}
+
+	return address, nil
+}
+
+// addressFields normalises the phone number and postal code of an address.
+func addressFields(cmd commands.SaveAddress) (entity.AddressFields, error) {
+	phone, err := entity.NormalizePhone(cmd.Phone)
+	if err != nil {
+		return entity.AddressFields{}, apperrors.Validation(appphrases.InvalidPhone, "Invalid mobile phone number")
+	}
+
+	postalCode, err := entity.NormalizePostalCode(cmd.PostalCode)
+	if err != nil {
+		return entity.AddressFields{}, apperrors.Validation(appphrases.InvalidPostalCode, "Invalid postal code")
+	}
+
+	return entity.AddressFields{
+		RecipientName: cmd.RecipientName,
+		Phone:         phone,
+		Province:      cmd.Province,
+		City:          cmd.City,
+		PostalCode:    postalCode,
+		Street:        cmd.Street,
+		Plate:         cmd.Plate,
+		Unit:          cmd.Unit,
+		Latitude:      cmd.Latitude,
+		Longitude:     cmd.Longitude,
+	}, nil
+}
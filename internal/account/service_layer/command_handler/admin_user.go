@@ -0,0 +1,190 @@
+package command_handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	appadapter "github.com/ali-mahdavi-dev/framework/adapter"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"github.com/ali-mahdavi-dev/framework/errors/phrases"
+	appphrases "shikposh-backend/pkg/phrases"
+
+	"github.com/pkg/errors"
+)
+
+// DisableUserHandler refuses every further login of the user and signs them
+// out on every device. Administrators cannot disable their own account.
+func (h *UserHandler) DisableUserHandler(ctx context.Context, cmd *commands.DisableUser) error {
+	if cmd.UserID == cmd.ActorID {
+		return apperrors.Validation(appphrases.CannotManageOwnAccount, "You cannot disable your own account")
+	}
+
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := h.findManagedUser(ctx, cmd.UserID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		user.Disable(entity.UserID(cmd.ActorID), now)
+		if err := h.uow.User(ctx).Modify(ctx, user); err != nil {
+			return fmt.Errorf("UserHandler.DisableUserHandler fail update user: %w", err)
+		}
+
+		if err := h.revokeAllSessions(ctx, user.ID, now); err != nil {
+			return fmt.Errorf("UserHandler.DisableUserHandler fail revoke sessions: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (h *UserHandler) EnableUserHandler(ctx context.Context, cmd *commands.EnableUser) error {
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := h.findManagedUser(ctx, cmd.UserID)
+		if err != nil {
+			return err
+		}
+
+		user.Enable(entity.UserID(cmd.ActorID))
+		if err := h.uow.User(ctx).Modify(ctx, user); err != nil {
+			return fmt.Errorf("UserHandler.EnableUserHandler fail update user: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ForceLogoutHandler revokes every session of the user, so each of their
+// devices has to sign in again.
+func (h *UserHandler) ForceLogoutHandler(ctx context.Context, cmd *commands.ForceLogoutUser) error {
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := h.findManagedUser(ctx, cmd.UserID)
+		if err != nil {
+			return err
+		}
+
+		if err := h.revokeAllSessions(ctx, user.ID, time.Now()); err != nil {
+			return fmt.Errorf("UserHandler.ForceLogoutHandler fail revoke sessions: %w", err)
+		}
+
+		user.ForceLogout(entity.UserID(cmd.ActorID))
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AdminResetPasswordHandler invalidates the password of the user, signs them
+// out and mails them a password reset link. Users without an email address
+// cannot receive the link.
+func (h *UserHandler) AdminResetPasswordHandler(ctx context.Context, cmd *commands.AdminResetPassword) error {
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := h.findManagedUser(ctx, cmd.UserID)
+		if err != nil {
+			return err
+		}
+
+		if user.Email == "" {
+			return apperrors.Validation(appphrases.EmailRequired, "User has no email address to send the reset link to")
+		}
+
+		user.ResetPasswordByAdmin(entity.UserID(cmd.ActorID))
+		if err := h.uow.User(ctx).Modify(ctx, user); err != nil {
+			return fmt.Errorf("UserHandler.AdminResetPasswordHandler fail update user: %w", err)
+		}
+
+		if err := h.revokeAllSessions(ctx, user.ID, time.Now()); err != nil {
+			return fmt.Errorf("UserHandler.AdminResetPasswordHandler fail revoke sessions: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ChangeUserRolesHandler replaces the roles of the user. Administrators
+// cannot change their own roles, so they cannot lock themselves out.
+func (h *UserHandler) ChangeUserRolesHandler(ctx context.Context, cmd *commands.ChangeUserRoles) error {
+	if cmd.UserID == cmd.ActorID {
+		return apperrors.Validation(appphrases.CannotManageOwnAccount, "You cannot change your own roles")
+	}
+
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := h.findManagedUser(ctx, cmd.UserID)
+		if err != nil {
+			return err
+		}
+
+		roles := make([]*entity.Role, 0, len(cmd.Roles))
+		for _, name := range cmd.Roles {
+			role, err := h.uow.Role(ctx).FindByName(ctx, name)
+			if err != nil {
+				if errors.Is(err, repository.ErrRoleNotFound) {
+					return apperrors.NotFound(appphrases.RoleNotFound, fmt.Sprintf("Role %q not found", name))
+				}
+				return fmt.Errorf("UserHandler.ChangeUserRolesHandler fail get role: %w", err)
+			}
+			roles = append(roles, role)
+		}
+
+		user.ReplaceRoles(roles, entity.UserID(cmd.ActorID))
+		if err := h.uow.User(ctx).ReplaceRoles(ctx, user, roles); err != nil {
+			return fmt.Errorf("UserHandler.ChangeUserRolesHandler fail replace roles: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (h *UserHandler) findManagedUser(ctx context.Context, id uint64) (*entity.User, error) {
+	user, err := h.uow.User(ctx).FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, appadapter.ErrEntityNotFound) {
+			return nil, apperrors.NotFound(phrases.UserNotFound)
+		}
+		return nil, fmt.Errorf("UserHandler.findManagedUser fail get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// revokeAllSessions signs the user out on every device.
+func (h *UserHandler) revokeAllSessions(ctx context.Context, userID entity.UserID, now time.Time) error {
+	if err := h.uow.Session(ctx).RevokeByUserID(ctx, userID, now); err != nil {
+		return err
+	}
+
+	return h.uow.RefreshToken(ctx).RevokeByUserID(ctx, userID)
+}
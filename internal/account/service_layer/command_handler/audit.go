@@ -0,0 +1,52 @@
+package command_handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"shikposh-backend/config"
+	"shikposh-backend/internal/unit_of_work"
+)
+
+// defaultAuditRetention is used when audit.retention is not configured.
+const defaultAuditRetention = 365 * 24 * time.Hour
+
+// AuditHandler maintains the audit log. Entries are written by the
+// CommandAudit middleware and AuditEventHandler.
+type AuditHandler struct {
+	uow unitofwork.PGUnitOfWork
+	cfg *config.Config
+}
+
+func NewAuditHandler(uow unitofwork.PGUnitOfWork, cfg *config.Config) *AuditHandler {
+	return &AuditHandler{uow: uow, cfg: cfg}
+}
+
+// PurgeAuditEvents deletes the entries older than audit.retention and
+// returns how many were deleted.
+func (h *AuditHandler) PurgeAuditEvents(ctx context.Context, now time.Time) (int64, error) {
+	var purged int64
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		purged, err = h.uow.AuditEvent(ctx).PurgeBefore(ctx, now.Add(-h.retention()))
+		if err != nil {
+			return fmt.Errorf("AuditHandler.PurgeAuditEvents fail purge audit events: %w", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+func (h *AuditHandler) retention() time.Duration {
+	if h.cfg.Audit.Retention <= 0 {
+		return defaultAuditRetention
+	}
+
+	return h.cfg.Audit.Retention * time.Hour
+}
@@ -0,0 +1,150 @@
+package command_handler
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+
+	"shikposh-backend/config"
+	"shikposh-backend/internal/account/adapter"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	appadapter "github.com/ali-mahdavi-dev/framework/adapter"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"github.com/ali-mahdavi-dev/framework/errors/phrases"
+	"github.com/ali-mahdavi-dev/framework/infrastructure/logging"
+	"shikposh-backend/internal/unit_of_work"
+	appphrases "shikposh-backend/pkg/phrases"
+
+	"github.com/pkg/errors"
+)
+
+// defaultAvatarMaxUploadSize is used when avatar.maxUploadSize is not configured.
+const defaultAvatarMaxUploadSize = 2 << 20
+
+// AvatarHandler manages uploaded avatars. Users without one, or whose upload
+// was removed or rejected, are shown their generated avatar.
+type AvatarHandler struct {
+	uow     unitofwork.PGUnitOfWork
+	cfg     *config.Config
+	storage adapter.FileStorage
+}
+
+func NewAvatarHandler(uow unitofwork.PGUnitOfWork, cfg *config.Config, storage adapter.FileStorage) *AvatarHandler {
+	return &AvatarHandler{uow: uow, cfg: cfg, storage: storage}
+}
+
+// UploadAvatarHandler stores the uploaded image, cropped and scaled to the
+// avatar size, as the avatar of the user and shows it on their reviews.
+func (h *AvatarHandler) UploadAvatarHandler(ctx context.Context, cmd *commands.UploadAvatar) error {
+	if int64(len(cmd.Content)) > h.maxUploadSize() {
+		return apperrors.Validation(appphrases.AvatarTooLarge, fmt.Sprintf("Avatar must be at most %d KB", h.maxUploadSize()>>10))
+	}
+
+	avatar, err := adapter.ProcessAvatarUpload(cmd.Content)
+	if err != nil {
+		if errors.Is(err, adapter.ErrUnsupportedAvatarUpload) || errors.Is(err, adapter.ErrAvatarUploadDimensions) {
+			return apperrors.Validation(appphrases.InvalidAvatarUpload, err.Error())
+		}
+		return err
+	}
+
+	// Every upload gets a new key, so caches never serve a replaced avatar.
+	key := fmt.Sprintf("avatars/%d-%s.png", cmd.UserID, rand.Text())
+	if err := h.storage.Save(ctx, key, avatar); err != nil {
+		return fmt.Errorf("AvatarHandler.UploadAvatarHandler fail store avatar: %w", err)
+	}
+
+	err = h.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := h.findUser(ctx, cmd.UserID)
+		if err != nil {
+			return err
+		}
+
+		user.SetUploadedAvatar(key, h.storage.URL(key))
+		return h.saveAvatar(ctx, user)
+	})
+
+	if err != nil {
+		if deleteErr := h.storage.Delete(ctx, key); deleteErr != nil {
+			logging.Warn("Failed to delete unused avatar").
+				WithString("key", key).
+				WithError(deleteErr).
+				Log()
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (h *AvatarHandler) RemoveAvatarHandler(ctx context.Context, cmd *commands.RemoveAvatar) error {
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := h.findUser(ctx, cmd.UserID)
+		if err != nil {
+			return err
+		}
+
+		if !user.RemoveUploadedAvatar() {
+			return nil
+		}
+		return h.saveAvatar(ctx, user)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (h *AvatarHandler) RejectAvatarHandler(ctx context.Context, cmd *commands.RejectAvatar) error {
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := h.findUser(ctx, cmd.UserID)
+		if err != nil {
+			return err
+		}
+
+		if !user.RejectUploadedAvatar(entity.UserID(cmd.ActorID)) {
+			return nil
+		}
+		return h.saveAvatar(ctx, user)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// saveAvatar persists the avatar of the user and shows it on their reviews.
+func (h *AvatarHandler) saveAvatar(ctx context.Context, user *entity.User) error {
+	if err := h.uow.User(ctx).Modify(ctx, user); err != nil {
+		return fmt.Errorf("AvatarHandler.saveAvatar fail update user: %w", err)
+	}
+	if err := h.uow.Review(ctx).UpdateUserAvatar(ctx, user.ID, user.AvatarLink()); err != nil {
+		return fmt.Errorf("AvatarHandler.saveAvatar fail update reviews: %w", err)
+	}
+
+	return nil
+}
+
+func (h *AvatarHandler) findUser(ctx context.Context, userID uint64) (*entity.User, error) {
+	user, err := h.uow.User(ctx).FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, appadapter.ErrEntityNotFound) {
+			return nil, apperrors.NotFound(phrases.UserNotFound)
+		}
+		return nil, fmt.Errorf("AvatarHandler.findUser fail get user: %w", err)
+	}
+
+	return user, nil
+}
+
+func (h *AvatarHandler) maxUploadSize() int64 {
+	if h.cfg.Avatar.MaxUploadSize <= 0 {
+		return defaultAvatarMaxUploadSize
+	}
+	return h.cfg.Avatar.MaxUploadSize << 10
+}
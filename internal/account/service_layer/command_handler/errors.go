@@ -0,0 +1,6 @@
+package command_handler
+
+import "github.com/pkg/errors"
+
+// ErrTooManyRequests is returned when a rate-limited action is repeated too soon.
+var ErrTooManyRequests = errors.New("too many requests, try again later")
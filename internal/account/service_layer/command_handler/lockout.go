@@ -0,0 +1,198 @@
+package command_handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"shikposh-backend/internal/account/adapter"
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"github.com/ali-mahdavi-dev/framework/infrastructure/logging"
+	appphrases "shikposh-backend/pkg/phrases"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultLoginFailureWindow is used when login.failureWindow is not configured.
+	defaultLoginFailureWindow = 15 * time.Minute
+	// defaultLoginBackoffMax is used when login.backoffMax is not configured.
+	defaultLoginBackoffMax = 5 * time.Minute
+	// defaultLockoutDuration is used when login.lockoutDuration is not configured.
+	defaultLockoutDuration = 30 * time.Minute
+)
+
+// loginAttemptKeys are the keys failed logins are counted under.
+type loginAttemptKeys struct {
+	user string
+	ip   string
+}
+
+func newLoginAttemptKeys(userName, ip string) loginAttemptKeys {
+	keys := loginAttemptKeys{user: "user:" + strings.ToLower(userName)}
+	if ip != "" {
+		keys.ip = "ip:" + ip
+	}
+
+	return keys
+}
+
+// UnlockAccountHandler lifts a lockout using the single-use token mailed
+// when the account was locked.
+func (h *UserHandler) UnlockAccountHandler(ctx context.Context, cmd *commands.UnlockAccount) error {
+	invalid := apperrors.Validation(appphrases.InvalidAccountUnlock, "Invalid or expired unlock token")
+	var userName string
+
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		now := time.Now()
+
+		token, err := h.uow.AccountUnlock(ctx).FindByTokenHash(ctx, entity.HashToken(cmd.Token))
+		if err != nil {
+			if errors.Is(err, repository.ErrAccountUnlockTokenNotFound) {
+				return invalid
+			}
+			return fmt.Errorf("UserHandler.UnlockAccountHandler fail get unlock token: %w", err)
+		}
+
+		if !token.IsUsable(now) {
+			return invalid
+		}
+
+		used, err := h.uow.AccountUnlock(ctx).MarkUsed(ctx, token, now)
+		if err != nil {
+			return fmt.Errorf("UserHandler.UnlockAccountHandler fail mark unlock token used: %w", err)
+		}
+		if !used {
+			return invalid
+		}
+
+		user, err := h.uow.User(ctx).FindByID(ctx, uint64(token.UserID))
+		if err != nil {
+			return fmt.Errorf("UserHandler.UnlockAccountHandler fail get user: %w", err)
+		}
+
+		user.Unlock()
+		if err := h.uow.User(ctx).Modify(ctx, user); err != nil {
+			return fmt.Errorf("UserHandler.UnlockAccountHandler fail update user: %w", err)
+		}
+
+		userName = user.UserName
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if err := h.attempts.Reset(ctx, newLoginAttemptKeys(userName, "").user); err != nil {
+		return fmt.Errorf("UserHandler.UnlockAccountHandler fail reset login failures: %w", err)
+	}
+
+	return nil
+}
+
+// checkLoginThrottle rejects the login while the username or the IP is
+// blocked after earlier failures.
+func (h *UserHandler) checkLoginThrottle(ctx context.Context, keys loginAttemptKeys) error {
+	for _, key := range []string{keys.user, keys.ip} {
+		if key == "" {
+			continue
+		}
+
+		blockedFor, err := h.attempts.BlockedFor(ctx, key)
+		if err != nil {
+			return fmt.Errorf("fail get login block: %w", err)
+		}
+		if blockedFor > 0 {
+			adapter.LoginFailures.WithLabelValues(adapter.LoginFailureThrottled).Inc()
+			return ErrTooManyRequests
+		}
+	}
+
+	return nil
+}
+
+// recordLoginFailure counts a failed login against the username and the IP,
+// and locks the account once it reached login.lockoutThreshold failures.
+// user is nil when the username is unknown.
+func (h *UserHandler) recordLoginFailure(ctx context.Context, keys loginAttemptKeys, user *entity.User, now time.Time) error {
+	adapter.LoginFailures.WithLabelValues(adapter.LoginFailureInvalidCredentials).Inc()
+
+	userFailures, err := h.registerLoginFailure(ctx, keys.user, h.cfg.Login.FreeAttempts)
+	if err != nil {
+		return err
+	}
+	if keys.ip != "" {
+		if _, err := h.registerLoginFailure(ctx, keys.ip, h.cfg.Login.IPFreeAttempts); err != nil {
+			return err
+		}
+	}
+
+	threshold := h.cfg.Login.LockoutThreshold
+	if user == nil || threshold <= 0 || userFailures < threshold {
+		return nil
+	}
+
+	user.Lock(now.Add(h.lockoutDuration()))
+	if err := h.uow.User(ctx).Modify(ctx, user); err != nil {
+		return fmt.Errorf("fail lock user: %w", err)
+	}
+
+	// The lock replaces the backoff, so the count starts over once it ends
+	if err := h.attempts.Reset(ctx, keys.user); err != nil {
+		return fmt.Errorf("fail reset login failures: %w", err)
+	}
+
+	adapter.AccountLockouts.Inc()
+	logging.Warn("Account locked after too many failed logins").
+		WithInt64("user_id", int64(user.ID)).
+		Log()
+
+	return nil
+}
+
+// registerLoginFailure counts the failure and blocks the key for the
+// backoff delay it earned.
+func (h *UserHandler) registerLoginFailure(ctx context.Context, key string, free int) (int, error) {
+	failures, err := h.attempts.RegisterFailure(ctx, key, h.loginFailureWindow())
+	if err != nil {
+		return 0, fmt.Errorf("fail register login failure: %w", err)
+	}
+
+	delay := adapter.LoginBackoff(failures, free, h.cfg.Login.BackoffBase*time.Second, h.loginBackoffMax())
+	if delay > 0 {
+		if err := h.attempts.Block(ctx, key, delay); err != nil {
+			return 0, fmt.Errorf("fail block login: %w", err)
+		}
+	}
+
+	return failures, nil
+}
+
+func (h *UserHandler) loginFailureWindow() time.Duration {
+	if h.cfg.Login.FailureWindow <= 0 {
+		return defaultLoginFailureWindow
+	}
+
+	return h.cfg.Login.FailureWindow * time.Second
+}
+
+func (h *UserHandler) loginBackoffMax() time.Duration {
+	if h.cfg.Login.BackoffMax <= 0 {
+		return defaultLoginBackoffMax
+	}
+
+	return h.cfg.Login.BackoffMax * time.Second
+}
+
+func (h *UserHandler) lockoutDuration() time.Duration {
+	if h.cfg.Login.LockoutDuration <= 0 {
+		return defaultLockoutDuration
+	}
+
+	return h.cfg.Login.LockoutDuration * time.Second
+}
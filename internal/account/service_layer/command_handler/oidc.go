@@ -0,0 +1,239 @@
+package command_handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"shikposh-backend/config"
+	"shikposh-backend/internal/account/adapter"
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	appadapter "github.com/ali-mahdavi-dev/framework/adapter"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"github.com/ali-mahdavi-dev/framework/errors/phrases"
+	"github.com/ali-mahdavi-dev/framework/infrastructure/logging"
+	"shikposh-backend/internal/unit_of_work"
+	appphrases "shikposh-backend/pkg/phrases"
+
+	"github.com/pkg/errors"
+)
+
+// defaultOidcAuthorizationExpireTime is used when oidc.authorizationExpireTime is not configured.
+const defaultOidcAuthorizationExpireTime = 10 * time.Minute
+
+// OidcLoginStart is where the client sends the user to sign in with a provider.
+type OidcLoginStart struct {
+	AuthorizationURL string `json:"authorization_url"`
+	ExpiresIn        int64  `json:"expires_in"`
+}
+
+// OidcHandler signs users in with OpenID Connect providers, using the
+// authorization code flow with PKCE. The first sign-in of an unknown
+// provider account creates its user.
+type OidcHandler struct {
+	uow       unitofwork.PGUnitOfWork
+	cfg       *config.Config
+	providers adapter.IdentityProviders
+	users     *UserHandler
+}
+
+func NewOidcHandler(uow unitofwork.PGUnitOfWork, cfg *config.Config, providers adapter.IdentityProviders, users *UserHandler) *OidcHandler {
+	return &OidcHandler{uow: uow, cfg: cfg, providers: providers, users: users}
+}
+
+// StartOidcLoginHandler remembers a new sign-in attempt and returns the
+// provider URL the user has to be sent to.
+func (h *OidcHandler) StartOidcLoginHandler(ctx context.Context, cmd *commands.StartOidcLogin) (*OidcLoginStart, error) {
+	provider, err := h.providers.Get(cmd.Provider)
+	if err != nil {
+		return nil, apperrors.Validation(appphrases.UnknownIdentityProvider, "Unknown identity provider")
+	}
+
+	authorization, state, err := entity.NewOidcAuthorization(cmd.Provider, time.Now().Add(h.authorizationExpireTime()))
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, authorization.Nonce, authorization.CodeChallenge())
+	if err != nil {
+		return nil, fmt.Errorf("OidcHandler.StartOidcLoginHandler fail build authorization url: %w", err)
+	}
+
+	err = h.uow.Do(ctx, func(ctx context.Context) error {
+		if err := h.uow.OidcAuthorization(ctx).Save(ctx, authorization); err != nil {
+			return fmt.Errorf("OidcHandler.StartOidcLoginHandler fail save authorization: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &OidcLoginStart{
+		AuthorizationURL: authURL,
+		ExpiresIn:        int64(h.authorizationExpireTime().Seconds()),
+	}, nil
+}
+
+// CompleteOidcLoginHandler redeems the code the provider sent the user back
+// with and opens a session of the user linked to the provider account.
+func (h *OidcHandler) CompleteOidcLoginHandler(ctx context.Context, cmd *commands.CompleteOidcLogin) (*LoginResult, error) {
+	provider, err := h.providers.Get(cmd.Provider)
+	if err != nil {
+		return nil, apperrors.Validation(appphrases.UnknownIdentityProvider, "Unknown identity provider")
+	}
+
+	authorization, err := h.consumeAuthorization(ctx, cmd.Provider, cmd.State)
+	if err != nil {
+		return nil, err
+	}
+
+	// The provider is called outside of any transaction, so a slow provider
+	// does not hold a database connection.
+	identity, err := provider.Exchange(ctx, cmd.Code, authorization.CodeVerifier, authorization.Nonce)
+	if err != nil {
+		if errors.Is(err, adapter.ErrIdentityProviderRejected) || errors.Is(err, adapter.ErrInvalidIdentityToken) {
+			logging.Warn("Identity provider sign-in rejected").
+				WithString("provider", cmd.Provider).
+				WithError(err).
+				Log()
+			return nil, apperrors.Unauthorized(appphrases.InvalidOidcAuthorization, "Sign-in with the identity provider failed")
+		}
+		return nil, fmt.Errorf("OidcHandler.CompleteOidcLoginHandler fail exchange code: %w", err)
+	}
+
+	var result *LoginResult
+	err = h.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := h.findOrCreateUser(ctx, identity)
+		if err != nil {
+			return err
+		}
+
+		if user.IsDisabled() {
+			return apperrors.Forbidden(appphrases.AccountDisabled, "Account is disabled")
+		}
+
+		result, err = h.users.completeLogin(ctx, user, cmd.UserAgent, cmd.IP, time.Now())
+		if err != nil {
+			return fmt.Errorf("OidcHandler.CompleteOidcLoginHandler fail complete login: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// consumeAuthorization looks up the sign-in attempt the state belongs to and
+// marks it used, so every state is accepted once.
+func (h *OidcHandler) consumeAuthorization(ctx context.Context, providerName, state string) (*entity.OidcAuthorization, error) {
+	invalid := apperrors.Unauthorized(appphrases.InvalidOidcAuthorization, "Invalid or expired sign-in attempt")
+
+	var authorization *entity.OidcAuthorization
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		authorization, err = h.uow.OidcAuthorization(ctx).FindByStateHash(ctx, entity.HashToken(state))
+		if err != nil {
+			if errors.Is(err, repository.ErrOidcAuthorizationNotFound) {
+				return invalid
+			}
+			return fmt.Errorf("OidcHandler.consumeAuthorization fail get authorization: %w", err)
+		}
+
+		now := time.Now()
+		if authorization.Provider != providerName || !authorization.IsUsable(now) {
+			return invalid
+		}
+
+		consumed, err := h.uow.OidcAuthorization(ctx).MarkUsed(ctx, authorization, now)
+		if err != nil {
+			return fmt.Errorf("OidcHandler.consumeAuthorization fail mark authorization used: %w", err)
+		}
+		if !consumed {
+			return invalid
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return authorization, nil
+}
+
+// findOrCreateUser returns the user linked to the provider account. An
+// unknown account is linked to the user with the same email when both sides
+// verified that email, and gets a new user when nobody uses it.
+func (h *OidcHandler) findOrCreateUser(ctx context.Context, identity *adapter.ExternalIdentity) (*entity.User, error) {
+	linked, err := h.uow.ExternalIdentity(ctx).FindByProviderAndSubject(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		user, err := h.uow.User(ctx).FindByID(ctx, uint64(linked.UserID))
+		if err != nil {
+			if errors.Is(err, appadapter.ErrEntityNotFound) {
+				return nil, apperrors.NotFound(phrases.UserNotFound)
+			}
+			return nil, fmt.Errorf("OidcHandler.findOrCreateUser fail get user: %w", err)
+		}
+		return user, nil
+	}
+	if !errors.Is(err, repository.ErrExternalIdentityNotFound) {
+		return nil, fmt.Errorf("OidcHandler.findOrCreateUser fail get external identity: %w", err)
+	}
+
+	if identity.Email != "" {
+		owner, err := h.uow.User(ctx).FindByEmail(ctx, identity.Email)
+		if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+			return nil, fmt.Errorf("OidcHandler.findOrCreateUser fail get user by email: %w", err)
+		}
+		if owner != nil {
+			// Without both verifications the provider account may belong to
+			// someone else than the owner of the email here
+			if !identity.EmailVerified || !owner.IsEmailVerified() {
+				return nil, apperrors.Conflict(appphrases.EmailAlreadyInUse, "Email address is already in use by another account")
+			}
+			if err := h.link(ctx, owner, identity); err != nil {
+				return nil, err
+			}
+			return owner, nil
+		}
+	}
+
+	user := entity.NewExternalUser(identity.Provider, identity.Subject, identity.GivenName, identity.FamilyName, identity.Email, identity.EmailVerified, time.Now())
+	if err := h.uow.User(ctx).Save(ctx, user); err != nil {
+		return nil, fmt.Errorf("OidcHandler.findOrCreateUser fail save user: %w", err)
+	}
+	if err := h.link(ctx, user, identity); err != nil {
+		return nil, err
+	}
+
+	logging.Info("User created from identity provider login").
+		WithInt64("user_id", int64(user.ID)).
+		WithString("provider", identity.Provider).
+		Log()
+
+	return user, nil
+}
+
+func (h *OidcHandler) link(ctx context.Context, user *entity.User, identity *adapter.ExternalIdentity) error {
+	linked := entity.NewExternalIdentity(user.ID, identity.Provider, identity.Subject, identity.Email)
+	if err := h.uow.ExternalIdentity(ctx).Save(ctx, linked); err != nil {
+		return fmt.Errorf("OidcHandler.link fail save external identity: %w", err)
+	}
+
+	return nil
+}
+
+func (h *OidcHandler) authorizationExpireTime() time.Duration {
+	if h.cfg.Oidc.AuthorizationExpireTime <= 0 {
+		return defaultOidcAuthorizationExpireTime
+	}
+	return h.cfg.Oidc.AuthorizationExpireTime * time.Second
+}
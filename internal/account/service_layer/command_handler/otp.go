@@ -0,0 +1,215 @@
+package command_handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"time"
+
+	"shikposh-backend/config"
+	"shikposh-backend/internal/account/adapter"
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"github.com/ali-mahdavi-dev/framework/infrastructure/logging"
+	"shikposh-backend/internal/unit_of_work"
+	appphrases "shikposh-backend/pkg/phrases"
+
+	"github.com/pkg/errors"
+)
+
+// Defaults used when the otp section of the config leaves a value unset.
+const (
+	defaultOtpExpireTime  = 2 * time.Minute
+	defaultOtpDigits      = 6
+	defaultOtpLimiter     = time.Minute
+	defaultOtpMaxAttempts = 5
+)
+
+// OtpHandler signs users in with a one-time code sent to their mobile phone.
+type OtpHandler struct {
+	uow   unitofwork.PGUnitOfWork
+	cfg   *config.Config
+	store adapter.OtpStore
+	sms   adapter.SmsSender
+	users *UserHandler
+}
+
+func NewOtpHandler(uow unitofwork.PGUnitOfWork, cfg *config.Config, store adapter.OtpStore, sms adapter.SmsSender, users *UserHandler) *OtpHandler {
+	return &OtpHandler{uow: uow, cfg: cfg, store: store, sms: sms, users: users}
+}
+
+// RequestOtpHandler texts a new code to the phone, at most once per otp.limiter.
+func (h *OtpHandler) RequestOtpHandler(ctx context.Context, cmd *commands.RequestOtp) error {
+	phone, err := entity.NormalizePhone(cmd.Phone)
+	if err != nil {
+		return apperrors.Validation(appphrases.InvalidPhone, "Invalid mobile phone number")
+	}
+
+	acquired, err := h.store.Acquire(ctx, phone, h.limiter())
+	if err != nil {
+		return fmt.Errorf("OtpHandler.RequestOtpHandler fail acquire rate limit: %w", err)
+	}
+	if !acquired {
+		return ErrTooManyRequests
+	}
+
+	code, err := adapter.GenerateOtpCode(h.digits())
+	if err != nil {
+		return fmt.Errorf("OtpHandler.RequestOtpHandler fail generate code: %w", err)
+	}
+
+	if err := h.store.Save(ctx, phone, adapter.HashOtpCode(phone, code), h.expireTime()); err != nil {
+		return fmt.Errorf("OtpHandler.RequestOtpHandler fail save code: %w", err)
+	}
+
+	if err := h.sms.Send(ctx, phone, fmt.Sprintf("Shikposh login code: %s", code)); err != nil {
+		return fmt.Errorf("OtpHandler.RequestOtpHandler fail send sms: %w", err)
+	}
+
+	return nil
+}
+
+// VerifyOtpHandler exchanges a valid code for a new session. The first
+// successful verification of an unknown phone creates its account.
+func (h *OtpHandler) VerifyOtpHandler(ctx context.Context, cmd *commands.VerifyOtp) (*LoginResult, error) {
+	phone, err := entity.NormalizePhone(cmd.Phone)
+	if err != nil {
+		return nil, apperrors.Validation(appphrases.InvalidPhone, "Invalid mobile phone number")
+	}
+
+	if err := h.consumeCode(ctx, phone, cmd.Code); err != nil {
+		return nil, err
+	}
+
+	var result *LoginResult
+	err = h.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := h.findOrCreateUser(ctx, phone)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if user.IsLocked(now) {
+			adapter.LoginFailures.WithLabelValues(adapter.LoginFailureLocked).Inc()
+			return apperrors.Forbidden(appphrases.AccountLocked, "Account is temporarily locked after too many failed logins")
+		}
+
+		if user.IsDisabled() {
+			return apperrors.Forbidden(appphrases.AccountDisabled, "Account is disabled")
+		}
+
+		result, err = h.users.completeLogin(ctx, user, cmd.UserAgent, cmd.IP, now)
+		if err != nil {
+			return fmt.Errorf("OtpHandler.VerifyOtpHandler fail complete login: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// consumeCode checks the code and deletes it once it matched. A code is
+// dropped after otp.maxAttempts wrong guesses. The deletion only succeeds
+// for the code that was checked, so concurrent verifications of the same
+// code sign in at most once.
+func (h *OtpHandler) consumeCode(ctx context.Context, phone, code string) error {
+	invalid := apperrors.Unauthorized(appphrases.InvalidOtp, "Invalid or expired code")
+
+	codeHash, attempts, err := h.store.Get(ctx, phone)
+	if err != nil {
+		if errors.Is(err, adapter.ErrOtpNotFound) {
+			return invalid
+		}
+		return fmt.Errorf("OtpHandler.consumeCode fail get code: %w", err)
+	}
+
+	if attempts >= h.maxAttempts() {
+		if err := h.store.Delete(ctx, phone); err != nil {
+			return fmt.Errorf("OtpHandler.consumeCode fail delete code: %w", err)
+		}
+		return invalid
+	}
+
+	if subtle.ConstantTimeCompare([]byte(codeHash), []byte(adapter.HashOtpCode(phone, code))) != 1 {
+		if _, err := h.store.IncrAttempts(ctx, phone); err != nil && !errors.Is(err, adapter.ErrOtpNotFound) {
+			return fmt.Errorf("OtpHandler.consumeCode fail record attempt: %w", err)
+		}
+		return invalid
+	}
+
+	consumed, err := h.store.Consume(ctx, phone, codeHash)
+	if err != nil {
+		return fmt.Errorf("OtpHandler.consumeCode fail consume code: %w", err)
+	}
+	if !consumed {
+		return invalid
+	}
+
+	return nil
+}
+
+func (h *OtpHandler) findOrCreateUser(ctx context.Context, phone string) (*entity.User, error) {
+	profile, err := h.uow.Profile(ctx).FindByPhone(ctx, phone)
+	if err == nil {
+		user, err := h.uow.User(ctx).FindByID(ctx, uint64(profile.UserID))
+		if err != nil {
+			return nil, fmt.Errorf("OtpHandler.findOrCreateUser fail get user: %w", err)
+		}
+		return user, nil
+	}
+	if !errors.Is(err, repository.ErrProfileNotFound) {
+		return nil, fmt.Errorf("OtpHandler.findOrCreateUser fail get profile by phone: %w", err)
+	}
+
+	user := entity.NewPhoneUser(phone)
+	if err := h.uow.User(ctx).Save(ctx, user); err != nil {
+		return nil, fmt.Errorf("OtpHandler.findOrCreateUser fail save user: %w", err)
+	}
+
+	profile = entity.NewProfile(user.ID)
+	profile.Phone = phone
+	if err := h.uow.Profile(ctx).Save(ctx, profile); err != nil {
+		return nil, fmt.Errorf("OtpHandler.findOrCreateUser fail save profile: %w", err)
+	}
+
+	logging.Info("User created from phone login").
+		WithInt64("user_id", int64(user.ID)).
+		Log()
+
+	return user, nil
+}
+
+func (h *OtpHandler) expireTime() time.Duration {
+	if h.cfg.Otp.ExpireTime <= 0 {
+		return defaultOtpExpireTime
+	}
+	return h.cfg.Otp.ExpireTime * time.Second
+}
+
+func (h *OtpHandler) limiter() time.Duration {
+	if h.cfg.Otp.Limiter <= 0 {
+		return defaultOtpLimiter
+	}
+	return h.cfg.Otp.Limiter * time.Second
+}
+
+func (h *OtpHandler) digits() int {
+	if h.cfg.Otp.Digits <= 0 {
+		return defaultOtpDigits
+	}
+	return h.cfg.Otp.Digits
+}
+
+func (h *OtpHandler) maxAttempts() int {
+	if h.cfg.Otp.MaxAttempts <= 0 {
+		return defaultOtpMaxAttempts
+	}
+	return h.cfg.Otp.MaxAttempts
+}
@@ -0,0 +1,142 @@
+package command_handler
+
+import (
+	"context"
+	"fmt"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	appadapter "github.com/ali-mahdavi-dev/framework/adapter"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"github.com/ali-mahdavi-dev/framework/errors/phrases"
+	appphrases "shikposh-backend/pkg/phrases"
+
+	"github.com/pkg/errors"
+)
+
+// UpdateUserHandler edits the name and email address of a user. A new email
+// address is unverified until the link mailed to it is followed.
+func (h *UserHandler) UpdateUserHandler(ctx context.Context, cmd *commands.UpdateUser) error {
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := h.uow.User(ctx).FindByID(ctx, cmd.UserID)
+		if err != nil {
+			if errors.Is(err, appadapter.ErrEntityNotFound) {
+				return apperrors.NotFound(phrases.UserNotFound)
+			}
+			return fmt.Errorf("UserHandler.UpdateUserHandler fail get user: %w", err)
+		}
+
+		if cmd.Email != nil && *cmd.Email != user.Email {
+			owner, err := h.uow.User(ctx).FindByEmail(ctx, *cmd.Email)
+			if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+				return fmt.Errorf("UserHandler.UpdateUserHandler fail get user by email: %w", err)
+			}
+			if owner != nil && owner.ID != user.ID {
+				return apperrors.Conflict(appphrases.EmailAlreadyInUse, "Email address is already in use")
+			}
+		}
+
+		user.UpdateDetails(cmd.FirstName, cmd.LastName, cmd.Email)
+		if err := h.uow.User(ctx).Modify(ctx, user); err != nil {
+			return fmt.Errorf("UserHandler.UpdateUserHandler fail update user: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateProfileHandler edits the profile of a user. Phone numbers are stored
+// in E.164 form and can belong to one account only, since they are also used
+// to sign in with a one-time code.
+func (h *UserHandler) UpdateProfileHandler(ctx context.Context, cmd *commands.UpdateProfile) error {
+	phone := cmd.Phone
+	if phone != nil && *phone != "" {
+		normalized, err := entity.NormalizePhone(*phone)
+		if err != nil {
+			return apperrors.Validation(appphrases.InvalidPhone, "Invalid mobile phone number")
+		}
+		phone = &normalized
+	}
+
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		userID := entity.UserID(cmd.UserID)
+
+		// The profile is created by an event handler after registration,
+		// so it may not exist yet
+		isNew := false
+		profile, err := h.uow.Profile(ctx).FindByUserID(ctx, userID)
+		if err != nil {
+			if !errors.Is(err, repository.ErrProfileNotFound) {
+				return fmt.Errorf("UserHandler.UpdateProfileHandler fail get profile: %w", err)
+			}
+			profile = entity.NewProfile(userID)
+			isNew = true
+		}
+
+		if phone != nil && *phone != profile.Phone {
+			if err := h.checkPhoneChange(ctx, profile, *phone); err != nil {
+				return err
+			}
+		}
+
+		profile.Update(cmd.Bio, phone)
+
+		if isNew {
+			err = h.uow.Profile(ctx).Save(ctx, profile)
+		} else {
+			err = h.uow.Profile(ctx).Modify(ctx, profile)
+		}
+		if err != nil {
+			return fmt.Errorf("UserHandler.UpdateProfileHandler fail save profile: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// checkPhoneChange refuses a number that belongs to another account and
+// keeps the number of accounts that have no password to sign in with.
+func (h *UserHandler) checkPhoneChange(ctx context.Context, profile *entity.Profile, phone string) error {
+	if phone == "" {
+		user, err := h.uow.User(ctx).FindByID(ctx, uint64(profile.UserID))
+		if err != nil {
+			if errors.Is(err, appadapter.ErrEntityNotFound) {
+				return apperrors.NotFound(phrases.UserNotFound)
+			}
+			return fmt.Errorf("UserHandler.checkPhoneChange fail get user: %w", err)
+		}
+
+		if user.Password == "" {
+			return apperrors.Validation(appphrases.PhoneRequired, "Phone number is required to sign in to this account")
+		}
+
+		return nil
+	}
+
+	owner, err := h.uow.Profile(ctx).FindByPhone(ctx, phone)
+	if err != nil {
+		if errors.Is(err, repository.ErrProfileNotFound) {
+			return nil
+		}
+		return fmt.Errorf("UserHandler.checkPhoneChange fail get profile by phone: %w", err)
+	}
+
+	if owner.UserID != profile.UserID {
+		return apperrors.Conflict(appphrases.PhoneAlreadyInUse, "Phone number is already in use")
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package command_handler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	appadapter "github.com/ali-mahdavi-dev/framework/adapter"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"shikposh-backend/internal/unit_of_work"
+	appphrases "shikposh-backend/pkg/phrases"
+
+	"github.com/pkg/errors"
+)
+
+// ServiceAccountHandler manages service accounts, the credentials of internal
+// jobs. Their API keys are handed out once and only their hashes are kept.
+type ServiceAccountHandler struct {
+	uow unitofwork.PGUnitOfWork
+}
+
+func NewServiceAccountHandler(uow unitofwork.PGUnitOfWork) *ServiceAccountHandler {
+	return &ServiceAccountHandler{uow: uow}
+}
+
+// CreateServiceAccountHandler creates a service account. Every scope must
+// name an existing permission other than those administering accounts.
+func (h *ServiceAccountHandler) CreateServiceAccountHandler(ctx context.Context, cmd *commands.CreateServiceAccount) (*entity.ServiceAccount, error) {
+	var account *entity.ServiceAccount
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		_, err := h.uow.ServiceAccount(ctx).FindByName(ctx, cmd.Name)
+		if err == nil {
+			return apperrors.Conflict(appphrases.ServiceAccountExists, "A service account with this name already exists")
+		}
+		if !errors.Is(err, repository.ErrServiceAccountNotFound) {
+			return fmt.Errorf("ServiceAccountHandler.CreateServiceAccountHandler fail get service account: %w", err)
+		}
+
+		scopes := slices.Compact(slices.Sorted(slices.Values(cmd.Scopes)))
+		if unassignable := slices.DeleteFunc(slices.Clone(scopes), commands.IsAssignableScope); len(unassignable) > 0 {
+			return apperrors.Validation(appphrases.UnassignableScope, fmt.Sprintf("Scopes not grantable to service accounts: %s", strings.Join(unassignable, ", ")))
+		}
+
+		permissions, err := h.uow.Role(ctx).FindPermissionsByNames(ctx, scopes)
+		if err != nil {
+			return fmt.Errorf("ServiceAccountHandler.CreateServiceAccountHandler fail get permissions: %w", err)
+		}
+		if unknown := unknownScopes(scopes, permissions); len(unknown) > 0 {
+			return apperrors.Validation(appphrases.UnknownScope, fmt.Sprintf("Unknown scopes: %s", strings.Join(unknown, ", ")))
+		}
+
+		account = entity.NewServiceAccount(cmd.Name, cmd.Description, permissions, entity.UserID(cmd.ActorID))
+		if err := h.uow.ServiceAccount(ctx).Save(ctx, account); err != nil {
+			return fmt.Errorf("ServiceAccountHandler.CreateServiceAccountHandler fail save service account: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// IssueApiKeyHandler issues a new key of the service account and returns it
+// together with the plain key, which cannot be retrieved afterwards.
+func (h *ServiceAccountHandler) IssueApiKeyHandler(ctx context.Context, cmd *commands.IssueApiKey) (*entity.ApiKey, string, error) {
+	if cmd.ExpiresAt != nil && !cmd.ExpiresAt.After(time.Now()) {
+		return nil, "", apperrors.Validation(appphrases.InvalidExpiry, "The expiry must be in the future")
+	}
+
+	var (
+		key   *entity.ApiKey
+		plain string
+	)
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		account, err := h.uow.ServiceAccount(ctx).FindByID(ctx, cmd.ServiceAccountID)
+		if err != nil {
+			if errors.Is(err, appadapter.ErrEntityNotFound) {
+				return apperrors.NotFound(appphrases.ServiceAccountNotFound, "Service account not found")
+			}
+			return fmt.Errorf("ServiceAccountHandler.IssueApiKeyHandler fail get service account: %w", err)
+		}
+
+		key, plain, err = entity.NewApiKey(account.ID, cmd.ExpiresAt, entity.UserID(cmd.ActorID))
+		if err != nil {
+			return err
+		}
+
+		if err := h.uow.ApiKey(ctx).Save(ctx, key); err != nil {
+			return fmt.Errorf("ServiceAccountHandler.IssueApiKeyHandler fail save api key: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, plain, nil
+}
+
+// RevokeApiKeyHandler revokes a key. Requests carrying it are rejected from
+// then on.
+func (h *ServiceAccountHandler) RevokeApiKeyHandler(ctx context.Context, cmd *commands.RevokeApiKey) error {
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		key, err := h.uow.ApiKey(ctx).FindByIDAndServiceAccountID(ctx, entity.ApiKeyID(cmd.ApiKeyID), entity.ServiceAccountID(cmd.ServiceAccountID))
+		if err != nil {
+			if errors.Is(err, repository.ErrApiKeyNotFound) {
+				return apperrors.NotFound(appphrases.ApiKeyNotFound, "API key not found")
+			}
+			return fmt.Errorf("ServiceAccountHandler.RevokeApiKeyHandler fail get api key: %w", err)
+		}
+
+		key.Revoke(entity.UserID(cmd.ActorID), time.Now())
+		if err := h.uow.ApiKey(ctx).Modify(ctx, key); err != nil {
+			return fmt.Errorf("ServiceAccountHandler.RevokeApiKeyHandler fail update api key: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// unknownScopes returns the scopes no permission was found for.
+func unknownScopes(scopes []string, permissions []*entity.Permission) []string {
+	var unknown []string
+	for _, scope := range scopes {
+		found := slices.ContainsFunc(permissions, func(p *entity.Permission) bool {
+			return p.Name == scope
+		})
+		if !found {
+			unknown = append(unknown, scope)
+		}
+	}
+	return unknown
+}
@@ -0,0 +1,254 @@
+package command_handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"shikposh-backend/internal/account/adapter"
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	appadapter "github.com/ali-mahdavi-dev/framework/adapter"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"github.com/ali-mahdavi-dev/framework/errors/phrases"
+	appphrases "shikposh-backend/pkg/phrases"
+
+	"github.com/pkg/errors"
+)
+
+// twoFactorChallengeExpireDuration is how long a password login waits for the second factor.
+const twoFactorChallengeExpireDuration = 5 * time.Minute
+
+type TotpEnrollmentResult struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type RecoveryCodesResult struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// EnrollTotpHandler generates a new TOTP secret for the user. Two-factor
+// authentication stays off until ConfirmTotpHandler receives a valid code.
+func (h *UserHandler) EnrollTotpHandler(ctx context.Context, cmd *commands.EnrollTotp) (*TotpEnrollmentResult, error) {
+	var result *TotpEnrollmentResult
+
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := h.uow.User(ctx).FindByID(ctx, cmd.UserID)
+		if err != nil {
+			if errors.Is(err, appadapter.ErrEntityNotFound) {
+				return apperrors.NotFound(phrases.UserNotFound)
+			}
+			return fmt.Errorf("UserHandler.EnrollTotpHandler fail get user: %w", err)
+		}
+
+		if user.IsTwoFactorEnabled() {
+			return apperrors.Conflict(appphrases.TwoFactorAlreadyEnabled, "Two-factor authentication is already enabled")
+		}
+
+		secret, err := adapter.GenerateTotpSecret()
+		if err != nil {
+			return fmt.Errorf("UserHandler.EnrollTotpHandler fail generate secret: %w", err)
+		}
+
+		user.StartTotpEnrollment(secret)
+		if err := h.uow.User(ctx).Modify(ctx, user); err != nil {
+			return fmt.Errorf("UserHandler.EnrollTotpHandler fail update user: %w", err)
+		}
+
+		result = &TotpEnrollmentResult{
+			Secret:          secret,
+			ProvisioningURI: adapter.TotpProvisioningURI(user.UserName, secret),
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ConfirmTotpHandler turns two-factor authentication on once the user proves
+// their authenticator app produces valid codes, and returns a fresh set of
+// recovery codes. The plain codes are never shown again.
+func (h *UserHandler) ConfirmTotpHandler(ctx context.Context, cmd *commands.ConfirmTotp) (*RecoveryCodesResult, error) {
+	var result *RecoveryCodesResult
+
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		now := time.Now()
+
+		user, err := h.uow.User(ctx).FindByID(ctx, cmd.UserID)
+		if err != nil {
+			if errors.Is(err, appadapter.ErrEntityNotFound) {
+				return apperrors.NotFound(phrases.UserNotFound)
+			}
+			return fmt.Errorf("UserHandler.ConfirmTotpHandler fail get user: %w", err)
+		}
+
+		if user.IsTwoFactorEnabled() {
+			return apperrors.Conflict(appphrases.TwoFactorAlreadyEnabled, "Two-factor authentication is already enabled")
+		}
+		if user.TotpSecret == "" {
+			return apperrors.Validation(appphrases.TwoFactorNotEnrolled, "Two-factor enrollment has not been started")
+		}
+
+		step, ok := adapter.ValidateTotp(user.TotpSecret, cmd.Code, now)
+		if !ok || !user.AcceptTotpStep(step) {
+			return apperrors.Validation(appphrases.InvalidTwoFactorCode, "Invalid two-factor code")
+		}
+
+		user.ConfirmTotpEnrollment(now)
+		if err := h.uow.User(ctx).Modify(ctx, user); err != nil {
+			return fmt.Errorf("UserHandler.ConfirmTotpHandler fail update user: %w", err)
+		}
+
+		codes, plainCodes, err := entity.NewRecoveryCodes(user.ID)
+		if err != nil {
+			return fmt.Errorf("UserHandler.ConfirmTotpHandler fail generate recovery codes: %w", err)
+		}
+
+		if err := h.uow.RecoveryCode(ctx).DeleteByUserID(ctx, user.ID); err != nil {
+			return fmt.Errorf("UserHandler.ConfirmTotpHandler fail delete old recovery codes: %w", err)
+		}
+		for _, code := range codes {
+			if err := h.uow.RecoveryCode(ctx).Save(ctx, code); err != nil {
+				return fmt.Errorf("UserHandler.ConfirmTotpHandler fail save recovery code: %w", err)
+			}
+		}
+
+		result = &RecoveryCodesResult{RecoveryCodes: plainCodes}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// VerifyTwoFactorHandler completes a login that returned a two-factor
+// challenge. It accepts a TOTP code or an unused recovery code; a challenge
+// is dropped after entity.MaxTwoFactorAttempts wrong codes.
+func (h *UserHandler) VerifyTwoFactorHandler(ctx context.Context, cmd *commands.VerifyTwoFactor) (*LoginResult, error) {
+	var (
+		result  *LoginResult
+		failed  bool
+		invalid = apperrors.Unauthorized(appphrases.InvalidTwoFactorCode, "Invalid two-factor code")
+	)
+
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		now := time.Now()
+
+		challenge, err := h.uow.TwoFactorChallenge(ctx).FindByTokenHash(ctx, entity.HashToken(cmd.ChallengeToken))
+		if err != nil {
+			if errors.Is(err, repository.ErrTwoFactorChallengeNotFound) {
+				return invalid
+			}
+			return fmt.Errorf("UserHandler.VerifyTwoFactorHandler fail get challenge: %w", err)
+		}
+
+		if !challenge.IsUsable(now) {
+			return invalid
+		}
+
+		user, err := h.uow.User(ctx).FindByID(ctx, uint64(challenge.UserID))
+		if err != nil {
+			return fmt.Errorf("UserHandler.VerifyTwoFactorHandler fail get user: %w", err)
+		}
+
+		if user.IsDisabled() {
+			return apperrors.Forbidden(appphrases.AccountDisabled, "Account is disabled")
+		}
+
+		ok, err := h.checkSecondFactor(ctx, user, cmd.Code, now)
+		if err != nil {
+			return fmt.Errorf("UserHandler.VerifyTwoFactorHandler fail check code: %w", err)
+		}
+		if !ok {
+			// The attempt has to be committed, so the error is returned only
+			// after the transaction is done.
+			failed = true
+			challenge.RecordFailedAttempt()
+			if err := h.uow.TwoFactorChallenge(ctx).Modify(ctx, challenge); err != nil {
+				return fmt.Errorf("UserHandler.VerifyTwoFactorHandler fail update challenge: %w", err)
+			}
+			return nil
+		}
+
+		used, err := h.uow.TwoFactorChallenge(ctx).MarkUsed(ctx, challenge, now)
+		if err != nil {
+			return fmt.Errorf("UserHandler.VerifyTwoFactorHandler fail mark challenge used: %w", err)
+		}
+		if !used {
+			return invalid
+		}
+
+		result, err = h.openSession(ctx, user, cmd.UserAgent, cmd.IP, now)
+		if err != nil {
+			return fmt.Errorf("UserHandler.VerifyTwoFactorHandler fail open session: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if failed {
+		return nil, invalid
+	}
+
+	return result, nil
+}
+
+// completeLogin opens a session right away, or hands out a two-factor
+// challenge when the user has two-factor authentication enabled.
+func (h *UserHandler) completeLogin(ctx context.Context, user *entity.User, userAgent, ip string, now time.Time) (*LoginResult, error) {
+	if !user.IsTwoFactorEnabled() {
+		return h.openSession(ctx, user, userAgent, ip, now)
+	}
+
+	challenge, plainToken, err := entity.NewTwoFactorChallenge(user.ID, now.Add(twoFactorChallengeExpireDuration))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.uow.TwoFactorChallenge(ctx).Save(ctx, challenge); err != nil {
+		return nil, fmt.Errorf("fail save two-factor challenge: %w", err)
+	}
+
+	return &LoginResult{
+		TwoFactorRequired: true,
+		ChallengeToken:    plainToken,
+		ExpiresIn:         int64(twoFactorChallengeExpireDuration.Seconds()),
+	}, nil
+}
+
+// checkSecondFactor accepts a TOTP code whose time step was not used yet, or
+// consumes one of the user's recovery codes.
+func (h *UserHandler) checkSecondFactor(ctx context.Context, user *entity.User, code string, now time.Time) (bool, error) {
+	if step, ok := adapter.ValidateTotp(user.TotpSecret, code, now); ok {
+		if !user.AcceptTotpStep(step) {
+			return false, nil
+		}
+		if err := h.uow.User(ctx).Modify(ctx, user); err != nil {
+			return false, fmt.Errorf("fail update user: %w", err)
+		}
+		return true, nil
+	}
+
+	recoveryCode, err := h.uow.RecoveryCode(ctx).FindUnusedByHash(ctx, user.ID, entity.HashRecoveryCode(code))
+	if err != nil {
+		if errors.Is(err, repository.ErrRecoveryCodeNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return h.uow.RecoveryCode(ctx).MarkUsed(ctx, recoveryCode, now)
+}
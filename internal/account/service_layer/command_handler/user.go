@@ -3,38 +3,56 @@ package command_handler
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"shikposh-backend/config"
+	"shikposh-backend/internal/account/adapter"
 	"shikposh-backend/internal/account/adapter/repository"
 	"shikposh-backend/internal/account/domain/commands"
 	"shikposh-backend/internal/account/domain/entity"
-	"github.com/ali-mahdavi-dev/framework/api/jwt"
+	appadapter "github.com/ali-mahdavi-dev/framework/adapter"
 	apperrors "github.com/ali-mahdavi-dev/framework/errors"
 	"github.com/ali-mahdavi-dev/framework/errors/phrases"
+	"github.com/ali-mahdavi-dev/framework/infrastructure/logging"
 	"shikposh-backend/internal/unit_of_work"
+	appphrases "shikposh-backend/pkg/phrases"
 
 	"github.com/pkg/errors"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type UserHandler struct {
-	uow unitofwork.PGUnitOfWork
-	cfg *config.Config
+	uow          unitofwork.PGUnitOfWork
+	cfg          *config.Config
+	accessTokens *adapter.AccessTokenKeys
+	attempts     adapter.LoginAttemptStore
 }
 
 type RegisterResult struct {
 	UserID uint64 `json:"user_id"`
 }
 
+// LoginResult carries either a token pair or, for users with two-factor
+// authentication enabled, a challenge token for VerifyTwoFactorHandler.
 type LoginResult struct {
-	Access string `json:"access"`
+	Access            string `json:"access,omitempty"`
+	Refresh           string `json:"refresh,omitempty"`
+	ExpiresIn         int64  `json:"expires_in"`
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
 }
 
-func NewUserHandler(uow unitofwork.PGUnitOfWork, cfg *config.Config) *UserHandler {
-	return &UserHandler{uow: uow, cfg: cfg}
+// defaultRefreshTokenExpireDuration is used when jwt.refreshTokenExpireDuration is not configured.
+const defaultRefreshTokenExpireDuration = 30 * 24 * time.Hour
+
+func NewUserHandler(uow unitofwork.PGUnitOfWork, cfg *config.Config, accessTokens *adapter.AccessTokenKeys, attempts adapter.LoginAttemptStore) *UserHandler {
+	return &UserHandler{uow: uow, cfg: cfg, accessTokens: accessTokens, attempts: attempts}
 }
 
 func (h *UserHandler) RegisterHandler(ctx context.Context, cmd *commands.RegisterUser) error {
+	if err := h.validatePassword(cmd.Password); err != nil {
+		return err
+	}
+
 	err := h.uow.Do(ctx, func(ctx context.Context) error {
 		// Check if username already exists
 		_, err := h.uow.User(ctx).FindByUserName(ctx, cmd.UserName)
@@ -47,7 +65,7 @@ func (h *UserHandler) RegisterHandler(ctx context.Context, cmd *commands.Registe
 		}
 
 		// Hash password before saving
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(cmd.Password), bcrypt.DefaultCost)
+		hashedPassword, err := adapter.HashPassword(cmd.Password)
 		if err != nil {
 			return fmt.Errorf("UserHandler.Register error hashing password: %w", err)
 		}
@@ -58,7 +76,7 @@ func (h *UserHandler) RegisterHandler(ctx context.Context, cmd *commands.Registe
 			cmd.FirstName,
 			cmd.LastName,
 			cmd.Email,
-			string(hashedPassword),
+			hashedPassword,
 		)
 
 		err = h.uow.User(ctx).Save(ctx, user)
@@ -78,17 +96,21 @@ func (h *UserHandler) RegisterHandler(ctx context.Context, cmd *commands.Registe
 
 func (h *UserHandler) LogoutHandler(ctx context.Context, cmd *commands.Logout) error {
 	err := h.uow.Do(ctx, func(ctx context.Context) error {
-		token, err := h.uow.Token(ctx).FindByUserID(ctx, entity.UserID(cmd.UserID))
+		session, err := h.uow.Session(ctx).FindBySessionID(ctx, entity.SessionID(cmd.SessionID))
 		if err != nil {
-			if errors.Is(err, repository.ErrTokenNotFound) {
+			if errors.Is(err, repository.ErrSessionNotFound) {
 				return apperrors.NotFound(phrases.UserNotFound)
 			}
 
-			return fmt.Errorf("UserHandler.LogoutHandler failed to get token by userID: %w", err)
+			return fmt.Errorf("UserHandler.LogoutHandler failed to get session: %w", err)
 		}
 
-		if err := h.uow.Token(ctx).Remove(ctx, token, false); err != nil {
-			return fmt.Errorf("UserHandler.LogoutHandler failed to remove existing token: %w", err)
+		if session.UserID != entity.UserID(cmd.UserID) {
+			return apperrors.NotFound(phrases.UserNotFound)
+		}
+
+		if err := h.revokeSession(ctx, session, time.Now()); err != nil {
+			return fmt.Errorf("UserHandler.LogoutHandler failed to revoke session: %w", err)
 		}
 
 		return nil
@@ -101,54 +123,482 @@ func (h *UserHandler) LogoutHandler(ctx context.Context, cmd *commands.Logout) e
 	return nil
 }
 
-func (h *UserHandler) LoginHandler(ctx context.Context, cmd *commands.LoginUser) (string, error) {
-	var accessToken string
-
+func (h *UserHandler) AssignRoleHandler(ctx context.Context, cmd *commands.AssignRole) error {
 	err := h.uow.Do(ctx, func(ctx context.Context) error {
 		user, err := h.uow.User(ctx).FindByUserName(ctx, cmd.UserName)
 		if err != nil {
 			if errors.Is(err, repository.ErrUserNotFound) {
 				return apperrors.NotFound(phrases.UserNotFound)
 			}
+			return fmt.Errorf("UserHandler.AssignRoleHandler fail get user by username: %w", err)
+		}
+
+		role, err := h.uow.Role(ctx).FindByName(ctx, cmd.Role)
+		if err != nil {
+			if errors.Is(err, repository.ErrRoleNotFound) {
+				return apperrors.NotFound(appphrases.RoleNotFound, "Role not found")
+			}
+			return fmt.Errorf("UserHandler.AssignRoleHandler fail get role: %w", err)
+		}
+
+		if err := h.uow.User(ctx).AssignRole(ctx, user, role); err != nil {
+			return fmt.Errorf("UserHandler.AssignRoleHandler fail assign role: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// VerifyEmailHandler marks the email address a verification token was sent
+// to as verified. Each token can be used once.
+func (h *UserHandler) VerifyEmailHandler(ctx context.Context, cmd *commands.VerifyEmail) error {
+	invalid := apperrors.Validation(appphrases.InvalidVerificationToken, "Invalid or expired verification token")
+
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		now := time.Now()
+
+		token, err := h.uow.EmailVerification(ctx).FindByTokenHash(ctx, entity.HashToken(cmd.Token))
+		if err != nil {
+			if errors.Is(err, repository.ErrEmailVerificationTokenNotFound) {
+				return invalid
+			}
+			return fmt.Errorf("UserHandler.VerifyEmailHandler fail get verification token: %w", err)
+		}
+
+		if !token.IsUsable(now) {
+			return invalid
+		}
+
+		user, err := h.uow.User(ctx).FindByID(ctx, uint64(token.UserID))
+		if err != nil {
+			return fmt.Errorf("UserHandler.VerifyEmailHandler fail get user: %w", err)
+		}
+
+		// A token sent to a previous address must not verify the current one
+		if user.Email != token.Email {
+			return invalid
+		}
+
+		used, err := h.uow.EmailVerification(ctx).MarkUsed(ctx, token, now)
+		if err != nil {
+			return fmt.Errorf("UserHandler.VerifyEmailHandler fail mark verification token used: %w", err)
+		}
+		if !used {
+			return invalid
+		}
+
+		user.VerifyEmail(now)
+		if err := h.uow.User(ctx).Modify(ctx, user); err != nil {
+			return fmt.Errorf("UserHandler.VerifyEmailHandler fail update user: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ResendVerificationHandler sends a new verification email, at most once per
+// verification.resendInterval. Unknown and already verified addresses are
+// ignored silently so the endpoint does not reveal which emails are registered.
+func (h *UserHandler) ResendVerificationHandler(ctx context.Context, cmd *commands.ResendVerificationEmail) error {
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := h.uow.User(ctx).FindByEmail(ctx, cmd.Email)
+		if err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return nil
+			}
+			return fmt.Errorf("UserHandler.ResendVerificationHandler fail get user by email: %w", err)
+		}
+
+		if user.IsEmailVerified() {
+			return nil
+		}
+
+		latest, err := h.uow.EmailVerification(ctx).FindLatestByUserID(ctx, user.ID)
+		if err != nil && !errors.Is(err, repository.ErrEmailVerificationTokenNotFound) {
+			return fmt.Errorf("UserHandler.ResendVerificationHandler fail get latest verification token: %w", err)
+		}
+		if latest != nil && time.Since(latest.CreatedAt) < h.cfg.Verification.ResendInterval*time.Minute {
+			return ErrTooManyRequests
+		}
+
+		user.RequestEmailVerification()
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RequestPasswordResetHandler mails a password reset link to the address.
+// Unknown addresses are ignored silently so the endpoint does not reveal
+// which emails are registered.
+func (h *UserHandler) RequestPasswordResetHandler(ctx context.Context, cmd *commands.RequestPasswordReset) error {
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := h.uow.User(ctx).FindByEmail(ctx, cmd.Email)
+		if err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return nil
+			}
+			return fmt.Errorf("UserHandler.RequestPasswordResetHandler fail get user by email: %w", err)
+		}
+
+		user.RequestPasswordReset()
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ResetPasswordHandler sets a new password using a single-use reset token
+// and logs the user out of every device.
+func (h *UserHandler) ResetPasswordHandler(ctx context.Context, cmd *commands.ResetPassword) error {
+	if err := h.validatePassword(cmd.NewPassword); err != nil {
+		return err
+	}
+
+	invalid := apperrors.Validation(appphrases.InvalidPasswordReset, "Invalid or expired password reset token")
+
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		now := time.Now()
+
+		token, err := h.uow.PasswordReset(ctx).FindByTokenHash(ctx, entity.HashToken(cmd.Token))
+		if err != nil {
+			if errors.Is(err, repository.ErrPasswordResetTokenNotFound) {
+				return invalid
+			}
+			return fmt.Errorf("UserHandler.ResetPasswordHandler fail get password reset token: %w", err)
+		}
+
+		if !token.IsUsable(now) {
+			return invalid
+		}
+
+		used, err := h.uow.PasswordReset(ctx).MarkUsed(ctx, token, now)
+		if err != nil {
+			return fmt.Errorf("UserHandler.ResetPasswordHandler fail mark password reset token used: %w", err)
+		}
+		if !used {
+			return invalid
+		}
+
+		user, err := h.uow.User(ctx).FindByID(ctx, uint64(token.UserID))
+		if err != nil {
+			return fmt.Errorf("UserHandler.ResetPasswordHandler fail get user: %w", err)
+		}
+
+		if err := h.changePassword(ctx, user, cmd.NewPassword, now); err != nil {
+			return fmt.Errorf("UserHandler.ResetPasswordHandler fail change password: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ChangePasswordHandler replaces the password of a signed-in user after
+// checking the current one. Every session is revoked, including the one
+// that made the request.
+func (h *UserHandler) ChangePasswordHandler(ctx context.Context, cmd *commands.ChangePassword) error {
+	if err := h.validatePassword(cmd.NewPassword); err != nil {
+		return err
+	}
+
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := h.uow.User(ctx).FindByID(ctx, cmd.UserID)
+		if err != nil {
+			if errors.Is(err, appadapter.ErrEntityNotFound) {
+				return apperrors.NotFound(phrases.UserNotFound)
+			}
+			return fmt.Errorf("UserHandler.ChangePasswordHandler fail get user: %w", err)
+		}
+
+		if err := adapter.ComparePassword(user.Password, cmd.CurrentPassword); err != nil {
+			return apperrors.Validation(appphrases.InvalidCurrentPassword, "Current password is incorrect")
+		}
+
+		if err := h.changePassword(ctx, user, cmd.NewPassword, time.Now()); err != nil {
+			return fmt.Errorf("UserHandler.ChangePasswordHandler fail change password: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// LoginHandler verifies the credentials and opens a new session for the
+// calling device. Sessions on other devices are left untouched. Users with
+// two-factor authentication get a challenge instead of tokens. Failed logins
+// slow down further attempts for the username and the IP and eventually lock
+// the account.
+func (h *UserHandler) LoginHandler(ctx context.Context, cmd *commands.LoginUser) (*LoginResult, error) {
+	keys := newLoginAttemptKeys(cmd.UserName, cmd.IP)
+	if err := h.checkLoginThrottle(ctx, keys); err != nil {
+		return nil, err
+	}
+
+	var (
+		result   *LoginResult
+		rejected error
+	)
+
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		now := time.Now()
+
+		user, err := h.uow.User(ctx).FindByUserName(ctx, cmd.UserName)
+		if err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				rejected = apperrors.NotFound(phrases.UserNotFound)
+				if err := h.recordLoginFailure(ctx, keys, nil, now); err != nil {
+					return fmt.Errorf("UserHandler.LoginHandler fail record login failure: %w", err)
+				}
+				return nil
+			}
 			return fmt.Errorf("UserHandler.LoginHandler fail get user by username: %w", err)
 		}
 
+		if user.IsLocked(now) {
+			adapter.LoginFailures.WithLabelValues(adapter.LoginFailureLocked).Inc()
+			return apperrors.Forbidden(appphrases.AccountLocked, "Account is temporarily locked after too many failed logins")
+		}
+
 		// Verify password
-		err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(cmd.Password))
+		err = adapter.ComparePassword(user.Password, cmd.Password)
+		if err != nil {
+			// The failure and a possible lockout have to be committed, so the
+			// error is returned only after the transaction is done.
+			rejected = apperrors.Unauthorized(phrases.UserNotFound)
+			if err := h.recordLoginFailure(ctx, keys, user, now); err != nil {
+				return fmt.Errorf("UserHandler.LoginHandler fail record login failure: %w", err)
+			}
+			return nil
+		}
+
+		if user.IsDisabled() {
+			return apperrors.Forbidden(appphrases.AccountDisabled, "Account is disabled")
+		}
+
+		if h.cfg.Verification.RequireVerifiedEmail && !user.IsEmailVerified() {
+			return apperrors.Forbidden(appphrases.EmailNotVerified, "Email address is not verified")
+		}
+
+		if err := h.attempts.Reset(ctx, keys.user); err != nil {
+			return fmt.Errorf("UserHandler.LoginHandler fail reset login failures: %w", err)
+		}
+
+		result, err = h.completeLogin(ctx, user, cmd.UserAgent, cmd.IP, now)
+		if err != nil {
+			return fmt.Errorf("UserHandler.LoginHandler fail complete login: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if rejected != nil {
+		return nil, rejected
+	}
+
+	return result, nil
+}
+
+// RefreshHandler exchanges a refresh token for a new token pair. Refresh tokens
+// are single-use: presenting one that was already rotated is treated as theft
+// and revokes the whole session, including the tokens issued from it.
+func (h *UserHandler) RefreshHandler(ctx context.Context, cmd *commands.RefreshToken) (*LoginResult, error) {
+	var (
+		result  *LoginResult
+		reused  *entity.RefreshToken
+		invalid = apperrors.Unauthorized(phrases.UserNotFound, "Invalid refresh token")
+	)
+
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		now := time.Now()
+
+		token, err := h.uow.RefreshToken(ctx).FindByTokenHash(ctx, entity.HashToken(cmd.RefreshToken))
 		if err != nil {
-			return apperrors.Unauthorized(phrases.UserNotFound)
+			if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+				return invalid
+			}
+			return fmt.Errorf("UserHandler.RefreshHandler fail get refresh token: %w", err)
 		}
 
-		// Check if user has existing token and remove it
-		token, err := h.uow.Token(ctx).FindByUserID(ctx, user.ID)
-		if err != nil && !errors.Is(err, repository.ErrTokenNotFound) {
-			return fmt.Errorf("UserHandler.LoginHandler failed to get token by userID: %w", err)
+		session, err := h.uow.Session(ctx).FindBySessionID(ctx, token.SessionID)
+		if err != nil {
+			if errors.Is(err, repository.ErrSessionNotFound) {
+				return invalid
+			}
+			return fmt.Errorf("UserHandler.RefreshHandler fail get session: %w", err)
 		}
 
-		if token != nil {
-			if err := h.uow.Token(ctx).Remove(ctx, token, false); err != nil {
-				return fmt.Errorf("UserHandler.LoginHandler failed to remove existing token: %w", err)
+		// The revocation has to be committed, so the error is returned only
+		// after the transaction is done.
+		revokeReused := func() error {
+			reused = token
+			if err := h.revokeSession(ctx, session, now); err != nil {
+				return fmt.Errorf("UserHandler.RefreshHandler fail revoke session: %w", err)
 			}
+			return nil
+		}
+
+		if token.IsReused() {
+			return revokeReused()
 		}
 
-		// Generate new access token
-		accessToken, err = jwt.GenerateToken(h.cfg.JWT.AccessTokenExpireDuration, h.cfg.JWT.Secret, uint64(user.ID))
+		if !token.IsUsable(now) || !session.IsActive(now) {
+			return invalid
+		}
+
+		used, err := h.uow.RefreshToken(ctx).MarkUsed(ctx, token, now)
 		if err != nil {
-			return fmt.Errorf("UserHandler.LoginHandler fail generate token: %w", err)
+			return fmt.Errorf("UserHandler.RefreshHandler fail mark refresh token used: %w", err)
+		}
+		if !used {
+			// A concurrent refresh exchanged the same token first.
+			return revokeReused()
+		}
+
+		session.Touch(cmd.UserAgent, cmd.IP, now)
+		if err := h.uow.Session(ctx).Modify(ctx, session); err != nil {
+			return fmt.Errorf("UserHandler.RefreshHandler fail update session: %w", err)
 		}
 
-		// Save new token
-		err = h.uow.Token(ctx).Save(ctx, entity.NewToken(accessToken, user.ID))
+		result, err = h.issueTokens(ctx, session, now)
 		if err != nil {
-			return fmt.Errorf("UserHandler.LoginHandler fail save token to db: %w", err)
+			return fmt.Errorf("UserHandler.RefreshHandler fail issue tokens: %w", err)
 		}
 
 		return nil
 	})
 
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	if reused != nil {
+		logging.Warn("Refresh token reuse detected, session revoked").
+			WithInt64("user_id", int64(reused.UserID)).
+			WithInt64("session_id", int64(reused.SessionID)).
+			Log()
+		return nil, invalid
+	}
+
+	return result, nil
+}
+
+// openSession starts a session for the calling device and issues its first token pair.
+func (h *UserHandler) openSession(ctx context.Context, user *entity.User, userAgent, ip string, now time.Time) (*LoginResult, error) {
+	session := entity.NewSession(user.ID, userAgent, ip, now.Add(h.refreshTokenExpireDuration()))
+	if err := h.uow.Session(ctx).Save(ctx, session); err != nil {
+		return nil, fmt.Errorf("fail save session: %w", err)
+	}
+
+	return h.issueTokens(ctx, session, now)
+}
+
+// issueTokens creates a new refresh token in the session's family and signs a
+// matching access token.
+func (h *UserHandler) issueTokens(ctx context.Context, session *entity.Session, now time.Time) (*LoginResult, error) {
+	refreshToken, plainRefreshToken, err := entity.NewRefreshToken(session.ID, session.UserID, session.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.uow.RefreshToken(ctx).Save(ctx, refreshToken); err != nil {
+		return nil, fmt.Errorf("fail save refresh token: %w", err)
+	}
+
+	accessTTL := h.cfg.JWT.AccessTokenExpireDuration * time.Minute
+	accessToken, err := h.accessTokens.Generate(now.Add(accessTTL), uint64(session.UserID), uint64(session.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResult{
+		Access:    accessToken,
+		Refresh:   plainRefreshToken,
+		ExpiresIn: int64(accessTTL.Seconds()),
+	}, nil
+}
+
+func (h *UserHandler) revokeSession(ctx context.Context, session *entity.Session, now time.Time) error {
+	session.Revoke(now)
+	if err := h.uow.Session(ctx).Modify(ctx, session); err != nil {
+		return err
+	}
+
+	return h.uow.RefreshToken(ctx).RevokeBySessionID(ctx, session.ID)
+}
+
+// changePassword stores the new password and revokes everything that was
+// issued under the old one: sessions, refresh tokens and pending reset tokens.
+func (h *UserHandler) changePassword(ctx context.Context, user *entity.User, password string, now time.Time) error {
+	hashedPassword, err := adapter.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	user.ChangePassword(hashedPassword)
+	if err := h.uow.User(ctx).Modify(ctx, user); err != nil {
+		return fmt.Errorf("fail update user: %w", err)
+	}
+
+	if err := h.uow.Session(ctx).RevokeByUserID(ctx, user.ID, now); err != nil {
+		return fmt.Errorf("fail revoke sessions: %w", err)
+	}
+	if err := h.uow.RefreshToken(ctx).RevokeByUserID(ctx, user.ID); err != nil {
+		return fmt.Errorf("fail revoke refresh tokens: %w", err)
+	}
+	if err := h.uow.PasswordReset(ctx).InvalidateByUserID(ctx, user.ID, now); err != nil {
+		return fmt.Errorf("fail invalidate password reset tokens: %w", err)
+	}
+
+	return nil
+}
+
+func (h *UserHandler) validatePassword(password string) error {
+	if err := adapter.ValidatePassword(h.cfg.Password, password); err != nil {
+		return apperrors.Validation(appphrases.WeakPassword, err.Error())
+	}
+
+	return nil
+}
+
+func (h *UserHandler) refreshTokenExpireDuration() time.Duration {
+	if h.cfg.JWT.RefreshTokenExpireDuration <= 0 {
+		return defaultRefreshTokenExpireDuration
 	}
 
-	return accessToken, nil
+	return h.cfg.JWT.RefreshTokenExpireDuration * time.Minute
 }
@@ -0,0 +1,47 @@
+package event_handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"shikposh-backend/internal/account/domain/entity"
+	"shikposh-backend/internal/account/domain/events"
+	"shikposh-backend/internal/unit_of_work"
+)
+
+// AuditEventHandler records what happens to accounts as a consequence of
+// commands, e.g. a lockout after failed logins, in the audit log. The
+// commands themselves are recorded by the CommandAudit middleware. Events
+// are handled after the request, so these entries have no request details.
+type AuditEventHandler struct {
+	uow unitofwork.PGUnitOfWork
+}
+
+func NewAuditEventHandler(uow unitofwork.PGUnitOfWork) *AuditEventHandler {
+	return &AuditEventHandler{uow: uow}
+}
+
+// RecordUserLocked handles the UserLockedEvent. The lockout is the system's
+// doing, so the entry has no actor.
+func (h *AuditEventHandler) RecordUserLocked(ctx context.Context, event *events.UserLockedEvent) error {
+	entry := entity.NewAuditEvent("user.lock", entity.AuditSuccess, time.Now()).
+		On("user", strconv.FormatUint(event.UserID, 10))
+	return h.record(ctx, entry)
+}
+
+// RecordExternalIdentityLinked handles the ExternalIdentityLinkedEvent.
+func (h *AuditEventHandler) RecordExternalIdentityLinked(ctx context.Context, event *events.ExternalIdentityLinkedEvent) error {
+	entry := entity.NewAuditEvent("user.identity.link", entity.AuditSuccess, time.Now()).
+		ActedBy("user", event.UserID).
+		On("identity_provider", event.Provider)
+	return h.record(ctx, entry)
+}
+
+func (h *AuditEventHandler) record(ctx context.Context, entry *entity.AuditEvent) error {
+	if err := h.uow.AuditEvent(ctx).Save(ctx, entry); err != nil {
+		return fmt.Errorf("AuditEventHandler fail record %s: %w", entry.Action, err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package event_handler
+
+import (
+	"context"
+	"fmt"
+
+	"shikposh-backend/internal/account/adapter"
+	"shikposh-backend/internal/account/domain/events"
+)
+
+// AvatarEventHandler cleans up the storage behind uploaded avatars.
+type AvatarEventHandler struct {
+	storage adapter.FileStorage
+}
+
+func NewAvatarEventHandler(storage adapter.FileStorage) *AvatarEventHandler {
+	return &AvatarEventHandler{storage: storage}
+}
+
+// DeleteDiscardedAvatar handles the AvatarDiscardedEvent and deletes the file
+// nobody links to anymore.
+func (h *AvatarEventHandler) DeleteDiscardedAvatar(ctx context.Context, event *events.AvatarDiscardedEvent) error {
+	if err := h.storage.Delete(ctx, event.Key); err != nil {
+		return fmt.Errorf("AvatarEventHandler.DeleteDiscardedAvatar fail delete avatar: %w", err)
+	}
+	return nil
+}
@@ -3,7 +3,11 @@ package event_handler
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
+	"shikposh-backend/config"
+	"shikposh-backend/internal/account/adapter"
 	"shikposh-backend/internal/account/adapter/repository"
 	"shikposh-backend/internal/account/domain/entity"
 	"shikposh-backend/internal/account/domain/events"
@@ -12,11 +16,20 @@ import (
 )
 
 type UserEventHandler struct {
-	uow unitofwork.PGUnitOfWork
+	uow    unitofwork.PGUnitOfWork
+	cfg    *config.Config
+	mailer adapter.MailSender
 }
 
-func NewUserEventHandler(uow unitofwork.PGUnitOfWork) *UserEventHandler {
-	return &UserEventHandler{uow: uow}
+const (
+	// defaultVerificationTokenExpireDuration is used when verification.tokenExpireDuration is not configured.
+	defaultVerificationTokenExpireDuration = 24 * time.Hour
+	// defaultResetTokenExpireDuration is used when password.resetTokenExpireDuration is not configured.
+	defaultResetTokenExpireDuration = 30 * time.Minute
+)
+
+func NewUserEventHandler(uow unitofwork.PGUnitOfWork, cfg *config.Config, mailer adapter.MailSender) *UserEventHandler {
+	return &UserEventHandler{uow: uow, cfg: cfg, mailer: mailer}
 }
 
 // RegisterEvent handles the RegisterUserEvent and creates a profile for the newly registered user
@@ -73,3 +86,306 @@ func (h *UserEventHandler) RegisterEvent(ctx context.Context, event *events.Regi
 
 	return nil
 }
+
+// SendVerificationEmail handles the RegisterUserEvent and sends a verification
+// link to the email address of the newly registered user
+func (h *UserEventHandler) SendVerificationEmail(ctx context.Context, event *events.RegisterUserEvent) error {
+	if event.UserID == nil {
+		return fmt.Errorf("UserEventHandler.SendVerificationEmail: UserID is nil")
+	}
+
+	// Users who signed up with a phone number have no email to verify, and
+	// identity providers may already have verified it
+	if event.Email == "" || event.EmailVerified {
+		return nil
+	}
+
+	return h.sendVerificationEmail(ctx, entity.UserID(*event.UserID), event.Email)
+}
+
+// ResendVerificationEmail handles the VerificationEmailRequestedEvent
+func (h *UserEventHandler) ResendVerificationEmail(ctx context.Context, event *events.VerificationEmailRequestedEvent) error {
+	return h.sendVerificationEmail(ctx, entity.UserID(event.UserID), event.Email)
+}
+
+func (h *UserEventHandler) sendVerificationEmail(ctx context.Context, userID entity.UserID, email string) error {
+	var plainToken string
+
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		token, plain, err := entity.NewEmailVerificationToken(userID, email, time.Now().Add(h.verificationTokenExpireDuration()))
+		if err != nil {
+			return err
+		}
+
+		if err := h.uow.EmailVerification(ctx).Save(ctx, token); err != nil {
+			return fmt.Errorf("error saving verification token: %w", err)
+		}
+
+		plainToken = plain
+		return nil
+	})
+	if err != nil {
+		logging.Error("Failed to issue email verification token").
+			WithInt64("user_id", int64(userID)).
+			WithError(err).
+			Log()
+		return fmt.Errorf("UserEventHandler.sendVerificationEmail fail transaction: %w", err)
+	}
+
+	// The mail is sent only once the token is committed, so the link always works
+	err = h.mailer.Send(ctx, adapter.Mail{
+		To:      email,
+		Subject: "Verify your email address",
+		Body:    fmt.Sprintf("Open the link below to verify your email address:\n%s", tokenLink(h.cfg.Verification.VerifyURL, plainToken)),
+	})
+	if err != nil {
+		return fmt.Errorf("UserEventHandler.sendVerificationEmail fail send mail: %w", err)
+	}
+
+	return nil
+}
+
+// SendPasswordResetEmail handles the PasswordResetRequestedEvent and mails a
+// single-use reset link to the user
+func (h *UserEventHandler) SendPasswordResetEmail(ctx context.Context, event *events.PasswordResetRequestedEvent) error {
+	userID := entity.UserID(event.UserID)
+	var plainToken string
+
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		token, plain, err := entity.NewPasswordResetToken(userID, time.Now().Add(h.resetTokenExpireDuration()))
+		if err != nil {
+			return err
+		}
+
+		if err := h.uow.PasswordReset(ctx).Save(ctx, token); err != nil {
+			return fmt.Errorf("error saving password reset token: %w", err)
+		}
+
+		plainToken = plain
+		return nil
+	})
+	if err != nil {
+		logging.Error("Failed to issue password reset token").
+			WithInt64("user_id", int64(userID)).
+			WithError(err).
+			Log()
+		return fmt.Errorf("UserEventHandler.SendPasswordResetEmail fail transaction: %w", err)
+	}
+
+	err = h.mailer.Send(ctx, adapter.Mail{
+		To:      event.Email,
+		Subject: "Reset your password",
+		Body:    fmt.Sprintf("Open the link below to choose a new password:\n%s", tokenLink(h.cfg.Password.ResetURL, plainToken)),
+	})
+	if err != nil {
+		return fmt.Errorf("UserEventHandler.SendPasswordResetEmail fail send mail: %w", err)
+	}
+
+	return nil
+}
+
+// SendUnlockEmail handles the UserLockedEvent and mails a link that unlocks
+// the account before the lockout ends
+func (h *UserEventHandler) SendUnlockEmail(ctx context.Context, event *events.UserLockedEvent) error {
+	// Users who signed up with a phone number have no address to mail
+	if event.Email == "" {
+		return nil
+	}
+
+	userID := entity.UserID(event.UserID)
+	var plainToken string
+
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		// The link is useless once the lockout is over
+		token, plain, err := entity.NewAccountUnlockToken(userID, event.LockedUntil)
+		if err != nil {
+			return err
+		}
+
+		if err := h.uow.AccountUnlock(ctx).Save(ctx, token); err != nil {
+			return fmt.Errorf("error saving account unlock token: %w", err)
+		}
+
+		plainToken = plain
+		return nil
+	})
+	if err != nil {
+		logging.Error("Failed to issue account unlock token").
+			WithInt64("user_id", int64(userID)).
+			WithError(err).
+			Log()
+		return fmt.Errorf("UserEventHandler.SendUnlockEmail fail transaction: %w", err)
+	}
+
+	err = h.mailer.Send(ctx, adapter.Mail{
+		To:      event.Email,
+		Subject: "Your account has been locked",
+		Body: fmt.Sprintf("Your account was locked after too many failed login attempts. It unlocks automatically at %s.\nIf these attempts were yours, open the link below to unlock it now:\n%s",
+			event.LockedUntil.UTC().Format(time.RFC1123), tokenLink(h.cfg.Login.UnlockURL, plainToken)),
+	})
+	if err != nil {
+		return fmt.Errorf("UserEventHandler.SendUnlockEmail fail send mail: %w", err)
+	}
+
+	return nil
+}
+
+// LogProfileUpdate handles the ProfileUpdatedEvent
+func (h *UserEventHandler) LogProfileUpdate(ctx context.Context, event *events.ProfileUpdatedEvent) error {
+	logging.Info("Profile updated").
+		WithInt64("user_id", int64(event.UserID)).
+		WithString("fields", strings.Join(event.Fields, ",")).
+		Log()
+
+	return nil
+}
+
+// SendAccountDeletedEmail handles the UserDeletedEvent and confirms the
+// closure of the account
+func (h *UserEventHandler) SendAccountDeletedEmail(ctx context.Context, event *events.UserDeletedEvent) error {
+	// Users who signed up with a phone number have no address to mail
+	if event.Email == "" {
+		return nil
+	}
+
+	err := h.mailer.Send(ctx, adapter.Mail{
+		To:      event.Email,
+		Subject: "Your account has been closed",
+		Body: fmt.Sprintf("Your account has been closed and you have been signed out on every device.\nYour personal data will be permanently erased on %s.",
+			event.PurgeAfter.UTC().Format(time.RFC1123)),
+	})
+	if err != nil {
+		return fmt.Errorf("UserEventHandler.SendAccountDeletedEmail fail send mail: %w", err)
+	}
+
+	return nil
+}
+
+// LogUserDisabled handles the UserDisabledEvent
+func (h *UserEventHandler) LogUserDisabled(ctx context.Context, event *events.UserDisabledEvent) error {
+	logging.Info("User disabled").
+		WithInt64("user_id", int64(event.UserID)).
+		WithInt64("actor_id", int64(event.ActorID)).
+		Log()
+	return nil
+}
+
+// LogUserEnabled handles the UserEnabledEvent
+func (h *UserEventHandler) LogUserEnabled(ctx context.Context, event *events.UserEnabledEvent) error {
+	logging.Info("User enabled").
+		WithInt64("user_id", int64(event.UserID)).
+		WithInt64("actor_id", int64(event.ActorID)).
+		Log()
+	return nil
+}
+
+// LogUserLoggedOut handles the UserLoggedOutEvent
+func (h *UserEventHandler) LogUserLoggedOut(ctx context.Context, event *events.UserLoggedOutEvent) error {
+	logging.Info("User signed out on every device").
+		WithInt64("user_id", int64(event.UserID)).
+		WithInt64("actor_id", int64(event.ActorID)).
+		Log()
+	return nil
+}
+
+// LogPasswordResetByAdmin handles the PasswordResetByAdminEvent
+func (h *UserEventHandler) LogPasswordResetByAdmin(ctx context.Context, event *events.PasswordResetByAdminEvent) error {
+	logging.Info("User password reset").
+		WithInt64("user_id", int64(event.UserID)).
+		WithInt64("actor_id", int64(event.ActorID)).
+		Log()
+	return nil
+}
+
+// LogUserRolesChanged handles the UserRolesChangedEvent
+func (h *UserEventHandler) LogUserRolesChanged(ctx context.Context, event *events.UserRolesChangedEvent) error {
+	logging.Info("User roles changed").
+		WithInt64("user_id", int64(event.UserID)).
+		WithInt64("actor_id", int64(event.ActorID)).
+		WithString("roles", strings.Join(event.Roles, ",")).
+		Log()
+	return nil
+}
+
+// LogServiceAccountCreated handles the ServiceAccountCreatedEvent
+func (h *UserEventHandler) LogServiceAccountCreated(ctx context.Context, event *events.ServiceAccountCreatedEvent) error {
+	if event.ServiceAccountID == nil {
+		return fmt.Errorf("UserEventHandler.LogServiceAccountCreated: ServiceAccountID is nil")
+	}
+
+	logging.Info("Service account created").
+		WithInt64("service_account_id", int64(*event.ServiceAccountID)).
+		WithString("name", event.Name).
+		WithString("scopes", strings.Join(event.Scopes, ",")).
+		WithInt64("actor_id", int64(event.ActorID)).
+		Log()
+	return nil
+}
+
+// LogApiKeyIssued handles the ApiKeyIssuedEvent
+func (h *UserEventHandler) LogApiKeyIssued(ctx context.Context, event *events.ApiKeyIssuedEvent) error {
+	if event.ApiKeyID == nil {
+		return fmt.Errorf("UserEventHandler.LogApiKeyIssued: ApiKeyID is nil")
+	}
+
+	logging.Info("API key issued").
+		WithInt64("service_account_id", int64(event.ServiceAccountID)).
+		WithInt64("api_key_id", int64(*event.ApiKeyID)).
+		WithInt64("actor_id", int64(event.ActorID)).
+		Log()
+	return nil
+}
+
+// LogApiKeyRevoked handles the ApiKeyRevokedEvent
+func (h *UserEventHandler) LogApiKeyRevoked(ctx context.Context, event *events.ApiKeyRevokedEvent) error {
+	logging.Info("API key revoked").
+		WithInt64("service_account_id", int64(event.ServiceAccountID)).
+		WithInt64("api_key_id", int64(event.ApiKeyID)).
+		WithInt64("actor_id", int64(event.ActorID)).
+		Log()
+	return nil
+}
+
+// LogExternalIdentityLinked handles the ExternalIdentityLinkedEvent
+func (h *UserEventHandler) LogExternalIdentityLinked(ctx context.Context, event *events.ExternalIdentityLinkedEvent) error {
+	logging.Info("External identity linked").
+		WithInt64("user_id", int64(event.UserID)).
+		WithString("provider", event.Provider).
+		Log()
+	return nil
+}
+
+// LogAvatarRejected handles the AvatarRejectedEvent
+func (h *UserEventHandler) LogAvatarRejected(ctx context.Context, event *events.AvatarRejectedEvent) error {
+	logging.Info("Avatar rejected").
+		WithInt64("user_id", int64(event.UserID)).
+		WithInt64("actor_id", int64(event.ActorID)).
+		Log()
+	return nil
+}
+
+// tokenLink points the frontend page at baseURL to the token. Without a
+// configured page the bare token is mailed.
+func tokenLink(baseURL, token string) string {
+	if baseURL == "" {
+		return token
+	}
+
+	return baseURL + "?token=" + token
+}
+
+func (h *UserEventHandler) verificationTokenExpireDuration() time.Duration {
+	if h.cfg.Verification.TokenExpireDuration <= 0 {
+		return defaultVerificationTokenExpireDuration
+	}
+
+	return h.cfg.Verification.TokenExpireDuration * time.Minute
+}
+
+func (h *UserEventHandler) resetTokenExpireDuration() time.Duration {
+	if h.cfg.Password.ResetTokenExpireDuration <= 0 {
+		return defaultResetTokenExpireDuration
+	}
+
+	return h.cfg.Password.ResetTokenExpireDuration * time.Minute
+}
@@ -0,0 +1,40 @@
+-- migrate:up
+ALTER TABLE products
+    ADD COLUMN min_price DECIMAL(10,2) DEFAULT 0 NOT NULL,
+    ADD COLUMN max_price DECIMAL(10,2) DEFAULT 0 NOT NULL,
+    ADD COLUMN price DECIMAL(10,2) DEFAULT 0 NOT NULL,
+    ADD COLUMN in_stock BOOLEAN DEFAULT false NOT NULL;
+
+-- Backfill from the details, the same way Product.RecalculatePricing does
+UPDATE products p SET
+    min_price = d.min_price,
+    max_price = d.max_price,
+    price = d.price,
+    in_stock = d.in_stock
+FROM (
+    SELECT
+        product_id,
+        COALESCE(MIN(price) FILTER (WHERE price > 0), 0) AS min_price,
+        COALESCE(MAX(price) FILTER (WHERE price > 0), 0) AS max_price,
+        COALESCE(MIN(CASE
+            WHEN original_price IS NULL AND discount > 0 THEN price * (100 - discount) / 100
+            ELSE price
+        END) FILTER (WHERE price > 0), 0) AS price,
+        BOOL_OR(stock > 0) AS in_stock
+    FROM product_details
+    WHERE deleted_at IS NULL
+    GROUP BY product_id
+) d
+WHERE d.product_id = p.id;
+
+CREATE INDEX idx_products_price ON products(price);
+CREATE INDEX idx_products_in_stock ON products(in_stock);
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_products_in_stock;
+DROP INDEX IF EXISTS idx_products_price;
+ALTER TABLE products
+    DROP COLUMN IF EXISTS in_stock,
+    DROP COLUMN IF EXISTS price,
+    DROP COLUMN IF EXISTS max_price,
+    DROP COLUMN IF EXISTS min_price;
@@ -0,0 +1,25 @@
+-- migrate:up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- normalize_search_text mirrors textnorm.Normalize, for the backfill below
+CREATE OR REPLACE FUNCTION normalize_search_text(input TEXT) RETURNS TEXT AS $$
+    SELECT btrim(regexp_replace(
+        lower(translate(
+            regexp_replace(input, '[\u200C\u200D\uFEFF\u0640\u064B-\u065F\u0670]', '', 'g'),
+            'يىكةأإٱؤ۰۱۲۳۴۵۶۷۸۹٠١٢٣٤٥٦٧٨٩',
+            'ییکهاااو01234567890123456789'
+        )),
+        '\s+', ' ', 'g'
+    ))
+$$ LANGUAGE SQL IMMUTABLE;
+
+ALTER TABLE products ADD COLUMN search_text TEXT DEFAULT '' NOT NULL;
+
+UPDATE products SET search_text = normalize_search_text(name || ' ' || COALESCE(brand, '') || ' ' || COALESCE(description, ''));
+
+CREATE INDEX idx_products_search_text ON products USING gin (search_text gin_trgm_ops);
+
+-- migrate:down
+DROP INDEX IF EXISTS idx_products_search_text;
+ALTER TABLE products DROP COLUMN IF EXISTS search_text;
+DROP FUNCTION IF EXISTS normalize_search_text(TEXT);
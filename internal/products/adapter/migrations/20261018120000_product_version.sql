@@ -0,0 +1,9 @@
+-- migrate:up
+-- Counts the changes of a product, carried by its events and used as the
+-- external version of its search document
+ALTER TABLE products
+    ADD COLUMN version BIGINT DEFAULT 1 NOT NULL;
+
+-- migrate:down
+ALTER TABLE products
+    DROP COLUMN IF EXISTS version;
@@ -16,6 +16,7 @@ type CategoryRepository interface {
 	adapter.BaseRepository[*entity.Category]
 	GetAll(ctx context.Context) ([]*entity.Category, error)
 	FindBySlug(ctx context.Context, slug string) (*entity.Category, error)
+	SuggestNames(ctx context.Context, prefix string, limit int) ([]CategorySuggestion, error)
 }
 
 type categoryGormRepository struct {
@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"time"
 
 	"shikposh-backend/internal/products/domain/entity"
 	"github.com/ali-mahdavi-dev/framework/adapter"
@@ -19,11 +22,13 @@ type OutboxRepository interface {
 	MarkAsCompleted(ctx context.Context, id entity.OutboxEventID) error
 	MarkAsFailed(ctx context.Context, id entity.OutboxEventID, errorMsg string) error
 	IncrementRetry(ctx context.Context, id entity.OutboxEventID) error
+	FindByTypeSince(ctx context.Context, eventType string, since time.Time) ([]*entity.OutboxEvent, error)
 }
 
 type outboxGormRepository struct {
 	adapter.BaseRepository[*entity.OutboxEvent]
 	frameworkRepo frameworkoutbox.Repository
+	db            *gorm.DB
 }
 
 func NewOutboxRepository(db *gorm.DB) OutboxRepository {
@@ -31,6 +36,7 @@ func NewOutboxRepository(db *gorm.DB) OutboxRepository {
 	return &outboxGormRepository{
 		BaseRepository: adapter.NewGormRepository[*entity.OutboxEvent](db),
 		frameworkRepo:  frameworkRepo,
+		db:             db,
 	}
 }
 
@@ -109,3 +115,39 @@ func (r *outboxGormRepository) MarkAsFailed(ctx context.Context, id entity.Outbo
 func (r *outboxGormRepository) IncrementRetry(ctx context.Context, id entity.OutboxEventID) error {
 	return r.frameworkRepo.IncrementRetry(ctx, frameworkoutbox.OutboxEventID(id))
 }
+
+// FindByTypeSince returns the events of eventType created since since, in the
+// order they were created, whatever their status.
+func (r *outboxGormRepository) FindByTypeSince(ctx context.Context, eventType string, since time.Time) ([]*entity.OutboxEvent, error) {
+	var rows []struct {
+		ID            uint64
+		CreatedAt     time.Time
+		EventType     string
+		AggregateType string
+		AggregateID   string
+		Payload       []byte
+	}
+	err := r.db.WithContext(ctx).Model(&entity.OutboxEvent{}).
+		Select("id, created_at, event_type, aggregate_type, aggregate_id, payload").
+		Where("event_type = ? AND created_at >= ?", eventType, since).
+		Order("id ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*entity.OutboxEvent, len(rows))
+	for i, row := range rows {
+		events[i] = &entity.OutboxEvent{
+			ID:            entity.OutboxEventID(row.ID),
+			CreatedAt:     row.CreatedAt,
+			EventType:     row.EventType,
+			AggregateType: row.AggregateType,
+			AggregateID:   row.AggregateID,
+		}
+		if err := json.Unmarshal(row.Payload, &events[i].Payload); err != nil {
+			return nil, fmt.Errorf("outboxGormRepository.FindByTypeSince fail decode payload of %d: %w", row.ID, err)
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks where the previous page of a list ended. After holds the sort
+// values of its last item, the ID last, and Sort the order they belong to, so
+// a cursor can't be replayed against a list sorted another way.
+type Cursor struct {
+	Sort  string        `json:"s"`
+	After []interface{} `json:"a"`
+}
+
+// Encode returns the opaque form of the cursor handed out to clients.
+func (c *Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a cursor returned by Encode. Numbers are kept as
+// json.Number so that IDs and sort values survive the round trip exactly.
+func DecodeCursor(encoded string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var cursor Cursor
+	if err := decoder.Decode(&cursor); err != nil || len(cursor.After) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// PageRequest asks for the page of a list that follows Cursor, or the first
+// page without one. WithTotal additionally counts every matching item, which
+// costs an extra query.
+type PageRequest struct {
+	Cursor    *Cursor
+	Limit     int
+	WithTotal bool
+}
+
+// PageLimit returns the requested page size, bounded to MaxPageLimit.
+func (p PageRequest) PageLimit() int {
+	if p.Limit <= 0 {
+		return DefaultPageLimit
+	}
+	if p.Limit > MaxPageLimit {
+		return MaxPageLimit
+	}
+	return p.Limit
+}
+
+// Page is one page of a list. NextCursor is nil on the last page.
+type Page[T any] struct {
+	Items      []T
+	NextCursor *Cursor
+	HasMore    bool
+	Total      *int64
+}
+
+// keyset is the order of a paginated query: a sort column followed by the
+// primary key as tie-breaker, both in the same direction.
+type keyset struct {
+	sort     string
+	column   string
+	idColumn string
+	desc     bool
+	// parse converts the sort value of a decoded cursor back into a value
+	// comparable with column.
+	parse func(value interface{}) (interface{}, error)
+}
+
+func (k keyset) order(query *gorm.DB) *gorm.DB {
+	direction := "ASC"
+	if k.desc {
+		direction = "DESC"
+	}
+	return query.Order(k.column + " " + direction).Order(k.idColumn + " " + direction)
+}
+
+// after restricts the query to the items that follow the cursor.
+func (k keyset) after(query *gorm.DB, cursor *Cursor) (*gorm.DB, error) {
+	if cursor.Sort != k.sort || len(cursor.After) != 2 {
+		return nil, ErrInvalidCursor
+	}
+	value, err := k.parse(cursor.After[0])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	id, err := parseCursorID(cursor.After[1])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	comparison := ">"
+	if k.desc {
+		comparison = "<"
+	}
+	return query.Where(
+		fmt.Sprintf("(%[1]s %[3]s ? OR (%[1]s = ? AND %[2]s %[3]s ?))", k.column, k.idColumn, comparison),
+		value, value, id,
+	), nil
+}
+
+// paginate loads the page of query described by page. It fetches one item
+// more than the page size to learn whether another page follows; cursorOf
+// returns the sort values of an item for the next cursor. Preloads are
+// applied by preload to the page only, since gorm would run them for the
+// count as well.
+func paginate[T any](query *gorm.DB, preload func(*gorm.DB) *gorm.DB, k keyset, page PageRequest, cursorOf func(item T) []interface{}) (*Page[T], error) {
+	result := &Page[T]{}
+	if page.WithTotal {
+		var total int64
+		if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, err
+		}
+		result.Total = &total
+	}
+
+	if page.Cursor != nil {
+		var err error
+		query, err = k.after(query, page.Cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	limit := page.PageLimit()
+	var items []T
+	if err := k.order(preload(query)).Limit(limit + 1).Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	if len(items) > limit {
+		items = items[:limit]
+		result.HasMore = true
+		result.NextCursor = &Cursor{Sort: k.sort, After: cursorOf(items[limit-1])}
+	}
+	result.Items = items
+	return result, nil
+}
+
+func parseCursorTime(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+func parseCursorFloat(value interface{}) (interface{}, error) {
+	n, ok := value.(json.Number)
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	return n.Float64()
+}
+
+func parseCursorID(value interface{}) (uint64, error) {
+	n, ok := value.(json.Number)
+	if !ok {
+		return 0, ErrInvalidCursor
+	}
+	id, err := n.Int64()
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidCursor
+	}
+	return uint64(id), nil
+}
@@ -3,31 +3,43 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"shikposh-backend/internal/products/domain/entity"
 	productaggregate "shikposh-backend/internal/products/domain/entity/product_aggregate"
+	"shikposh-backend/pkg/textnorm"
 	"github.com/ali-mahdavi-dev/framework/adapter"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var ErrProductNotFound = errors.New("product not found")
 
 type ProductRepository interface {
 	adapter.BaseRepository[*productaggregate.Product]
-	GetAll(ctx context.Context) ([]*productaggregate.Product, error)
+	GetAll(ctx context.Context, page PageRequest) (*Page[*productaggregate.Product], error)
 	FindBySlug(ctx context.Context, slug string) (*productaggregate.Product, error)
 	FindByCategoryID(ctx context.Context, categoryID entity.CategoryID) ([]*productaggregate.Product, error)
-	FindByCategorySlug(ctx context.Context, categorySlug string) ([]*productaggregate.Product, error)
-	FindFeatured(ctx context.Context) ([]*productaggregate.Product, error)
+	FindByCategorySlug(ctx context.Context, categorySlug string, page PageRequest) (*Page[*productaggregate.Product], error)
+	FindFeatured(ctx context.Context, page PageRequest) (*Page[*productaggregate.Product], error)
+	FindBatch(ctx context.Context, afterID uint64, limit int) ([]*productaggregate.Product, error)
+	FindBatchChangedSince(ctx context.Context, since time.Time, afterID uint64, limit int) ([]*productaggregate.Product, error)
 	Search(ctx context.Context, query string) ([]*productaggregate.Product, error)
-	Filter(ctx context.Context, filters ProductFilters) ([]*productaggregate.Product, error)
+	Filter(ctx context.Context, filters ProductFilters, page PageRequest) (*Page[*productaggregate.Product], error)
+	Facets(ctx context.Context, filters ProductFilters, options FacetOptions) (*ProductFacets, error)
+	SuggestNames(ctx context.Context, prefix string, limit int) ([]ProductSuggestion, error)
+	SuggestBrands(ctx context.Context, prefix string, limit int) ([]string, error)
 	ClearFeatures(ctx context.Context, product *productaggregate.Product) error
 	ClearDetails(ctx context.Context, product *productaggregate.Product) error
 	ClearSpecs(ctx context.Context, product *productaggregate.Product) error
 	ClearAllAssociations(ctx context.Context, product *productaggregate.Product) error
 }
 
+// ProductFilters select the products of a list. A category matches its
+// descendants as well, and a product matches a list of brands, tags, sizes or
+// colors if it has any of them.
 type ProductFilters struct {
 	Query    *string
 	Category *string
@@ -36,9 +48,49 @@ type ProductFilters struct {
 	Rating   *float64
 	Featured *bool
 	Tags     []string
+	Brands   []string
+	Sizes    []string
+	Colors   []string
+	InStock  *bool
 	Sort     *string
 }
 
+// Sort orders of product lists. Filter falls back to ProductSortNewest for an
+// empty or unknown sort.
+const (
+	ProductSortNewest    = "newest"
+	ProductSortRating    = "rating"
+	ProductSortPriceAsc  = "price_asc"
+	ProductSortPriceDesc = "price_desc"
+)
+
+// productSort is a keyset of the products table along with the sort value of
+// a product, from which the cursor of the next page is built.
+type productSort struct {
+	keyset
+	value func(p *productaggregate.Product) interface{}
+}
+
+func productSortBy(sort string) productSort {
+	switch sort {
+	case ProductSortRating:
+		return productSort{
+			keyset: keyset{sort: sort, column: "products.rating", idColumn: "products.id", desc: true, parse: parseCursorFloat},
+			value:  func(p *productaggregate.Product) interface{} { return p.Rating },
+		}
+	case ProductSortPriceAsc, ProductSortPriceDesc:
+		return productSort{
+			keyset: keyset{sort: sort, column: "products.price", idColumn: "products.id", desc: sort == ProductSortPriceDesc, parse: parseCursorFloat},
+			value:  func(p *productaggregate.Product) interface{} { return p.Price },
+		}
+	default:
+		return productSort{
+			keyset: keyset{sort: ProductSortNewest, column: "products.created_at", idColumn: "products.id", desc: true, parse: parseCursorTime},
+			value:  func(p *productaggregate.Product) interface{} { return p.CreatedAt },
+		}
+	}
+}
+
 type productGormRepository struct {
 	adapter.BaseRepository[*productaggregate.Product]
 	db *gorm.DB
@@ -58,7 +110,6 @@ func (r *productGormRepository) Model(ctx context.Context) *gorm.DB {
 // withPreloads applies all necessary preloads to the query
 func (r *productGormRepository) withPreloads(query *gorm.DB) *gorm.DB {
 	return query.
-		Preload("Category").
 		Preload("Details").
 		Preload("Details.Images").
 		Preload("Features", func(db *gorm.DB) *gorm.DB {
@@ -66,20 +117,25 @@ func (r *productGormRepository) withPreloads(query *gorm.DB) *gorm.DB {
 		}).
 		Preload("Specs", func(db *gorm.DB) *gorm.DB {
 			return db.Order("\"order\" ASC")
-		}).
-		Preload("Images")
+		})
 }
 
-func (r *productGormRepository) GetAll(ctx context.Context) ([]*productaggregate.Product, error) {
-	var products []*productaggregate.Product
-	err := r.withPreloads(r.Model(ctx)).Find(&products).Error
+func (r *productGormRepository) GetAll(ctx context.Context, page PageRequest) (*Page[*productaggregate.Product], error) {
+	return r.paginate(r.Model(ctx), productSortBy(ProductSortNewest), page)
+}
+
+// paginate loads a page of the products matched by query and marks them seen.
+func (r *productGormRepository) paginate(query *gorm.DB, sort productSort, page PageRequest) (*Page[*productaggregate.Product], error) {
+	result, err := paginate(query, r.withPreloads, sort.keyset, page, func(p *productaggregate.Product) []interface{} {
+		return []interface{}{sort.value(p), uint64(p.ID)}
+	})
 	if err != nil {
 		return nil, err
 	}
-	for _, p := range products {
+	for _, p := range result.Items {
 		r.SetSeen(p)
 	}
-	return products, nil
+	return result, nil
 }
 
 func (r *productGormRepository) FindBySlug(ctx context.Context, slug string) (*productaggregate.Product, error) {
@@ -107,38 +163,48 @@ func (r *productGormRepository) FindByCategoryID(ctx context.Context, categoryID
 	return products, nil
 }
 
-func (r *productGormRepository) FindByCategorySlug(ctx context.Context, categorySlug string) ([]*productaggregate.Product, error) {
+func (r *productGormRepository) FindByCategorySlug(ctx context.Context, categorySlug string, page PageRequest) (*Page[*productaggregate.Product], error) {
+	query := r.Model(ctx).Where("products.category_id IN (?)", categorySubtree(categorySlug))
+	return r.paginate(query, productSortBy(ProductSortNewest), page)
+}
+
+func (r *productGormRepository) FindFeatured(ctx context.Context, page PageRequest) (*Page[*productaggregate.Product], error) {
+	return r.paginate(r.Model(ctx).Where("is_featured = ?", true), productSortBy(ProductSortNewest), page)
+}
+
+// FindBatch returns up to limit products with an ID above afterID, in ID
+// order, for walking through every product.
+func (r *productGormRepository) FindBatch(ctx context.Context, afterID uint64, limit int) ([]*productaggregate.Product, error) {
 	var products []*productaggregate.Product
 	err := r.withPreloads(r.Model(ctx)).
-		Joins("JOIN categories ON products.category_id = categories.id").
-		Where("categories.slug = ?", categorySlug).
+		Where("products.id > ?", afterID).
+		Order("products.id ASC").
+		Limit(limit).
 		Find(&products).Error
 	if err != nil {
 		return nil, err
 	}
-	for _, p := range products {
-		r.SetSeen(p)
-	}
 	return products, nil
 }
 
-func (r *productGormRepository) FindFeatured(ctx context.Context) ([]*productaggregate.Product, error) {
+// FindBatchChangedSince is FindBatch for the products changed since since.
+func (r *productGormRepository) FindBatchChangedSince(ctx context.Context, since time.Time, afterID uint64, limit int) ([]*productaggregate.Product, error) {
 	var products []*productaggregate.Product
-	err := r.withPreloads(r.Model(ctx)).Where("is_featured = ?", true).Find(&products).Error
+	err := r.withPreloads(r.Model(ctx)).
+		Where("products.updated_at >= ? AND products.id > ?", since, afterID).
+		Order("products.id ASC").
+		Limit(limit).
+		Find(&products).Error
 	if err != nil {
 		return nil, err
 	}
-	for _, p := range products {
-		r.SetSeen(p)
-	}
 	return products, nil
 }
 
 func (r *productGormRepository) Search(ctx context.Context, query string) ([]*productaggregate.Product, error) {
 	var products []*productaggregate.Product
-	searchPattern := "%" + query + "%"
 	err := r.withPreloads(r.Model(ctx)).
-		Where("name ILIKE ? OR description ILIKE ? OR brand ILIKE ?", searchPattern, searchPattern, searchPattern).
+		Where(`products.search_text LIKE ? ESCAPE '\'`, searchPattern(query)).
 		Find(&products).Error
 	if err != nil {
 		return nil, err
@@ -149,68 +215,89 @@ func (r *productGormRepository) Search(ctx context.Context, query string) ([]*pr
 	return products, nil
 }
 
-func (r *productGormRepository) Filter(ctx context.Context, filters ProductFilters) ([]*productaggregate.Product, error) {
-	query := r.withPreloads(r.Model(ctx))
+func (r *productGormRepository) Filter(ctx context.Context, filters ProductFilters, page PageRequest) (*Page[*productaggregate.Product], error) {
+	sort := ProductSortNewest
+	if filters.Sort != nil {
+		sort = *filters.Sort
+	}
+	return r.paginate(r.applyFilters(r.Model(ctx), filters), productSortBy(sort), page)
+}
 
+// applyFilters restricts query, over the products table, to the products that
+// match filters.
+func (r *productGormRepository) applyFilters(query *gorm.DB, filters ProductFilters) *gorm.DB {
 	if filters.Query != nil && *filters.Query != "" {
-		searchPattern := "%" + *filters.Query + "%"
-		query = query.Where("name ILIKE ? OR description ILIKE ? OR brand ILIKE ?", searchPattern, searchPattern, searchPattern)
+		query = query.Where(`products.search_text LIKE ? ESCAPE '\'`, searchPattern(*filters.Query))
 	}
 
 	if filters.Category != nil && *filters.Category != "" {
-		query = query.Joins("JOIN categories ON products.category_id = categories.id").
-			Where("categories.slug = ?", *filters.Category)
+		query = query.Where("products.category_id IN (?)", categorySubtree(*filters.Category))
 	}
 
 	if filters.MinPrice != nil {
-		query = query.Where("price >= ?", *filters.MinPrice)
+		query = query.Where("products.price >= ?", *filters.MinPrice)
 	}
 
 	if filters.MaxPrice != nil {
-		query = query.Where("price <= ?", *filters.MaxPrice)
+		query = query.Where("products.price <= ?", *filters.MaxPrice)
 	}
 
 	if filters.Rating != nil {
-		query = query.Where("rating >= ?", *filters.Rating)
+		query = query.Where("products.rating >= ?", *filters.Rating)
 	}
 
 	if filters.Featured != nil && *filters.Featured {
-		query = query.Where("is_featured = ?", true)
+		query = query.Where("products.is_featured = ?", true)
 	}
 
 	if len(filters.Tags) > 0 {
-		for _, tag := range filters.Tags {
-			query = query.Where("tags @> ?", `["`+tag+`"]`)
-		}
+		query = query.Where("EXISTS (SELECT 1 FROM "+r.jsonArrayElements("products.tags", "tag")+" WHERE tag.value IN ?)", filters.Tags)
 	}
 
-	// Apply sorting
-	if filters.Sort != nil {
-		switch *filters.Sort {
-		case "price_asc":
-			query = query.Order("price ASC")
-		case "price_desc":
-			query = query.Order("price DESC")
-		case "rating":
-			query = query.Order("rating DESC")
-		case "newest":
-			query = query.Order("created_at DESC")
-		default:
-			query = query.Order("created_at DESC")
-		}
-	} else {
-		query = query.Order("created_at DESC")
+	if len(filters.Brands) > 0 {
+		query = query.Where("products.brand IN ?", filters.Brands)
 	}
 
-	var products []*productaggregate.Product
-	err := query.Find(&products).Error
-	if err != nil {
-		return nil, err
+	if len(filters.Sizes) > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM "+r.jsonArrayElements("products.sizes", "size")+" WHERE size.value IN ?)", filters.Sizes)
 	}
-	for _, p := range products {
-		r.SetSeen(p)
+
+	if len(filters.Colors) > 0 {
+		query = query.Where(`EXISTS (SELECT 1 FROM product_details WHERE product_details.product_id = products.id
+			AND product_details.deleted_at IS NULL AND product_details.color_key IN ?)`, filters.Colors)
 	}
-	return products, nil
+
+	if filters.InStock != nil {
+		query = query.Where("products.in_stock = ?", *filters.InStock)
+	}
+
+	return query
+}
+
+// searchPattern returns the LIKE pattern matching the search texts that
+// contain query once normalised. The trigram index on search_text serves it.
+func searchPattern(query string) string {
+	return "%" + escapeLike(textnorm.Normalize(query)) + "%"
+}
+
+// categorySubtree selects the IDs of the category with the slug and of all of
+// its descendants.
+func categorySubtree(slug string) clause.Expr {
+	return gorm.Expr(`WITH RECURSIVE subtree(id) AS (
+		SELECT id FROM categories WHERE slug = ? AND deleted_at IS NULL
+		UNION ALL
+		SELECT categories.id FROM categories JOIN subtree ON categories.parent_id = subtree.id WHERE categories.deleted_at IS NULL
+	) SELECT id FROM subtree`, slug)
+}
+
+// jsonArrayElements returns the table function that expands a JSON array
+// column into rows with a value column, named alias. Postgres and SQLite call
+// it differently.
+func (r *productGormRepository) jsonArrayElements(column, alias string) string {
+	if r.db.Dialector.Name() == "sqlite" {
+		return fmt.Sprintf("json_each(%s) AS %s", column, alias)
+	}
+	return fmt.Sprintf("jsonb_array_elements_text(%s) AS %s(value)", column, alias)
 }
 
 func (r *productGormRepository) ClearFeatures(ctx context.Context, product *productaggregate.Product) error {
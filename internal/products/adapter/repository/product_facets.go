@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"shikposh-backend/internal/products/domain/entity"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultPriceInterval is the width of the price histogram buckets.
+	DefaultPriceInterval = 100000
+	// MinPriceInterval is the narrowest price histogram bucket a search may ask for.
+	MinPriceInterval = 1000
+	// MaxPriceBuckets is the number of price histogram buckets counted, the
+	// cheapest first, however wide the prices range.
+	MaxPriceBuckets = 100
+	// facetSize is the number of values of a facet that are counted.
+	facetSize = 100
+)
+
+// RatingFacetThresholds are the lower bounds of the rating buckets, that is
+// "4 stars and up" and so on.
+var RatingFacetThresholds = []float64{4, 3, 2, 1}
+
+// FacetOptions shape the facets of a faceted search.
+type FacetOptions struct {
+	PriceInterval float64
+}
+
+// Interval returns the width of the price histogram buckets.
+func (o FacetOptions) Interval() float64 {
+	if o.PriceInterval <= 0 {
+		return DefaultPriceInterval
+	}
+	return o.PriceInterval
+}
+
+// FacetBucket is the number of products with a value of a facet.
+type FacetBucket struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// RangeFacetBucket is the number of products whose value lies in [From, To).
+// To is omitted for open ranges.
+type RangeFacetBucket struct {
+	From  float64  `json:"from"`
+	To    *float64 `json:"to,omitempty"`
+	Count int64    `json:"count"`
+}
+
+// CategoryFacetBucket is the number of products of a category, its
+// descendants included.
+type CategoryFacetBucket struct {
+	ID       uint64                 `json:"id"`
+	Slug     string                 `json:"slug"`
+	Name     string                 `json:"name"`
+	Count    int64                  `json:"count"`
+	Children []*CategoryFacetBucket `json:"children,omitempty"`
+}
+
+// ProductFacets count the products matching a search per value of every facet.
+// Each facet is counted with all selected filters but its own, so that the
+// other values of a facet stay selectable.
+type ProductFacets struct {
+	Brands     []FacetBucket          `json:"brands"`
+	Categories []*CategoryFacetBucket `json:"categories"`
+	Tags       []FacetBucket          `json:"tags"`
+	Sizes      []FacetBucket          `json:"sizes"`
+	Colors     []FacetBucket          `json:"colors"`
+	Prices     []RangeFacetBucket     `json:"prices"`
+	Ratings    []RangeFacetBucket     `json:"ratings"`
+	InStock    int64                  `json:"in_stock"`
+}
+
+// BuildCategoryFacets arranges the product counts of categories, keyed by the
+// category ID, into the category tree. Each category counts the products of
+// its descendants as well; categories without products are left out.
+func BuildCategoryFacets(categories []*entity.Category, counts map[uint64]int64) []*CategoryFacetBucket {
+	buckets := make(map[uint64]*CategoryFacetBucket, len(categories))
+	parents := make(map[uint64]uint64, len(categories))
+	for _, category := range categories {
+		buckets[uint64(category.ID)] = &CategoryFacetBucket{
+			ID:   uint64(category.ID),
+			Slug: category.Slug,
+			Name: category.Name,
+		}
+		if category.ParentID != nil {
+			parents[uint64(category.ID)] = uint64(*category.ParentID)
+		}
+	}
+
+	// Roll the counts up to every ancestor, guarding against parent cycles
+	for id, count := range counts {
+		visited := map[uint64]bool{}
+		for current, ok := id, true; ok && !visited[current]; current, ok = parents[current] {
+			visited[current] = true
+			if bucket, found := buckets[current]; found {
+				bucket.Count += count
+			}
+		}
+	}
+
+	var roots []*CategoryFacetBucket
+	for _, category := range categories {
+		bucket := buckets[uint64(category.ID)]
+		if bucket.Count == 0 {
+			continue
+		}
+		if parent, ok := buckets[parents[bucket.ID]]; ok && category.ParentID != nil {
+			parent.Children = append(parent.Children, bucket)
+		} else {
+			roots = append(roots, bucket)
+		}
+	}
+	sortCategoryFacets(roots)
+	return roots
+}
+
+func sortCategoryFacets(buckets []*CategoryFacetBucket) {
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Count != buckets[j].Count {
+			return buckets[i].Count > buckets[j].Count
+		}
+		return buckets[i].Name < buckets[j].Name
+	})
+	for _, bucket := range buckets {
+		sortCategoryFacets(bucket.Children)
+	}
+}
+
+// Facets counts the facets of the products matching filters with GROUP BY
+// queries, one per facet.
+func (r *productGormRepository) Facets(ctx context.Context, filters ProductFilters, options FacetOptions) (*ProductFacets, error) {
+	facets := &ProductFacets{}
+	var err error
+
+	// filtered returns the products matching filters once unset clears the
+	// filter of the facet being counted.
+	filtered := func(unset func(f *ProductFilters)) *gorm.DB {
+		f := filters
+		unset(&f)
+		return r.applyFilters(r.Model(ctx), f)
+	}
+
+	facets.Brands, err = r.termFacet(filtered(func(f *ProductFilters) { f.Brands = nil }), "products.brand", "")
+	if err != nil {
+		return nil, fmt.Errorf("productGormRepository.Facets fail to count brands: %w", err)
+	}
+
+	facets.Tags, err = r.termFacet(filtered(func(f *ProductFilters) { f.Tags = nil }), "tag.value", "CROSS JOIN "+r.jsonArrayElements("products.tags", "tag"))
+	if err != nil {
+		return nil, fmt.Errorf("productGormRepository.Facets fail to count tags: %w", err)
+	}
+
+	facets.Sizes, err = r.termFacet(filtered(func(f *ProductFilters) { f.Sizes = nil }), "size.value", "CROSS JOIN "+r.jsonArrayElements("products.sizes", "size"))
+	if err != nil {
+		return nil, fmt.Errorf("productGormRepository.Facets fail to count sizes: %w", err)
+	}
+
+	facets.Colors, err = r.termFacet(filtered(func(f *ProductFilters) { f.Colors = nil }), "product_details.color_key",
+		"JOIN product_details ON product_details.product_id = products.id AND product_details.deleted_at IS NULL AND product_details.color_key IS NOT NULL")
+	if err != nil {
+		return nil, fmt.Errorf("productGormRepository.Facets fail to count colors: %w", err)
+	}
+
+	facets.Categories, err = r.categoryFacet(ctx, filtered(func(f *ProductFilters) { f.Category = nil }))
+	if err != nil {
+		return nil, fmt.Errorf("productGormRepository.Facets fail to count categories: %w", err)
+	}
+
+	facets.Prices, err = r.priceFacet(filtered(func(f *ProductFilters) { f.MinPrice, f.MaxPrice = nil, nil }), options.Interval())
+	if err != nil {
+		return nil, fmt.Errorf("productGormRepository.Facets fail to count prices: %w", err)
+	}
+
+	facets.Ratings, err = r.ratingFacet(filtered(func(f *ProductFilters) { f.Rating = nil }))
+	if err != nil {
+		return nil, fmt.Errorf("productGormRepository.Facets fail to count ratings: %w", err)
+	}
+
+	err = filtered(func(f *ProductFilters) { f.InStock = nil }).Where("products.in_stock = ?", true).Count(&facets.InStock).Error
+	if err != nil {
+		return nil, fmt.Errorf("productGormRepository.Facets fail to count products in stock: %w", err)
+	}
+
+	return facets, nil
+}
+
+// termFacet counts the distinct products of query per value of column, which
+// join brings in when it isn't a column of products.
+func (r *productGormRepository) termFacet(query *gorm.DB, column, join string) ([]FacetBucket, error) {
+	if join != "" {
+		query = query.Joins(join)
+	}
+	buckets := []FacetBucket{}
+	err := query.
+		Select(column + " AS value, COUNT(DISTINCT products.id) AS count").
+		Group(column).
+		Order("count DESC").Order("value ASC").
+		Limit(facetSize).
+		Scan(&buckets).Error
+	return buckets, err
+}
+
+func (r *productGormRepository) categoryFacet(ctx context.Context, query *gorm.DB) ([]*CategoryFacetBucket, error) {
+	var rows []struct {
+		CategoryID uint64
+		Count      int64
+	}
+	err := query.Select("products.category_id AS category_id, COUNT(*) AS count").Group("products.category_id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var categories []*entity.Category
+	if err := r.db.WithContext(ctx).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint64]int64, len(rows))
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Count
+	}
+	return BuildCategoryFacets(categories, counts), nil
+}
+
+func (r *productGormRepository) priceFacet(query *gorm.DB, interval float64) ([]RangeFacetBucket, error) {
+	bucket := "FLOOR(products.price / ?) * ?"
+	if r.db.Dialector.Name() == "sqlite" {
+		bucket = "CAST(products.price / ? AS INTEGER) * ?"
+	}
+
+	var rows []struct {
+		Bucket float64
+		Count  int64
+	}
+	err := query.
+		Select(bucket+" AS bucket, COUNT(*) AS count", interval, interval).
+		Group("bucket").
+		Order("bucket ASC").
+		Limit(MaxPriceBuckets).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]RangeFacetBucket, len(rows))
+	for i, row := range rows {
+		to := row.Bucket + interval
+		buckets[i] = RangeFacetBucket{From: row.Bucket, To: &to, Count: row.Count}
+	}
+	return buckets, nil
+}
+
+func (r *productGormRepository) ratingFacet(query *gorm.DB) ([]RangeFacetBucket, error) {
+	columns := make([]string, len(RatingFacetThresholds))
+	for i, threshold := range RatingFacetThresholds {
+		columns[i] = fmt.Sprintf("COALESCE(SUM(CASE WHEN products.rating >= %g THEN 1 ELSE 0 END), 0)", threshold)
+	}
+
+	counts := make([]int64, len(RatingFacetThresholds))
+	dest := make([]interface{}, len(counts))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+	if err := query.Select(strings.Join(columns, ", ")).Row().Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	buckets := make([]RangeFacetBucket, len(RatingFacetThresholds))
+	for i, threshold := range RatingFacetThresholds {
+		buckets[i] = RangeFacetBucket{From: threshold, Count: counts[i]}
+	}
+	return buckets, nil
+}
@@ -16,8 +16,10 @@ var ErrReviewNotFound = errors.New("review not found")
 
 type ReviewRepository interface {
 	adapter.BaseRepository[*entity.Review]
-	FindByProductID(ctx context.Context, productID productaggregate.ProductID) ([]*entity.Review, error)
+	FindByProductID(ctx context.Context, productID productaggregate.ProductID, page PageRequest) (*Page[*entity.Review], error)
 	FindByUserID(ctx context.Context, userID accountentity.UserID) ([]*entity.Review, error)
+	AnonymizeByUserID(ctx context.Context, userID accountentity.UserID, userName string) error
+	UpdateUserAvatar(ctx context.Context, userID accountentity.UserID, avatar string) error
 }
 
 type reviewGormRepository struct {
@@ -36,16 +38,22 @@ func (r *reviewGormRepository) Model(ctx context.Context) *gorm.DB {
 	return r.db.WithContext(ctx).Model(&entity.Review{}).Preload("Product")
 }
 
-func (r *reviewGormRepository) FindByProductID(ctx context.Context, productID productaggregate.ProductID) ([]*entity.Review, error) {
-	var reviews []*entity.Review
-	err := r.Model(ctx).Where("product_id = ?", uint64(productID)).Order("created_at DESC").Find(&reviews).Error
+// reviewsNewestFirst is the order reviews of a product are paginated in.
+var reviewsNewestFirst = keyset{sort: "newest", column: "reviews.created_at", idColumn: "reviews.id", desc: true, parse: parseCursorTime}
+
+func (r *reviewGormRepository) FindByProductID(ctx context.Context, productID productaggregate.ProductID, page PageRequest) (*Page[*entity.Review], error) {
+	query := r.db.WithContext(ctx).Model(&entity.Review{}).Where("product_id = ?", uint64(productID))
+	preload := func(db *gorm.DB) *gorm.DB { return db.Preload("Product") }
+	result, err := paginate(query, preload, reviewsNewestFirst, page, func(review *entity.Review) []interface{} {
+		return []interface{}{review.CreatedAt, uint64(review.ID)}
+	})
 	if err != nil {
 		return nil, err
 	}
-	for _, review := range reviews {
+	for _, review := range result.Items {
 		r.SetSeen(review)
 	}
-	return reviews, nil
+	return result, nil
 }
 
 func (r *reviewGormRepository) FindByUserID(ctx context.Context, userID accountentity.UserID) ([]*entity.Review, error) {
@@ -59,3 +67,19 @@ func (r *reviewGormRepository) FindByUserID(ctx context.Context, userID accounte
 	}
 	return reviews, nil
 }
+
+// AnonymizeByUserID replaces the author name of every review of the user and
+// drops their avatar. The reviews themselves stay, since they still count
+// towards the product rating.
+func (r *reviewGormRepository) AnonymizeByUserID(ctx context.Context, userID accountentity.UserID, userName string) error {
+	return r.db.WithContext(ctx).Model(&entity.Review{}).
+		Where("user_id = ?", uint64(userID)).
+		Updates(map[string]interface{}{"user_name": userName, "user_avatar": nil}).Error
+}
+
+// UpdateUserAvatar shows the new avatar of the user on all of their reviews.
+func (r *reviewGormRepository) UpdateUserAvatar(ctx context.Context, userID accountentity.UserID, avatar string) error {
+	return r.db.WithContext(ctx).Model(&entity.Review{}).
+		Where("user_id = ?", uint64(userID)).
+		Update("user_avatar", avatar).Error
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+	"shikposh-backend/pkg/textnorm"
+)
+
+const (
+	DefaultSuggestLimit = 5
+	MaxSuggestLimit     = 10
+)
+
+// ProductSuggestion is a product whose name completes what was typed.
+type ProductSuggestion struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// CategorySuggestion is a category whose name completes what was typed.
+type CategorySuggestion struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// Suggestions are the completions of a partly typed search. DidYouMean holds
+// spelling corrections of the whole text, which only Elasticsearch offers.
+type Suggestions struct {
+	Products   []ProductSuggestion  `json:"products"`
+	Categories []CategorySuggestion `json:"categories"`
+	Brands     []string             `json:"brands"`
+	DidYouMean []string             `json:"did_you_mean"`
+}
+
+// prefixPatterns returns the LIKE patterns matching a normalised text that
+// starts with prefix, and one in which any word does.
+func prefixPatterns(prefix string) (string, string) {
+	escaped := escapeLike(textnorm.Normalize(prefix))
+	return escaped + "%", "% " + escaped + "%"
+}
+
+// escapeLike escapes the LIKE wildcards in s, for patterns used with ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// prefixCondition matches the rows in which any word of column starts with
+// the prefix of the patterns of prefixPatterns. The column is normalised the
+// way search_text is, so that Arabic letters, digits and case match as they
+// do in search. SQLite has no normalize_search_text and only lower-cases it.
+func prefixCondition(db *gorm.DB, column string) string {
+	normalized := "normalize_search_text(" + column + ")"
+	if db.Dialector.Name() == "sqlite" {
+		normalized = "LOWER(" + column + ")"
+	}
+	return normalized + ` LIKE ? ESCAPE '\' OR ` + normalized + ` LIKE ? ESCAPE '\'`
+}
+
+// SuggestNames returns the products with a word of their name starting with
+// prefix, the best rated first.
+func (r *productGormRepository) SuggestNames(ctx context.Context, prefix string, limit int) ([]ProductSuggestion, error) {
+	start, word := prefixPatterns(prefix)
+	suggestions := []ProductSuggestion{}
+	err := r.Model(ctx).
+		Select("products.id, products.name, products.slug").
+		Where(prefixCondition(r.db, "products.name"), start, word).
+		Order("products.rating DESC").Order("products.id DESC").
+		Limit(limit).
+		Scan(&suggestions).Error
+	return suggestions, err
+}
+
+// SuggestBrands returns the brands with a word starting with prefix, in
+// alphabetical order.
+func (r *productGormRepository) SuggestBrands(ctx context.Context, prefix string, limit int) ([]string, error) {
+	start, word := prefixPatterns(prefix)
+	brands := []string{}
+	err := r.Model(ctx).
+		Distinct("products.brand").
+		Where(prefixCondition(r.db, "products.brand"), start, word).
+		Order("products.brand ASC").
+		Limit(limit).
+		Pluck("products.brand", &brands).Error
+	return brands, err
+}
+
+// SuggestNames returns the categories with a word of their name starting
+// with prefix, in alphabetical order.
+func (r *categoryGormRepository) SuggestNames(ctx context.Context, prefix string, limit int) ([]CategorySuggestion, error) {
+	start, word := prefixPatterns(prefix)
+	suggestions := []CategorySuggestion{}
+	err := r.Model(ctx).
+		Select("categories.id, categories.name, categories.slug").
+		Where(prefixCondition(r.db, "categories.name"), start, word).
+		Order("categories.name ASC").
+		Limit(limit).
+		Scan(&suggestions).Error
+	return suggestions, err
+}
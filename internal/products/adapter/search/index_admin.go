@@ -0,0 +1,267 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"shikposh-backend/config"
+)
+
+var (
+	ErrIndexAdminRequest = errors.New("elasticsearch rejected the request")
+	// ErrVersionConflict is returned when a document is written with a version
+	// no newer than the one it is indexed with.
+	ErrVersionConflict = errors.New("document has a newer version")
+)
+
+// Document is a document of an index along with its external version.
+// Elasticsearch only writes a document over one of an older version, so
+// writes delivered out of order can't replace newer data.
+type Document struct {
+	ID      string
+	Version int64
+	Body    map[string]interface{}
+}
+
+// IndexAdmin manages the indices and aliases of Elasticsearch, which the
+// framework connection only reads and writes documents of, and writes
+// documents with their versions, which the framework connection can't.
+type IndexAdmin interface {
+	CreateIndex(ctx context.Context, index string, body map[string]interface{}) error
+	DeleteIndex(ctx context.Context, index string) error
+	// IndexExists reports whether index names a concrete index, not an alias.
+	IndexExists(ctx context.Context, index string) (bool, error)
+	// AliasIndices returns the indices alias points at, none if it doesn't exist.
+	AliasIndices(ctx context.Context, alias string) ([]string, error)
+	// UpdateAliases applies the alias actions atomically.
+	UpdateAliases(ctx context.Context, actions []map[string]interface{}) error
+	// Bulk indexes documents into index.
+	Bulk(ctx context.Context, index string, documents []Document) error
+	Refresh(ctx context.Context, index string) error
+	// IndexDocument indexes document into index, or returns ErrVersionConflict
+	// if it is indexed with the same or a newer version.
+	IndexDocument(ctx context.Context, index string, document Document) error
+	// DeleteDocument deletes the document id from index as of version, or
+	// returns ErrVersionConflict if it is indexed with the same or a newer one.
+	// Deleting a document that doesn't exist succeeds.
+	DeleteDocument(ctx context.Context, index, id string, version int64) error
+}
+
+type httpIndexAdmin struct {
+	baseURL  string
+	username string
+	password string
+	client   *http.Client
+}
+
+func NewIndexAdmin(cfg config.ElasticsearchConfig, client *http.Client) IndexAdmin {
+	return &httpIndexAdmin{
+		baseURL:  fmt.Sprintf("http://%s:%s", cfg.Host, cfg.Port),
+		username: cfg.Username,
+		password: cfg.Password,
+		client:   client,
+	}
+}
+
+func (a *httpIndexAdmin) CreateIndex(ctx context.Context, index string, body map[string]interface{}) error {
+	if _, err := a.do(ctx, http.MethodPut, "/"+url.PathEscape(index), "application/json", body); err != nil {
+		return fmt.Errorf("httpIndexAdmin.CreateIndex fail create %s: %w", index, err)
+	}
+	return nil
+}
+
+func (a *httpIndexAdmin) DeleteIndex(ctx context.Context, index string) error {
+	if _, err := a.do(ctx, http.MethodDelete, "/"+url.PathEscape(index), "", nil); err != nil {
+		return fmt.Errorf("httpIndexAdmin.DeleteIndex fail delete %s: %w", index, err)
+	}
+	return nil
+}
+
+func (a *httpIndexAdmin) IndexExists(ctx context.Context, index string) (bool, error) {
+	// Asking for the index by name would resolve an alias of that name too
+	response, err := a.do(ctx, http.MethodGet, "/_cat/indices/"+url.PathEscape(index)+"?format=json&h=index", "", nil)
+	if errors.Is(err, errNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("httpIndexAdmin.IndexExists fail get %s: %w", index, err)
+	}
+
+	var indices []struct {
+		Index string `json:"index"`
+	}
+	if err := json.Unmarshal(response, &indices); err != nil {
+		return false, fmt.Errorf("httpIndexAdmin.IndexExists fail decode response: %w", err)
+	}
+	for _, i := range indices {
+		if i.Index == index {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (a *httpIndexAdmin) AliasIndices(ctx context.Context, alias string) ([]string, error) {
+	response, err := a.do(ctx, http.MethodGet, "/_alias/"+url.PathEscape(alias), "", nil)
+	if errors.Is(err, errNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("httpIndexAdmin.AliasIndices fail get %s: %w", alias, err)
+	}
+
+	var indices map[string]interface{}
+	if err := json.Unmarshal(response, &indices); err != nil {
+		return nil, fmt.Errorf("httpIndexAdmin.AliasIndices fail decode response: %w", err)
+	}
+	names := make([]string, 0, len(indices))
+	for name := range indices {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (a *httpIndexAdmin) UpdateAliases(ctx context.Context, actions []map[string]interface{}) error {
+	if _, err := a.do(ctx, http.MethodPost, "/_aliases", "application/json", map[string]interface{}{"actions": actions}); err != nil {
+		return fmt.Errorf("httpIndexAdmin.UpdateAliases fail update aliases: %w", err)
+	}
+	return nil
+}
+
+func (a *httpIndexAdmin) Bulk(ctx context.Context, index string, documents []Document) error {
+	if len(documents) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, document := range documents {
+		action := map[string]interface{}{"index": map[string]interface{}{
+			"_index":       index,
+			"_id":          document.ID,
+			"version":      document.Version,
+			"version_type": "external",
+		}}
+		if err := encoder.Encode(action); err != nil {
+			return fmt.Errorf("httpIndexAdmin.Bulk fail encode action: %w", err)
+		}
+		if err := encoder.Encode(document.Body); err != nil {
+			return fmt.Errorf("httpIndexAdmin.Bulk fail encode document %s: %w", document.ID, err)
+		}
+	}
+
+	response, err := a.do(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", body.Bytes())
+	if err != nil {
+		return fmt.Errorf("httpIndexAdmin.Bulk fail index into %s: %w", index, err)
+	}
+
+	// A bulk request succeeds even when some of its documents fail
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID    string          `json:"_id"`
+			Error json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(response, &result); err != nil {
+		return fmt.Errorf("httpIndexAdmin.Bulk fail decode response: %w", err)
+	}
+	if result.Errors {
+		for _, item := range result.Items {
+			for _, outcome := range item {
+				if len(outcome.Error) > 0 {
+					return fmt.Errorf("httpIndexAdmin.Bulk fail index document %s: %w: %s", outcome.ID, ErrIndexAdminRequest, outcome.Error)
+				}
+			}
+		}
+		return fmt.Errorf("httpIndexAdmin.Bulk fail index into %s: %w", index, ErrIndexAdminRequest)
+	}
+	return nil
+}
+
+func (a *httpIndexAdmin) Refresh(ctx context.Context, index string) error {
+	if _, err := a.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_refresh", "", nil); err != nil {
+		return fmt.Errorf("httpIndexAdmin.Refresh fail refresh %s: %w", index, err)
+	}
+	return nil
+}
+
+func (a *httpIndexAdmin) IndexDocument(ctx context.Context, index string, document Document) error {
+	path := "/" + url.PathEscape(index) + "/_doc/" + url.PathEscape(document.ID) + versionQuery(document.Version)
+	if _, err := a.do(ctx, http.MethodPut, path, "application/json", document.Body); err != nil {
+		return fmt.Errorf("httpIndexAdmin.IndexDocument fail index %s into %s: %w", document.ID, index, err)
+	}
+	return nil
+}
+
+func (a *httpIndexAdmin) DeleteDocument(ctx context.Context, index, id string, version int64) error {
+	path := "/" + url.PathEscape(index) + "/_doc/" + url.PathEscape(id) + versionQuery(version)
+	_, err := a.do(ctx, http.MethodDelete, path, "", nil)
+	if err != nil && !errors.Is(err, errNotFound) {
+		return fmt.Errorf("httpIndexAdmin.DeleteDocument fail delete %s from %s: %w", id, index, err)
+	}
+	return nil
+}
+
+// versionQuery returns the query string a document is written at version with.
+func versionQuery(version int64) string {
+	return "?version=" + strconv.FormatInt(version, 10) + "&version_type=external"
+}
+
+var errNotFound = errors.New("not found")
+
+// do sends a request to Elasticsearch and returns the response body. body is
+// encoded as JSON unless it is already raw bytes.
+func (a *httpIndexAdmin) do(ctx context.Context, method, path, contentType string, body interface{}) ([]byte, error) {
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		reader = bytes.NewReader(b)
+	default:
+		encoded, err := json.Marshal(b)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, a.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if a.username != "" {
+		req.SetBasicAuth(a.username, a.password)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	response, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errNotFound
+	}
+	if resp.StatusCode == http.StatusConflict {
+		return nil, fmt.Errorf("%w: %s %s: %s", ErrVersionConflict, method, path, response)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%w: %s %s: %d %s", ErrIndexAdminRequest, method, path, resp.StatusCode, response)
+	}
+	return response, nil
+}
@@ -0,0 +1,110 @@
+package search
+
+import (
+	"strconv"
+	"time"
+
+	productaggregate "shikposh-backend/internal/products/domain/entity/product_aggregate"
+)
+
+// ProductIndexAlias is the alias products are searched and indexed through. It
+// points at one versioned index, which a reindex builds and swaps in.
+const ProductIndexAlias = "products"
+
+// NewProductIndexName returns the name of a versioned products index created at now.
+func NewProductIndexName(now time.Time) string {
+	return ProductIndexAlias + "_" + now.UTC().Format("20060102150405")
+}
+
+// ProductDocument returns the document a product is indexed as: the fields it
+// is shown with, along with those only searches need, at its version.
+func ProductDocument(product *productaggregate.Product) Document {
+	body := product.ToMap()
+	body["color_keys"] = product.ColorKeys()
+	body["suggest"] = product.SuggestInput()
+	return Document{
+		ID:      ProductDocumentID(uint64(product.ID)),
+		Version: product.Version,
+		Body:    body,
+	}
+}
+
+// ProductDocumentID returns the ID of the document of a product.
+func ProductDocumentID(productID uint64) string {
+	return strconv.FormatUint(productID, 10)
+}
+
+// PersianAnalyzer analyses the searchable text of products the way
+// textnorm.Normalize does: it strips zero-width characters and diacritics,
+// folds Arabic letters and digits, and lower-cases.
+const PersianAnalyzer = "persian_search"
+
+// ProductIndexSettings returns the settings and mappings products indices are
+// created with. Text fields keep a keyword subfield, which filters, sorts and
+// aggregations use.
+func ProductIndexSettings() map[string]interface{} {
+	searchable := map[string]interface{}{
+		"type":     "text",
+		"analyzer": PersianAnalyzer,
+		"fields": map[string]interface{}{
+			"keyword": map[string]interface{}{"type": "keyword", "ignore_above": 256},
+		},
+	}
+	plain := map[string]interface{}{
+		"type": "text",
+		"fields": map[string]interface{}{
+			"keyword": map[string]interface{}{"type": "keyword", "ignore_above": 256},
+		},
+	}
+
+	return map[string]interface{}{
+		"settings": map[string]interface{}{
+			"analysis": map[string]interface{}{
+				"char_filter": map[string]interface{}{
+					"zero_width": map[string]interface{}{
+						"type":        "pattern_replace",
+						"pattern":     "[\\u200C\\u200D\\uFEFF]",
+						"replacement": "",
+					},
+				},
+				"analyzer": map[string]interface{}{
+					PersianAnalyzer: map[string]interface{}{
+						"type":        "custom",
+						"char_filter": []string{"zero_width"},
+						"tokenizer":   "standard",
+						"filter":      []string{"lowercase", "decimal_digit", "arabic_normalization", "persian_normalization"},
+					},
+				},
+			},
+		},
+		"mappings": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"id":           plain,
+				"name":         searchable,
+				"slug":         map[string]interface{}{"type": "keyword"},
+				"brand":        searchable,
+				"description":  map[string]interface{}{"type": "text", "analyzer": PersianAnalyzer},
+				"category_id":  map[string]interface{}{"type": "long"},
+				"tags":         searchable,
+				"sizes":        plain,
+				"color_keys":   plain,
+				"image":        map[string]interface{}{"type": "keyword", "index": false},
+				"rating":       map[string]interface{}{"type": "float"},
+				"review_count": map[string]interface{}{"type": "integer"},
+				"price":        map[string]interface{}{"type": "double"},
+				"min_price":    map[string]interface{}{"type": "double"},
+				"max_price":    map[string]interface{}{"type": "double"},
+				"discount":     map[string]interface{}{"type": "integer"},
+				"in_stock":     map[string]interface{}{"type": "boolean"},
+				"is_new":       map[string]interface{}{"type": "boolean"},
+				"is_featured":  map[string]interface{}{"type": "boolean"},
+				"created_at":   map[string]interface{}{"type": "date"},
+				"suggest": map[string]interface{}{
+					"type":            "completion",
+					"analyzer":        PersianAnalyzer,
+					"search_analyzer": PersianAnalyzer,
+				},
+			},
+		},
+	}
+}
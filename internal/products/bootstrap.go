@@ -2,8 +2,11 @@ package products
 
 import (
 	"context"
+	"net/http"
+	"time"
 
 	"shikposh-backend/config"
+	"shikposh-backend/internal/products/adapter/search"
 	"shikposh-backend/internal/products/entrypoint"
 	"shikposh-backend/internal/products/entrypoint/handler"
 	"shikposh-backend/internal/products/query"
@@ -19,12 +22,16 @@ import (
 	commandmiddleware "github.com/ali-mahdavi-dev/framework/service_layer/command_event_handler/command_middleware"
 	"github.com/ali-mahdavi-dev/framework/service_layer/messagebus"
 	"shikposh-backend/internal/unit_of_work"
+	"shikposh-backend/pkg/middleware"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
 )
 
-func Bootstrap(router fiber.Router, db *gorm.DB, cfg *config.Config, elasticsearch elasticsearchx.Connection) error {
+// SearchIndexAdminTimeout bounds every call that manages the search indices.
+const SearchIndexAdminTimeout = 30 * time.Second
+
+func Bootstrap(router fiber.Router, db *gorm.DB, cfg *config.Config, elasticsearch elasticsearchx.Connection, mw *middleware.Middleware) error {
 	// Create event channel and unit of work for this module
 	eventCh := make(chan adapter.EventWithWaitGroup, 100)
 	uow := unitofwork.New(db, eventCh)
@@ -50,6 +57,7 @@ func Bootstrap(router fiber.Router, db *gorm.DB, cfg *config.Config, elasticsear
 		reviewHandler,
 		productHandler,
 		bus,
+		mw,
 	)
 
 	entrypoint.NewProductsRouter(router, entrypoint.ProductManagementRouter{
@@ -59,6 +67,7 @@ func Bootstrap(router fiber.Router, db *gorm.DB, cfg *config.Config, elasticsear
 	// register command middlewares
 	bus.AddCommandMiddleware(
 		commandmiddleware.Logging(),
+		mw.CommandAuthorization(),
 	)
 
 	// command handlers
@@ -73,6 +82,9 @@ func Bootstrap(router fiber.Router, db *gorm.DB, cfg *config.Config, elasticsear
 	// event handlers
 	bus.AddEventHandler(
 		commandeventhandler.NewEventHandler(productEventHandler.ProductCreatedEvent),
+		commandeventhandler.NewEventHandler(productEventHandler.ProductUpdatedEvent),
+		commandeventhandler.NewEventHandler(productEventHandler.ProductReviewedEvent),
+		commandeventhandler.NewEventHandler(productEventHandler.ProductDeletedEvent),
 	)
 
 	// Initialize outbox processor (reads from outbox and sends to Kafka)
@@ -83,7 +95,15 @@ func Bootstrap(router fiber.Router, db *gorm.DB, cfg *config.Config, elasticsear
 
 	// Initialize Kafka consumer (consumes from Kafka and indexes in Elasticsearch)
 	if elasticsearch != nil {
-		outboxConsumer := outbox.NewConsumer(uow, elasticsearch, kafkaService)
+		// Create the products index with its mappings before anything is indexed into it
+		indexAdmin := search.NewIndexAdmin(cfg.Elasticsearch, &http.Client{Timeout: SearchIndexAdminTimeout})
+		if err := command_handler.NewSearchIndexHandler(uow, indexAdmin).EnsureProductIndex(ctx, time.Now()); err != nil {
+			logging.Warn("Failed to ensure the products search index").
+				WithError(err).
+				Log()
+		}
+
+		outboxConsumer := outbox.NewConsumer(uow, indexAdmin, kafkaService)
 		if outboxConsumer != nil {
 			go func() {
 				if err := outboxConsumer.Start(ctx); err != nil {
@@ -0,0 +1,10 @@
+package commands
+
+// ProductsWritePermission is required to create, update or delete products.
+const ProductsWritePermission = "products:write"
+
+func (CreateProduct) RequiredPermission() string { return ProductsWritePermission }
+
+func (UpdateProduct) RequiredPermission() string { return ProductsWritePermission }
+
+func (DeleteProduct) RequiredPermission() string { return ProductsWritePermission }
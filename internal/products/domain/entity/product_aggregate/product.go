@@ -2,10 +2,12 @@ package product_aggregate
 
 import (
 	"strconv"
+	"strings"
 	"time"
 
 	"shikposh-backend/internal/products/domain/commands"
 	"shikposh-backend/internal/products/domain/events"
+	"shikposh-backend/pkg/textnorm"
 	"github.com/ali-mahdavi-dev/framework/adapter"
 
 	"gorm.io/gorm"
@@ -37,11 +39,23 @@ type Product struct {
 	Details     []ProductDetail  `json:"-" gorm:"foreignKey:ProductID"` // Aggregate Entity - Not in JSON, will be converted to colors and variants maps
 	Specs       []ProductSpec    `json:"-" gorm:"foreignKey:ProductID"` // Aggregate Entity - Not in JSON, will be converted to map
 	CategoryID  uint64           `json:"category_id" gorm:"category_id"`
-	Tags        []string         `json:"tags,omitempty" gorm:"type:jsonb"`
+	Tags        []string         `json:"tags,omitempty" gorm:"type:jsonb;serializer:json"`
 	Image       string           `json:"image" gorm:"image"` // Main image (for backward compatibility)
 	IsNew       bool             `json:"is_new" gorm:"is_new;default:false"`
 	IsFeatured  bool             `json:"is_featured" gorm:"is_featured;default:false"`
-	Sizes       []string         `json:"sizes" gorm:"type:jsonb"`
+	Sizes       []string         `json:"sizes" gorm:"type:jsonb;serializer:json"`
+	// Pricing is denormalised from Details by RecalculatePricing, so that
+	// lists can be filtered and sorted by price without joining the details.
+	MinPrice float64 `json:"min_price" gorm:"min_price;default:0"` // Lowest list price of the details
+	MaxPrice float64 `json:"max_price" gorm:"max_price;default:0"` // Highest list price of the details
+	Price    float64 `json:"price" gorm:"price;default:0"`         // Lowest effective (discounted) price of the details
+	InStock  bool    `json:"in_stock" gorm:"in_stock;default:false"`
+	// SearchText is the name, brand and description normalised for searching,
+	// kept up to date by BeforeSave.
+	SearchText string `json:"-" gorm:"search_text;type:text;not null;default:''"`
+	// Version counts the changes of the product. The events of a change carry
+	// it, so that their consumers can tell an outdated delivery from a newer one.
+	Version int64 `json:"-" gorm:"version;not null;default:1"`
 }
 
 func (p *Product) TableName() string {
@@ -63,11 +77,13 @@ func NewProduct(cmd *commands.CreateProduct) *Product {
 		IsFeatured:  cmd.IsFeatured,
 		Rating:      0,
 		ReviewCount: 0,
+		Version:     1,
 	}
-	productID := uint64(product.ID)
 	categoryID := uint64(product.CategoryID)
 	product.AddEvent(&events.ProductCreatedEvent{
-		ProductID:   &productID,
+		// Points at the ID of the product, which is only known once it is saved
+		ProductID:   (*uint64)(&product.ID),
+		Version:     product.Version,
 		Name:        product.Name,
 		Slug:        product.Slug,
 		Brand:       product.Brand,
@@ -78,6 +94,119 @@ func NewProduct(cmd *commands.CreateProduct) *Product {
 	return product
 }
 
+// MarkUpdated records a change of the product for its consumers. It must be
+// called whenever the product is modified other than by a review.
+func (p *Product) MarkUpdated() {
+	p.Version++
+	p.AddEvent(&events.ProductUpdatedEvent{
+		ProductID: uint64(p.ID),
+		Version:   p.Version,
+	})
+}
+
+// AddReview counts a new review of the product.
+func (p *Product) AddReview() {
+	p.ReviewCount++
+	p.Version++
+	p.AddEvent(&events.ProductReviewedEvent{
+		ProductID:   uint64(p.ID),
+		Version:     p.Version,
+		Rating:      p.Rating,
+		ReviewCount: p.ReviewCount,
+	})
+}
+
+// MarkDeleted records the deletion of the product for its consumers.
+func (p *Product) MarkDeleted(softDelete bool) {
+	p.Version++
+	p.AddEvent(&events.ProductDeletedEvent{
+		ProductID:  uint64(p.ID),
+		Version:    p.Version,
+		SoftDelete: softDelete,
+	})
+}
+
+// RecalculatePricing updates the denormalised pricing fields from the details.
+// It must be called whenever the details of the product change. Details without
+// a price, such as color definitions, only count towards InStock.
+func (p *Product) RecalculatePricing() {
+	p.MinPrice, p.MaxPrice, p.Price, p.InStock = 0, 0, 0, false
+	for i := range p.Details {
+		detail := &p.Details[i]
+		if detail.Stock > 0 {
+			p.InStock = true
+		}
+		if detail.Price <= 0 {
+			continue
+		}
+		if p.MinPrice == 0 || detail.Price < p.MinPrice {
+			p.MinPrice = detail.Price
+		}
+		if detail.Price > p.MaxPrice {
+			p.MaxPrice = detail.Price
+		}
+		if effective := detail.EffectivePrice(); p.Price == 0 || effective < p.Price {
+			p.Price = effective
+		}
+	}
+}
+
+// ColorKeys returns the distinct color keys of the details, in order.
+func (p *Product) ColorKeys() []string {
+	keys := []string{}
+	seen := map[string]bool{}
+	for i := range p.Details {
+		if key := p.Details[i].ColorKey; key != nil && !seen[*key] {
+			seen[*key] = true
+			keys = append(keys, *key)
+		}
+	}
+	return keys
+}
+
+// SuggestInput returns the entry of the product in the completion field of the
+// search index: its name from every word on, so that typing any word of it
+// completes it, weighted by the rating to suggest the best rated first.
+func (p *Product) SuggestInput() map[string]interface{} {
+	words := strings.Fields(p.Name)
+	inputs := make([]string, 0, len(words))
+	for i := range words {
+		inputs = append(inputs, strings.Join(words[i:], " "))
+	}
+	return map[string]interface{}{
+		"input":  inputs,
+		"weight": int(p.Rating*10) + 1,
+	}
+}
+
+// cheapestDetail returns the detail the product is sold the cheapest with,
+// or nil if no detail has a price.
+func (p *Product) cheapestDetail() *ProductDetail {
+	var cheapest *ProductDetail
+	for i := range p.Details {
+		detail := &p.Details[i]
+		if detail.Price > 0 && (cheapest == nil || detail.EffectivePrice() < cheapest.EffectivePrice()) {
+			cheapest = detail
+		}
+	}
+	return cheapest
+}
+
+// SearchableText returns the normalised text the database searches products by.
+func (p *Product) SearchableText() string {
+	text := p.Name + " " + p.Brand
+	if p.Description != nil {
+		text += " " + *p.Description
+	}
+	return textnorm.Normalize(text)
+}
+
+// BeforeSave hook to refresh the search text whenever the product is saved
+func (p *Product) BeforeSave(tx *gorm.DB) error {
+	p.SearchText = p.SearchableText()
+	return nil
+}
+
 // BeforeCreate hook to ensure JSON fields are properly initialized
 // This will be called by GORM automatically
 func (p *Product) BeforeCreate(tx *gorm.DB) error {
@@ -101,22 +230,12 @@ func (p *Product) BeforeCreate(tx *gorm.DB) error {
 
 // ToMap converts Colors and Variants to map format for JSON response
 func (p *Product) ToMap() map[string]interface{} {
-	// Get default price from first detail if exists, otherwise use 0
-	defaultPrice := 0.0
+	// Discount and original price are those of the detail the price comes from
 	defaultDiscount := 0
 	var defaultOriginalPrice *float64
-
-	if len(p.Details) > 0 {
-		for i := range p.Details {
-			if p.Details[i].Price > 0 {
-				defaultPrice = p.Details[i].Price
-				defaultDiscount = p.Details[i].Discount
-				if p.Details[i].OriginalPrice != nil {
-					defaultOriginalPrice = p.Details[i].OriginalPrice
-				}
-				break
-			}
-		}
+	if cheapest := p.cheapestDetail(); cheapest != nil {
+		defaultDiscount = cheapest.Discount
+		defaultOriginalPrice = cheapest.OriginalPrice
 	}
 
 	result := map[string]interface{}{
@@ -130,7 +249,10 @@ func (p *Product) ToMap() map[string]interface{} {
 		"category_id":  p.CategoryID,
 		"tags":         p.Tags,
 		"image":        p.Image,
-		"price":        defaultPrice, // Default price from first detail
+		"price":        p.Price,
+		"min_price":    p.MinPrice,
+		"max_price":    p.MaxPrice,
+		"in_stock":     p.InStock,
 		"discount":     defaultDiscount,
 		"is_new":       p.IsNew,
 		"is_featured":  p.IsFeatured,
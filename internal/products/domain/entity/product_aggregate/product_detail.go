@@ -49,3 +49,13 @@ func NewProductDetail(productID ProductID, input commands.ProductDetailInput) Pr
 		Images:        []shared.Attachment{},
 	}
 }
+
+// EffectivePrice is the price the detail is sold at. A detail with an original
+// price already carries its discounted price; otherwise its discount, a
+// percentage, is taken off the price.
+func (pd *ProductDetail) EffectivePrice() float64 {
+	if pd.OriginalPrice != nil || pd.Discount <= 0 {
+		return pd.Price
+	}
+	return pd.Price * float64(100-pd.Discount) / 100
+}
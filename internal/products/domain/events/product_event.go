@@ -3,9 +3,33 @@ package events
 // ProductCreatedEvent is raised when a new product is created
 type ProductCreatedEvent struct {
 	ProductID   *uint64 `json:"product_id"`
+	Version     int64  `json:"version"`
 	Name        string `json:"name"`
 	Slug        string `json:"slug"`
 	Brand       string `json:"brand"`
 	CategoryID  uint64 `json:"category_id"`
 	Description string `json:"description,omitempty"`
 }
+
+// ProductUpdatedEvent is raised when the fields, details, features or specs
+// of a product change
+type ProductUpdatedEvent struct {
+	ProductID uint64 `json:"product_id"`
+	Version   int64  `json:"version"`
+}
+
+// ProductReviewedEvent is raised when a review changes the rating or review
+// count of a product
+type ProductReviewedEvent struct {
+	ProductID   uint64  `json:"product_id"`
+	Version     int64   `json:"version"`
+	Rating      float64 `json:"rating"`
+	ReviewCount int     `json:"review_count"`
+}
+
+// ProductDeletedEvent is raised when a product is deleted
+type ProductDeletedEvent struct {
+	ProductID  uint64 `json:"product_id"`
+	Version    int64  `json:"version"`
+	SoftDelete bool   `json:"soft_delete"`
+}
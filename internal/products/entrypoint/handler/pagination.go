@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"shikposh-backend/internal/products/adapter/repository"
+	"shikposh-backend/pkg/phrases"
+	httpapi "github.com/ali-mahdavi-dev/framework/api/http"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// PageResponse is the envelope of every list endpoint. NextCursor is passed
+// back as the cursor parameter to get the following page, and is null on the
+// last one. Total is only counted when include_total=true is asked for.
+type PageResponse struct {
+	Items      interface{} `json:"items"`
+	NextCursor *string     `json:"next_cursor"`
+	HasMore    bool        `json:"has_more"`
+	Total      *int64      `json:"total,omitempty"`
+}
+
+// SearchResponse is a page of products along with the facets of the search.
+type SearchResponse struct {
+	PageResponse
+	Facets *repository.ProductFacets `json:"facets"`
+}
+
+// parsePageRequest reads the cursor, limit and include_total query parameters.
+func parsePageRequest(c fiber.Ctx) (repository.PageRequest, error) {
+	page := repository.PageRequest{
+		WithTotal: c.Query("include_total") == "true",
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		decoded, err := repository.DecodeCursor(cursor)
+		if err != nil {
+			return page, apperrors.Validation(phrases.InvalidPagination, "Invalid cursor")
+		}
+		page.Cursor = decoded
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 {
+			return page, apperrors.Validation(phrases.InvalidPagination, "limit must be a positive number")
+		}
+		page.Limit = value
+	}
+
+	return page, nil
+}
+
+// newPageResponse converts the items of page with convert.
+func newPageResponse[T any, R any](page *repository.Page[T], convert func([]T) R) PageResponse {
+	response := PageResponse{
+		Items:   convert(page.Items),
+		HasMore: page.HasMore,
+		Total:   page.Total,
+	}
+	if page.NextCursor != nil {
+		nextCursor := page.NextCursor.Encode()
+		response.NextCursor = &nextCursor
+	}
+	return response
+}
+
+// resPageError reports a cursor that doesn't belong to the listing as a
+// validation error, and any other error as is.
+func resPageError(c fiber.Ctx, err error) error {
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		err = apperrors.Validation(phrases.InvalidPagination, "Invalid cursor")
+	}
+	return httpapi.ResError(c, err)
+}
@@ -2,21 +2,54 @@ package handler
 
 import (
 	"errors"
+	"fmt"
+	"math"
 	"strconv"
 	"strings"
 
 	"shikposh-backend/internal/products/adapter/repository"
 	"shikposh-backend/internal/products/domain/commands"
+	"shikposh-backend/internal/products/domain/entity"
 	productaggregate "shikposh-backend/internal/products/domain/entity/product_aggregate"
 	"shikposh-backend/internal/products/query"
 	"shikposh-backend/internal/products/service_layer/command_handler"
+	"shikposh-backend/pkg/middleware"
+	"shikposh-backend/pkg/phrases"
 	httpapi "github.com/ali-mahdavi-dev/framework/api/http"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
 	"github.com/ali-mahdavi-dev/framework/service_layer/messagebus"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/spf13/cast"
 )
 
+// parseList parses a comma-separated query parameter, dropping empty entries.
+func parseList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	var list []string
+	for _, entry := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(entry); trimmed != "" {
+			list = append(list, trimmed)
+		}
+	}
+	return list
+}
+
+// parseFacetOptions parses the options of the facets of a product search.
+func parseFacetOptions(c fiber.Ctx) (repository.FacetOptions, error) {
+	options := repository.FacetOptions{}
+	if interval := c.Query("price_interval"); interval != "" {
+		value, err := strconv.ParseFloat(interval, 64)
+		if err != nil || math.IsInf(value, 0) || !(value >= repository.MinPriceInterval) {
+			return options, apperrors.Validation(phrases.InvalidFacetQuery, fmt.Sprintf("price_interval must be a number of at least %d", repository.MinPriceInterval))
+		}
+		options.PriceInterval = value
+	}
+	return options, nil
+}
+
 // convertProductsToMap converts a slice of products to map format for JSON response
 func convertProductsToMap(products []*productaggregate.Product) []map[string]interface{} {
 	result := make([]map[string]interface{}, len(products))
@@ -33,6 +66,7 @@ type ProductHandler struct {
 	reviewHandler        *command_handler.ReviewCommandHandler
 	productHandler       *command_handler.ProductCommandHandler
 	bus                  messagebus.MessageBus
+	mw                   *middleware.Middleware
 }
 
 func NewProductHandler(
@@ -42,6 +76,7 @@ func NewProductHandler(
 	reviewHandler *command_handler.ReviewCommandHandler,
 	productHandler *command_handler.ProductCommandHandler,
 	bus messagebus.MessageBus,
+	mw *middleware.Middleware,
 ) *ProductHandler {
 	return &ProductHandler{
 		productQueryHandler:  productQueryHandler,
@@ -50,52 +85,70 @@ func NewProductHandler(
 		reviewHandler:        reviewHandler,
 		productHandler:       productHandler,
 		bus:                  bus,
+		mw:                   mw,
 	}
 }
 
 func (p *ProductHandler) RegisterRoutes(r fiber.Router) {
-	publicRoute := r.Group("/api/v1/public")
+	publicRoute := r.Group("/api/v1/public", p.mw.OptionalAuthMiddleware())
 	{
 		// Products
 		publicRoute.Get("/products", p.GetAllProducts)
-		publicRoute.Get("/products/:slug", p.GetProductBySlug)
 		publicRoute.Get("/products/featured", p.GetFeaturedProducts)
+		publicRoute.Get("/products/:slug", p.GetProductBySlug)
 		publicRoute.Get("/products/category/:category", p.GetProductsByCategory)
 
+		// Search
+		publicRoute.Get("/search/suggest", p.SuggestProducts)
+
 		// Categories
 		publicRoute.Get("/categories", p.GetAllCategories)
 
 		// Reviews
 		publicRoute.Get("/products/:id/reviews", p.GetReviewsByProductID)
-		publicRoute.Post("/reviews", p.CreateReview)
+		publicRoute.Post("/reviews", p.mw.AuthMiddleware(), p.CreateReview)
 		publicRoute.Patch("/reviews/:id", p.UpdateReviewHelpful)
 	}
 
 	// Admin routes for product CRUD
-	adminRoute := r.Group("/api/v1/admin")
+	adminRoute := r.Group("/api/v1/admin/products",
+		p.mw.AuthMiddleware(),
+		p.mw.RequireTwoFactorEnrollment(),
+		p.mw.RequirePermission(commands.ProductsWritePermission),
+	)
 	{
-		adminRoute.Post("/products", p.CreateProduct)
-		adminRoute.Put("/products/:id", p.UpdateProduct)
-		adminRoute.Delete("/products/:id", p.DeleteProduct)
+		adminRoute.Post("", p.CreateProduct)
+		adminRoute.Put("/:id", p.UpdateProduct)
+		adminRoute.Delete("/:id", p.DeleteProduct)
 	}
 }
 
 // GetAllProducts godoc
 //
 //	@Summary		Get all products
-//	@Description	Retrieves all products with optional filtering
+//	@Description	Retrieves a page of products with optional filtering, and with facets=true the facets of the search
 //	@Tags			products
 //	@Accept			json
 //	@Produce		json
-//	@Param			q			query		string	false	"Search query"
-//	@Param			category	query		string	false	"Category slug"
-//	@Param			min			query		number	false	"Minimum price"
-//	@Param			max			query		number	false	"Maximum price"
-//	@Param			rating		query		number	false	"Minimum rating"
-//	@Param			featured	query		boolean	false	"Featured products only"
-//	@Param			tags		query		string	false	"Comma-separated tags"
-//	@Param			sort		query		string	false	"Sort order (price_asc, price_desc, rating, newest)"
-//	@Success		200			{object}	httpapi.ResponseResult
+//	@Param			q				query		string	false	"Search query"
+//	@Param			category		query		string	false	"Category slug, its subcategories included"
+//	@Param			min				query		number	false	"Minimum price"
+//	@Param			max				query		number	false	"Maximum price"
+//	@Param			rating			query		number	false	"Minimum rating"
+//	@Param			featured		query		boolean	false	"Featured products only"
+//	@Param			tags			query		string	false	"Comma-separated tags, any of which matches"
+//	@Param			brand			query		string	false	"Comma-separated brands, any of which matches"
+//	@Param			size			query		string	false	"Comma-separated sizes, any of which matches"
+//	@Param			color			query		string	false	"Comma-separated color keys, any of which matches"
+//	@Param			in_stock		query		boolean	false	"Products in stock only"
+//	@Param			sort			query		string	false	"Sort order (price_asc, price_desc, rating, newest)"
+//	@Param			cursor			query		string	false	"next_cursor of the previous page"
+//	@Param			limit			query		int		false	"Page size, 20 by default and at most 100"
+//	@Param			include_total	query		boolean	false	"Count all matching products"
+//	@Param			facets			query		boolean	false	"Count the products per brand, category, tag, size, color, price, rating and stock"
+//	@Param			price_interval	query		number	false	"Width of the price facet buckets, at least 1000"
+//	@Success		200				{object}	SearchResponse
+//	@Failure		400				{object}	httpapi.ResponseResult	"Invalid cursor, limit or price interval"
 //	@Router			/api/v1/public/products [get]
 func (p *ProductHandler) GetAllProducts(c fiber.Ctx) error {
 	ctx := c.Context()
@@ -127,41 +180,55 @@ func (p *ProductHandler) GetAllProducts(c fiber.Ctx) error {
 		featuredVal := true
 		filters.Featured = &featuredVal
 	}
-	if tags := c.Query("tags"); tags != "" {
-		// Parse comma-separated tags
-		tagList := strings.Split(tags, ",")
-		cleanedTags := []string{}
-		for _, tag := range tagList {
-			if trimmed := strings.TrimSpace(tag); trimmed != "" {
-				cleanedTags = append(cleanedTags, trimmed)
-			}
-		}
-		if len(cleanedTags) > 0 {
-			filters.Tags = cleanedTags
-		}
+	filters.Tags = parseList(c.Query("tags"))
+	filters.Brands = parseList(c.Query("brand"))
+	filters.Sizes = parseList(c.Query("size"))
+	filters.Colors = parseList(c.Query("color"))
+	if inStock := c.Query("in_stock"); inStock == "true" {
+		inStockVal := true
+		filters.InStock = &inStockVal
 	}
 	if sort := c.Query("sort"); sort != "" {
 		filters.Sort = &sort
 	}
 
+	page, err := parsePageRequest(c)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	if c.Query("facets") == "true" {
+		options, err := parseFacetOptions(c)
+		if err != nil {
+			return httpapi.ResError(c, err)
+		}
+		result, err := p.productQueryHandler.SearchProductsWithFacets(ctx, filters, page, options)
+		if err != nil {
+			return resPageError(c, err)
+		}
+		return httpapi.ResSuccess(c, SearchResponse{
+			PageResponse: newPageResponse(result.Products, convertProductsToMap),
+			Facets:       result.Facets,
+		})
+	}
+
 	// Use filter if any filters are set, otherwise get all
-	var productsList []*productaggregate.Product
-	var err error
+	var productsPage *repository.Page[*productaggregate.Product]
 	if filters.Query != nil || filters.Category != nil || filters.MinPrice != nil ||
 		filters.MaxPrice != nil || filters.Rating != nil || filters.Featured != nil ||
-		len(filters.Tags) > 0 || filters.Sort != nil {
-		productsList, err = p.productQueryHandler.GetFilteredProducts(ctx, filters)
+		len(filters.Tags) > 0 || len(filters.Brands) > 0 || len(filters.Sizes) > 0 ||
+		len(filters.Colors) > 0 || filters.InStock != nil || filters.Sort != nil {
+		productsPage, err = p.productQueryHandler.GetFilteredProducts(ctx, filters, page)
 	} else {
-		productsList, err = p.productQueryHandler.GetAllProducts(ctx)
+		productsPage, err = p.productQueryHandler.GetAllProducts(ctx, page)
 	}
 
 	if err != nil {
-		return httpapi.ResError(c, err)
+		return resPageError(c, err)
 	}
 
 	// Convert to map format
-	productsMap := convertProductsToMap(productsList)
-	return httpapi.ResSuccess(c, productsMap)
+	return httpapi.ResSuccess(c, newPageResponse(productsPage, convertProductsToMap))
 }
 
 // GetProductBySlug godoc
@@ -214,53 +281,104 @@ func (p *ProductHandler) GetProductByID(c fiber.Ctx) error {
 	return httpapi.ResSuccess(c, productMap)
 }
 
+// SuggestProducts godoc
+//
+//	@Summary		Suggest completions of a search
+//	@Description	Completes a partly typed search with product names, categories and brands, and offers spelling corrections of it
+//	@Tags			products
+//	@Accept			json
+//	@Produce		json
+//	@Param			q		query		string	true	"Partly typed search"
+//	@Param			limit	query		int		false	"Suggestions of each kind, 5 by default and at most 10"
+//	@Success		200		{object}	repository.Suggestions
+//	@Failure		400		{object}	httpapi.ResponseResult	"Missing query or invalid limit"
+//	@Router			/api/v1/public/search/suggest [get]
+func (p *ProductHandler) SuggestProducts(c fiber.Ctx) error {
+	ctx := c.Context()
+
+	prefix := strings.TrimSpace(c.Query("q"))
+	if prefix == "" {
+		return httpapi.ResError(c, apperrors.Validation(phrases.InvalidSuggestQuery, "q is required"))
+	}
+
+	limit := repository.DefaultSuggestLimit
+	if raw := c.Query("limit"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 1 {
+			return httpapi.ResError(c, apperrors.Validation(phrases.InvalidSuggestQuery, "limit must be a positive number"))
+		}
+		limit = min(value, repository.MaxSuggestLimit)
+	}
+
+	suggestions, err := p.productQueryHandler.SuggestProducts(ctx, prefix, limit)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	return httpapi.ResSuccess(c, suggestions)
+}
+
 // GetFeaturedProducts godoc
 //
 //	@Summary		Get featured products
-//	@Description	Retrieves all featured products
+//	@Description	Retrieves a page of featured products, the newest first
 //	@Tags			products
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	httpapi.ResponseResult
+//	@Param			cursor			query		string	false	"next_cursor of the previous page"
+//	@Param			limit			query		int		false	"Page size, 20 by default and at most 100"
+//	@Param			include_total	query		boolean	false	"Count all featured products"
+//	@Success		200				{object}	PageResponse
+//	@Failure		400				{object}	httpapi.ResponseResult	"Invalid cursor or limit"
 //	@Router			/api/v1/public/products/featured [get]
 func (p *ProductHandler) GetFeaturedProducts(c fiber.Ctx) error {
 	ctx := c.Context()
-
-	products, err := p.productQueryHandler.GetFeaturedProducts(ctx)
+	page, err := parsePageRequest(c)
 	if err != nil {
 		return httpapi.ResError(c, err)
 	}
 
+	products, err := p.productQueryHandler.GetFeaturedProducts(ctx, page)
+	if err != nil {
+		return resPageError(c, err)
+	}
+
 	// Convert to map format
-	productsMap := convertProductsToMap(products)
-	return httpapi.ResSuccess(c, productsMap)
+	return httpapi.ResSuccess(c, newPageResponse(products, convertProductsToMap))
 }
 
 // GetProductsByCategory godoc
 //
 //	@Summary		Get products by category
-//	@Description	Retrieves all products in a specific category
+//	@Description	Retrieves a page of the products in a specific category, the newest first
 //	@Tags			products
 //	@Accept			json
 //	@Produce		json
-//	@Param			category	path		string	true	"Category slug"
-//	@Success		200			{object}	httpapi.ResponseResult
+//	@Param			category		path		string	true	"Category slug"
+//	@Param			cursor			query		string	false	"next_cursor of the previous page"
+//	@Param			limit			query		int		false	"Page size, 20 by default and at most 100"
+//	@Param			include_total	query		boolean	false	"Count all products of the category"
+//	@Success		200				{object}	PageResponse
+//	@Failure		400				{object}	httpapi.ResponseResult	"Invalid cursor or limit"
 //	@Router			/api/v1/public/products/category/{category} [get]
 func (p *ProductHandler) GetProductsByCategory(c fiber.Ctx) error {
 	ctx := c.Context()
 	categorySlug := c.Params("category")
+	page, err := parsePageRequest(c)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
 
-	products, err := p.productQueryHandler.GetProductsByCategory(ctx, categorySlug)
+	products, err := p.productQueryHandler.GetProductsByCategory(ctx, categorySlug, page)
 	if err != nil {
 		if errors.Is(err, repository.ErrCategoryNotFound) {
 			return httpapi.ResError(c, fiber.NewError(fiber.StatusNotFound, "Category not found"))
 		}
-		return httpapi.ResError(c, err)
+		return resPageError(c, err)
 	}
 
 	// Convert to map format
-	productsMap := convertProductsToMap(products)
-	return httpapi.ResSuccess(c, productsMap)
+	return httpapi.ResSuccess(c, newPageResponse(products, convertProductsToMap))
 }
 
 // GetAllCategories godoc
@@ -286,12 +404,16 @@ func (p *ProductHandler) GetAllCategories(c fiber.Ctx) error {
 // GetReviewsByProductID godoc
 //
 //	@Summary		Get reviews by product ID
-//	@Description	Retrieves all reviews for a specific product
+//	@Description	Retrieves a page of the reviews of a specific product, the newest first
 //	@Tags			reviews
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		uint64	true	"Product ID"
-//	@Success		200	{object}	httpapi.ResponseResult
+//	@Param			id				path		uint64	true	"Product ID"
+//	@Param			cursor			query		string	false	"next_cursor of the previous page"
+//	@Param			limit			query		int		false	"Page size, 20 by default and at most 100"
+//	@Param			include_total	query		boolean	false	"Count all reviews of the product"
+//	@Success		200				{object}	PageResponse
+//	@Failure		400				{object}	httpapi.ResponseResult	"Invalid cursor or limit"
 //	@Router			/api/v1/public/products/{id}/reviews [get]
 func (p *ProductHandler) GetReviewsByProductID(c fiber.Ctx) error {
 	ctx := c.Context()
@@ -300,21 +422,20 @@ func (p *ProductHandler) GetReviewsByProductID(c fiber.Ctx) error {
 		return httpapi.ResError(c, err)
 	}
 
-	reviews, err := p.reviewQueryHandler.GetReviewsByProductID(ctx, productaggregate.ProductID(productID))
+	page, err := parsePageRequest(c)
+	if err != nil {
+		return httpapi.ResError(c, err)
+	}
+
+	reviews, err := p.reviewQueryHandler.GetReviewsByProductID(ctx, productaggregate.ProductID(productID), page)
 	if err != nil {
 		if errors.Is(err, repository.ErrProductNotFound) {
 			return httpapi.ResError(c, fiber.NewError(fiber.StatusNotFound, "Product not found"))
 		}
-		return httpapi.ResError(c, err)
+		return resPageError(c, err)
 	}
 
-	// Return paginated response
-	pr := &httpapi.PaginationResult{
-		Total: int64(len(reviews)),
-		Skip:  0,
-		Limit: int64(len(reviews)),
-	}
-	return httpapi.ResPage(c, reviews, pr)
+	return httpapi.ResSuccess(c, newPageResponse(reviews, func(items []*entity.Review) []*entity.Review { return items }))
 }
 
 // CreateReview godoc
@@ -326,6 +447,8 @@ func (p *ProductHandler) GetReviewsByProductID(c fiber.Ctx) error {
 //	@Produce		json
 //	@Param			request	body		commands.CreateReview	true	"CreateReview request"
 //	@Success		200		{object}	httpapi.ResponseResult
+//	@Failure		401		{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		403		{object}	httpapi.ResponseResult	"Service account"
 //	@Router			/api/v1/public/reviews [post]
 func (p *ProductHandler) CreateReview(c fiber.Ctx) error {
 	ctx := c.Context()
@@ -335,11 +458,15 @@ func (p *ProductHandler) CreateReview(c fiber.Ctx) error {
 		return httpapi.ResError(c, err)
 	}
 
-	// Get user ID from context (set by auth middleware)
-	userID := c.Get("user_id")
-	if userID != "" {
-		cmd.UserID = cast.ToUint64(userID)
+	// Reviews are always attributed to the signed-in user, never to a client-supplied ID
+	identity, ok := middleware.IdentityFromContext(ctx)
+	if !ok {
+		return httpapi.ResError(c, apperrors.Unauthorized(phrases.AuthenticationRequired, "Authentication required"))
+	}
+	if identity.IsServiceAccount() {
+		return httpapi.ResError(c, apperrors.Forbidden(phrases.PermissionDenied, "Service accounts cannot write reviews"))
 	}
+	cmd.UserID = identity.UserID
 
 	err := p.bus.Handle(ctx, cmd)
 	if err != nil {
@@ -391,6 +518,8 @@ func (p *ProductHandler) UpdateReviewHelpful(c fiber.Ctx) error {
 //	@Produce		json
 //	@Param			request	body		commands.CreateProduct	true	"CreateProduct request"
 //	@Success		201		{object}	httpapi.ResponseResult
+//	@Failure		401		{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		403		{object}	httpapi.ResponseResult	"Missing products:write permission"
 //	@Router			/api/v1/admin/products [post]
 func (p *ProductHandler) CreateProduct(c fiber.Ctx) error {
 	ctx := c.Context()
@@ -418,6 +547,8 @@ func (p *ProductHandler) CreateProduct(c fiber.Ctx) error {
 //	@Param			id		path		uint64				true	"Product ID"
 //	@Param			request	body		commands.UpdateProduct	true	"UpdateProduct request"
 //	@Success		200		{object}	httpapi.ResponseResult
+//	@Failure		401		{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		403		{object}	httpapi.ResponseResult	"Missing products:write permission"
 //	@Router			/api/v1/admin/products/{id} [put]
 func (p *ProductHandler) UpdateProduct(c fiber.Ctx) error {
 	ctx := c.Context()
@@ -451,6 +582,8 @@ func (p *ProductHandler) UpdateProduct(c fiber.Ctx) error {
 //	@Param			id			path		uint64	true	"Product ID"
 //	@Param			soft_delete	query		boolean	false	"Soft delete (default: true)"
 //	@Success		200			{object}	httpapi.ResponseResult
+//	@Failure		401			{object}	httpapi.ResponseResult	"User not authenticated"
+//	@Failure		403			{object}	httpapi.ResponseResult	"Missing products:write permission"
 //	@Router			/api/v1/admin/products/{id} [delete]
 func (p *ProductHandler) DeleteProduct(c fiber.Ctx) error {
 	ctx := c.Context()
@@ -4,14 +4,22 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"shikposh-backend/internal/products/adapter/repository"
+	"shikposh-backend/internal/products/adapter/search"
+	"shikposh-backend/internal/products/domain/entity"
 	productaggregate "shikposh-backend/internal/products/domain/entity/product_aggregate"
 	elasticsearchx "github.com/ali-mahdavi-dev/framework/infrastructure/elasticsearch"
 	"github.com/ali-mahdavi-dev/framework/infrastructure/logging"
 	"shikposh-backend/internal/unit_of_work"
 )
 
+// searchCursorPrefix marks the cursors of pages served by Elasticsearch. They
+// hold Elasticsearch sort values, so a listing that started there continues
+// there, and one that started on the database stays on the database.
+const searchCursorPrefix = "search:"
+
 type ProductQueryHandler struct {
 	uow           unitofwork.PGUnitOfWork
 	elasticsearch elasticsearchx.Connection
@@ -22,15 +30,15 @@ func NewProductQueryHandler(uow unitofwork.PGUnitOfWork, elasticsearch elasticse
 	return &ProductQueryHandler{
 		uow:           uow,
 		elasticsearch: elasticsearch,
-		indexName:     "products",
+		indexName:     search.ProductIndexAlias,
 	}
 }
 
-func (h *ProductQueryHandler) GetAllProducts(ctx context.Context) ([]*productaggregate.Product, error) {
-	var products []*productaggregate.Product
+func (h *ProductQueryHandler) GetAllProducts(ctx context.Context, page repository.PageRequest) (*repository.Page[*productaggregate.Product], error) {
+	var products *repository.Page[*productaggregate.Product]
 	err := h.uow.Do(ctx, func(ctx context.Context) error {
 		var err error
-		products, err = h.uow.Product(ctx).GetAll(ctx)
+		products, err = h.uow.Product(ctx).GetAll(ctx, page)
 		if err != nil {
 			return err
 		}
@@ -97,11 +105,11 @@ func (h *ProductQueryHandler) GetProductBySlug(ctx context.Context, slug string)
 	return product, err
 }
 
-func (h *ProductQueryHandler) GetFeaturedProducts(ctx context.Context) ([]*productaggregate.Product, error) {
-	var products []*productaggregate.Product
+func (h *ProductQueryHandler) GetFeaturedProducts(ctx context.Context, page repository.PageRequest) (*repository.Page[*productaggregate.Product], error) {
+	var products *repository.Page[*productaggregate.Product]
 	err := h.uow.Do(ctx, func(ctx context.Context) error {
 		var err error
-		products, err = h.uow.Product(ctx).FindFeatured(ctx)
+		products, err = h.uow.Product(ctx).FindFeatured(ctx, page)
 		if err != nil {
 			return err
 		}
@@ -110,11 +118,11 @@ func (h *ProductQueryHandler) GetFeaturedProducts(ctx context.Context) ([]*produ
 	return products, err
 }
 
-func (h *ProductQueryHandler) GetProductsByCategory(ctx context.Context, categorySlug string) ([]*productaggregate.Product, error) {
-	var products []*productaggregate.Product
+func (h *ProductQueryHandler) GetProductsByCategory(ctx context.Context, categorySlug string, page repository.PageRequest) (*repository.Page[*productaggregate.Product], error) {
+	var products *repository.Page[*productaggregate.Product]
 	err := h.uow.Do(ctx, func(ctx context.Context) error {
 		var err error
-		products, err = h.uow.Product(ctx).FindByCategorySlug(ctx, categorySlug)
+		products, err = h.uow.Product(ctx).FindByCategorySlug(ctx, categorySlug, page)
 		if err != nil {
 			return err
 		}
@@ -153,15 +161,19 @@ func (h *ProductQueryHandler) SearchProducts(ctx context.Context, searchQuery st
 	return products, err
 }
 
-func (h *ProductQueryHandler) GetFilteredProducts(ctx context.Context, filters repository.ProductFilters) ([]*productaggregate.Product, error) {
-	// Try Elasticsearch first if available
-	if h.elasticsearch != nil {
-		products, err := h.searchInElasticsearchWithFilters(ctx, filters)
+func (h *ProductQueryHandler) GetFilteredProducts(ctx context.Context, filters repository.ProductFilters, page repository.PageRequest) (*repository.Page[*productaggregate.Product], error) {
+	// Try Elasticsearch first if available, unless the listing started on the database
+	if h.elasticsearch != nil && (page.Cursor == nil || strings.HasPrefix(page.Cursor.Sort, searchCursorPrefix)) {
+		result, err := h.searchInElasticsearchWithFilters(ctx, filters, page, nil)
 		if err == nil {
 			logging.Debug("Products filtered from Elasticsearch").
-				WithInt("count", len(products)).
+				WithInt("count", len(result.Products.Items)).
 				Log()
-			return products, nil
+			return result.Products, nil
+		}
+		if page.Cursor != nil {
+			// The database can't continue a listing paged by Elasticsearch
+			return nil, err
 		}
 		logging.Warn("Elasticsearch search failed, falling back to database").
 			WithError(err).
@@ -169,10 +181,10 @@ func (h *ProductQueryHandler) GetFilteredProducts(ctx context.Context, filters r
 	}
 
 	// Fallback to database
-	var products []*productaggregate.Product
+	var products *repository.Page[*productaggregate.Product]
 	err := h.uow.Do(ctx, func(ctx context.Context) error {
 		var err error
-		products, err = h.uow.Product(ctx).Filter(ctx, filters)
+		products, err = h.uow.Product(ctx).Filter(ctx, filters, page)
 		if err != nil {
 			return err
 		}
@@ -199,12 +211,25 @@ func (h *ProductQueryHandler) searchInElasticsearch(ctx context.Context, query s
 }
 
 // searchInElasticsearchWithFilters performs a search with all filters applied in Elasticsearch
-func (h *ProductQueryHandler) searchInElasticsearchWithFilters(ctx context.Context, filters repository.ProductFilters) ([]*productaggregate.Product, error) {
-	// Build bool query with must, should, and filter clauses
+// and returns the requested page of its hits. With facetOptions the selected facet filters
+// go to the post_filter instead, and the facets are aggregated as well.
+func (h *ProductQueryHandler) searchInElasticsearchWithFilters(ctx context.Context, filters repository.ProductFilters, page repository.PageRequest, facetOptions *repository.FacetOptions) (*ProductSearchResult, error) {
+	// Categories are needed to match the descendants of the selected category
+	// and to arrange the category facet
+	var categories []*entity.Category
+	if (filters.Category != nil && *filters.Category != "") || facetOptions != nil {
+		var err error
+		categories, err = h.getAllCategories(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	filterClauses, facetClauses := elasticsearchFilters(filters, categories)
+
+	// Build bool query with must and filter clauses
 	boolQuery := map[string]interface{}{
 		"must":   []interface{}{},
-		"should": []interface{}{},
-		"filter": []interface{}{},
+		"filter": filterClauses,
 	}
 
 	// Add search query if provided
@@ -219,86 +244,60 @@ func (h *ProductQueryHandler) searchInElasticsearchWithFilters(ctx context.Conte
 		})
 	}
 
-	// Add category filter
-	if filters.Category != nil && *filters.Category != "" {
-		boolQuery["filter"] = append(boolQuery["filter"].([]interface{}), map[string]interface{}{
-			"term": map[string]interface{}{
-				"category": *filters.Category,
-			},
-		})
+	// Build the final query
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": boolQuery,
+		},
+		"size":             page.PageLimit() + 1,
+		"track_total_hits": page.WithTotal,
 	}
 
-	// Add price range filter
-	if filters.MinPrice != nil || filters.MaxPrice != nil {
-		priceRange := map[string]interface{}{}
-		if filters.MinPrice != nil {
-			priceRange["gte"] = *filters.MinPrice
-		}
-		if filters.MaxPrice != nil {
-			priceRange["lte"] = *filters.MaxPrice
-		}
-		boolQuery["filter"] = append(boolQuery["filter"].([]interface{}), map[string]interface{}{
-			"range": map[string]interface{}{
-				"price": priceRange,
-			},
-		})
+	if facetOptions == nil {
+		boolQuery["filter"] = append(filterClauses, facetFilterClauses(facetClauses, "")...)
+	} else {
+		query["post_filter"] = facetFilter(facetClauses, "")
+		query["aggs"] = elasticsearchAggregations(facetClauses, *facetOptions)
 	}
 
-	// Add rating filter
-	if filters.Rating != nil {
-		boolQuery["filter"] = append(boolQuery["filter"].([]interface{}), map[string]interface{}{
-			"range": map[string]interface{}{
-				"rating": map[string]interface{}{
-					"gte": *filters.Rating,
-				},
-			},
-		})
+	// Sort by the requested order, or by relevance, with the product ID as
+	// tie-breaker so that search_after never skips or repeats a hit
+	sortName := ""
+	if filters.Sort != nil {
+		sortName = *filters.Sort
 	}
-
-	// Add featured filter
-	if filters.Featured != nil && *filters.Featured {
-		boolQuery["filter"] = append(boolQuery["filter"].([]interface{}), map[string]interface{}{
-			"term": map[string]interface{}{
-				"is_featured": true,
-			},
-		})
+	sort := h.buildSortClause(sortName)
+	if len(sort) == 0 {
+		sortName = "relevance"
+		sort = append(sort, map[string]interface{}{"_score": map[string]interface{}{"order": "desc"}})
 	}
+	query["sort"] = append(sort, map[string]interface{}{"id.keyword": map[string]interface{}{"order": "asc"}})
 
-	// Add tags filter
-	if len(filters.Tags) > 0 {
-		tagQueries := make([]interface{}, 0, len(filters.Tags))
-		for _, tag := range filters.Tags {
-			tagQueries = append(tagQueries, map[string]interface{}{
-				"term": map[string]interface{}{
-					"tags": tag,
-				},
-			})
+	cursorSort := searchCursorPrefix + sortName
+	if page.Cursor != nil {
+		if page.Cursor.Sort != cursorSort {
+			return nil, repository.ErrInvalidCursor
 		}
-		boolQuery["filter"] = append(boolQuery["filter"].([]interface{}), map[string]interface{}{
-			"bool": map[string]interface{}{
-				"should":               tagQueries,
-				"minimum_should_match": 1,
-			},
-		})
+		query["search_after"] = page.Cursor.After
 	}
 
-	// Build the final query
-	query := map[string]interface{}{
-		"query": map[string]interface{}{
-			"bool": boolQuery,
-		},
-		"size": 100,
+	response, err := h.elasticsearch.Search(ctx, h.indexName, query)
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch search failed: %w", err)
 	}
 
-	// Add sorting if provided
-	if filters.Sort != nil {
-		sort := h.buildSortClause(*filters.Sort)
-		if len(sort) > 0 {
-			query["sort"] = sort
+	result := &ProductSearchResult{}
+	result.Products, err = h.elasticsearchPage(ctx, response, cursorSort, page)
+	if err != nil {
+		return nil, err
+	}
+	if facetOptions != nil {
+		result.Facets, err = elasticsearchFacets(response, categories, facetOptions.Interval())
+		if err != nil {
+			return nil, err
 		}
 	}
-
-	return h.executeElasticsearchQuery(ctx, query)
+	return result, nil
 }
 
 // buildSortClause builds Elasticsearch sort clause
@@ -370,6 +369,73 @@ func (h *ProductQueryHandler) executeElasticsearchQuery(ctx context.Context, que
 	return products, nil
 }
 
+// elasticsearchPage converts the hits of a search for page.PageLimit()+1 hits to a
+// page of products. The cursor of the next page holds the sort values of the last
+// hit of this one.
+func (h *ProductQueryHandler) elasticsearchPage(ctx context.Context, result map[string]interface{}, cursorSort string, page repository.PageRequest) (*repository.Page[*productaggregate.Product], error) {
+	hits, ok := result["hits"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid elasticsearch response format")
+	}
+
+	hitsArray, ok := hits["hits"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid hits format")
+	}
+
+	productsPage := &repository.Page[*productaggregate.Product]{}
+	if page.WithTotal {
+		if total, ok := hits["total"].(map[string]interface{}); ok {
+			if value, ok := total["value"].(float64); ok {
+				count := int64(value)
+				productsPage.Total = &count
+			}
+		}
+	}
+
+	limit := page.PageLimit()
+	if len(hitsArray) > limit {
+		hitsArray = hitsArray[:limit]
+		productsPage.HasMore = true
+	}
+
+	productsPage.Items = make([]*productaggregate.Product, 0, len(hitsArray))
+	for _, hit := range hitsArray {
+		hitMap, ok := hit.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		// The cursor moves past every hit, even those that fail to convert
+		if sortValues, ok := hitMap["sort"].([]interface{}); ok {
+			productsPage.NextCursor = &repository.Cursor{Sort: cursorSort, After: sortValues}
+		}
+
+		source, ok := hitMap["_source"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		product, err := h.mapToProduct(ctx, source)
+		if err != nil {
+			logging.Warn("Failed to convert Elasticsearch hit to product").
+				WithError(err).
+				Log()
+			continue
+		}
+
+		productsPage.Items = append(productsPage.Items, product)
+	}
+
+	if !productsPage.HasMore {
+		productsPage.NextCursor = nil
+	} else if productsPage.NextCursor == nil {
+		return nil, fmt.Errorf("elasticsearch hits have no sort values")
+	}
+
+	return productsPage, nil
+}
+
 // mapToProduct converts a map (from Elasticsearch) to Product entity
 func (h *ProductQueryHandler) mapToProduct(ctx context.Context, data map[string]interface{}) (*productaggregate.Product, error) {
 	// Get product ID
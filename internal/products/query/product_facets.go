@@ -0,0 +1,317 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"shikposh-backend/internal/products/adapter/repository"
+	"shikposh-backend/internal/products/domain/entity"
+	productaggregate "shikposh-backend/internal/products/domain/entity/product_aggregate"
+	"github.com/ali-mahdavi-dev/framework/infrastructure/logging"
+)
+
+// Names of the facets, which are also the names of their aggregations
+const (
+	facetBrands     = "brands"
+	facetCategories = "categories"
+	facetTags       = "tags"
+	facetSizes      = "sizes"
+	facetColors     = "colors"
+	facetPrices     = "prices"
+	facetRatings    = "ratings"
+	facetInStock    = "in_stock"
+)
+
+var facetNames = []string{facetBrands, facetCategories, facetTags, facetSizes, facetColors, facetPrices, facetRatings, facetInStock}
+
+// facetTermsSize is the number of values of a facet that are aggregated.
+const facetTermsSize = 100
+
+// ProductSearchResult is a page of the products matching a search, along with
+// the facets of all of them. Facets is nil unless they were asked for.
+type ProductSearchResult struct {
+	Products *repository.Page[*productaggregate.Product]
+	Facets   *repository.ProductFacets
+}
+
+// SearchProductsWithFacets returns a page of the products matching filters and
+// the facets of the search, with post-filter semantics: every facet is counted
+// with all selected filters but its own.
+func (h *ProductQueryHandler) SearchProductsWithFacets(ctx context.Context, filters repository.ProductFilters, page repository.PageRequest, options repository.FacetOptions) (*ProductSearchResult, error) {
+	// Try Elasticsearch first if available, unless the listing started on the database
+	if h.elasticsearch != nil && (page.Cursor == nil || strings.HasPrefix(page.Cursor.Sort, searchCursorPrefix)) {
+		result, err := h.searchInElasticsearchWithFilters(ctx, filters, page, &options)
+		if err == nil {
+			logging.Debug("Faceted products search served from Elasticsearch").
+				WithInt("count", len(result.Products.Items)).
+				Log()
+			return result, nil
+		}
+		if page.Cursor != nil {
+			// The database can't continue a listing paged by Elasticsearch
+			return nil, err
+		}
+		logging.Warn("Elasticsearch faceted search failed, falling back to database").
+			WithError(err).
+			Log()
+	}
+
+	// Fallback to database
+	result := &ProductSearchResult{}
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		result.Products, err = h.uow.Product(ctx).Filter(ctx, filters, page)
+		if err != nil {
+			return err
+		}
+		result.Facets, err = h.uow.Product(ctx).Facets(ctx, filters, options)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (h *ProductQueryHandler) getAllCategories(ctx context.Context) ([]*entity.Category, error) {
+	var categories []*entity.Category
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		categories, err = h.uow.Category(ctx).GetAll(ctx)
+		return err
+	})
+	return categories, err
+}
+
+// elasticsearchFilters returns the filter clauses of filters that have no facet,
+// and those that have one by the name of their facet.
+func elasticsearchFilters(filters repository.ProductFilters, categories []*entity.Category) ([]interface{}, map[string]interface{}) {
+	clauses := []interface{}{}
+	facetClauses := map[string]interface{}{}
+
+	// Add featured filter
+	if filters.Featured != nil && *filters.Featured {
+		clauses = append(clauses, map[string]interface{}{
+			"term": map[string]interface{}{"is_featured": true},
+		})
+	}
+
+	// Add category filter, which matches the descendants of the category too
+	if filters.Category != nil && *filters.Category != "" {
+		facetClauses[facetCategories] = map[string]interface{}{
+			"terms": map[string]interface{}{"category_id": categorySubtreeIDs(categories, *filters.Category)},
+		}
+	}
+
+	// Add price range filter
+	if filters.MinPrice != nil || filters.MaxPrice != nil {
+		priceRange := map[string]interface{}{}
+		if filters.MinPrice != nil {
+			priceRange["gte"] = *filters.MinPrice
+		}
+		if filters.MaxPrice != nil {
+			priceRange["lte"] = *filters.MaxPrice
+		}
+		facetClauses[facetPrices] = map[string]interface{}{
+			"range": map[string]interface{}{"price": priceRange},
+		}
+	}
+
+	// Add rating filter
+	if filters.Rating != nil {
+		facetClauses[facetRatings] = map[string]interface{}{
+			"range": map[string]interface{}{
+				"rating": map[string]interface{}{"gte": *filters.Rating},
+			},
+		}
+	}
+
+	// Add the filters matching any of a list of values
+	for name, values := range map[string][]string{
+		facetTags:   filters.Tags,
+		facetBrands: filters.Brands,
+		facetSizes:  filters.Sizes,
+		facetColors: filters.Colors,
+	} {
+		if len(values) > 0 {
+			facetClauses[name] = map[string]interface{}{
+				"terms": map[string]interface{}{facetFields[name]: values},
+			}
+		}
+	}
+
+	// Add stock filter
+	if filters.InStock != nil {
+		facetClauses[facetInStock] = map[string]interface{}{
+			"term": map[string]interface{}{"in_stock": *filters.InStock},
+		}
+	}
+
+	return clauses, facetClauses
+}
+
+// facetFields are the fields of the terms facets.
+var facetFields = map[string]string{
+	facetBrands:     "brand.keyword",
+	facetCategories: "category_id",
+	facetTags:       "tags.keyword",
+	facetSizes:      "sizes.keyword",
+	facetColors:     "color_keys.keyword",
+}
+
+// facetFilterClauses returns the facet filter clauses, in a stable order,
+// leaving out that of the facet named except.
+func facetFilterClauses(facetClauses map[string]interface{}, except string) []interface{} {
+	clauses := []interface{}{}
+	for _, name := range facetNames {
+		if clause, ok := facetClauses[name]; ok && name != except {
+			clauses = append(clauses, clause)
+		}
+	}
+	return clauses
+}
+
+func facetFilter(facetClauses map[string]interface{}, except string) map[string]interface{} {
+	return map[string]interface{}{
+		"bool": map[string]interface{}{"filter": facetFilterClauses(facetClauses, except)},
+	}
+}
+
+// elasticsearchAggregations returns an aggregation per facet. Each is nested in a
+// filter aggregation with the facet filters but its own, since the post_filter
+// doesn't apply to aggregations.
+func elasticsearchAggregations(facetClauses map[string]interface{}, options repository.FacetOptions) map[string]interface{} {
+	ratingRanges := make([]interface{}, len(repository.RatingFacetThresholds))
+	for i, threshold := range repository.RatingFacetThresholds {
+		ratingRanges[i] = map[string]interface{}{"from": threshold}
+	}
+
+	facets := map[string]interface{}{
+		facetPrices: map[string]interface{}{
+			"histogram": map[string]interface{}{"field": "price", "interval": options.Interval(), "min_doc_count": 1},
+			"aggs": map[string]interface{}{
+				"cheapest": map[string]interface{}{"bucket_sort": map[string]interface{}{"size": repository.MaxPriceBuckets}},
+			},
+		},
+		facetRatings: map[string]interface{}{
+			"range": map[string]interface{}{"field": "rating", "ranges": ratingRanges},
+		},
+		facetInStock: map[string]interface{}{
+			"filter": map[string]interface{}{"term": map[string]interface{}{"in_stock": true}},
+		},
+	}
+	for name, field := range facetFields {
+		size := facetTermsSize
+		if name == facetCategories {
+			// Every category is needed to roll the counts up the tree
+			size = 1000
+		}
+		facets[name] = map[string]interface{}{
+			"terms": map[string]interface{}{"field": field, "size": size},
+		}
+	}
+
+	aggs := make(map[string]interface{}, len(facets))
+	for name, facet := range facets {
+		aggs[name] = map[string]interface{}{
+			"filter": facetFilter(facetClauses, name),
+			"aggs":   map[string]interface{}{"values": facet},
+		}
+	}
+	return aggs
+}
+
+// elasticsearchFacets reads the facets from the aggregations of a search.
+func elasticsearchFacets(result map[string]interface{}, categories []*entity.Category, interval float64) (*repository.ProductFacets, error) {
+	aggs, ok := result["aggregations"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("elasticsearch response has no aggregations")
+	}
+	values := func(name string) map[string]interface{} {
+		facet, _ := aggs[name].(map[string]interface{})
+		values, _ := facet["values"].(map[string]interface{})
+		return values
+	}
+	buckets := func(name string) []map[string]interface{} {
+		raw, _ := values(name)["buckets"].([]interface{})
+		buckets := make([]map[string]interface{}, 0, len(raw))
+		for _, bucket := range raw {
+			if b, ok := bucket.(map[string]interface{}); ok {
+				buckets = append(buckets, b)
+			}
+		}
+		return buckets
+	}
+	termBuckets := func(name string) []repository.FacetBucket {
+		result := []repository.FacetBucket{}
+		for _, bucket := range buckets(name) {
+			result = append(result, repository.FacetBucket{
+				Value: fmt.Sprint(bucket["key"]),
+				Count: toInt64(bucket["doc_count"]),
+			})
+		}
+		return result
+	}
+
+	facets := &repository.ProductFacets{
+		Brands:  termBuckets(facetBrands),
+		Tags:    termBuckets(facetTags),
+		Sizes:   termBuckets(facetSizes),
+		Colors:  termBuckets(facetColors),
+		Prices:  []repository.RangeFacetBucket{},
+		Ratings: []repository.RangeFacetBucket{},
+		InStock: toInt64(values(facetInStock)["doc_count"]),
+	}
+
+	categoryCounts := map[uint64]int64{}
+	for _, bucket := range buckets(facetCategories) {
+		if id, ok := bucket["key"].(float64); ok {
+			categoryCounts[uint64(id)] = toInt64(bucket["doc_count"])
+		}
+	}
+	facets.Categories = repository.BuildCategoryFacets(categories, categoryCounts)
+
+	for _, bucket := range buckets(facetPrices) {
+		from, _ := bucket["key"].(float64)
+		to := from + interval
+		facets.Prices = append(facets.Prices, repository.RangeFacetBucket{From: from, To: &to, Count: toInt64(bucket["doc_count"])})
+	}
+
+	for _, bucket := range buckets(facetRatings) {
+		from, _ := bucket["from"].(float64)
+		facets.Ratings = append(facets.Ratings, repository.RangeFacetBucket{From: from, Count: toInt64(bucket["doc_count"])})
+	}
+
+	return facets, nil
+}
+
+func toInt64(value interface{}) int64 {
+	count, _ := value.(float64)
+	return int64(count)
+}
+
+// categorySubtreeIDs returns the IDs of the category with the slug and of all
+// of its descendants. It is empty for an unknown slug, which matches nothing.
+func categorySubtreeIDs(categories []*entity.Category, slug string) []uint64 {
+	children := map[uint64][]uint64{}
+	ids := []uint64{}
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[uint64(*category.ParentID)] = append(children[uint64(*category.ParentID)], uint64(category.ID))
+		}
+		if category.Slug == slug {
+			ids = append(ids, uint64(category.ID))
+		}
+	}
+
+	visited := map[uint64]bool{}
+	for i := 0; i < len(ids); i++ {
+		if visited[ids[i]] {
+			continue
+		}
+		visited[ids[i]] = true
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids
+}
@@ -0,0 +1,169 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"shikposh-backend/internal/products/adapter/repository"
+	"github.com/ali-mahdavi-dev/framework/infrastructure/logging"
+)
+
+// SuggestProducts completes a partly typed search with the names of products,
+// brands and categories, and offers spelling corrections of it. Categories
+// aren't in the search index, so they always come from the database.
+func (h *ProductQueryHandler) SuggestProducts(ctx context.Context, prefix string, limit int) (*repository.Suggestions, error) {
+	var suggestions *repository.Suggestions
+
+	// Try Elasticsearch first if available
+	if h.elasticsearch != nil {
+		result, err := h.suggestInElasticsearch(ctx, prefix, limit)
+		if err == nil {
+			logging.Debug("Suggestions served from Elasticsearch").
+				WithString("prefix", prefix).
+				WithInt("count", len(result.Products)).
+				Log()
+			suggestions = result
+		} else {
+			logging.Warn("Elasticsearch suggest failed, falling back to database").
+				WithError(err).
+				Log()
+		}
+	}
+
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		if suggestions == nil {
+			// Fallback to database, which only completes prefixes
+			suggestions = &repository.Suggestions{DidYouMean: []string{}}
+			suggestions.Products, err = h.uow.Product(ctx).SuggestNames(ctx, prefix, limit)
+			if err != nil {
+				return err
+			}
+			suggestions.Brands, err = h.uow.Product(ctx).SuggestBrands(ctx, prefix, limit)
+			if err != nil {
+				return err
+			}
+		}
+		suggestions.Categories, err = h.uow.Category(ctx).SuggestNames(ctx, prefix, limit)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// suggestInElasticsearch completes product names with the completion field,
+// corrects the spelling with a phrase suggester on the name, and aggregates
+// the brands matching the prefix.
+func (h *ProductQueryHandler) suggestInElasticsearch(ctx context.Context, prefix string, limit int) (*repository.Suggestions, error) {
+	request := map[string]interface{}{
+		"size":    0,
+		"_source": []string{"name", "slug"},
+		"suggest": map[string]interface{}{
+			"names": map[string]interface{}{
+				"prefix": prefix,
+				"completion": map[string]interface{}{
+					"field":           "suggest",
+					"size":            limit,
+					"skip_duplicates": true,
+					"fuzzy":           map[string]interface{}{"fuzziness": "AUTO", "min_length": 4},
+				},
+			},
+			"did_you_mean": map[string]interface{}{
+				"text": prefix,
+				"phrase": map[string]interface{}{
+					"field":      "name",
+					"size":       3,
+					"max_errors": 2,
+					"direct_generator": []interface{}{
+						map[string]interface{}{"field": "name", "suggest_mode": "always"},
+					},
+				},
+			},
+		},
+		"aggs": map[string]interface{}{
+			"brands": map[string]interface{}{
+				"filter": map[string]interface{}{
+					"match_phrase_prefix": map[string]interface{}{"brand": prefix},
+				},
+				"aggs": map[string]interface{}{
+					"values": map[string]interface{}{
+						"terms": map[string]interface{}{"field": "brand.keyword", "size": limit},
+					},
+				},
+			},
+		},
+	}
+
+	result, err := h.elasticsearch.Search(ctx, h.indexName, request)
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch suggest failed: %w", err)
+	}
+	return elasticsearchSuggestions(result, prefix)
+}
+
+// elasticsearchSuggestions reads the product and brand suggestions and the
+// spelling corrections of prefix from a suggest response.
+func elasticsearchSuggestions(result map[string]interface{}, prefix string) (*repository.Suggestions, error) {
+	suggest, ok := result["suggest"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("elasticsearch response has no suggestions")
+	}
+	suggestions := &repository.Suggestions{
+		Products:   []repository.ProductSuggestion{},
+		Brands:     []string{},
+		DidYouMean: []string{},
+	}
+
+	// A product can complete through more than one word of its name
+	seen := map[uint64]bool{}
+	for _, option := range suggestOptions(suggest, "names") {
+		id, err := strconv.ParseUint(fmt.Sprint(option["_id"]), 10, 64)
+		if err != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		source, _ := option["_source"].(map[string]interface{})
+		name, _ := source["name"].(string)
+		slug, _ := source["slug"].(string)
+		suggestions.Products = append(suggestions.Products, repository.ProductSuggestion{ID: id, Name: name, Slug: slug})
+	}
+
+	for _, option := range suggestOptions(suggest, "did_you_mean") {
+		if text, ok := option["text"].(string); ok && text != prefix {
+			suggestions.DidYouMean = append(suggestions.DidYouMean, text)
+		}
+	}
+
+	aggs, _ := result["aggregations"].(map[string]interface{})
+	brands, _ := aggs["brands"].(map[string]interface{})
+	values, _ := brands["values"].(map[string]interface{})
+	buckets, _ := values["buckets"].([]interface{})
+	for _, bucket := range buckets {
+		if b, ok := bucket.(map[string]interface{}); ok {
+			suggestions.Brands = append(suggestions.Brands, fmt.Sprint(b["key"]))
+		}
+	}
+
+	return suggestions, nil
+}
+
+// suggestOptions returns the options of the first entry of a suggester, the
+// only one since the text isn't split into several entries.
+func suggestOptions(suggest map[string]interface{}, name string) []map[string]interface{} {
+	entries, _ := suggest[name].([]interface{})
+	if len(entries) == 0 {
+		return nil
+	}
+	entry, _ := entries[0].(map[string]interface{})
+	raw, _ := entry["options"].([]interface{})
+	options := make([]map[string]interface{}, 0, len(raw))
+	for _, option := range raw {
+		if o, ok := option.(map[string]interface{}); ok {
+			options = append(options, o)
+		}
+	}
+	return options
+}
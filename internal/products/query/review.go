@@ -3,6 +3,7 @@ package query
 import (
 	"context"
 
+	"shikposh-backend/internal/products/adapter/repository"
 	"shikposh-backend/internal/products/domain/entity"
 	productaggregate "shikposh-backend/internal/products/domain/entity/product_aggregate"
 	"shikposh-backend/internal/unit_of_work"
@@ -16,11 +17,11 @@ func NewReviewQueryHandler(uow unitofwork.PGUnitOfWork) *ReviewQueryHandler {
 	return &ReviewQueryHandler{uow: uow}
 }
 
-func (h *ReviewQueryHandler) GetReviewsByProductID(ctx context.Context, productID productaggregate.ProductID) ([]*entity.Review, error) {
-	var reviews []*entity.Review
+func (h *ReviewQueryHandler) GetReviewsByProductID(ctx context.Context, productID productaggregate.ProductID, page repository.PageRequest) (*repository.Page[*entity.Review], error) {
+	var reviews *repository.Page[*entity.Review]
 	err := h.uow.Do(ctx, func(ctx context.Context) error {
 		var err error
-		reviews, err = h.uow.Review(ctx).FindByProductID(ctx, productID)
+		reviews, err = h.uow.Review(ctx).FindByProductID(ctx, productID, page)
 		if err != nil {
 			return err
 		}
@@ -71,6 +71,8 @@ func (h *ProductCommandHandler) CreateProductHandler(ctx context.Context, cmd *c
 			}
 		}
 
+		product.RecalculatePricing()
+
 		// Validate product using specification pattern
 		canBePublishedSpec := specification.NewProductCanBePublishedSpecification()
 		if !canBePublishedSpec.IsSatisfiedBy(product) {
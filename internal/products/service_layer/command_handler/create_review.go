@@ -27,6 +27,15 @@ func (h *ReviewCommandHandler) CreateReviewHandler(ctx context.Context, cmd *com
 		// Create review
 		review := entity.NewReview(cmd)
 
+		author, err := h.uow.User(ctx).FindByID(ctx, cmd.UserID)
+		switch {
+		case err == nil:
+			avatar := author.AvatarLink()
+			review.UserAvatar = &avatar
+		case !errors.Is(err, appadapter.ErrEntityNotFound):
+			return fmt.Errorf("ReviewCommandHandler.CreateReviewHandler error finding author: %w", err)
+		}
+
 		// Validate review using specification pattern
 		canBePublishedSpec := specification.NewReviewCanBePublishedSpecification()
 		if !canBePublishedSpec.IsSatisfiedBy(review) {
@@ -38,9 +47,9 @@ func (h *ReviewCommandHandler) CreateReviewHandler(ctx context.Context, cmd *com
 		}
 
 		// Update product rating and review count
-		product.ReviewCount++
 		// Recalculate average rating (simplified - in production, you might want to store this)
 		// For now, we'll just increment the count
+		product.AddReview()
 		if err := h.uow.Product(ctx).Save(ctx, product); err != nil {
 			return fmt.Errorf("ReviewCommandHandler.CreateReviewHandler error updating product: %w", err)
 		}
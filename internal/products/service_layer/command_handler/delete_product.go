@@ -30,6 +30,7 @@ func (h *ProductCommandHandler) DeleteProductHandler(ctx context.Context, cmd *c
 		}
 
 		// Delete product (soft or hard delete)
+		product.MarkDeleted(cmd.SoftDelete)
 		if err := h.uow.Product(ctx).Remove(ctx, product, cmd.SoftDelete); err != nil {
 			return fmt.Errorf("ProductCommandHandler.DeleteProductHandler error deleting product: %w", err)
 		}
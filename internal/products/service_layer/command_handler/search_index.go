@@ -0,0 +1,267 @@
+package command_handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"shikposh-backend/internal/products/adapter/search"
+	"shikposh-backend/internal/products/domain/entity"
+	productaggregate "shikposh-backend/internal/products/domain/entity/product_aggregate"
+	"shikposh-backend/internal/unit_of_work"
+	"github.com/ali-mahdavi-dev/framework/infrastructure/logging"
+
+	"github.com/spf13/cast"
+)
+
+// DefaultReindexBatchSize is the number of products indexed per bulk request.
+const DefaultReindexBatchSize = 500
+
+// reindexCatchUpMargin is how long before a reindex started the changes it
+// applies again begin, to cover transactions that were open when it started.
+const reindexCatchUpMargin = time.Minute
+
+// SearchIndexHandler manages the Elasticsearch indices products are searched in.
+// Documents are written one by one by the outbox consumer.
+type SearchIndexHandler struct {
+	uow   unitofwork.PGUnitOfWork
+	admin search.IndexAdmin
+}
+
+func NewSearchIndexHandler(uow unitofwork.PGUnitOfWork, admin search.IndexAdmin) *SearchIndexHandler {
+	return &SearchIndexHandler{uow: uow, admin: admin}
+}
+
+// ReindexResult describes the index a reindex built.
+type ReindexResult struct {
+	Index    string
+	Indexed  int
+	Replaced []string
+	// CaughtUp counts the changes made while the index was built that were
+	// applied to it afterwards.
+	CaughtUp int
+}
+
+// EnsureProductIndex creates a versioned index behind the products alias
+// unless the alias or an index of its name exists, so that documents are
+// never indexed with dynamic mappings.
+func (h *SearchIndexHandler) EnsureProductIndex(ctx context.Context, now time.Time) error {
+	indices, err := h.admin.AliasIndices(ctx, search.ProductIndexAlias)
+	if err != nil {
+		return fmt.Errorf("SearchIndexHandler.EnsureProductIndex fail get alias: %w", err)
+	}
+	if len(indices) > 0 {
+		return nil
+	}
+	legacy, err := h.admin.IndexExists(ctx, search.ProductIndexAlias)
+	if err != nil {
+		return fmt.Errorf("SearchIndexHandler.EnsureProductIndex fail check index: %w", err)
+	}
+	if legacy {
+		logging.Warn("Products index predates versioned indices, run search reindex to replace it").Log()
+		return nil
+	}
+
+	index := search.NewProductIndexName(now)
+	if err := h.admin.CreateIndex(ctx, index, search.ProductIndexSettings()); err != nil {
+		return fmt.Errorf("SearchIndexHandler.EnsureProductIndex fail create index: %w", err)
+	}
+	err = h.admin.UpdateAliases(ctx, []map[string]interface{}{
+		{"add": map[string]interface{}{"index": index, "alias": search.ProductIndexAlias}},
+	})
+	if err != nil {
+		return fmt.Errorf("SearchIndexHandler.EnsureProductIndex fail add alias: %w", err)
+	}
+	return nil
+}
+
+// Reindex builds a new versioned index from every product in batches of
+// batchSize, then atomically points the products alias at it. The indices it
+// replaces are deleted unless keepPrevious. now is when the reindex starts.
+//
+// Until the alias is swapped the outbox consumer writes to the index being
+// replaced, so the products changed and deleted since now are applied to the
+// new index once it is swapped in. Documents are written with their versions,
+// so a change applied by both the consumer and the catch-up is kept once.
+func (h *SearchIndexHandler) Reindex(ctx context.Context, now time.Time, batchSize int, keepPrevious bool) (*ReindexResult, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultReindexBatchSize
+	}
+
+	result := &ReindexResult{Index: search.NewProductIndexName(now)}
+	if err := h.admin.CreateIndex(ctx, result.Index, search.ProductIndexSettings()); err != nil {
+		return nil, fmt.Errorf("SearchIndexHandler.Reindex fail create index: %w", err)
+	}
+
+	if err := h.fill(ctx, result, batchSize); err != nil {
+		// Leave no half-built index behind
+		if deleteErr := h.admin.DeleteIndex(ctx, result.Index); deleteErr != nil {
+			logging.Warn("Failed to delete partial products index").
+				WithString("index", result.Index).
+				WithError(deleteErr).
+				Log()
+		}
+		return nil, err
+	}
+
+	if err := h.swapAlias(ctx, result); err != nil {
+		return nil, err
+	}
+
+	if err := h.catchUp(ctx, result, now.Add(-reindexCatchUpMargin), batchSize); err != nil {
+		return nil, err
+	}
+
+	if !keepPrevious {
+		for _, index := range result.Replaced {
+			if err := h.admin.DeleteIndex(ctx, index); err != nil {
+				return nil, fmt.Errorf("SearchIndexHandler.Reindex fail delete replaced index: %w", err)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// fill indexes every product into the index of result.
+func (h *SearchIndexHandler) fill(ctx context.Context, result *ReindexResult, batchSize int) error {
+	var afterID uint64
+	for {
+		var products []*productaggregate.Product
+		err := h.uow.Do(ctx, func(ctx context.Context) error {
+			var err error
+			products, err = h.uow.Product(ctx).FindBatch(ctx, afterID, batchSize)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("SearchIndexHandler.Reindex fail read products: %w", err)
+		}
+		if len(products) == 0 {
+			break
+		}
+
+		documents := make([]search.Document, 0, len(products))
+		for _, product := range products {
+			documents = append(documents, search.ProductDocument(product))
+		}
+		afterID = uint64(products[len(products)-1].ID)
+
+		if err := h.admin.Bulk(ctx, result.Index, documents); err != nil {
+			return fmt.Errorf("SearchIndexHandler.Reindex fail index products: %w", err)
+		}
+		result.Indexed += len(documents)
+		logging.Info("Products indexed").
+			WithString("index", result.Index).
+			WithInt("indexed", result.Indexed).
+			Log()
+
+		if len(products) < batchSize {
+			break
+		}
+	}
+
+	if err := h.admin.Refresh(ctx, result.Index); err != nil {
+		return fmt.Errorf("SearchIndexHandler.Reindex fail refresh index: %w", err)
+	}
+	return nil
+}
+
+// swapAlias points the products alias at the index of result in one step, and
+// records the indices it pointed at before. An index named like the alias,
+// created before versioned indices, is replaced in the same step.
+func (h *SearchIndexHandler) swapAlias(ctx context.Context, result *ReindexResult) error {
+	previous, err := h.admin.AliasIndices(ctx, search.ProductIndexAlias)
+	if err != nil {
+		return fmt.Errorf("SearchIndexHandler.Reindex fail get alias: %w", err)
+	}
+
+	actions := []map[string]interface{}{}
+	for _, index := range previous {
+		actions = append(actions, map[string]interface{}{
+			"remove": map[string]interface{}{"index": index, "alias": search.ProductIndexAlias},
+		})
+	}
+	if len(previous) == 0 {
+		legacy, err := h.admin.IndexExists(ctx, search.ProductIndexAlias)
+		if err != nil {
+			return fmt.Errorf("SearchIndexHandler.Reindex fail check index: %w", err)
+		}
+		if legacy {
+			actions = append(actions, map[string]interface{}{
+				"remove_index": map[string]interface{}{"index": search.ProductIndexAlias},
+			})
+		}
+	}
+	actions = append(actions, map[string]interface{}{
+		"add": map[string]interface{}{"index": result.Index, "alias": search.ProductIndexAlias, "is_write_index": true},
+	})
+
+	if err := h.admin.UpdateAliases(ctx, actions); err != nil {
+		return fmt.Errorf("SearchIndexHandler.Reindex fail swap alias: %w", err)
+	}
+	result.Replaced = previous
+	return nil
+}
+
+// catchUp applies to the index of result the products changed and deleted
+// since since, which the outbox consumer wrote to the index it replaced.
+func (h *SearchIndexHandler) catchUp(ctx context.Context, result *ReindexResult, since time.Time, batchSize int) error {
+	var afterID uint64
+	for {
+		var products []*productaggregate.Product
+		err := h.uow.Do(ctx, func(ctx context.Context) error {
+			var err error
+			products, err = h.uow.Product(ctx).FindBatchChangedSince(ctx, since, afterID, batchSize)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("SearchIndexHandler.Reindex fail read changed products: %w", err)
+		}
+
+		for _, product := range products {
+			err := h.admin.IndexDocument(ctx, result.Index, search.ProductDocument(product))
+			if errors.Is(err, search.ErrVersionConflict) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("SearchIndexHandler.Reindex fail index changed product: %w", err)
+			}
+			result.CaughtUp++
+		}
+
+		if len(products) < batchSize {
+			break
+		}
+		afterID = uint64(products[len(products)-1].ID)
+	}
+
+	// Deleted products are no longer read, hard-deleted ones not at all, so
+	// their deletions are replayed from the outbox
+	var deletions []*entity.OutboxEvent
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		deletions, err = h.uow.Outbox(ctx).FindByTypeSince(ctx, "ProductDeletedEvent", since)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("SearchIndexHandler.Reindex fail read deletions: %w", err)
+	}
+
+	for _, deletion := range deletions {
+		version, err := cast.ToInt64E(deletion.Payload["version"])
+		if err != nil {
+			return fmt.Errorf("SearchIndexHandler.Reindex fail read version of deletion %d: %w", deletion.ID, err)
+		}
+		err = h.admin.DeleteDocument(ctx, result.Index, deletion.AggregateID, version)
+		if errors.Is(err, search.ErrVersionConflict) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("SearchIndexHandler.Reindex fail delete product: %w", err)
+		}
+		result.CaughtUp++
+	}
+
+	return nil
+}
@@ -134,6 +134,8 @@ func (h *ProductCommandHandler) UpdateProductHandler(ctx context.Context, cmd *c
 			}
 		}
 
+		product.RecalculatePricing()
+
 		// Validate product using specification pattern
 		canBePublishedSpec := specification.NewProductCanBePublishedSpecification()
 		if !canBePublishedSpec.IsSatisfiedBy(product) {
@@ -141,6 +143,7 @@ func (h *ProductCommandHandler) UpdateProductHandler(ctx context.Context, cmd *c
 		}
 
 		// Save product
+		product.MarkUpdated()
 		if err := h.uow.Product(ctx).Modify(ctx, product); err != nil {
 			return fmt.Errorf("ProductCommandHandler.UpdateProductHandler error saving product: %w", err)
 		}
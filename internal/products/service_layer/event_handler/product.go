@@ -29,6 +29,29 @@ func (h *ProductEventHandler) ProductCreatedEvent(ctx context.Context, event *ev
 		return fmt.Errorf("product_id is nil in ProductCreatedEvent")
 	}
 
+	return h.saveToOutbox(ctx, "ProductCreatedEvent", *event.ProductID, event)
+}
+
+// ProductUpdatedEvent handles the ProductUpdatedEvent
+// Saves the event to outbox table for later processing
+func (h *ProductEventHandler) ProductUpdatedEvent(ctx context.Context, event *events.ProductUpdatedEvent) error {
+	return h.saveToOutbox(ctx, "ProductUpdatedEvent", event.ProductID, event)
+}
+
+// ProductReviewedEvent handles the ProductReviewedEvent
+// Saves the event to outbox table for later processing
+func (h *ProductEventHandler) ProductReviewedEvent(ctx context.Context, event *events.ProductReviewedEvent) error {
+	return h.saveToOutbox(ctx, "ProductReviewedEvent", event.ProductID, event)
+}
+
+// ProductDeletedEvent handles the ProductDeletedEvent
+// Saves the event to outbox table for later processing
+func (h *ProductEventHandler) ProductDeletedEvent(ctx context.Context, event *events.ProductDeletedEvent) error {
+	return h.saveToOutbox(ctx, "ProductDeletedEvent", event.ProductID, event)
+}
+
+// saveToOutbox saves an event of the product to the outbox table
+func (h *ProductEventHandler) saveToOutbox(ctx context.Context, eventType string, productID uint64, event interface{}) error {
 	err := h.uow.Do(ctx, func(ctx context.Context) error {
 		// Convert event to JSON payload
 		eventJSON, err := json.Marshal(event)
@@ -43,9 +66,9 @@ func (h *ProductEventHandler) ProductCreatedEvent(ctx context.Context, event *ev
 
 		// Create outbox event
 		outboxEvent := &entity.OutboxEvent{
-			EventType:     "ProductCreatedEvent",
+			EventType:     eventType,
 			AggregateType: "Product",
-			AggregateID:   strconv.FormatUint(*event.ProductID, 10),
+			AggregateID:   strconv.FormatUint(productID, 10),
 			Payload:       payload,
 			Status:        entity.OutboxStatusPending,
 			RetryCount:    0,
@@ -57,9 +80,9 @@ func (h *ProductEventHandler) ProductCreatedEvent(ctx context.Context, event *ev
 			return fmt.Errorf("failed to save event to outbox: %w", err)
 		}
 
-		logging.Info("ProductCreatedEvent saved to outbox").
-			WithInt64("product_id", int64(*event.ProductID)).
-			WithString("product_name", event.Name).
+		logging.Info("Product event saved to outbox").
+			WithString("event_type", eventType).
+			WithInt64("product_id", int64(productID)).
 			WithInt64("outbox_id", int64(outboxEvent.ID)).
 			Log()
 
@@ -67,8 +90,9 @@ func (h *ProductEventHandler) ProductCreatedEvent(ctx context.Context, event *ev
 	})
 
 	if err != nil {
-		logging.Error("Failed to handle ProductCreatedEvent").
-			WithInt64("product_id", int64(*event.ProductID)).
+		logging.Error("Failed to handle product event").
+			WithString("event_type", eventType).
+			WithInt64("product_id", int64(productID)).
 			WithError(err).
 			Log()
 		return err
@@ -2,12 +2,15 @@ package outbox
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 
-	elasticsearchx "github.com/ali-mahdavi-dev/framework/infrastructure/elasticsearch"
+	"github.com/ali-mahdavi-dev/framework/adapter"
 	"github.com/ali-mahdavi-dev/framework/infrastructure/logging"
 	frameworkoutbox "github.com/ali-mahdavi-dev/framework/service_layer/outbox"
+	productaggregate "shikposh-backend/internal/products/domain/entity/product_aggregate"
+	"shikposh-backend/internal/products/adapter/search"
 	"shikposh-backend/internal/unit_of_work"
 )
 
@@ -16,28 +19,34 @@ type Consumer struct {
 	*frameworkoutbox.Consumer
 }
 
-// ProductEventHandler implements frameworkoutbox.EventHandler for products
+// ProductEventHandler implements frameworkoutbox.EventHandler for products.
+// It writes the search documents of products with their versions, so that an
+// event delivered after a newer one of the same product changes nothing.
 type ProductEventHandler struct {
-	uow           unitofwork.PGUnitOfWork
-	elasticsearch elasticsearchx.Connection
-	indexName     string
+	uow       unitofwork.PGUnitOfWork
+	admin     search.IndexAdmin
+	indexName string
+}
+
+func NewProductEventHandler(uow unitofwork.PGUnitOfWork, admin search.IndexAdmin) *ProductEventHandler {
+	return &ProductEventHandler{
+		uow:       uow,
+		admin:     admin,
+		indexName: search.ProductIndexAlias,
+	}
 }
 
 func NewConsumer(
 	uow unitofwork.PGUnitOfWork,
-	elasticsearch elasticsearchx.Connection,
+	admin search.IndexAdmin,
 	kafkaService frameworkoutbox.MessageConsumer,
 ) *Consumer {
-	if elasticsearch == nil {
+	if admin == nil {
 		logging.Warn("Elasticsearch not available, consumer will not start").Log()
 		return nil
 	}
 
-	handler := &ProductEventHandler{
-		uow:           uow,
-		elasticsearch: elasticsearch,
-		indexName:     "products",
-	}
+	handler := NewProductEventHandler(uow, admin)
 
 	frameworkConsumer := frameworkoutbox.NewConsumer(kafkaService, handler, "product.events")
 	return &Consumer{
@@ -48,8 +57,10 @@ func NewConsumer(
 // HandleEvent implements frameworkoutbox.EventHandler
 func (h *ProductEventHandler) HandleEvent(ctx context.Context, eventType string, payload map[string]interface{}) error {
 	switch eventType {
-	case "ProductCreatedEvent":
-		return h.handleProductCreatedEvent(ctx, payload)
+	case "ProductCreatedEvent", "ProductUpdatedEvent", "ProductReviewedEvent":
+		return h.indexProduct(ctx, eventType, payload)
+	case "ProductDeletedEvent":
+		return h.deleteProduct(ctx, payload)
 	default:
 		logging.Warn("Unknown event type, skipping").
 			WithString("event_type", eventType).
@@ -58,54 +69,109 @@ func (h *ProductEventHandler) HandleEvent(ctx context.Context, eventType string,
 	}
 }
 
-func (h *ProductEventHandler) handleProductCreatedEvent(ctx context.Context, payload map[string]interface{}) error {
-	// Extract product_id from payload
-	productIDRaw, ok := payload["product_id"]
-	if !ok {
-		return fmt.Errorf("product_id is missing in payload")
-	}
-
-	var productID uint64
-	switch v := productIDRaw.(type) {
-	case float64:
-		productID = uint64(v)
-	case string:
-		parsed, err := strconv.ParseUint(v, 10, 64)
-		if err != nil {
-			return fmt.Errorf("failed to parse product_id: %w", err)
-		}
-		productID = parsed
-	default:
-		return fmt.Errorf("product_id has invalid type")
+// indexProduct indexes the product of an event as it is in the database now,
+// which is at least as new as the event.
+func (h *ProductEventHandler) indexProduct(ctx context.Context, eventType string, payload map[string]interface{}) error {
+	productID, err := payloadNumber(payload, "product_id")
+	if err != nil {
+		return err
 	}
 
 	// Get full product from database
-	var productMap map[string]interface{}
-	err := h.uow.Do(ctx, func(ctx context.Context) error {
-		product, err := h.uow.Product(ctx).FindByID(ctx, productID)
-		if err != nil {
-			return fmt.Errorf("failed to get product from database: %w", err)
-		}
-
-		// Convert product to map using ToMap method
-		productMap = product.ToMap()
-		return nil
+	var product *productaggregate.Product
+	err = h.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		product, err = h.uow.Product(ctx).FindByID(ctx, productID)
+		return err
 	})
-
+	if errors.Is(err, adapter.ErrEntityNotFound) {
+		// The product has been deleted since, and its deletion removes the document
+		logging.Info("Product no longer exists, skipping").
+			WithString("event_type", eventType).
+			WithInt64("product_id", int64(productID)).
+			Log()
+		return nil
+	}
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to get product from database: %w", err)
 	}
 
 	// Index product in Elasticsearch
-	productIDStr := strconv.FormatUint(productID, 10)
-	if err := h.elasticsearch.IndexDocument(ctx, h.indexName, productIDStr, productMap); err != nil {
+	document := search.ProductDocument(product)
+	err = h.admin.IndexDocument(ctx, h.indexName, document)
+	if errors.Is(err, search.ErrVersionConflict) {
+		logging.Info("Product already indexed at a newer version, skipping").
+			WithString("event_type", eventType).
+			WithInt64("product_id", int64(productID)).
+			WithInt64("version", document.Version).
+			Log()
+		return nil
+	}
+	if err != nil {
 		return fmt.Errorf("failed to index product in elasticsearch: %w", err)
 	}
 
 	logging.Info("Product indexed in Elasticsearch from Kafka").
+		WithString("event_type", eventType).
 		WithInt64("product_id", int64(productID)).
+		WithInt64("version", document.Version).
 		WithString("index", h.indexName).
 		Log()
 
 	return nil
 }
+
+// deleteProduct deletes the document of a deleted product as of the version
+// of its deletion.
+func (h *ProductEventHandler) deleteProduct(ctx context.Context, payload map[string]interface{}) error {
+	productID, err := payloadNumber(payload, "product_id")
+	if err != nil {
+		return err
+	}
+	version, err := payloadNumber(payload, "version")
+	if err != nil {
+		return err
+	}
+
+	err = h.admin.DeleteDocument(ctx, h.indexName, search.ProductDocumentID(productID), int64(version))
+	if errors.Is(err, search.ErrVersionConflict) {
+		logging.Info("Product indexed at a newer version than its deletion, skipping").
+			WithInt64("product_id", int64(productID)).
+			WithInt64("version", int64(version)).
+			Log()
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete product from elasticsearch: %w", err)
+	}
+
+	logging.Info("Product deleted from Elasticsearch from Kafka").
+		WithInt64("product_id", int64(productID)).
+		WithInt64("version", int64(version)).
+		WithString("index", h.indexName).
+		Log()
+
+	return nil
+}
+
+// payloadNumber extracts a number from an event payload, which has it as a
+// JSON number or a string.
+func payloadNumber(payload map[string]interface{}, key string) (uint64, error) {
+	raw, ok := payload[key]
+	if !ok {
+		return 0, fmt.Errorf("%s is missing in payload", key)
+	}
+
+	switch v := raw.(type) {
+	case float64:
+		return uint64(v), nil
+	case string:
+		parsed, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse %s: %w", key, err)
+		}
+		return parsed, nil
+	default:
+		return 0, fmt.Errorf("%s has invalid type", key)
+	}
+}
@@ -16,8 +16,21 @@ type PGUnitOfWork interface {
 	adapter.UnitOfWork
 	// account repositories
 	User(ctx context.Context) accountrepository.UserRepository
-	Token(ctx context.Context) accountrepository.TokenRepository
+	Session(ctx context.Context) accountrepository.SessionRepository
+	RefreshToken(ctx context.Context) accountrepository.RefreshTokenRepository
+	Role(ctx context.Context) accountrepository.RoleRepository
+	EmailVerification(ctx context.Context) accountrepository.EmailVerificationRepository
+	PasswordReset(ctx context.Context) accountrepository.PasswordResetRepository
+	TwoFactorChallenge(ctx context.Context) accountrepository.TwoFactorChallengeRepository
+	RecoveryCode(ctx context.Context) accountrepository.RecoveryCodeRepository
+	AccountUnlock(ctx context.Context) accountrepository.AccountUnlockRepository
 	Profile(ctx context.Context) accountrepository.ProfileRepository
+	Address(ctx context.Context) accountrepository.AddressRepository
+	ServiceAccount(ctx context.Context) accountrepository.ServiceAccountRepository
+	ApiKey(ctx context.Context) accountrepository.ApiKeyRepository
+	ExternalIdentity(ctx context.Context) accountrepository.ExternalIdentityRepository
+	OidcAuthorization(ctx context.Context) accountrepository.OidcAuthorizationRepository
+	AuditEvent(ctx context.Context) accountrepository.AuditEventRepository
 
 	// product repositories
 	Product(ctx context.Context) productrepository.ProductRepository
@@ -46,11 +59,18 @@ func (uow *pgUnitOfWork) User(ctx context.Context) accountrepository.UserReposit
 	}).(accountrepository.UserRepository)
 }
 
-// Token returns the TokenRepository instance for the current transaction.
-func (uow *pgUnitOfWork) Token(ctx context.Context) accountrepository.TokenRepository {
-	return uow.BaseUnitOfWork.GetOrCreateRepository(ctx, "token", func(session *gorm.DB) adapter.SeenedRepository {
-		return accountrepository.NewTokenRepository(session)
-	}).(accountrepository.TokenRepository)
+// Session returns the SessionRepository instance for the current transaction.
+func (uow *pgUnitOfWork) Session(ctx context.Context) accountrepository.SessionRepository {
+	return uow.BaseUnitOfWork.GetOrCreateRepository(ctx, "session", func(session *gorm.DB) adapter.SeenedRepository {
+		return accountrepository.NewSessionRepository(session)
+	}).(accountrepository.SessionRepository)
+}
+
+// RefreshToken returns the RefreshTokenRepository instance for the current transaction.
+func (uow *pgUnitOfWork) RefreshToken(ctx context.Context) accountrepository.RefreshTokenRepository {
+	return uow.BaseUnitOfWork.GetOrCreateRepository(ctx, "refresh_token", func(session *gorm.DB) adapter.SeenedRepository {
+		return accountrepository.NewRefreshTokenRepository(session)
+	}).(accountrepository.RefreshTokenRepository)
 }
 
 // Profile returns the ProfileRepository instance for the current transaction.
@@ -60,6 +80,90 @@ func (uow *pgUnitOfWork) Profile(ctx context.Context) accountrepository.ProfileR
 	}).(accountrepository.ProfileRepository)
 }
 
+// Address returns the AddressRepository instance for the current transaction.
+func (uow *pgUnitOfWork) Address(ctx context.Context) accountrepository.AddressRepository {
+	return uow.BaseUnitOfWork.GetOrCreateRepository(ctx, "address", func(session *gorm.DB) adapter.SeenedRepository {
+		return accountrepository.NewAddressRepository(session)
+	}).(accountrepository.AddressRepository)
+}
+
+// ServiceAccount returns the ServiceAccountRepository instance for the current transaction.
+func (uow *pgUnitOfWork) ServiceAccount(ctx context.Context) accountrepository.ServiceAccountRepository {
+	return uow.BaseUnitOfWork.GetOrCreateRepository(ctx, "service_account", func(session *gorm.DB) adapter.SeenedRepository {
+		return accountrepository.NewServiceAccountRepository(session)
+	}).(accountrepository.ServiceAccountRepository)
+}
+
+// ApiKey returns the ApiKeyRepository instance for the current transaction.
+func (uow *pgUnitOfWork) ApiKey(ctx context.Context) accountrepository.ApiKeyRepository {
+	return uow.BaseUnitOfWork.GetOrCreateRepository(ctx, "api_key", func(session *gorm.DB) adapter.SeenedRepository {
+		return accountrepository.NewApiKeyRepository(session)
+	}).(accountrepository.ApiKeyRepository)
+}
+
+// ExternalIdentity returns the ExternalIdentityRepository instance for the current transaction.
+func (uow *pgUnitOfWork) ExternalIdentity(ctx context.Context) accountrepository.ExternalIdentityRepository {
+	return uow.BaseUnitOfWork.GetOrCreateRepository(ctx, "external_identity", func(session *gorm.DB) adapter.SeenedRepository {
+		return accountrepository.NewExternalIdentityRepository(session)
+	}).(accountrepository.ExternalIdentityRepository)
+}
+
+// OidcAuthorization returns the OidcAuthorizationRepository instance for the current transaction.
+func (uow *pgUnitOfWork) OidcAuthorization(ctx context.Context) accountrepository.OidcAuthorizationRepository {
+	return uow.BaseUnitOfWork.GetOrCreateRepository(ctx, "oidc_authorization", func(session *gorm.DB) adapter.SeenedRepository {
+		return accountrepository.NewOidcAuthorizationRepository(session)
+	}).(accountrepository.OidcAuthorizationRepository)
+}
+
+// AuditEvent returns the AuditEventRepository instance for the current transaction.
+func (uow *pgUnitOfWork) AuditEvent(ctx context.Context) accountrepository.AuditEventRepository {
+	return uow.BaseUnitOfWork.GetOrCreateRepository(ctx, "audit_event", func(session *gorm.DB) adapter.SeenedRepository {
+		return accountrepository.NewAuditEventRepository(session)
+	}).(accountrepository.AuditEventRepository)
+}
+
+// Role returns the RoleRepository instance for the current transaction.
+func (uow *pgUnitOfWork) Role(ctx context.Context) accountrepository.RoleRepository {
+	return uow.BaseUnitOfWork.GetOrCreateRepository(ctx, "role", func(session *gorm.DB) adapter.SeenedRepository {
+		return accountrepository.NewRoleRepository(session)
+	}).(accountrepository.RoleRepository)
+}
+
+// EmailVerification returns the EmailVerificationRepository instance for the current transaction.
+func (uow *pgUnitOfWork) EmailVerification(ctx context.Context) accountrepository.EmailVerificationRepository {
+	return uow.BaseUnitOfWork.GetOrCreateRepository(ctx, "email_verification", func(session *gorm.DB) adapter.SeenedRepository {
+		return accountrepository.NewEmailVerificationRepository(session)
+	}).(accountrepository.EmailVerificationRepository)
+}
+
+// PasswordReset returns the PasswordResetRepository instance for the current transaction.
+func (uow *pgUnitOfWork) PasswordReset(ctx context.Context) accountrepository.PasswordResetRepository {
+	return uow.BaseUnitOfWork.GetOrCreateRepository(ctx, "password_reset", func(session *gorm.DB) adapter.SeenedRepository {
+		return accountrepository.NewPasswordResetRepository(session)
+	}).(accountrepository.PasswordResetRepository)
+}
+
+// TwoFactorChallenge returns the TwoFactorChallengeRepository instance for the current transaction.
+func (uow *pgUnitOfWork) TwoFactorChallenge(ctx context.Context) accountrepository.TwoFactorChallengeRepository {
+	return uow.BaseUnitOfWork.GetOrCreateRepository(ctx, "two_factor_challenge", func(session *gorm.DB) adapter.SeenedRepository {
+		return accountrepository.NewTwoFactorChallengeRepository(session)
+	}).(accountrepository.TwoFactorChallengeRepository)
+}
+
+// RecoveryCode returns the RecoveryCodeRepository instance for the current transaction.
+func (uow *pgUnitOfWork) RecoveryCode(ctx context.Context) accountrepository.RecoveryCodeRepository {
+	return uow.BaseUnitOfWork.GetOrCreateRepository(ctx, "recovery_code", func(session *gorm.DB) adapter.SeenedRepository {
+		return accountrepository.NewRecoveryCodeRepository(session)
+	}).(accountrepository.RecoveryCodeRepository)
+}
+
+// AccountUnlock returns the AccountUnlockRepository instance for the current transaction.
+func (uow *pgUnitOfWork) AccountUnlock(ctx context.Context) accountrepository.AccountUnlockRepository {
+	return uow.BaseUnitOfWork.GetOrCreateRepository(ctx, "account_unlock", func(session *gorm.DB) adapter.SeenedRepository {
+		return accountrepository.NewAccountUnlockRepository(session)
+	}).(accountrepository.AccountUnlockRepository)
+}
+
 // Product returns the ProductRepository instance for the current transaction.
 func (uow *pgUnitOfWork) Product(ctx context.Context) productrepository.ProductRepository {
 	return uow.BaseUnitOfWork.GetOrCreateRepository(ctx, "product", func(session *gorm.DB) adapter.SeenedRepository {
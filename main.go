@@ -6,5 +6,5 @@
 // @type						apiKey
 // @in							header
 // @name						Authorization
-// @description				Type "Bearer" followed by a space and your JWT token.
+// @description				Type "Bearer" followed by a space and your JWT token, or "ApiKey" followed by a space and the API key of a service account.
 package main
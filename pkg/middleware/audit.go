@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/internal/account/domain/entity"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"github.com/ali-mahdavi-dev/framework/infrastructure/logging"
+	commandeventhandler "github.com/ali-mahdavi-dev/framework/service_layer/command_event_handler"
+
+	"github.com/pkg/errors"
+)
+
+// AuditedCommand is implemented by commands recorded in the audit log.
+type AuditedCommand interface {
+	AuditAction() string
+}
+
+// AuditTargeter is implemented by audited commands acting on something, such
+// as a user account.
+type AuditTargeter interface {
+	AuditTarget() (targetType, targetID string)
+}
+
+// CommandAudit is a command bus middleware recording the outcome of commands
+// implementing AuditedCommand in the audit log. It must come before
+// CommandAuthorization, so commands refused for lack of permission are
+// recorded too.
+func (m *Middleware) CommandAudit() commandeventhandler.CommandMiddleware {
+	return func(next commandeventhandler.HandlerFunc) commandeventhandler.HandlerFunc {
+		return func(ctx context.Context, cmd any) error {
+			err := next(ctx, cmd)
+			m.Audit(ctx, cmd, err)
+			return err
+		}
+	}
+}
+
+// Audit records the outcome of an audited command, err being what its handler
+// returned. Handlers returning a result are called directly rather than through
+// the command bus, so their controllers call Audit themselves.
+//
+// The entry is written outside the command's transaction, so it is kept when
+// the command fails. Failing to write it is logged and does not fail the
+// command.
+func (m *Middleware) Audit(ctx context.Context, cmd any, err error) {
+	audited, ok := cmd.(AuditedCommand)
+	if !ok {
+		return
+	}
+
+	outcome, reason := auditOutcome(err)
+	event := entity.NewAuditEvent(audited.AuditAction(), outcome, time.Now())
+	event.Reason = reason
+
+	if identity, ok := IdentityFromContext(ctx); ok {
+		principal := identity.Principal()
+		event.ActedBy(string(principal.Type), principal.ID)
+	}
+	if targeter, ok := cmd.(AuditTargeter); ok {
+		event.On(targeter.AuditTarget())
+	}
+	if info, ok := RequestInfoFromContext(ctx); ok {
+		event.IP = info.IP
+		event.UserAgent = info.UserAgent
+		event.RequestID = info.RequestID
+	}
+
+	if err := m.Uow.AuditEvent(ctx).Save(ctx, event); err != nil {
+		logging.Error("Failed to record audit event").
+			WithString("action", event.Action).
+			WithError(err).
+			Log()
+	}
+}
+
+// auditOutcome classifies the error of a command. Errors other than
+// application errors have no reason, their message may hold internals.
+func auditOutcome(err error) (entity.AuditOutcome, string) {
+	if err == nil {
+		return entity.AuditSuccess, ""
+	}
+
+	var appErr apperrors.Error
+	if !errors.As(err, &appErr) {
+		return entity.AuditFailure, ""
+	}
+
+	switch appErr.Type() {
+	case apperrors.ErrorTypeUnauthorized, apperrors.ErrorTypeForbidden:
+		return entity.AuditDenied, string(appErr.Type())
+	default:
+		return entity.AuditFailure, string(appErr.Type())
+	}
+}
@@ -1,66 +1,215 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"slices"
 	"strings"
+	"time"
 
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/commands"
 	"shikposh-backend/internal/account/domain/entity"
 	httpapi "github.com/ali-mahdavi-dev/framework/api/http"
+	"github.com/ali-mahdavi-dev/framework/infrastructure/logging"
 
 	"github.com/gofiber/fiber/v3"
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/spf13/cast"
 )
 
-var errFailGetTokenFromDB = errors.New("fail to get token from DB")
-var errTokenDoesNotExist = errors.New("token does not exist")
+var (
+	errFailGetSessionFromDB = errors.New("fail to get session from DB")
+	errFailGetApiKeyFromDB  = errors.New("fail to get api key from DB")
+)
+
+// lastSeenInterval limits how often the last-seen time of a session, or the
+// last-used time of an API key, is written back.
+const lastSeenInterval = time.Minute
+
+// authError is returned by authenticate when the request carries no usable
+// credentials; its message is sent back to the client.
+type authError struct {
+	message string
+}
 
+func (e *authError) Error() string {
+	return e.message
+}
+
+// AuthMiddleware protects a route: requests without a valid access token of an
+// active session, or a usable API key of a service account, are rejected.
 func (m *Middleware) AuthMiddleware() fiber.Handler {
 	return func(c fiber.Ctx) error {
-		// Get token from Authorization header
-		authHeader := c.Get("Authorization")
-		if authHeader == "" {
-			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{"error": "Authorization header required"})
+		// Already authenticated by a previous handler in the chain
+		if _, ok := IdentityFromContext(c.Context()); ok {
+			return c.Next()
 		}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token format"})
+		if err := m.authenticate(c); err != nil {
+			var authErr *authError
+			if errors.As(err, &authErr) {
+				return c.Status(http.StatusUnauthorized).JSON(fiber.Map{"error": authErr.message})
+			}
+			return httpapi.ResError(c, err)
 		}
 
-		tokenStr := parts[1]
+		return c.Next()
+	}
+}
 
-		// Parse JWT
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
+// OptionalAuthMiddleware is mounted on public routes. Anonymous requests pass
+// through untouched, while a valid access token still attaches the caller's
+// identity so handlers can personalise the response.
+func (m *Middleware) OptionalAuthMiddleware() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if _, ok := IdentityFromContext(c.Context()); ok || c.Get("Authorization") == "" {
+			return c.Next()
+		}
+
+		if err := m.authenticate(c); err != nil {
+			var authErr *authError
+			if !errors.As(err, &authErr) {
+				return httpapi.ResError(c, err)
 			}
-			return m.Cfg.JWTSecret, nil
-		})
+		}
 
-		if err != nil || !token.Valid {
-			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token"})
+		return c.Next()
+	}
+}
+
+// authenticate validates the credentials of the Authorization header, a
+// Bearer access token of a user or an ApiKey of a service account, and stores
+// the resulting identity on the request.
+func (m *Middleware) authenticate(c fiber.Ctx) error {
+	// Get token from Authorization header
+	authHeader := c.Get("Authorization")
+	if authHeader == "" {
+		return &authError{message: "Authorization header required"}
+	}
+
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 {
+		return &authError{message: "Invalid token format"}
+	}
+
+	var (
+		identity Identity
+		err      error
+	)
+	switch parts[0] {
+	case "Bearer":
+		identity, err = m.authenticateUser(c, parts[1])
+	case "ApiKey":
+		identity, err = m.authenticateServiceAccount(c, parts[1])
+	default:
+		return &authError{message: "Invalid token format"}
+	}
+	if err != nil {
+		return err
+	}
+
+	c.Locals("principal", identity.Principal())
+	c.SetContext(WithIdentity(c.Context(), identity))
+
+	return nil
+}
+
+// authenticateUser validates the access token against its session.
+func (m *Middleware) authenticateUser(c fiber.Ctx, token string) (Identity, error) {
+	claims, err := m.Cfg.AccessTokens.Parse(token)
+	if err != nil {
+		return Identity{}, &authError{message: "Invalid token"}
+	}
+
+	// Validate token against its session so logged out devices are rejected
+	ctx := c.Context()
+	session, err := m.Uow.Session(ctx).FindBySessionID(ctx, entity.SessionID(claims.SessionID))
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return Identity{}, &authError{message: "Session not found"}
 		}
+		return Identity{}, errFailGetSessionFromDB
+	}
 
-		// Extract claims and validate token from DB
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			userID := cast.ToUint64(claims["user_id"])
-			ctx := c.Context()
-			user, err := m.Uow.Token(ctx).FindByUserID(ctx, entity.UserID(userID))
-			if err != nil {
-				return httpapi.ResError(c, errFailGetTokenFromDB)
-			}
-			if user.Token != tokenStr {
-				return httpapi.ResError(c, errTokenDoesNotExist)
-			}
+	now := time.Now()
+	if session.UserID != entity.UserID(claims.UserID) || !session.IsActive(now) {
+		return Identity{}, &authError{message: "Session expired or revoked"}
+	}
 
-			// Store user_id in Fiber context
-			c.Locals("user_id", userID)
-		} else {
-			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token claims"})
+	if now.Sub(session.LastSeenAt) >= lastSeenInterval {
+		m.touchSession(ctx, session, c.Get(fiber.HeaderUserAgent), c.IP(), now)
+	}
+
+	// Store user_id and session_id in Fiber context
+	c.Locals("user_id", claims.UserID)
+	c.Locals("session_id", claims.SessionID)
+
+	return Identity{UserID: claims.UserID, SessionID: claims.SessionID}, nil
+}
+
+// authenticateServiceAccount looks the API key up by its hash. The scopes of
+// its service account become the caller's permissions, except those that
+// administer accounts, which a service account may hold from before they
+// were refused.
+func (m *Middleware) authenticateServiceAccount(c fiber.Ctx, plainKey string) (Identity, error) {
+	ctx := c.Context()
+	key, err := m.Uow.ApiKey(ctx).FindByKeyHash(ctx, entity.HashToken(plainKey))
+	if err != nil {
+		if errors.Is(err, repository.ErrApiKeyNotFound) {
+			return Identity{}, &authError{message: "Invalid API key"}
 		}
+		return Identity{}, errFailGetApiKeyFromDB
+	}
 
-		return c.Next()
+	now := time.Now()
+	if !key.IsUsable(now) {
+		return Identity{}, &authError{message: "API key expired or revoked"}
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastSeenInterval {
+		m.touchApiKey(ctx, key, now)
+	}
+
+	c.Locals("service_account_id", uint64(key.ServiceAccountID))
+
+	return Identity{
+		ServiceAccountID: uint64(key.ServiceAccountID),
+		Permissions:      grantedScopes(key.ServiceAccount),
+	}, nil
+}
+
+// grantedScopes returns the scopes of the service account that it may use.
+func grantedScopes(account *entity.ServiceAccount) []string {
+	return slices.DeleteFunc(account.ScopeNames(), func(scope string) bool {
+		return !commands.IsAssignableScope(scope)
+	})
+}
+
+// touchSession records device activity. Failures are only logged since they
+// must not block an otherwise authenticated request.
+func (m *Middleware) touchSession(ctx context.Context, session *entity.Session, userAgent, ip string, now time.Time) {
+	err := m.Uow.Do(ctx, func(ctx context.Context) error {
+		session.Touch(userAgent, ip, now)
+		return m.Uow.Session(ctx).Modify(ctx, session)
+	})
+	if err != nil {
+		logging.Warn("Failed to update session last seen").
+			WithInt64("session_id", int64(session.ID)).
+			WithError(err).
+			Log()
+	}
+}
+
+// touchApiKey records the use of an API key. Like touchSession, failures are
+// only logged.
+func (m *Middleware) touchApiKey(ctx context.Context, key *entity.ApiKey, now time.Time) {
+	err := m.Uow.Do(ctx, func(ctx context.Context) error {
+		return m.Uow.ApiKey(ctx).MarkUsed(ctx, key, now)
+	})
+	if err != nil {
+		logging.Warn("Failed to update API key last used").
+			WithInt64("api_key_id", int64(key.ID)).
+			WithError(err).
+			Log()
 	}
 }
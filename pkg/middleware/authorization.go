@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"shikposh-backend/internal/account/domain/entity"
+	httpapi "github.com/ali-mahdavi-dev/framework/api/http"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	commandeventhandler "github.com/ali-mahdavi-dev/framework/service_layer/command_event_handler"
+	"shikposh-backend/pkg/phrases"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// PermissionRequirer is implemented by commands that may only be handled on
+// behalf of a caller holding the returned permission.
+type PermissionRequirer interface {
+	RequiredPermission() string
+}
+
+// RequirePermission rejects requests whose authenticated user lacks the given
+// permission. It must be mounted after AuthMiddleware.
+func (m *Middleware) RequirePermission(permission string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		ctx := c.Context()
+		identity, ok := IdentityFromContext(ctx)
+		if !ok {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{"error": "Authentication required"})
+		}
+
+		identity, err := m.loadPermissions(ctx, identity)
+		if err != nil {
+			return httpapi.ResError(c, err)
+		}
+		c.SetContext(WithIdentity(ctx, identity))
+
+		if !slices.Contains(identity.Permissions, permission) {
+			return c.Status(http.StatusForbidden).JSON(fiber.Map{"error": "Permission denied"})
+		}
+
+		return c.Next()
+	}
+}
+
+// CommandAuthorization is a command bus middleware enforcing the permission
+// declared by commands implementing PermissionRequirer.
+func (m *Middleware) CommandAuthorization() commandeventhandler.CommandMiddleware {
+	return func(next commandeventhandler.HandlerFunc) commandeventhandler.HandlerFunc {
+		return func(ctx context.Context, cmd any) error {
+			requirer, ok := cmd.(PermissionRequirer)
+			if !ok {
+				return next(ctx, cmd)
+			}
+
+			identity, ok := IdentityFromContext(ctx)
+			if !ok {
+				return apperrors.Unauthorized(phrases.AuthenticationRequired, "Authentication required")
+			}
+
+			identity, err := m.loadPermissions(ctx, identity)
+			if err != nil {
+				return err
+			}
+
+			if !slices.Contains(identity.Permissions, requirer.RequiredPermission()) {
+				return apperrors.Forbidden(phrases.PermissionDenied, fmt.Sprintf("Permission %q required", requirer.RequiredPermission()))
+			}
+
+			return next(WithIdentity(ctx, identity), cmd)
+		}
+	}
+}
+
+func (m *Middleware) loadPermissions(ctx context.Context, identity Identity) (Identity, error) {
+	if identity.Permissions != nil {
+		return identity, nil
+	}
+
+	permissions, err := m.Uow.User(ctx).FindPermissions(ctx, entity.UserID(identity.UserID))
+	if err != nil {
+		return identity, fmt.Errorf("Middleware.loadPermissions fail get user permissions: %w", err)
+	}
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	identity.Permissions = permissions
+	return identity, nil
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import "context"
+
+type identityKey struct{}
+
+// PrincipalType tells the kinds of authenticated callers apart.
+type PrincipalType string
+
+const (
+	PrincipalUser    PrincipalType = "user"
+	PrincipalService PrincipalType = "service"
+)
+
+// Principal is the caller a request is made by, a user or a service account.
+// AuthMiddleware stores it in the "principal" Fiber local.
+type Principal struct {
+	Type PrincipalType
+	ID   uint64
+}
+
+// Identity is the authenticated caller attached to the request context. It
+// travels with the context into the command bus, so command middlewares can
+// authorize commands no matter where they were dispatched from.
+type Identity struct {
+	UserID    uint64
+	SessionID uint64
+	// ServiceAccountID is set instead of UserID and SessionID when the caller
+	// authenticated with an API key.
+	ServiceAccountID uint64
+	// Permissions is nil until the caller's permissions have been loaded. A
+	// service account's permissions are its scopes.
+	Permissions []string
+}
+
+// IsServiceAccount reports whether the caller is a service account rather
+// than a user.
+func (i Identity) IsServiceAccount() bool {
+	return i.ServiceAccountID != 0
+}
+
+func (i Identity) Principal() Principal {
+	if i.IsServiceAccount() {
+		return Principal{Type: PrincipalService, ID: i.ServiceAccountID}
+	}
+	return Principal{Type: PrincipalUser, ID: i.UserID}
+}
+
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
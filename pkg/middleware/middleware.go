@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	accountadapter "shikposh-backend/internal/account/adapter"
 	"shikposh-backend/internal/unit_of_work"
 
 	"github.com/ali-mahdavi-dev/framework/adapter"
@@ -11,7 +12,9 @@ import (
 )
 
 type MiddlewareConfig struct {
-	JWTSecret string
+	// AccessTokens verifies the access tokens of users. The account module
+	// signs them with the same key set.
+	AccessTokens *accountadapter.AccessTokenKeys
 }
 
 type Middleware struct {
@@ -30,10 +33,13 @@ func NewMiddleware(cfg MiddlewareConfig, db *gorm.DB) *Middleware {
 	}
 }
 
+// Register installs the middlewares shared by every route. Authentication is
+// not part of it: each module's RegisterRoutes mounts OptionalAuthMiddleware on
+// its public groups and AuthMiddleware on the routes that need a user.
 func (m *Middleware) Register(app *fiber.App) {
 	// Request ID middleware should be registered first
 	// so it's available for all subsequent middleware and handlers
 	app.Use(frameworkmiddleware.RequestIDMiddleware())
+	app.Use(RequestInfoMiddleware())
 	app.Use(frameworkmiddleware.DefaultStructuredLogger())
-	app.Use(m.AuthMiddleware())
 }
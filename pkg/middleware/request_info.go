@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type requestInfoKey struct{}
+
+// RequestInfo describes the HTTP request a command is handled for. Like the
+// Identity it travels with the request context into the command bus, where
+// the audit log records it.
+type RequestInfo struct {
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+func RequestInfoFromContext(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info, ok
+}
+
+// RequestInfoMiddleware attaches the RequestInfo to the request context. It
+// must be mounted after RequestIDMiddleware, which sets the X-Request-ID
+// response header.
+func RequestInfoMiddleware() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		requestID := c.GetRespHeader(fiber.HeaderXRequestID)
+		if requestID == "" {
+			requestID = c.Get(fiber.HeaderXRequestID)
+		}
+
+		c.SetContext(WithRequestInfo(c.Context(), RequestInfo{
+			IP:        c.IP(),
+			UserAgent: c.Get(fiber.HeaderUserAgent),
+			RequestID: requestID,
+		}))
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"shikposh-backend/internal/account/domain/entity"
+	httpapi "github.com/ali-mahdavi-dev/framework/api/http"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// RequireTwoFactorEnrollment rejects admin-role users that have not enabled
+// two-factor authentication yet. It must be mounted after AuthMiddleware.
+func (m *Middleware) RequireTwoFactorEnrollment() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		ctx := c.Context()
+		identity, ok := IdentityFromContext(ctx)
+		if !ok {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{"error": "Authentication required"})
+		}
+
+		// Service accounts have no second factor; their keys carry only their scopes
+		if identity.IsServiceAccount() {
+			return c.Next()
+		}
+
+		user, err := m.Uow.User(ctx).FindByID(ctx, identity.UserID)
+		if err != nil {
+			return httpapi.ResError(c, fmt.Errorf("Middleware.RequireTwoFactorEnrollment fail get user: %w", err))
+		}
+		if user.IsTwoFactorEnabled() {
+			return c.Next()
+		}
+
+		isAdmin, err := m.Uow.User(ctx).HasRole(ctx, user.ID, entity.RoleAdmin)
+		if err != nil {
+			return httpapi.ResError(c, fmt.Errorf("Middleware.RequireTwoFactorEnrollment fail check role: %w", err))
+		}
+		if isAdmin {
+			return c.Status(http.StatusForbidden).JSON(fiber.Map{"error": "Two-factor authentication enrollment required"})
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,44 @@
+// Package phrases holds the error phrase IDs of this service that the
+// framework's phrases package does not provide.
+package phrases
+
+const (
+	AuthenticationRequired   = "AuthenticationRequired"
+	PermissionDenied         = "PermissionDenied"
+	RoleNotFound             = "RoleNotFound"
+	EmailNotVerified         = "EmailNotVerified"
+	InvalidVerificationToken = "InvalidVerificationToken"
+	WeakPassword             = "WeakPassword"
+	InvalidPasswordReset     = "InvalidPasswordReset"
+	InvalidCurrentPassword   = "InvalidCurrentPassword"
+	InvalidPhone             = "InvalidPhone"
+	InvalidOtp               = "InvalidOtp"
+	InvalidTwoFactorCode     = "InvalidTwoFactorCode"
+	TwoFactorAlreadyEnabled  = "TwoFactorAlreadyEnabled"
+	TwoFactorNotEnrolled     = "TwoFactorNotEnrolled"
+	AccountLocked            = "AccountLocked"
+	InvalidAccountUnlock     = "InvalidAccountUnlock"
+	EmailAlreadyInUse        = "EmailAlreadyInUse"
+	PhoneAlreadyInUse        = "PhoneAlreadyInUse"
+	PhoneRequired            = "PhoneRequired"
+	AddressNotFound          = "AddressNotFound"
+	InvalidPostalCode        = "InvalidPostalCode"
+	AccountDisabled          = "AccountDisabled"
+	CannotManageOwnAccount   = "CannotManageOwnAccount"
+	EmailRequired            = "EmailRequired"
+	ServiceAccountNotFound   = "ServiceAccountNotFound"
+	ServiceAccountExists     = "ServiceAccountExists"
+	ApiKeyNotFound           = "ApiKeyNotFound"
+	UnknownScope             = "UnknownScope"
+	UnassignableScope        = "UnassignableScope"
+	InvalidExpiry            = "InvalidExpiry"
+	UnknownIdentityProvider  = "UnknownIdentityProvider"
+	InvalidOidcAuthorization = "InvalidOidcAuthorization"
+	InvalidAvatarOptions     = "InvalidAvatarOptions"
+	InvalidAvatarUpload      = "InvalidAvatarUpload"
+	AvatarTooLarge           = "AvatarTooLarge"
+	InvalidAuditFilter       = "InvalidAuditFilter"
+	InvalidPagination        = "InvalidPagination"
+	InvalidSuggestQuery      = "InvalidSuggestQuery"
+	InvalidFacetQuery        = "InvalidFacetQuery"
+)
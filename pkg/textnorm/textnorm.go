@@ -0,0 +1,54 @@
+// Package textnorm normalises Persian text for searching, so that the ways a
+// word can be typed all compare equal.
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+)
+
+// replacements fold the Arabic forms of letters, which Arabic keyboard
+// layouts type, into their Persian forms.
+var replacements = map[rune]rune{
+	'\u064A': '\u06CC', // Arabic yeh to Persian yeh
+	'\u0649': '\u06CC', // alef maksura to Persian yeh
+	'\u0643': '\u06A9', // Arabic kaf to Persian kaf
+	'\u0629': '\u0647', // teh marbuta to heh
+	'\u0623': '\u0627', // alef with hamza above to alef
+	'\u0625': '\u0627', // alef with hamza below to alef
+	'\u0671': '\u0627', // alef wasla to alef
+	'\u0624': '\u0648', // waw with hamza above to waw
+}
+
+// dropped reports whether r is stripped: zero-width joiners and non-joiners,
+// the byte order mark, tatweel and diacritics.
+func dropped(r rune) bool {
+	switch {
+	case r == '\u200C', r == '\u200D', r == '\uFEFF', r == '\u0640':
+		return true
+	case r >= '\u064B' && r <= '\u065F', r == '\u0670':
+		return true
+	}
+	return false
+}
+
+// Normalize folds letters and digits to one form, strips zero-width
+// characters and diacritics, lower-cases and collapses whitespace.
+func Normalize(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if dropped(r) {
+			return -1
+		}
+		if replacement, ok := replacements[r]; ok {
+			return replacement
+		}
+		switch {
+		case r >= '\u06F0' && r <= '\u06F9': // Persian digits
+			return '0' + r - '\u06F0'
+		case r >= '\u0660' && r <= '\u0669': // Arabic-Indic digits
+			return '0' + r - '\u0660'
+		}
+		return unicode.ToLower(r)
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -51,13 +51,15 @@ var _ = Describe("User Registration Acceptance Scenarios", func() {
 			loginCmd := factory.CreateLoginCommand("newuser", "password123")
 
 			// Phase 2: Exercise (Act) - Login user
-			token, err := handler.LoginHandler(ctx, loginCmd)
+			result, err := handler.LoginHandler(ctx, loginCmd)
 
-			// Phase 3: Verify (Assert) - Verify login and token
+			// Phase 3: Verify (Assert) - Verify login and session
 			Expect(err).NotTo(HaveOccurred())
-			Expect(token).NotTo(BeEmpty())
-			savedToken := helpers.FindTokenByUserID(builder.DB, user.ID)
-			Expect(savedToken.Token).To(Equal(token))
+			Expect(result.Access).NotTo(BeEmpty())
+			savedToken := helpers.FindRefreshToken(builder.DB, result.Refresh)
+			Expect(savedToken.UserID).To(Equal(user.ID))
+			session := helpers.FindSessionByID(builder.DB, savedToken.SessionID)
+			Expect(session.UserID).To(Equal(user.ID))
 		})
 	})
 
@@ -96,19 +98,20 @@ var _ = Describe("User Registration Acceptance Scenarios", func() {
 			loginCmd := factory.CreateLoginCommand("logoutuser", "password123")
 
 			// Phase 2: Exercise (Act) - Login user
-			_, err = handler.LoginHandler(ctx, loginCmd)
+			result, err := handler.LoginHandler(ctx, loginCmd)
 			Expect(err).NotTo(HaveOccurred())
 
 			// Phase 1: Setup (Arrange) - Prepare logout command
 			user := helpers.FindUserByUsername(builder.DB, "logoutuser")
-			logoutCmd := factory.CreateLogoutCommand(uint64(user.ID))
+			sessionID := helpers.FindRefreshToken(builder.DB, result.Refresh).SessionID
+			logoutCmd := factory.CreateLogoutCommand(uint64(user.ID), uint64(sessionID))
 
 			// Phase 2: Exercise (Act) - Logout user
 			err = handler.LogoutHandler(ctx, logoutCmd)
 
-			// Phase 3: Verify (Assert) - Verify token removal
+			// Phase 3: Verify (Assert) - Verify session revocation
 			Expect(err).NotTo(HaveOccurred())
-			helpers.VerifyTokenRemoved(builder.DB, user.ID)
+			helpers.VerifySessionRevoked(builder.DB, sessionID)
 		})
 	})
 })
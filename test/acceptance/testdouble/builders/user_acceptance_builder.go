@@ -2,6 +2,7 @@ package builders
 
 import (
 	"shikposh-backend/config"
+	accountadapter "shikposh-backend/internal/account/adapter"
 	"shikposh-backend/internal/account/domain/entity"
 	"shikposh-backend/internal/account/service_layer/command_handler"
 	"github.com/ali-mahdavi-dev/framework/adapter"
@@ -25,8 +26,9 @@ func NewUserAcceptanceTestBuilder() *UserAcceptanceTestBuilder {
 
 	err = db.AutoMigrate(
 		&entity.User{},
-		&entity.Token{},
 		&entity.Profile{},
+		&entity.Session{},
+		&entity.RefreshToken{},
 	)
 	Expect(err).NotTo(HaveOccurred())
 
@@ -35,8 +37,9 @@ func NewUserAcceptanceTestBuilder() *UserAcceptanceTestBuilder {
 
 	cfg := &config.Config{
 		JWT: config.JWTConfig{
-			Secret:                    "test-secret-for-acceptance",
-			AccessTokenExpireDuration: 3600,
+			Secret:                     "test-secret-for-acceptance",
+			AccessTokenExpireDuration:  15,
+			RefreshTokenExpireDuration: 60,
 		},
 	}
 
@@ -48,12 +51,16 @@ func NewUserAcceptanceTestBuilder() *UserAcceptanceTestBuilder {
 }
 
 func (b *UserAcceptanceTestBuilder) BuildHandler() *command_handler.UserHandler {
-	return command_handler.NewUserHandler(b.UOW, b.Cfg)
+	accessTokens, err := accountadapter.NewAccessTokenKeys(b.Cfg.JWT)
+	Expect(err).NotTo(HaveOccurred())
+
+	return command_handler.NewUserHandler(b.UOW, b.Cfg, accessTokens, accountadapter.NewMemoryLoginAttemptStore())
 }
 
 func (b *UserAcceptanceTestBuilder) Cleanup() {
 	b.DB.Exec("DELETE FROM users")
-	b.DB.Exec("DELETE FROM tokens")
+	b.DB.Exec("DELETE FROM refresh_tokens")
+	b.DB.Exec("DELETE FROM sessions")
 	b.DB.Exec("DELETE FROM profiles")
 }
 
@@ -40,10 +40,11 @@ func (f *UserFactory) CreateLoginCommand(username, password string) *commands.Lo
 	}
 }
 
-// CreateLogoutCommand creates a logout command
-func (f *UserFactory) CreateLogoutCommand(userID uint64) *commands.Logout {
+// CreateLogoutCommand creates a logout command for a session
+func (f *UserFactory) CreateLogoutCommand(userID, sessionID uint64) *commands.Logout {
 	return &commands.Logout{
-		UserID: userID,
+		UserID:    userID,
+		SessionID: sessionID,
 	}
 }
 
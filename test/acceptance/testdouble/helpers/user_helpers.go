@@ -19,18 +19,20 @@ func FindUserByUsername(db *gorm.DB, username string) *entity.User {
 	return user
 }
 
-// FindTokenByUserID finds a token by user ID
-func FindTokenByUserID(db *gorm.DB, userID entity.UserID) *entity.Token {
-	tokenRepo := repository.NewTokenRepository(db)
-	token, err := tokenRepo.FindByUserID(context.Background(), userID)
+// FindRefreshToken finds a refresh token by its plain value
+func FindRefreshToken(db *gorm.DB, plainToken string) *entity.RefreshToken {
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	token, err := refreshTokenRepo.FindByTokenHash(context.Background(), entity.HashToken(plainToken))
 	Expect(err).NotTo(HaveOccurred())
 	return token
 }
 
-// FindTokenByUserIDWithError finds a token by user ID and returns error if not found
-func FindTokenByUserIDWithError(db *gorm.DB, userID entity.UserID) (*entity.Token, error) {
-	tokenRepo := repository.NewTokenRepository(db)
-	return tokenRepo.FindByUserID(context.Background(), userID)
+// FindSessionByID finds a session by ID
+func FindSessionByID(db *gorm.DB, sessionID entity.SessionID) *entity.Session {
+	sessionRepo := repository.NewSessionRepository(db)
+	session, err := sessionRepo.FindBySessionID(context.Background(), sessionID)
+	Expect(err).NotTo(HaveOccurred())
+	return session
 }
 
 // VerifyPasswordHashed verifies that password is hashed correctly
@@ -39,10 +41,17 @@ func VerifyPasswordHashed(hashedPassword, plainPassword string) {
 	Expect(err).NotTo(HaveOccurred())
 }
 
-// VerifyTokenRemoved verifies that token was removed
-func VerifyTokenRemoved(db *gorm.DB, userID entity.UserID) {
-	_, err := FindTokenByUserIDWithError(db, userID)
-	Expect(err).To(HaveOccurred())
+// VerifySessionRevoked verifies that the session and its refresh tokens were revoked
+func VerifySessionRevoked(db *gorm.DB, sessionID entity.SessionID) {
+	session := FindSessionByID(db, sessionID)
+	Expect(session.RevokedAt).NotTo(BeNil())
+
+	var activeTokens int64
+	err := db.Model(&entity.RefreshToken{}).
+		Where("session_id = ? AND revoked_at IS NULL", uint64(sessionID)).
+		Count(&activeTokens).Error
+	Expect(err).NotTo(HaveOccurred())
+	Expect(activeTokens).To(BeZero())
 }
 
 // VerifyUserCount verifies the count of users with given username
@@ -6,17 +6,25 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"time"
 
 	"shikposh-backend/config"
+	accountadapter "shikposh-backend/internal/account/adapter"
+	accountentity "shikposh-backend/internal/account/domain/entity"
 	products "shikposh-backend/internal/products"
 	"shikposh-backend/internal/products/adapter/repository"
 	"shikposh-backend/internal/products/domain/commands"
 	"shikposh-backend/internal/products/domain/entity"
 	productaggregate "shikposh-backend/internal/products/domain/entity/product_aggregate"
+	"shikposh-backend/internal/products/domain/entity/shared"
+	"shikposh-backend/pkg/middleware"
 
 	"github.com/gofiber/fiber/v3"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -52,6 +60,272 @@ var _ = Describe("Product API E2E", func() {
 				Expect(result["data"]).NotTo(BeNil())
 			})
 		})
+
+		Context("when the catalog spans several pages", func() {
+			It("should page through every product, the newest first", func() {
+				// Phase 1: Setup (Arrange)
+				products := builder.CreateProducts(5)
+
+				// Phase 2: Exercise (Act)
+				first := builder.GetPage("/api/v1/public/products?limit=2&include_total=true")
+				second := builder.GetPage("/api/v1/public/products?limit=2&cursor=" + first["next_cursor"].(string))
+				last := builder.GetPage("/api/v1/public/products?limit=2&cursor=" + second["next_cursor"].(string))
+
+				// Phase 3: Verify (Assert)
+				Expect(first["total"]).To(BeEquivalentTo(5))
+				Expect(first["has_more"]).To(BeTrue())
+				Expect(pageSlugs(first)).To(Equal([]string{products[4].Slug, products[3].Slug}))
+				Expect(second).NotTo(HaveKey("total"))
+				Expect(pageSlugs(second)).To(Equal([]string{products[2].Slug, products[1].Slug}))
+				Expect(pageSlugs(last)).To(Equal([]string{products[0].Slug}))
+				Expect(last["has_more"]).To(BeFalse())
+				Expect(last["next_cursor"]).To(BeNil())
+			})
+		})
+
+		Context("when filtering and sorting by price", func() {
+			It("should use the effective price of the products", func() {
+				// Phase 1: Setup (Arrange)
+				products := builder.CreateProducts(3)
+				for i, price := range []float64{300000, 100000, 200000} {
+					products[i].Details = []productaggregate.ProductDetail{{ProductID: products[i].ID, Price: price, Stock: 1}}
+					products[i].RecalculatePricing()
+					Expect(builder.db.Save(products[i]).Error).NotTo(HaveOccurred())
+				}
+
+				// Phase 2: Exercise (Act)
+				page := builder.GetPage("/api/v1/public/products?min=150000&sort=price_asc")
+
+				// Phase 3: Verify (Assert)
+				Expect(pageSlugs(page)).To(Equal([]string{products[2].Slug, products[0].Slug}))
+				Expect(page["items"].([]interface{})[0]).To(HaveKeyWithValue("price", BeEquivalentTo(200000)))
+			})
+		})
+
+		Context("when searching with Arabic letters and Persian digits", func() {
+			It("should match the products however their text was typed", func() {
+				// Phase 1: Setup (Arrange)
+				products := builder.CreateProducts(2)
+				products[0].Name = "کیف چرمی مدل ۲۰"
+				Expect(builder.db.Save(products[0]).Error).NotTo(HaveOccurred())
+
+				// Phase 2: Exercise (Act)
+				page := builder.GetPage("/api/v1/public/products?q=" + url.QueryEscape("كيف چرمي مدل 20"))
+
+				// Phase 3: Verify (Assert)
+				Expect(pageSlugs(page)).To(Equal([]string{products[0].Slug}))
+			})
+		})
+
+		Context("when the cursor is malformed", func() {
+			It("should return bad request status", func() {
+				// Phase 1: Setup (Arrange)
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/public/products?cursor=not-a-cursor", nil)
+
+				// Phase 2: Exercise (Act)
+				resp, err := builder.app.Test(req)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		Context("when the cursor was issued for another sort order", func() {
+			It("should return bad request status", func() {
+				// Phase 1: Setup (Arrange)
+				builder.CreateProducts(3)
+				first := builder.GetPage("/api/v1/public/products?limit=1")
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/public/products?sort=rating&cursor="+first["next_cursor"].(string), nil)
+
+				// Phase 2: Exercise (Act)
+				resp, err := builder.app.Test(req)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		Context("when asking for facets", func() {
+			It("should count every facet but the selected one with the selected filters", func() {
+				// Phase 1: Setup (Arrange)
+				products := builder.CreateProducts(3)
+				red, blue := "red", "blue"
+				for i, brand := range []string{"Nike", "Nike", "Adidas"} {
+					products[i].Brand = brand
+					products[i].Tags = []string{"summer"}
+					products[i].Sizes = []string{"M"}
+					products[i].Details = []productaggregate.ProductDetail{{ProductID: products[i].ID, ColorKey: &red, Price: 150000, Stock: 1}}
+					products[i].RecalculatePricing()
+					Expect(builder.db.Save(products[i]).Error).NotTo(HaveOccurred())
+				}
+				products[2].Details = append(products[2].Details, productaggregate.ProductDetail{ProductID: products[2].ID, ColorKey: &blue, Price: 150000})
+				Expect(builder.db.Save(products[2]).Error).NotTo(HaveOccurred())
+
+				// Phase 2: Exercise (Act)
+				page := builder.GetPage("/api/v1/public/products?facets=true&brand=Nike")
+
+				// Phase 3: Verify (Assert)
+				Expect(pageSlugs(page)).To(ConsistOf(products[0].Slug, products[1].Slug))
+				facets := page["facets"].(map[string]interface{})
+				Expect(facets["brands"]).To(ConsistOf(
+					facetBucket("Nike", 2),
+					facetBucket("Adidas", 1),
+				))
+				Expect(facets["colors"]).To(ConsistOf(facetBucket("red", 2)))
+				Expect(facets["tags"]).To(ConsistOf(facetBucket("summer", 2)))
+				Expect(facets["sizes"]).To(ConsistOf(facetBucket("M", 2)))
+				Expect(facets["prices"]).To(ConsistOf(HaveKeyWithValue("count", BeEquivalentTo(2))))
+				Expect(facets["in_stock"]).To(BeEquivalentTo(2))
+			})
+
+			It("should roll the counts of subcategories up to their parents", func() {
+				// Phase 1: Setup (Arrange)
+				products := builder.CreateProducts(2)
+				parentID := entity.CategoryID(products[0].CategoryID)
+				shirts := &entity.Category{Name: "Shirts", Slug: "shirts", ParentID: &parentID}
+				Expect(builder.db.Create(shirts).Error).NotTo(HaveOccurred())
+				products[1].CategoryID = uint64(shirts.ID)
+				Expect(builder.db.Save(products[1]).Error).NotTo(HaveOccurred())
+
+				// Phase 2: Exercise (Act)
+				page := builder.GetPage("/api/v1/public/products?facets=true&category=clothing")
+
+				// Phase 3: Verify (Assert)
+				Expect(pageSlugs(page)).To(ConsistOf(products[0].Slug, products[1].Slug))
+				categories := page["facets"].(map[string]interface{})["categories"].([]interface{})
+				Expect(categories).To(HaveLen(1))
+				Expect(categories[0]).To(HaveKeyWithValue("slug", "clothing"))
+				Expect(categories[0]).To(HaveKeyWithValue("count", BeEquivalentTo(2)))
+				Expect(categories[0]).To(HaveKeyWithValue("children", ConsistOf(
+					And(HaveKeyWithValue("slug", "shirts"), HaveKeyWithValue("count", BeEquivalentTo(1))),
+				)))
+			})
+		})
+
+		Context("when the price interval is not a number or narrower than the minimum", func() {
+			It("should return bad request status", func() {
+				for _, interval := range []string{"cheap", "0.01", "-5000", "NaN", "Inf"} {
+					// Phase 1: Setup (Arrange)
+					req := httptest.NewRequest(http.MethodGet, "/api/v1/public/products?facets=true&price_interval="+interval, nil)
+
+					// Phase 2: Exercise (Act)
+					resp, err := builder.app.Test(req)
+
+					// Phase 3: Verify (Assert)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp.StatusCode).To(Equal(http.StatusBadRequest), interval)
+				}
+			})
+		})
+	})
+
+	Describe("GET /api/v1/public/search/suggest", func() {
+		Context("when words of product, brand and category names start with the query", func() {
+			It("should suggest them", func() {
+				// Phase 1: Setup (Arrange)
+				products := builder.CreateProducts(3)
+				for i, item := range []struct{ name, brand string }{
+					{"Summer Shirt", "Shirtex"},
+					{"Linen shirt", "Nike"},
+					{"Winter Coat", "Adidas"},
+				} {
+					products[i].Name = item.name
+					products[i].Brand = item.brand
+					Expect(builder.db.Save(products[i]).Error).NotTo(HaveOccurred())
+				}
+				parentID := entity.CategoryID(products[0].CategoryID)
+				Expect(builder.db.Create(&entity.Category{Name: "Shirts", Slug: "shirts", ParentID: &parentID}).Error).NotTo(HaveOccurred())
+
+				// Phase 2: Exercise (Act)
+				suggestions := builder.GetPage("/api/v1/public/search/suggest?q=shi")
+
+				// Phase 3: Verify (Assert)
+				Expect(suggestions["products"]).To(ConsistOf(
+					HaveKeyWithValue("name", "Summer Shirt"),
+					HaveKeyWithValue("name", "Linen shirt"),
+				))
+				Expect(suggestions["brands"]).To(ConsistOf("Shirtex"))
+				Expect(suggestions["categories"]).To(ConsistOf(HaveKeyWithValue("slug", "shirts")))
+				Expect(suggestions["did_you_mean"]).To(BeEmpty())
+			})
+		})
+
+		Context("when the query is typed with Arabic letters", func() {
+			It("should suggest the names written with Persian ones", func() {
+				// Phase 1: Setup (Arrange)
+				products := builder.CreateProducts(2)
+				products[0].Name = "کیف چرم"
+				products[1].Name = "کفش چرم"
+				for _, product := range products {
+					Expect(builder.db.Save(product).Error).NotTo(HaveOccurred())
+				}
+
+				// Phase 2: Exercise (Act)
+				suggestions := builder.GetPage("/api/v1/public/search/suggest?q=" + url.QueryEscape("كيف"))
+
+				// Phase 3: Verify (Assert)
+				Expect(suggestions["products"]).To(ConsistOf(HaveKeyWithValue("name", "کیف چرم")))
+			})
+		})
+
+		Context("when the query is missing", func() {
+			It("should return bad request status", func() {
+				// Phase 1: Setup (Arrange)
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/public/search/suggest?q=%20", nil)
+
+				// Phase 2: Exercise (Act)
+				resp, err := builder.app.Test(req)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	Describe("GET /api/v1/public/products/featured", func() {
+		Context("when requesting featured products", func() {
+			It("should only list featured products", func() {
+				// Phase 1: Setup (Arrange)
+				products := builder.CreateProducts(3)
+				Expect(builder.db.Model(products[1]).Update("is_featured", true).Error).NotTo(HaveOccurred())
+
+				// Phase 2: Exercise (Act)
+				page := builder.GetPage("/api/v1/public/products/featured")
+
+				// Phase 3: Verify (Assert)
+				Expect(pageSlugs(page)).To(Equal([]string{products[1].Slug}))
+				Expect(page["has_more"]).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("GET /api/v1/public/products/:id/reviews", func() {
+		Context("when the product has more reviews than fit a page", func() {
+			It("should return them in pages, the newest first", func() {
+				// Phase 1: Setup (Arrange)
+				product := builder.CreateProducts(1)[0]
+				createdAt := time.Now().Add(-time.Hour)
+				for i := 0; i < 3; i++ {
+					review := &entity.Review{ProductID: product.ID, UserName: "reviewer", Rating: i + 1, Comment: "comment", CreatedAt: createdAt.Add(time.Duration(i) * time.Minute)}
+					Expect(builder.db.Create(review).Error).NotTo(HaveOccurred())
+				}
+				path := "/api/v1/public/products/" + strconv.FormatUint(uint64(product.ID), 10) + "/reviews?limit=2"
+
+				// Phase 2: Exercise (Act)
+				first := builder.GetPage(path)
+				second := builder.GetPage(path + "&cursor=" + first["next_cursor"].(string))
+
+				// Phase 3: Verify (Assert)
+				Expect(first["items"]).To(HaveLen(2))
+				Expect(first["items"].([]interface{})[0]).To(HaveKeyWithValue("rating", BeEquivalentTo(3)))
+				Expect(second["items"]).To(HaveLen(1))
+				Expect(second["items"].([]interface{})[0]).To(HaveKeyWithValue("rating", BeEquivalentTo(1)))
+				Expect(second["has_more"]).To(BeFalse())
+			})
+		})
 	})
 
 	Describe("POST /api/v1/admin/products", func() {
@@ -80,6 +354,7 @@ var _ = Describe("Product API E2E", func() {
 				Expect(err).NotTo(HaveOccurred())
 				req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/products", bytes.NewBuffer(body))
 				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Authorization", "Bearer "+builder.AccessToken("catalogmanager", builder.catalogManager))
 
 				// Phase 2: Exercise (Act)
 				resp, err := builder.app.Test(req)
@@ -89,13 +364,67 @@ var _ = Describe("Product API E2E", func() {
 				Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
 			})
 		})
+
+		Context("when the request is not authenticated", func() {
+			It("should return unauthorized status", func() {
+				// Phase 1: Setup (Arrange)
+				req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/products", bytes.NewBufferString("{}"))
+				req.Header.Set("Content-Type", "application/json")
+
+				// Phase 2: Exercise (Act)
+				resp, err := builder.app.Test(req)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+			})
+		})
+	})
+
+	Describe("DELETE /api/v1/admin/products/:id", func() {
+		Context("when an admin has not enrolled in two-factor authentication", func() {
+			It("should return forbidden status", func() {
+				// Phase 1: Setup (Arrange)
+				req := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/products/1", nil)
+				req.Header.Set("Authorization", "Bearer "+builder.AccessToken("admin", builder.admin))
+
+				// Phase 2: Exercise (Act)
+				resp, err := builder.app.Test(req)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+				var result map[string]interface{}
+				Expect(json.NewDecoder(resp.Body).Decode(&result)).To(Succeed())
+				Expect(result["error"]).To(Equal("Two-factor authentication enrollment required"))
+			})
+		})
+
+		Context("when the user lacks the products:write permission", func() {
+			It("should return forbidden status", func() {
+				// Phase 1: Setup (Arrange)
+				req := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/products/1", nil)
+				req.Header.Set("Authorization", "Bearer "+builder.AccessToken("customer"))
+
+				// Phase 2: Exercise (Act)
+				resp, err := builder.app.Test(req)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+			})
+		})
 	})
 })
 
 // ProductE2ETestBuilder helps build E2E test scenarios for products
 type ProductE2ETestBuilder struct {
-	app *fiber.App
-	db  *gorm.DB
+	app            *fiber.App
+	db             *gorm.DB
+	cfg            *config.Config
+	catalogManager *accountentity.Role
+	admin          *accountentity.Role
+	accessTokens   *accountadapter.AccessTokenKeys
 }
 
 func NewProductE2ETestBuilder() *ProductE2ETestBuilder {
@@ -108,9 +437,23 @@ func NewProductE2ETestBuilder() *ProductE2ETestBuilder {
 		&productaggregate.ProductFeature{},
 		&productaggregate.ProductDetail{},
 		&productaggregate.ProductSpec{},
+		&shared.Attachment{},
+		&entity.Review{},
+		&accountentity.User{},
+		&accountentity.Role{},
+		&accountentity.Permission{},
+		&accountentity.Session{},
 	)
 	Expect(err).NotTo(HaveOccurred())
 
+	catalogManager := &accountentity.Role{
+		Name:        accountentity.RoleCatalogManager,
+		Permissions: []*accountentity.Permission{{Name: commands.ProductsWritePermission}},
+	}
+	Expect(db.Create(catalogManager).Error).NotTo(HaveOccurred())
+	admin := &accountentity.Role{Name: accountentity.RoleAdmin, Permissions: catalogManager.Permissions}
+	Expect(db.Create(admin).Error).NotTo(HaveOccurred())
+
 	app := fiber.New(fiber.Config{
 		ErrorHandler: func(c fiber.Ctx, err error) error {
 			code := fiber.StatusInternalServerError
@@ -123,22 +466,106 @@ func NewProductE2ETestBuilder() *ProductE2ETestBuilder {
 		},
 	})
 
-	cfg := &config.Config{}
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			Secret:                    "test-secret-for-e2e",
+			AccessTokenExpireDuration: 15,
+		},
+	}
+	accessTokens, err := accountadapter.NewAccessTokenKeys(cfg.JWT)
+	Expect(err).NotTo(HaveOccurred())
+	mw := middleware.NewMiddleware(middleware.MiddlewareConfig{AccessTokens: accessTokens}, db)
+	mw.Register(app)
 
 	// Bootstrap products module
-	err = products.Bootstrap(app, db, cfg, nil)
+	err = products.Bootstrap(app, db, cfg, nil, mw)
 	Expect(err).NotTo(HaveOccurred())
 
 	return &ProductE2ETestBuilder{
-		app: app,
-		db:  db,
+		app:            app,
+		db:             db,
+		cfg:            cfg,
+		catalogManager: catalogManager,
+		admin:          admin,
+		accessTokens:   accessTokens,
+	}
+}
+
+// AccessToken creates a user with the given roles and an active session for it,
+// and returns a signed access token of that session.
+func (b *ProductE2ETestBuilder) AccessToken(username string, roles ...*accountentity.Role) string {
+	user := &accountentity.User{
+		AvatarIdentifier: "avatar123",
+		UserName:         username,
+		Email:            username + "@example.com",
+		Password:         "hashed",
+		Roles:            roles,
 	}
+	Expect(b.db.Create(user).Error).NotTo(HaveOccurred())
+
+	session := accountentity.NewSession(user.ID, "e2e", "127.0.0.1", time.Now().Add(time.Hour))
+	Expect(b.db.Create(session).Error).NotTo(HaveOccurred())
+
+	token, err := b.accessTokens.Generate(time.Now().Add(time.Hour), uint64(user.ID), uint64(session.ID))
+	Expect(err).NotTo(HaveOccurred())
+	return token
+}
+
+// CreateProducts creates n products of one category, each a minute newer than
+// the one before, and returns them oldest first.
+func (b *ProductE2ETestBuilder) CreateProducts(n int) []*productaggregate.Product {
+	category := &entity.Category{Name: "Clothing", Slug: "clothing"}
+	Expect(b.db.Create(category).Error).NotTo(HaveOccurred())
+
+	createdAt := time.Now().Add(-time.Hour)
+	products := make([]*productaggregate.Product, n)
+	for i := range products {
+		products[i] = &productaggregate.Product{
+			Name:       "Product " + strconv.Itoa(i),
+			Slug:       "product-" + strconv.Itoa(i),
+			CategoryID: uint64(category.ID),
+			CreatedAt:  createdAt.Add(time.Duration(i) * time.Minute),
+		}
+		Expect(b.db.Create(products[i]).Error).NotTo(HaveOccurred())
+	}
+	return products
+}
+
+// GetPage requests a page of a list endpoint, or any other public endpoint,
+// and returns its data.
+func (b *ProductE2ETestBuilder) GetPage(path string) map[string]interface{} {
+	resp, err := b.app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+	var result map[string]interface{}
+	Expect(json.NewDecoder(resp.Body).Decode(&result)).To(Succeed())
+	return result["data"].(map[string]interface{})
+}
+
+// pageSlugs returns the slugs of the products of a page, in order.
+func pageSlugs(page map[string]interface{}) []string {
+	slugs := []string{}
+	for _, item := range page["items"].([]interface{}) {
+		slugs = append(slugs, item.(map[string]interface{})["slug"].(string))
+	}
+	return slugs
+}
+
+// facetBucket matches a facet bucket with the value and count.
+func facetBucket(value string, count int) types.GomegaMatcher {
+	return And(HaveKeyWithValue("value", value), HaveKeyWithValue("count", BeEquivalentTo(count)))
 }
 
 func (b *ProductE2ETestBuilder) Cleanup() {
+	b.db.Exec("DELETE FROM reviews")
 	b.db.Exec("DELETE FROM products")
 	b.db.Exec("DELETE FROM categories")
 	b.db.Exec("DELETE FROM product_features")
+	b.db.Exec("DELETE FROM attachments")
 	b.db.Exec("DELETE FROM product_details")
 	b.db.Exec("DELETE FROM product_specs")
+	b.db.Exec("DELETE FROM user_roles")
+	b.db.Exec("DELETE FROM sessions")
+	b.db.Exec("DELETE FROM users")
 }
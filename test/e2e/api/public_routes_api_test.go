@@ -0,0 +1,278 @@
+package e2e_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"shikposh-backend/config"
+	account "shikposh-backend/internal/account"
+	accountadapter "shikposh-backend/internal/account/adapter"
+	accountentity "shikposh-backend/internal/account/domain/entity"
+	products "shikposh-backend/internal/products"
+	"shikposh-backend/internal/products/domain/entity"
+	productaggregate "shikposh-backend/internal/products/domain/entity/product_aggregate"
+	"shikposh-backend/internal/products/domain/entity/shared"
+	"shikposh-backend/pkg/middleware"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("Public and private routes E2E", func() {
+	var (
+		builder *PublicRoutesE2ETestBuilder
+	)
+
+	BeforeEach(func() {
+		builder = NewPublicRoutesE2ETestBuilder()
+	})
+
+	Describe("anonymous catalog browsing", func() {
+		DescribeTable("should serve public routes without a token",
+			func(path string) {
+				// Phase 1: Setup (Arrange)
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+
+				// Phase 2: Exercise (Act)
+				resp, err := builder.app.Test(req)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			},
+			Entry("products", "/api/v1/public/products"),
+			Entry("product by slug", "/api/v1/public/products/mens-t-shirt"),
+			Entry("categories", "/api/v1/public/categories"),
+			Entry("product reviews", "/api/v1/public/products/1/reviews"),
+		)
+
+		It("should list the catalog for anonymous users", func() {
+			// Phase 1: Setup (Arrange)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/public/products", nil)
+
+			// Phase 2: Exercise (Act)
+			resp, err := builder.app.Test(req)
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			var result map[string]interface{}
+			Expect(json.NewDecoder(resp.Body).Decode(&result)).To(Succeed())
+			Expect(result["data"]).To(HaveKeyWithValue("items", HaveLen(1)))
+		})
+
+		It("should ignore an invalid token on public routes", func() {
+			// Phase 1: Setup (Arrange)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/public/products", nil)
+			req.Header.Set("Authorization", "Bearer not-a-token")
+
+			// Phase 2: Exercise (Act)
+			resp, err := builder.app.Test(req)
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		It("should let anonymous users register", func() {
+			// Phase 1: Setup (Arrange)
+			body, _ := json.Marshal(map[string]string{
+				"avatar_identifier": "avatar123",
+				"user_name":         "anonymous",
+				"first_name":        "Anon",
+				"last_name":         "User",
+				"email":             "anonymous@example.com",
+				"password":          "password123",
+			})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/public/register", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			// Phase 2: Exercise (Act)
+			resp, err := builder.app.Test(req)
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+		})
+	})
+
+	Describe("private routes", func() {
+		It("should reject logout without a token", func() {
+			// Phase 1: Setup (Arrange)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/public/logout", nil)
+
+			// Phase 2: Exercise (Act)
+			resp, err := builder.app.Test(req)
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should reject admin routes without a token", func() {
+			// Phase 1: Setup (Arrange)
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/products/1", nil)
+
+			// Phase 2: Exercise (Act)
+			resp, err := builder.app.Test(req)
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Describe("POST /api/v1/public/reviews", func() {
+		It("should attribute a review to the signed-in user, not to the user_id in the body", func() {
+			// Phase 1: Setup (Arrange)
+			userID, token := builder.SignIn("reviewer")
+			req := builder.reviewRequest(999)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			// Phase 2: Exercise (Act)
+			resp, err := builder.app.Test(req)
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+			var review entity.Review
+			Expect(builder.db.First(&review).Error).NotTo(HaveOccurred())
+			Expect(review.UserID).To(Equal(accountentity.UserID(userID)))
+		})
+
+		It("should reject an anonymous review that claims a user_id", func() {
+			// Phase 1: Setup (Arrange)
+			req := builder.reviewRequest(999)
+
+			// Phase 2: Exercise (Act)
+			resp, err := builder.app.Test(req)
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+			var count int64
+			Expect(builder.db.Model(&entity.Review{}).Count(&count).Error).NotTo(HaveOccurred())
+			Expect(count).To(BeZero())
+		})
+
+		It("should reject a review sent with an invalid token", func() {
+			// Phase 1: Setup (Arrange)
+			req := builder.reviewRequest(999)
+			req.Header.Set("Authorization", "Bearer not-a-token")
+
+			// Phase 2: Exercise (Act)
+			resp, err := builder.app.Test(req)
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+	})
+})
+
+// PublicRoutesE2ETestBuilder boots both modules behind the shared middleware stack
+type PublicRoutesE2ETestBuilder struct {
+	app          *fiber.App
+	db           *gorm.DB
+	cfg          *config.Config
+	product      *productaggregate.Product
+	accessTokens *accountadapter.AccessTokenKeys
+}
+
+func NewPublicRoutesE2ETestBuilder() *PublicRoutesE2ETestBuilder {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	Expect(err).NotTo(HaveOccurred())
+
+	err = db.AutoMigrate(
+		&accountentity.User{},
+		&accountentity.Profile{},
+		&accountentity.Role{},
+		&accountentity.Permission{},
+		&accountentity.Session{},
+		&accountentity.RefreshToken{},
+		&accountentity.AuditEvent{},
+		&entity.Category{},
+		&entity.Review{},
+		&productaggregate.Product{},
+		&productaggregate.ProductFeature{},
+		&productaggregate.ProductDetail{},
+		&productaggregate.ProductSpec{},
+		&shared.Attachment{},
+	)
+	Expect(err).NotTo(HaveOccurred())
+
+	category := &entity.Category{Name: "Clothing", Slug: "clothing"}
+	Expect(db.Create(category).Error).NotTo(HaveOccurred())
+	product := &productaggregate.Product{
+		Name:       "Men's T-Shirt",
+		Slug:       "mens-t-shirt",
+		Brand:      "Test Brand",
+		CategoryID: uint64(category.ID),
+	}
+	Expect(db.Create(product).Error).NotTo(HaveOccurred())
+
+	app := fiber.New()
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			Secret:                    "test-secret-for-e2e",
+			AccessTokenExpireDuration: 15,
+		},
+		Storage: config.StorageConfig{
+			Dir: GinkgoT().TempDir(),
+		},
+	}
+
+	accessTokens, err := accountadapter.NewAccessTokenKeys(cfg.JWT)
+	Expect(err).NotTo(HaveOccurred())
+	mw := middleware.NewMiddleware(middleware.MiddlewareConfig{AccessTokens: accessTokens}, db)
+	mw.Register(app)
+
+	Expect(account.Bootstrap(app, db, cfg, mw)).To(Succeed())
+	Expect(products.Bootstrap(app, db, cfg, nil, mw)).To(Succeed())
+
+	return &PublicRoutesE2ETestBuilder{
+		app:          app,
+		db:           db,
+		cfg:          cfg,
+		product:      product,
+		accessTokens: accessTokens,
+	}
+}
+
+// SignIn creates a user with an active session and returns its ID and access token.
+func (b *PublicRoutesE2ETestBuilder) SignIn(username string) (uint64, string) {
+	user := &accountentity.User{
+		AvatarIdentifier: "avatar123",
+		UserName:         username,
+		Email:            username + "@example.com",
+		Password:         "hashed",
+	}
+	Expect(b.db.Create(user).Error).NotTo(HaveOccurred())
+
+	session := accountentity.NewSession(user.ID, "e2e", "127.0.0.1", time.Now().Add(time.Hour))
+	Expect(b.db.Create(session).Error).NotTo(HaveOccurred())
+
+	token, err := b.accessTokens.Generate(time.Now().Add(time.Hour), uint64(user.ID), uint64(session.ID))
+	Expect(err).NotTo(HaveOccurred())
+	return uint64(user.ID), token
+}
+
+// reviewRequest builds a review submission whose body claims the given user ID.
+func (b *PublicRoutesE2ETestBuilder) reviewRequest(claimedUserID uint64) *http.Request {
+	body, _ := json.Marshal(map[string]interface{}{
+		"product_id": uint64(b.product.ID),
+		"user_id":    claimedUserID,
+		"user_name":  "reviewer",
+		"rating":     5,
+		"comment":    "Great quality and fits well",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/public/reviews", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
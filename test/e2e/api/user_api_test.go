@@ -3,12 +3,25 @@ package e2e_test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"shikposh-backend/config"
 	account "shikposh-backend/internal/account"
+	"shikposh-backend/internal/account/adapter"
 	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	productentity "shikposh-backend/internal/products/domain/entity"
+	"shikposh-backend/pkg/middleware"
+	"shikposh-backend/test/integration/testdouble/fakes"
 
 	"github.com/gofiber/fiber/v3"
 	. "github.com/onsi/ginkgo/v2"
@@ -130,16 +143,855 @@ var _ = Describe("User API E2E", func() {
 				Expect(result["data"]).NotTo(BeNil())
 				if data, ok := result["data"].(map[string]interface{}); ok {
 					Expect(data["access"]).NotTo(BeEmpty())
+					Expect(data["refresh"]).NotTo(BeEmpty())
 				}
 			})
 		})
 	})
+
+	Describe("POST /api/v1/public/token/refresh", func() {
+		Context("when refresh token is valid", func() {
+			It("should return a new token pair and reject the old refresh token", func() {
+				// Phase 1: Setup (Arrange) - Register and login user
+				registerCmd := commands.RegisterUser{
+					AvatarIdentifier: "avatar123",
+					UserName:         "refreshuser",
+					FirstName:        "Test",
+					LastName:         "User",
+					Email:            "refresh@example.com",
+					Password:         "password123",
+				}
+				registerBody, _ := json.Marshal(registerCmd)
+				registerReq := httptest.NewRequest(http.MethodPost, "/api/v1/public/register", bytes.NewBuffer(registerBody))
+				registerReq.Header.Set("Content-Type", "application/json")
+				builder.app.Test(registerReq)
+
+				loginBody, _ := json.Marshal(commands.LoginUser{UserName: "refreshuser", Password: "password123"})
+				loginReq := httptest.NewRequest(http.MethodPost, "/api/v1/public/login", bytes.NewBuffer(loginBody))
+				loginReq.Header.Set("Content-Type", "application/json")
+				loginResp, err := builder.app.Test(loginReq)
+				Expect(err).NotTo(HaveOccurred())
+				refreshToken := builder.decodeData(loginResp)["refresh"].(string)
+
+				// Phase 2: Exercise (Act)
+				resp := builder.refresh(refreshToken)
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				data := builder.decodeData(resp)
+				Expect(data["access"]).NotTo(BeEmpty())
+				Expect(data["refresh"]).NotTo(Equal(refreshToken))
+
+				reuseResp := builder.refresh(refreshToken)
+				Expect(reuseResp.StatusCode).To(Equal(http.StatusUnauthorized))
+			})
+		})
+	})
+
+	Describe("POST /api/v1/public/logout", func() {
+		Context("when the access token is valid", func() {
+			It("should end the session and reject its refresh token", func() {
+				// Phase 1: Setup (Arrange)
+				tokens := builder.registerAndLogin("logoutuser")
+				req := httptest.NewRequest(http.MethodPost, "/api/v1/public/logout", nil)
+				req.Header.Set("Authorization", "Bearer "+tokens["access"].(string))
+
+				// Phase 2: Exercise (Act)
+				resp, err := builder.app.Test(req)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+				refreshResp := builder.refresh(tokens["refresh"].(string))
+				Expect(refreshResp.StatusCode).To(Equal(http.StatusUnauthorized))
+			})
+		})
+
+		Context("when the access token is invalid", func() {
+			It("should return unauthorized", func() {
+				// Phase 1: Setup (Arrange)
+				req := httptest.NewRequest(http.MethodPost, "/api/v1/public/logout", nil)
+				req.Header.Set("Authorization", "Bearer not-a-token")
+
+				// Phase 2: Exercise (Act)
+				resp, err := builder.app.Test(req)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+			})
+		})
+	})
+
+	Describe("POST /api/v1/public/verify-email", func() {
+		Context("when the token from the registration mail is used", func() {
+			It("should verify the email and allow login when verification is required", func() {
+				// Phase 1: Setup (Arrange)
+				builder.cfg.Verification.RequireVerifiedEmail = true
+				builder.register("verifyuser")
+				Expect(builder.login("verifyuser").StatusCode).To(Equal(http.StatusForbidden))
+				token := builder.latestMailToken("verifyuser@example.com")
+
+				// Phase 2: Exercise (Act)
+				resp := builder.post("/api/v1/public/verify-email", commands.VerifyEmail{Token: token})
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+				Expect(builder.login("verifyuser").StatusCode).To(Equal(http.StatusOK))
+				reuseResp := builder.post("/api/v1/public/verify-email", commands.VerifyEmail{Token: token})
+				Expect(reuseResp.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		Context("when the token is unknown", func() {
+			It("should return bad request", func() {
+				// Phase 1: Setup (Arrange)
+				payload := commands.VerifyEmail{Token: "unknown-token"}
+
+				// Phase 2: Exercise (Act)
+				resp := builder.post("/api/v1/public/verify-email", payload)
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	Describe("POST /api/v1/public/verify-email/resend", func() {
+		Context("when a verification mail was just sent", func() {
+			It("should return too many requests", func() {
+				// Phase 1: Setup (Arrange)
+				builder.register("resenduser")
+				builder.latestMailToken("resenduser@example.com")
+
+				// Phase 2: Exercise (Act)
+				resp := builder.post("/api/v1/public/verify-email/resend", commands.ResendVerificationEmail{Email: "resenduser@example.com"})
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusTooManyRequests))
+				Expect(builder.mailsTo("resenduser@example.com")).To(HaveLen(1))
+			})
+		})
+
+		Context("when the email is not registered", func() {
+			It("should accept the request without sending a mail", func() {
+				// Phase 1: Setup (Arrange)
+				payload := commands.ResendVerificationEmail{Email: "nobody@example.com"}
+
+				// Phase 2: Exercise (Act)
+				resp := builder.post("/api/v1/public/verify-email/resend", payload)
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+				Expect(builder.mailsTo("nobody@example.com")).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("POST /api/v1/public/password/reset", func() {
+		Context("when the token from the reset mail is used", func() {
+			It("should change the password and log out every device", func() {
+				// Phase 1: Setup (Arrange)
+				tokens := builder.registerAndLogin("resetuser")
+				forgotResp := builder.post("/api/v1/public/password/forgot", commands.RequestPasswordReset{Email: "resetuser@example.com"})
+				Expect(forgotResp.StatusCode).To(Equal(http.StatusNoContent))
+				token := builder.latestMailToken("resetuser@example.com")
+
+				// Phase 2: Exercise (Act)
+				resp := builder.post("/api/v1/public/password/reset", commands.ResetPassword{Token: token, NewPassword: "newpassword456"})
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+				Expect(builder.refresh(tokens["refresh"].(string)).StatusCode).To(Equal(http.StatusUnauthorized))
+				Expect(builder.loginWithPassword("resetuser", "password123").StatusCode).To(Equal(http.StatusUnauthorized))
+				Expect(builder.loginWithPassword("resetuser", "newpassword456").StatusCode).To(Equal(http.StatusOK))
+				reuseResp := builder.post("/api/v1/public/password/reset", commands.ResetPassword{Token: token, NewPassword: "otherpassword789"})
+				Expect(reuseResp.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		Context("when the new password breaks the password rules", func() {
+			It("should return bad request", func() {
+				// Phase 1: Setup (Arrange)
+				builder.register("weakresetuser")
+				builder.post("/api/v1/public/password/forgot", commands.RequestPasswordReset{Email: "weakresetuser@example.com"})
+				token := builder.latestMailToken("weakresetuser@example.com")
+
+				// Phase 2: Exercise (Act)
+				resp := builder.post("/api/v1/public/password/reset", commands.ResetPassword{Token: token, NewPassword: "abc"})
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+				Expect(builder.login("weakresetuser").StatusCode).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
+	Describe("POST /api/v1/public/password/change", func() {
+		Context("when the current password is correct", func() {
+			It("should change the password and revoke the current session", func() {
+				// Phase 1: Setup (Arrange)
+				tokens := builder.registerAndLogin("changeuser")
+				payload := commands.ChangePassword{CurrentPassword: "password123", NewPassword: "newpassword456"}
+
+				// Phase 2: Exercise (Act)
+				resp := builder.postAuthorized("/api/v1/public/password/change", tokens["access"].(string), payload)
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+				logoutResp := builder.postAuthorized("/api/v1/public/logout", tokens["access"].(string), nil)
+				Expect(logoutResp.StatusCode).To(Equal(http.StatusUnauthorized))
+				Expect(builder.loginWithPassword("changeuser", "newpassword456").StatusCode).To(Equal(http.StatusOK))
+			})
+		})
+
+		Context("when the request is anonymous", func() {
+			It("should return unauthorized", func() {
+				// Phase 1: Setup (Arrange)
+				payload := commands.ChangePassword{CurrentPassword: "password123", NewPassword: "newpassword456"}
+
+				// Phase 2: Exercise (Act)
+				resp := builder.post("/api/v1/public/password/change", payload)
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+			})
+		})
+	})
+
+	Describe("POST /api/v1/public/otp/request", func() {
+		Context("when a code is requested twice within the limiter window", func() {
+			It("should send the first code and throttle the second request", func() {
+				// Phase 1: Setup (Arrange)
+				payload := commands.RequestOtp{Phone: "09121234567"}
+
+				// Phase 2: Exercise (Act)
+				firstResp := builder.post("/api/v1/public/otp/request", payload)
+				secondResp := builder.post("/api/v1/public/otp/request", payload)
+
+				// Phase 3: Verify (Assert)
+				Expect(firstResp.StatusCode).To(Equal(http.StatusNoContent))
+				Expect(secondResp.StatusCode).To(Equal(http.StatusTooManyRequests))
+			})
+		})
+
+		Context("when the phone is not a mobile number", func() {
+			It("should return bad request", func() {
+				// Phase 1: Setup (Arrange)
+				payload := commands.RequestOtp{Phone: "not-a-phone"}
+
+				// Phase 2: Exercise (Act)
+				resp := builder.post("/api/v1/public/otp/request", payload)
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	Describe("POST /api/v1/public/otp/verify", func() {
+		Context("when the code is wrong", func() {
+			It("should return unauthorized", func() {
+				// Phase 1: Setup (Arrange)
+				builder.post("/api/v1/public/otp/request", commands.RequestOtp{Phone: "09127654321"})
+				payload := commands.VerifyOtp{Phone: "09127654321", Code: "0000000"}
+
+				// Phase 2: Exercise (Act)
+				resp := builder.post("/api/v1/public/otp/verify", payload)
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+			})
+		})
+	})
+
+	Describe("POST /api/v1/public/account/unlock", func() {
+		Context("when the account was locked after too many failed logins", func() {
+			It("should unlock it with the token from the lockout mail", func() {
+				// Phase 1: Setup (Arrange)
+				builder.register("lockeduser")
+				for range 3 {
+					Expect(builder.loginWithPassword("lockeduser", "wrongpassword").StatusCode).To(Equal(http.StatusUnauthorized))
+				}
+				Expect(builder.login("lockeduser").StatusCode).To(Equal(http.StatusForbidden))
+				// The registration sent the verification mail first
+				Eventually(func() []string { return builder.mailsTo("lockeduser@example.com") }).Should(HaveLen(2))
+				payload := commands.UnlockAccount{Token: builder.latestMailToken("lockeduser@example.com")}
+
+				// Phase 2: Exercise (Act)
+				resp := builder.post("/api/v1/public/account/unlock", payload)
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+				Expect(builder.login("lockeduser").StatusCode).To(Equal(http.StatusOK))
+				Expect(builder.post("/api/v1/public/account/unlock", payload).StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	Describe("POST /api/v1/public/login/2fa", func() {
+		Context("when the user enabled two-factor authentication", func() {
+			It("should answer the login with a challenge and open the session for a valid code", func() {
+				// Phase 1: Setup (Arrange)
+				secret, _ := builder.enableTwoFactor("totpuser")
+				loginData := builder.decodeData(builder.login("totpuser"))
+				Expect(loginData["two_factor_required"]).To(BeTrue())
+				Expect(loginData).NotTo(HaveKey("access"))
+				// The current time step was used by the confirmation.
+				code, err := adapter.TotpCode(secret, time.Now().Add(30*time.Second))
+				Expect(err).NotTo(HaveOccurred())
+				payload := commands.VerifyTwoFactor{ChallengeToken: loginData["challenge_token"].(string), Code: code}
+
+				// Phase 2: Exercise (Act)
+				resp := builder.post("/api/v1/public/login/2fa", payload)
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				data := builder.decodeData(resp)
+				Expect(data["access"]).NotTo(BeEmpty())
+				Expect(data["refresh"]).NotTo(BeEmpty())
+				Expect(builder.post("/api/v1/public/login/2fa", payload).StatusCode).To(Equal(http.StatusUnauthorized))
+			})
+		})
+
+		Context("when a recovery code is used a second time", func() {
+			It("should return unauthorized", func() {
+				// Phase 1: Setup (Arrange)
+				_, recoveryCodes := builder.enableTwoFactor("recoveryuser")
+				first := builder.decodeData(builder.login("recoveryuser"))
+				firstResp := builder.post("/api/v1/public/login/2fa", commands.VerifyTwoFactor{ChallengeToken: first["challenge_token"].(string), Code: recoveryCodes[0]})
+				Expect(firstResp.StatusCode).To(Equal(http.StatusOK))
+				second := builder.decodeData(builder.login("recoveryuser"))
+
+				// Phase 2: Exercise (Act)
+				resp := builder.post("/api/v1/public/login/2fa", commands.VerifyTwoFactor{ChallengeToken: second["challenge_token"].(string), Code: recoveryCodes[0]})
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+			})
+		})
+	})
+
+	Describe("/api/v1/me", func() {
+		Context("when the user is not authenticated", func() {
+			It("should return unauthorized", func() {
+				// Phase 1: Setup (Arrange)
+				accessToken := ""
+
+				// Phase 2: Exercise (Act)
+				resp := builder.request(http.MethodGet, "/api/v1/me", accessToken, nil)
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+			})
+		})
+
+		Context("when the user changes their email address", func() {
+			It("should mark it unverified and mail a verification link to it", func() {
+				// Phase 1: Setup (Arrange)
+				accessToken := builder.registerAndLogin("emailchanger")["access"].(string)
+				verifyResp := builder.post("/api/v1/public/verify-email", commands.VerifyEmail{Token: builder.latestMailToken("emailchanger@example.com")})
+				Expect(verifyResp.StatusCode).To(Equal(http.StatusNoContent))
+				email := "changed@example.com"
+
+				// Phase 2: Exercise (Act)
+				resp := builder.request(http.MethodPatch, "/api/v1/me", accessToken, commands.UpdateUser{Email: &email})
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				data := builder.decodeData(resp)
+				Expect(data["email"]).To(Equal(email))
+				Expect(data["email_verified"]).To(BeFalse())
+				Expect(data).NotTo(HaveKey("password"))
+				token := builder.latestMailToken(email)
+				Expect(builder.post("/api/v1/public/verify-email", commands.VerifyEmail{Token: token}).StatusCode).To(Equal(http.StatusNoContent))
+				me := builder.decodeData(builder.request(http.MethodGet, "/api/v1/me", accessToken, nil))
+				Expect(me["email_verified"]).To(BeTrue())
+			})
+		})
+
+		Context("when the user closes their account", func() {
+			It("should sign them out, block their login and anonymise their reviews", func() {
+				// Phase 1: Setup (Arrange)
+				tokens := builder.registerAndLogin("leavinguser")
+				accessToken := tokens["access"].(string)
+				me := builder.decodeData(builder.request(http.MethodGet, "/api/v1/me", accessToken, nil))
+				review := &productentity.Review{UserID: entity.UserID(me["id"].(float64)), UserName: "leavinguser", Rating: 5, Comment: "Great"}
+				Expect(builder.db.Create(review).Error).NotTo(HaveOccurred())
+
+				// Phase 2: Exercise (Act)
+				wrongResp := builder.request(http.MethodDelete, "/api/v1/me", accessToken, commands.DeleteAccount{Password: "wrong-password"})
+				resp := builder.request(http.MethodDelete, "/api/v1/me", accessToken, commands.DeleteAccount{Password: "password123"})
+
+				// Phase 3: Verify (Assert)
+				Expect(wrongResp.StatusCode).To(Equal(http.StatusBadRequest))
+				Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+				Expect(builder.request(http.MethodGet, "/api/v1/me", accessToken, nil).StatusCode).To(Equal(http.StatusUnauthorized))
+				loginResp := builder.post("/api/v1/public/login", commands.LoginUser{UserName: "leavinguser", Password: "password123"})
+				Expect(loginResp.StatusCode).To(Equal(http.StatusNotFound))
+				var stored productentity.Review
+				Expect(builder.db.First(&stored, review.ID).Error).NotTo(HaveOccurred())
+				Expect(stored.UserName).To(Equal("Deleted user"))
+				var user entity.User
+				Expect(builder.db.Unscoped().First(&user, uint64(me["id"].(float64))).Error).NotTo(HaveOccurred())
+				Expect(user.DeletedAt.Valid).To(BeTrue())
+				Expect(user.PurgeAfter).NotTo(BeNil())
+			})
+		})
+	})
+
+	Describe("GET /api/v1/me/export", func() {
+		Context("when the user downloads their data", func() {
+			It("should return a JSON attachment with their account and addresses", func() {
+				// Phase 1: Setup (Arrange)
+				accessToken := builder.registerAndLogin("exportuser")["access"].(string)
+				created := builder.postAuthorized("/api/v1/me/addresses", accessToken, commands.SaveAddress{
+					RecipientName: "Export User",
+					Phone:         "09121234567",
+					Province:      "Tehran",
+					City:          "Tehran",
+					PostalCode:    "1193653471",
+					Street:        "Valiasr St.",
+					Plate:         "12",
+				})
+				Expect(created.StatusCode).To(Equal(http.StatusOK))
+
+				// Phase 2: Exercise (Act)
+				resp := builder.request(http.MethodGet, "/api/v1/me/export", accessToken, nil)
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				Expect(resp.Header.Get("Content-Disposition")).To(HavePrefix("attachment; filename="))
+				var export map[string]interface{}
+				Expect(json.NewDecoder(resp.Body).Decode(&export)).To(Succeed())
+				Expect(export["user"].(map[string]interface{})["user_name"]).To(Equal("exportuser"))
+				Expect(export["addresses"]).To(HaveLen(1))
+				Expect(export["reviews"]).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("/api/v1/me/profile", func() {
+		Context("when the user sets a phone number in local form", func() {
+			It("should store it in E.164 form", func() {
+				// Phase 1: Setup (Arrange)
+				accessToken := builder.registerAndLogin("profileuser")["access"].(string)
+				bio := "Hello"
+				phone := "0912 765 4321"
+
+				// Phase 2: Exercise (Act)
+				resp := builder.request(http.MethodPatch, "/api/v1/me/profile", accessToken, commands.UpdateProfile{Bio: &bio, Phone: &phone})
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				profile := builder.decodeData(builder.request(http.MethodGet, "/api/v1/me/profile", accessToken, nil))
+				Expect(profile["bio"]).To(Equal(bio))
+				Expect(profile["phone"]).To(Equal("+989127654321"))
+			})
+		})
+	})
+	Describe("/api/v1/admin/users", func() {
+		Context("when support staff looks up a user by email", func() {
+			It("should list the user and show their active sessions", func() {
+				// Phase 1: Setup (Arrange)
+				builder.registerAndLogin("customer")
+				staffToken := builder.registerAndLogin("supportstaff")["access"].(string)
+				builder.grantPermissions("supportstaff", commands.UsersReadPermission)
+
+				// Phase 2: Exercise (Act)
+				listResp := builder.request(http.MethodGet, "/api/v1/admin/users?q=CUSTOMER@example&limit=10", staffToken, nil)
+
+				// Phase 3: Verify (Assert)
+				Expect(listResp.StatusCode).To(Equal(http.StatusOK))
+				var list struct {
+					Data       []map[string]interface{} `json:"data"`
+					Pagination map[string]interface{}   `json:"pagination"`
+				}
+				Expect(json.NewDecoder(listResp.Body).Decode(&list)).To(Succeed())
+				Expect(list.Data).To(HaveLen(1))
+				Expect(list.Data[0]["user_name"]).To(Equal("customer"))
+				Expect(list.Pagination["total"]).To(BeEquivalentTo(1))
+				detail := builder.decodeData(builder.request(http.MethodGet, fmt.Sprintf("/api/v1/admin/users/%v", list.Data[0]["id"]), staffToken, nil))
+				Expect(detail["sessions"]).To(HaveLen(1))
+				Expect(detail).NotTo(HaveKey("password"))
+			})
+		})
+
+		Context("when an administrator disables and enables a user", func() {
+			It("should sign the user out and refuse their logins until enabled", func() {
+				// Phase 1: Setup (Arrange)
+				customerToken := builder.registerAndLogin("customer")["access"].(string)
+				customer := builder.decodeData(builder.request(http.MethodGet, "/api/v1/me", customerToken, nil))
+				staffToken := builder.registerAndLogin("supportstaff")["access"].(string)
+				builder.grantPermissions("supportstaff", commands.UsersReadPermission, commands.UsersWritePermission)
+				userPath := fmt.Sprintf("/api/v1/admin/users/%v", customer["id"])
+
+				// Phase 2: Exercise (Act)
+				disableResp := builder.postAuthorized(userPath+"/disable", staffToken, nil)
+				disabledLoginResp := builder.login("customer")
+				enableResp := builder.postAuthorized(userPath+"/enable", staffToken, nil)
+
+				// Phase 3: Verify (Assert)
+				Expect(disableResp.StatusCode).To(Equal(http.StatusNoContent))
+				Expect(disabledLoginResp.StatusCode).To(Equal(http.StatusForbidden))
+				Expect(builder.request(http.MethodGet, "/api/v1/me", customerToken, nil).StatusCode).To(Equal(http.StatusUnauthorized))
+				Expect(enableResp.StatusCode).To(Equal(http.StatusNoContent))
+				Expect(builder.login("customer").StatusCode).To(Equal(http.StatusOK))
+			})
+		})
+
+		Context("when a user without users:write changes roles", func() {
+			It("should return forbidden", func() {
+				// Phase 1: Setup (Arrange)
+				builder.register("customer")
+				staffToken := builder.registerAndLogin("supportstaff")["access"].(string)
+				builder.grantPermissions("supportstaff", commands.UsersReadPermission)
+
+				// Phase 2: Exercise (Act)
+				resp := builder.request(http.MethodPut, "/api/v1/admin/users/1/roles", staffToken, commands.ChangeUserRoles{Roles: []string{"admin"}})
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+			})
+		})
+	})
+
+	Describe("GET /api/v1/admin/audit", func() {
+		Context("when an auditor searches the logins of a user", func() {
+			It("should list the failed and successful logins with their request details", func() {
+				// Phase 1: Setup (Arrange)
+				builder.register("customer")
+				Expect(builder.loginWithPassword("customer", "wrong-password").StatusCode).To(Equal(http.StatusUnauthorized))
+				Expect(builder.login("customer").StatusCode).To(Equal(http.StatusOK))
+				auditorToken := builder.registerAndLogin("auditor")["access"].(string)
+				builder.grantPermissions("auditor", commands.AuditReadPermission)
+
+				// Phase 2: Exercise (Act)
+				resp := builder.request(http.MethodGet, "/api/v1/admin/audit?action=user.login&target_id=customer", auditorToken, nil)
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				var list struct {
+					Data []map[string]interface{} `json:"data"`
+				}
+				Expect(json.NewDecoder(resp.Body).Decode(&list)).To(Succeed())
+				Expect(list.Data).To(HaveLen(2))
+				Expect(list.Data[0]["outcome"]).To(Equal("success"))
+				Expect(list.Data[1]["outcome"]).To(Equal("denied"))
+				Expect(list.Data[1]["reason"]).To(Equal("UNAUTHORIZED"))
+				Expect(list.Data[1]["target_type"]).To(Equal("user_name"))
+				Expect(list.Data[1]["ip"]).NotTo(BeEmpty())
+			})
+		})
+
+		Context("when an administrator acts on a user", func() {
+			It("should record the administrator as the actor and the user as the target", func() {
+				// Phase 1: Setup (Arrange)
+				builder.register("customer")
+				var customer entity.User
+				Expect(builder.db.Where("user_name = ?", "customer").First(&customer).Error).NotTo(HaveOccurred())
+				staffToken := builder.registerAndLogin("supportstaff")["access"].(string)
+				staff := builder.decodeData(builder.request(http.MethodGet, "/api/v1/me", staffToken, nil))
+				builder.grantPermissions("supportstaff", commands.UsersReadPermission, commands.UsersWritePermission, commands.AuditReadPermission)
+				Expect(builder.postAuthorized(fmt.Sprintf("/api/v1/admin/users/%d/disable", customer.ID), staffToken, nil).StatusCode).To(Equal(http.StatusNoContent))
+
+				// Phase 2: Exercise (Act)
+				resp := builder.request(http.MethodGet, fmt.Sprintf("/api/v1/admin/audit?actor_type=user&actor_id=%v&target_type=user", staff["id"]), staffToken, nil)
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				var list struct {
+					Data []map[string]interface{} `json:"data"`
+				}
+				Expect(json.NewDecoder(resp.Body).Decode(&list)).To(Succeed())
+				Expect(list.Data).To(HaveLen(1))
+				Expect(list.Data[0]["action"]).To(Equal("admin.user.disable"))
+				Expect(list.Data[0]["target_id"]).To(Equal(fmt.Sprint(customer.ID)))
+				Expect(list.Data[0]["outcome"]).To(Equal("success"))
+			})
+		})
+
+		Context("when the user lacks audit:read", func() {
+			It("should return forbidden", func() {
+				// Phase 1: Setup (Arrange)
+				accessToken := builder.registerAndLogin("customer")["access"].(string)
+
+				// Phase 2: Exercise (Act)
+				resp := builder.request(http.MethodGet, "/api/v1/admin/audit", accessToken, nil)
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+			})
+		})
+	})
+
+	Describe("/api/v1/admin/service-accounts", func() {
+		Context("when an administrator issues an API key to a service account", func() {
+			It("should authenticate the key with the scopes of the account until it is revoked", func() {
+				// Phase 1: Setup (Arrange)
+				adminToken := builder.registerAndLogin("keyadmin")["access"].(string)
+				builder.grantPermissions("keyadmin", commands.ServiceAccountsWritePermission, commands.UsersReadPermission, commands.UsersWritePermission)
+				account := builder.decodeData(builder.postAuthorized("/api/v1/admin/service-accounts", adminToken, commands.CreateServiceAccount{
+					Name:   "erp-import",
+					Scopes: []string{commands.UsersReadPermission},
+				}))
+				keysPath := fmt.Sprintf("/api/v1/admin/service-accounts/%v/keys", account["id"])
+
+				// Phase 2: Exercise (Act)
+				issued := builder.decodeData(builder.postAuthorized(keysPath, adminToken, nil))
+				apiKey := issued["key"].(string)
+				listResp := builder.requestWithApiKey(http.MethodGet, "/api/v1/admin/users", apiKey, nil)
+				writeResp := builder.requestWithApiKey(http.MethodPost, "/api/v1/admin/users/1/disable", apiKey, nil)
+				revokeResp := builder.request(http.MethodDelete, fmt.Sprintf("%s/%v", keysPath, issued["id"]), adminToken, nil)
+
+				// Phase 3: Verify (Assert)
+				Expect(apiKey).To(HavePrefix(entity.ApiKeyPrefix))
+				Expect(listResp.StatusCode).To(Equal(http.StatusOK))
+				Expect(writeResp.StatusCode).To(Equal(http.StatusForbidden))
+				Expect(revokeResp.StatusCode).To(Equal(http.StatusNoContent))
+				Expect(builder.requestWithApiKey(http.MethodGet, "/api/v1/admin/users", apiKey, nil).StatusCode).To(Equal(http.StatusUnauthorized))
+
+				var storedKey entity.ApiKey
+				Expect(builder.db.First(&storedKey).Error).NotTo(HaveOccurred())
+				Expect(storedKey.KeyHash).To(Equal(entity.HashToken(apiKey)))
+				Expect(storedKey.LastUsedAt).NotTo(BeNil())
+			})
+		})
+
+		Context("when a scope names no permission", func() {
+			It("should return bad request", func() {
+				// Phase 1: Setup (Arrange)
+				adminToken := builder.registerAndLogin("keyadmin")["access"].(string)
+				builder.grantPermissions("keyadmin", commands.ServiceAccountsWritePermission)
+
+				// Phase 2: Exercise (Act)
+				resp := builder.postAuthorized("/api/v1/admin/service-accounts", adminToken, commands.CreateServiceAccount{
+					Name:   "price-sync",
+					Scopes: []string{"prices:write"},
+				})
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		Context("when an API key has expired", func() {
+			It("should return unauthorized", func() {
+				// Phase 1: Setup (Arrange)
+				account := &entity.ServiceAccount{Name: "price-sync"}
+				Expect(builder.db.Create(account).Error).NotTo(HaveOccurred())
+				expiresAt := time.Now().Add(-time.Minute)
+				key, apiKey, err := entity.NewApiKey(account.ID, &expiresAt, 0)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(builder.db.Create(key).Error).NotTo(HaveOccurred())
+
+				// Phase 2: Exercise (Act)
+				resp := builder.requestWithApiKey(http.MethodGet, "/api/v1/me", apiKey, nil)
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+			})
+		})
+	})
+
+	Describe("GET /.well-known/jwks.json", func() {
+		Context("when no asymmetric keys are configured", func() {
+			It("should publish an empty, cacheable key set without authentication", func() {
+				// Phase 1: Setup (Arrange)
+				req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+
+				// Phase 2: Exercise (Act)
+				resp, err := builder.app.Test(req)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				Expect(resp.Header.Get("Cache-Control")).To(ContainSubstring("max-age"))
+				var jwks adapter.JSONWebKeySet
+				Expect(json.NewDecoder(resp.Body).Decode(&jwks)).To(Succeed())
+				Expect(jwks.Keys).NotTo(BeNil())
+				Expect(jwks.Keys).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("GET /api/v1/public/avatar/:id", func() {
+		Context("when an avatar is requested in a size and format", func() {
+			It("should serve a cacheable image and answer revalidation with not modified", func() {
+				// Phase 1: Setup (Arrange)
+				path := "/api/v1/public/avatar/avatar123?size=64&format=webp"
+
+				// Phase 2: Exercise (Act)
+				resp := builder.request(http.MethodGet, path, "", nil)
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				Expect(resp.Header.Get("Content-Type")).To(Equal("image/webp"))
+				Expect(resp.Header.Get("Cache-Control")).To(ContainSubstring("max-age=31536000"))
+				etag := resp.Header.Get("ETag")
+				Expect(etag).NotTo(BeEmpty())
+
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				req.Header.Set("If-None-Match", etag)
+				revalidated, err := builder.app.Test(req)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(revalidated.StatusCode).To(Equal(http.StatusNotModified))
+			})
+		})
+
+		Context("when the size is out of range", func() {
+			It("should return bad request", func() {
+				// Phase 2: Exercise (Act)
+				resp := builder.request(http.MethodGet, "/api/v1/public/avatar/avatar123?size=4096", "", nil)
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	Describe("/api/v1/me/avatar", func() {
+		Context("when the user uploads an image", func() {
+			It("should show and serve it as their avatar until they remove it", func() {
+				// Phase 1: Setup (Arrange)
+				accessToken := builder.registerAndLogin("avataruser")["access"].(string)
+				img := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+				var content bytes.Buffer
+				Expect(png.Encode(&content, img)).To(Succeed())
+
+				// Phase 2: Exercise (Act)
+				resp := builder.uploadAvatar(accessToken, content.Bytes())
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				avatarURL := builder.decodeData(resp)["avatar_url"].(string)
+				Expect(avatarURL).To(HavePrefix("/media/avatars/"))
+				served := builder.request(http.MethodGet, avatarURL, "", nil)
+				Expect(served.StatusCode).To(Equal(http.StatusOK))
+				avatar, err := png.Decode(served.Body)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(avatar.Bounds().Dx()).To(Equal(adapter.DefaultAvatarSize))
+
+				Expect(builder.request(http.MethodDelete, "/api/v1/me/avatar", accessToken, nil).StatusCode).To(Equal(http.StatusNoContent))
+				me := builder.decodeData(builder.request(http.MethodGet, "/api/v1/me", accessToken, nil))
+				Expect(me["avatar_url"]).To(HavePrefix("/api/v1/public/avatar/"))
+			})
+		})
+
+		Context("when the upload is not an image", func() {
+			It("should return bad request", func() {
+				// Phase 1: Setup (Arrange)
+				accessToken := builder.registerAndLogin("avatartext")["access"].(string)
+
+				// Phase 2: Exercise (Act)
+				resp := builder.uploadAvatar(accessToken, []byte("not an image"))
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	Describe("/api/v1/public/oidc", func() {
+		Context("when a user signs in with a configured provider", func() {
+			It("should create the user and return a token pair", func() {
+				// Phase 1: Setup (Arrange)
+				builder.oidc.SignInAs(fakes.OidcAccount{Subject: "2001", Email: "oidc@example.com", EmailVerified: true, GivenName: "Oidc"})
+				startResp := builder.request(http.MethodGet, "/api/v1/public/oidc/fake/authorize", "", nil)
+				Expect(startResp.StatusCode).To(Equal(http.StatusOK))
+				start := builder.decodeData(startResp)
+				code, state, err := builder.oidc.Authorize(start["authorization_url"].(string))
+				Expect(err).NotTo(HaveOccurred())
+
+				// Phase 2: Exercise (Act)
+				resp := builder.post("/api/v1/public/oidc/fake/callback", commands.CompleteOidcLogin{Code: code, State: state})
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				Expect(resp.Header.Get("Authorization")).To(HavePrefix("Bearer "))
+				meResp := builder.request(http.MethodGet, "/api/v1/me", builder.decodeData(resp)["access"].(string), nil)
+				Expect(meResp.StatusCode).To(Equal(http.StatusOK))
+				Expect(builder.decodeData(meResp)["email"]).To(Equal("oidc@example.com"))
+			})
+		})
+
+		Context("when the provider is not configured", func() {
+			It("should return bad request", func() {
+				// Phase 2: Exercise (Act)
+				resp := builder.request(http.MethodGet, "/api/v1/public/oidc/myspace/authorize", "", nil)
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	Describe("/api/v1/me/addresses", func() {
+		address := func(recipientName string) commands.SaveAddress {
+			return commands.SaveAddress{
+				RecipientName: recipientName,
+				Phone:         "09121234567",
+				Province:      "Tehran",
+				City:          "Tehran",
+				PostalCode:    "11936-53471",
+				Street:        "Valiasr St.",
+				Plate:         "12",
+			}
+		}
+
+		Context("when a user manages their address book", func() {
+			It("should keep exactly one default address", func() {
+				// Phase 1: Setup (Arrange)
+				accessToken := builder.registerAndLogin("addressuser")["access"].(string)
+				first := builder.decodeData(builder.postAuthorized("/api/v1/me/addresses", accessToken, address("First")))
+				second := builder.decodeData(builder.postAuthorized("/api/v1/me/addresses", accessToken, address("Second")))
+				Expect(first["is_default"]).To(BeTrue())
+				Expect(first["postal_code"]).To(Equal("1193653471"))
+				Expect(second["is_default"]).To(BeFalse())
+				secondPath := fmt.Sprintf("/api/v1/me/addresses/%v", second["id"])
+
+				// Phase 2: Exercise (Act)
+				setDefaultResp := builder.postAuthorized(secondPath+"/default", accessToken, nil)
+				deleteResp := builder.request(http.MethodDelete, secondPath, accessToken, nil)
+
+				// Phase 3: Verify (Assert)
+				Expect(setDefaultResp.StatusCode).To(Equal(http.StatusNoContent))
+				Expect(deleteResp.StatusCode).To(Equal(http.StatusNoContent))
+				listResp := builder.request(http.MethodGet, "/api/v1/me/addresses", accessToken, nil)
+				Expect(listResp.StatusCode).To(Equal(http.StatusOK))
+				var list struct {
+					Data []map[string]interface{} `json:"data"`
+				}
+				Expect(json.NewDecoder(listResp.Body).Decode(&list)).To(Succeed())
+				Expect(list.Data).To(HaveLen(1))
+				Expect(list.Data[0]["recipient_name"]).To(Equal("First"))
+				Expect(list.Data[0]["is_default"]).To(BeTrue())
+			})
+		})
+
+		Context("when a user reads the address of another user", func() {
+			It("should return not found", func() {
+				// Phase 1: Setup (Arrange)
+				ownerToken := builder.registerAndLogin("addressowner")["access"].(string)
+				created := builder.decodeData(builder.postAuthorized("/api/v1/me/addresses", ownerToken, address("Owner")))
+				otherToken := builder.registerAndLogin("addressthief")["access"].(string)
+
+				// Phase 2: Exercise (Act)
+				resp := builder.request(http.MethodGet, fmt.Sprintf("/api/v1/me/addresses/%v", created["id"]), otherToken, nil)
+
+				// Phase 3: Verify (Assert)
+				Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
 })
 
 // E2ETestBuilder helps build E2E test scenarios with HTTP server
 type E2ETestBuilder struct {
-	app *fiber.App
-	db  *gorm.DB
+	app  *fiber.App
+	db   *gorm.DB
+	cfg  *config.Config
+	oidc *fakes.OidcProvider
 }
 
 func NewE2ETestBuilder() *E2ETestBuilder {
@@ -149,10 +1001,30 @@ func NewE2ETestBuilder() *E2ETestBuilder {
 
 	// Auto-migrate
 	err = db.AutoMigrate(
-	// Account tables will be migrated by bootstrap
+		&entity.User{},
+		&entity.Profile{},
+		&entity.Session{},
+		&entity.RefreshToken{},
+		&entity.EmailVerificationToken{},
+		&entity.PasswordResetToken{},
+		&entity.TwoFactorChallenge{},
+		&entity.RecoveryCode{},
+		&entity.AccountUnlockToken{},
+		&entity.Address{},
+		&productentity.Review{},
+		&entity.Role{},
+		&entity.Permission{},
+		&entity.ServiceAccount{},
+		&entity.ApiKey{},
+		&entity.ExternalIdentity{},
+		&entity.OidcAuthorization{},
+		&entity.AuditEvent{},
 	)
 	Expect(err).NotTo(HaveOccurred())
 
+	oidc, err := fakes.NewOidcProvider()
+	Expect(err).NotTo(HaveOccurred())
+
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
 		ErrorHandler: func(c fiber.Ctx, err error) error {
@@ -169,23 +1041,261 @@ func NewE2ETestBuilder() *E2ETestBuilder {
 	// Bootstrap account module
 	cfg := &config.Config{
 		JWT: config.JWTConfig{
-			Secret:                    "test-secret-for-e2e",
-			AccessTokenExpireDuration: 3600,
+			Secret:                     "test-secret-for-e2e",
+			AccessTokenExpireDuration:  15,
+			RefreshTokenExpireDuration: 60,
+		},
+		Password: config.PasswordConfig{
+			MinLength: 6,
+			ResetURL:  "https://shikposh.test/reset-password",
+		},
+		Mail: config.MailConfig{
+			Sender: "file",
+			Dir:    GinkgoT().TempDir(),
+			From:   "no-reply@shikposh.test",
+		},
+		Verification: config.VerificationConfig{
+			TokenExpireDuration: 60,
+			ResendInterval:      1,
+			VerifyURL:           "https://shikposh.test/verify-email",
+		},
+		Login: config.LoginConfig{
+			FreeAttempts:     10,
+			IPFreeAttempts:   100,
+			LockoutThreshold: 3,
+			LockoutDuration:  600,
+			UnlockURL:        "https://shikposh.test/unlock-account",
+		},
+		Oidc: config.OidcConfig{
+			Providers: []config.OidcProviderConfig{oidc.Config("fake", "https://shikposh.test/login/fake/callback")},
+		},
+		Storage: config.StorageConfig{
+			Dir: GinkgoT().TempDir(),
+		},
+		Avatar: config.AvatarConfig{
+			MaxUploadSize: 64,
 		},
 	}
 
+	accessTokens, err := adapter.NewAccessTokenKeys(cfg.JWT)
+	Expect(err).NotTo(HaveOccurred())
+	mw := middleware.NewMiddleware(middleware.MiddlewareConfig{AccessTokens: accessTokens}, db)
+	mw.Register(app)
+
 	// Bootstrap account routes
-	err = account.Bootstrap(app, db, cfg)
+	err = account.Bootstrap(app, db, cfg, mw)
 	Expect(err).NotTo(HaveOccurred())
 
 	return &E2ETestBuilder{
-		app: app,
-		db:  db,
+		app:  app,
+		db:   db,
+		cfg:  cfg,
+		oidc: oidc,
 	}
 }
 
 func (b *E2ETestBuilder) Cleanup() {
+	b.oidc.Close()
 	b.db.Exec("DELETE FROM users")
-	b.db.Exec("DELETE FROM tokens")
+	b.db.Exec("DELETE FROM refresh_tokens")
+	b.db.Exec("DELETE FROM sessions")
 	b.db.Exec("DELETE FROM profiles")
+	b.db.Exec("DELETE FROM email_verification_tokens")
+	b.db.Exec("DELETE FROM password_reset_tokens")
+	b.db.Exec("DELETE FROM two_factor_challenges")
+	b.db.Exec("DELETE FROM recovery_codes")
+	b.db.Exec("DELETE FROM account_unlock_tokens")
+	b.db.Exec("DELETE FROM addresses")
+	b.db.Exec("DELETE FROM reviews")
+	b.db.Exec("DELETE FROM user_roles")
+	b.db.Exec("DELETE FROM role_permissions")
+	b.db.Exec("DELETE FROM roles")
+	b.db.Exec("DELETE FROM permissions")
+	b.db.Exec("DELETE FROM api_keys")
+	b.db.Exec("DELETE FROM service_account_scopes")
+	b.db.Exec("DELETE FROM service_accounts")
+	b.db.Exec("DELETE FROM external_identities")
+	b.db.Exec("DELETE FROM oidc_authorizations")
+	b.db.Exec("DELETE FROM audit_events")
+}
+
+// registerAndLogin registers a user through the API and returns the token pair of its login.
+func (b *E2ETestBuilder) registerAndLogin(username string) map[string]interface{} {
+	registerBody, _ := json.Marshal(commands.RegisterUser{
+		AvatarIdentifier: "avatar123",
+		UserName:         username,
+		FirstName:        "Test",
+		LastName:         "User",
+		Email:            username + "@example.com",
+		Password:         "password123",
+	})
+	registerReq := httptest.NewRequest(http.MethodPost, "/api/v1/public/register", bytes.NewBuffer(registerBody))
+	registerReq.Header.Set("Content-Type", "application/json")
+	_, err := b.app.Test(registerReq)
+	Expect(err).NotTo(HaveOccurred())
+
+	loginBody, _ := json.Marshal(commands.LoginUser{UserName: username, Password: "password123"})
+	loginReq := httptest.NewRequest(http.MethodPost, "/api/v1/public/login", bytes.NewBuffer(loginBody))
+	loginReq.Header.Set("Content-Type", "application/json")
+	loginResp, err := b.app.Test(loginReq)
+	Expect(err).NotTo(HaveOccurred())
+	return b.decodeData(loginResp)
+}
+
+func (b *E2ETestBuilder) register(username string) {
+	body, _ := json.Marshal(commands.RegisterUser{
+		AvatarIdentifier: "avatar123",
+		UserName:         username,
+		FirstName:        "Test",
+		LastName:         "User",
+		Email:            username + "@example.com",
+		Password:         "password123",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/public/register", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := b.app.Test(req)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+}
+
+func (b *E2ETestBuilder) login(username string) *http.Response {
+	return b.loginWithPassword(username, "password123")
+}
+
+func (b *E2ETestBuilder) loginWithPassword(username, password string) *http.Response {
+	body, _ := json.Marshal(commands.LoginUser{UserName: username, Password: password})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/public/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := b.app.Test(req)
+	Expect(err).NotTo(HaveOccurred())
+	return resp
+}
+
+func (b *E2ETestBuilder) post(path string, payload interface{}) *http.Response {
+	return b.postAuthorized(path, "", payload)
+}
+
+func (b *E2ETestBuilder) postAuthorized(path, accessToken string, payload interface{}) *http.Response {
+	return b.request(http.MethodPost, path, accessToken, payload)
+}
+
+func (b *E2ETestBuilder) request(method, path, accessToken string, payload interface{}) *http.Response {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(method, path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	resp, err := b.app.Test(req)
+	Expect(err).NotTo(HaveOccurred())
+	return resp
+}
+
+// requestWithApiKey sends the request on behalf of a service account.
+func (b *E2ETestBuilder) requestWithApiKey(method, path, apiKey string, payload interface{}) *http.Response {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(method, path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "ApiKey "+apiKey)
+	resp, err := b.app.Test(req)
+	Expect(err).NotTo(HaveOccurred())
+	return resp
+}
+
+// uploadAvatar sends content as the avatar file of a multipart form.
+func (b *E2ETestBuilder) uploadAvatar(accessToken string, content []byte) *http.Response {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("avatar", "avatar.png")
+	Expect(err).NotTo(HaveOccurred())
+	_, err = part.Write(content)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(form.Close()).To(Succeed())
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/me/avatar", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := b.app.Test(req)
+	Expect(err).NotTo(HaveOccurred())
+	return resp
+}
+
+// mailsTo returns the bodies of the mails the file mail sender wrote for the address.
+func (b *E2ETestBuilder) mailsTo(email string) []string {
+	files, err := filepath.Glob(filepath.Join(b.cfg.Mail.Dir, "*.eml"))
+	Expect(err).NotTo(HaveOccurred())
+
+	var mails []string
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		Expect(err).NotTo(HaveOccurred())
+		if strings.Contains(string(content), "To: "+email+"\r\n") {
+			mails = append(mails, string(content))
+		}
+	}
+	return mails
+}
+
+// latestMailToken returns the token linked in the latest mail sent to the address.
+func (b *E2ETestBuilder) latestMailToken(email string) string {
+	var mails []string
+	Eventually(func() []string {
+		mails = b.mailsTo(email)
+		return mails
+	}).ShouldNot(BeEmpty())
+
+	_, token, found := strings.Cut(mails[len(mails)-1], "?token=")
+	Expect(found).To(BeTrue())
+	return strings.TrimSpace(token)
+}
+
+// enableTwoFactor registers a user and enrolls it in two-factor authentication
+// through the API. It returns the TOTP secret and the recovery codes.
+func (b *E2ETestBuilder) enableTwoFactor(username string) (string, []string) {
+	accessToken := b.registerAndLogin(username)["access"].(string)
+
+	enrollResp := b.postAuthorized("/api/v1/public/2fa/enroll", accessToken, nil)
+	Expect(enrollResp.StatusCode).To(Equal(http.StatusOK))
+	secret := b.decodeData(enrollResp)["secret"].(string)
+
+	code, err := adapter.TotpCode(secret, time.Now())
+	Expect(err).NotTo(HaveOccurred())
+	confirmResp := b.postAuthorized("/api/v1/public/2fa/confirm", accessToken, commands.ConfirmTotp{Code: code})
+	Expect(confirmResp.StatusCode).To(Equal(http.StatusOK))
+
+	var recoveryCodes []string
+	for _, recoveryCode := range b.decodeData(confirmResp)["recovery_codes"].([]interface{}) {
+		recoveryCodes = append(recoveryCodes, recoveryCode.(string))
+	}
+	return secret, recoveryCodes
+}
+
+// grantPermissions gives the user a role holding the permissions.
+func (b *E2ETestBuilder) grantPermissions(username string, permissions ...string) {
+	role := &entity.Role{Name: "e2e-" + username}
+	for _, permission := range permissions {
+		role.Permissions = append(role.Permissions, &entity.Permission{Name: permission})
+	}
+	Expect(b.db.Create(role).Error).NotTo(HaveOccurred())
+
+	var user entity.User
+	Expect(b.db.Where("user_name = ?", username).First(&user).Error).NotTo(HaveOccurred())
+	Expect(b.db.Model(&user).Association("Roles").Append(role)).To(Succeed())
+}
+
+func (b *E2ETestBuilder) refresh(refreshToken string) *http.Response {
+	body, _ := json.Marshal(commands.RefreshToken{RefreshToken: refreshToken})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/public/token/refresh", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := b.app.Test(req)
+	Expect(err).NotTo(HaveOccurred())
+	return resp
+}
+
+func (b *E2ETestBuilder) decodeData(resp *http.Response) map[string]interface{} {
+	var result map[string]interface{}
+	Expect(json.NewDecoder(resp.Body).Decode(&result)).To(Succeed())
+	data, ok := result["data"].(map[string]interface{})
+	Expect(ok).To(BeTrue())
+	return data
 }
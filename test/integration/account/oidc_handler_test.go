@@ -0,0 +1,123 @@
+package integration_test
+
+import (
+	"context"
+
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/service_layer/command_handler"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"shikposh-backend/test/integration/testdouble/builders"
+	"shikposh-backend/test/integration/testdouble/factories"
+	"shikposh-backend/test/integration/testdouble/fakes"
+	"shikposh-backend/test/integration/testdouble/helpers"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OidcHandler Integration", func() {
+	var (
+		builder  *builders.UserIntegrationTestBuilder
+		provider *fakes.OidcProvider
+		handler  *command_handler.OidcHandler
+		ctx      context.Context
+	)
+
+	// signIn runs the whole authorization code flow against the fake provider.
+	signIn := func() (*command_handler.LoginResult, error) {
+		start, err := handler.StartOidcLoginHandler(ctx, &commands.StartOidcLogin{Provider: "fake"})
+		Expect(err).NotTo(HaveOccurred())
+		code, state, err := provider.Authorize(start.AuthorizationURL)
+		Expect(err).NotTo(HaveOccurred())
+
+		return handler.CompleteOidcLoginHandler(ctx, &commands.CompleteOidcLogin{Provider: "fake", Code: code, State: state})
+	}
+
+	BeforeEach(func() {
+		var err error
+		builder, err = builders.NewUserIntegrationTestBuilder()
+		Expect(err).NotTo(HaveOccurred())
+		provider, err = fakes.NewOidcProvider()
+		Expect(err).NotTo(HaveOccurred())
+		handler, err = builder.BuildOidcHandler(provider.Config("fake", "https://shikposh.test/login/fake/callback"))
+		Expect(err).NotTo(HaveOccurred())
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		provider.Close()
+		builder.Cleanup()
+	})
+
+	Describe("CompleteOidcLoginHandler", func() {
+		Context("when the provider account is unknown", func() {
+			It("should register a user and link the provider account", func() {
+				// Phase 1: Setup (Arrange)
+				provider.SignInAs(fakes.OidcAccount{Subject: "1001", Email: "sara@example.com", EmailVerified: true, GivenName: "Sara"})
+
+				// Phase 2: Exercise (Act)
+				result, err := signIn()
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Access).NotTo(BeEmpty())
+				user := helpers.FindUserByUsername(builder.DB, "fake_1001")
+				Expect(user.Email).To(Equal("sara@example.com"))
+				Expect(user.IsEmailVerified()).To(BeTrue())
+				Expect(helpers.FindSessionsByUserID(builder.DB, user.ID)).To(HaveLen(1))
+			})
+		})
+
+		Context("when the provider account signs in again", func() {
+			It("should reuse the linked user", func() {
+				// Phase 1: Setup (Arrange)
+				provider.SignInAs(fakes.OidcAccount{Subject: "1002", Email: "reza@example.com", EmailVerified: true})
+				_, err := signIn()
+				Expect(err).NotTo(HaveOccurred())
+
+				// Phase 2: Exercise (Act)
+				_, err = signIn()
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				user := helpers.FindUserByUsername(builder.DB, "fake_1002")
+				Expect(helpers.FindSessionsByUserID(builder.DB, user.ID)).To(HaveLen(2))
+			})
+		})
+
+		Context("when an unverified account already uses the email", func() {
+			It("should return conflict error", func() {
+				// Phase 1: Setup (Arrange)
+				factories.CreateUser(builder.DB, "existing", "taken@example.com", "password123")
+				provider.SignInAs(fakes.OidcAccount{Subject: "1003", Email: "taken@example.com", EmailVerified: true})
+
+				// Phase 2: Exercise (Act)
+				_, err := signIn()
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				Expect(helpers.GetErrorType(err)).To(Equal(apperrors.ErrorTypeConflict))
+			})
+		})
+
+		Context("when the state is replayed", func() {
+			It("should return unauthorized error", func() {
+				// Phase 1: Setup (Arrange)
+				provider.SignInAs(fakes.OidcAccount{Subject: "1004"})
+				start, err := handler.StartOidcLoginHandler(ctx, &commands.StartOidcLogin{Provider: "fake"})
+				Expect(err).NotTo(HaveOccurred())
+				code, state, err := provider.Authorize(start.AuthorizationURL)
+				Expect(err).NotTo(HaveOccurred())
+				_, err = handler.CompleteOidcLoginHandler(ctx, &commands.CompleteOidcLogin{Provider: "fake", Code: code, State: state})
+				Expect(err).NotTo(HaveOccurred())
+
+				// Phase 2: Exercise (Act)
+				_, err = handler.CompleteOidcLoginHandler(ctx, &commands.CompleteOidcLogin{Provider: "fake", Code: code, State: state})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				Expect(helpers.GetErrorType(err)).To(Equal(apperrors.ErrorTypeUnauthorized))
+			})
+		})
+	})
+})
@@ -2,9 +2,10 @@ package integration_test
 
 import (
 	"context"
+	"time"
 
-	"shikposh-backend/internal/account/adapter/repository"
 	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
 	"shikposh-backend/internal/account/service_layer/command_handler"
 	apperrors "github.com/ali-mahdavi-dev/framework/errors"
 	"shikposh-backend/test/integration/testdouble/builders"
@@ -26,7 +27,8 @@ var _ = Describe("UserHandler Integration", func() {
 		var err error
 		builder, err = builders.NewUserIntegrationTestBuilder()
 		Expect(err).NotTo(HaveOccurred())
-		handler = builder.BuildHandler()
+		handler, err = builder.BuildHandler()
+		Expect(err).NotTo(HaveOccurred())
 		ctx = context.Background()
 	})
 
@@ -70,57 +72,201 @@ var _ = Describe("UserHandler Integration", func() {
 
 	Describe("LoginHandler", func() {
 		Context("when credentials are valid", func() {
-			It("should login and create token in database", func() {
+			It("should login and create a session in database", func() {
 				// Phase 1: Setup (Arrange)
 				user := factories.CreateUser(builder.DB, "testuser", "test@example.com", "password123")
 				loginCmd := factories.CreateLoginCommand("testuser", "password123")
+				loginCmd.UserAgent = "integration-agent"
+				loginCmd.IP = "10.0.0.1"
 
 				// Phase 2: Exercise (Act)
-				token, err := handler.LoginHandler(ctx, loginCmd)
+				result, err := handler.LoginHandler(ctx, loginCmd)
 
 				// Phase 3: Verify (Assert)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(token).NotTo(BeEmpty())
-				savedToken := helpers.FindTokenByUserID(builder.DB, user.ID)
-				Expect(savedToken.Token).To(Equal(token))
+				Expect(result.Access).NotTo(BeEmpty())
+				sessions := helpers.FindSessionsByUserID(builder.DB, user.ID)
+				Expect(sessions).To(HaveLen(1))
+				Expect(sessions[0].UserAgent).To(Equal("integration-agent"))
+				Expect(sessions[0].IP).To(Equal("10.0.0.1"))
+				savedToken := helpers.FindRefreshToken(builder.DB, result.Refresh)
+				Expect(savedToken.SessionID).To(Equal(sessions[0].ID))
 			})
 		})
 
-		Context("when user logs in again", func() {
-			It("should replace existing token", func() {
+		Context("when user logs in on another device", func() {
+			It("should keep the existing session", func() {
 				// Phase 1: Setup (Arrange)
 				user := factories.CreateUser(builder.DB, "testuser", "test@example.com", "password123")
-				factories.CreateToken(builder.DB, user.ID, "old-token")
-				loginCmd := factories.CreateLoginCommand("testuser", "password123")
+				first, err := handler.LoginHandler(ctx, factories.CreateLoginCommand("testuser", "password123"))
+				Expect(err).NotTo(HaveOccurred())
 
 				// Phase 2: Exercise (Act)
-				newToken, err := handler.LoginHandler(ctx, loginCmd)
+				second, err := handler.LoginHandler(ctx, factories.CreateLoginCommand("testuser", "password123"))
 
 				// Phase 3: Verify (Assert)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(newToken).NotTo(Equal("old-token"))
-				savedToken := helpers.FindTokenByUserID(builder.DB, user.ID)
-				Expect(savedToken.Token).To(Equal(newToken))
+				Expect(second.Refresh).NotTo(Equal(first.Refresh))
+				sessions := helpers.FindSessionsByUserID(builder.DB, user.ID)
+				Expect(sessions).To(HaveLen(2))
+				for _, session := range sessions {
+					Expect(session.RevokedAt).To(BeNil())
+				}
+			})
+		})
+	})
+
+	Describe("RefreshHandler", func() {
+		Context("when refresh token is valid", func() {
+			It("should rotate the refresh token within the same session", func() {
+				// Phase 1: Setup (Arrange)
+				factories.CreateUser(builder.DB, "testuser", "test@example.com", "password123")
+				login, err := handler.LoginHandler(ctx, factories.CreateLoginCommand("testuser", "password123"))
+				Expect(err).NotTo(HaveOccurred())
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.RefreshHandler(ctx, &commands.RefreshToken{RefreshToken: login.Refresh})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Refresh).NotTo(Equal(login.Refresh))
+				oldToken := helpers.FindRefreshToken(builder.DB, login.Refresh)
+				newToken := helpers.FindRefreshToken(builder.DB, result.Refresh)
+				Expect(oldToken.UsedAt).NotTo(BeNil())
+				Expect(newToken.SessionID).To(Equal(oldToken.SessionID))
+			})
+		})
+
+		Context("when a rotated refresh token is reused", func() {
+			It("should revoke the session and the whole token family", func() {
+				// Phase 1: Setup (Arrange)
+				factories.CreateUser(builder.DB, "testuser", "test@example.com", "password123")
+				login, err := handler.LoginHandler(ctx, factories.CreateLoginCommand("testuser", "password123"))
+				Expect(err).NotTo(HaveOccurred())
+				rotated, err := handler.RefreshHandler(ctx, &commands.RefreshToken{RefreshToken: login.Refresh})
+				Expect(err).NotTo(HaveOccurred())
+
+				// Phase 2: Exercise (Act)
+				_, err = handler.RefreshHandler(ctx, &commands.RefreshToken{RefreshToken: login.Refresh})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				Expect(helpers.GetErrorType(err)).To(Equal(apperrors.ErrorTypeUnauthorized))
+				latest := helpers.FindRefreshToken(builder.DB, rotated.Refresh)
+				Expect(latest.RevokedAt).NotTo(BeNil())
+				Expect(helpers.FindSessionByID(builder.DB, latest.SessionID).RevokedAt).NotTo(BeNil())
+
+				_, err = handler.RefreshHandler(ctx, &commands.RefreshToken{RefreshToken: rotated.Refresh})
+				Expect(err).To(HaveOccurred())
 			})
 		})
 	})
 
 	Describe("LogoutHandler", func() {
-		Context("when user has valid token", func() {
-			It("should remove token from database", func() {
+		Context("when session is active", func() {
+			It("should revoke only the current session", func() {
 				// Phase 1: Setup (Arrange)
 				user := factories.CreateUser(builder.DB, "testuser", "test@example.com", "password123")
-				factories.CreateToken(builder.DB, user.ID, "test-token")
-				logoutCmd := &commands.Logout{UserID: uint64(user.ID)}
+				current, err := handler.LoginHandler(ctx, factories.CreateLoginCommand("testuser", "password123"))
+				Expect(err).NotTo(HaveOccurred())
+				other, err := handler.LoginHandler(ctx, factories.CreateLoginCommand("testuser", "password123"))
+				Expect(err).NotTo(HaveOccurred())
+				currentToken := helpers.FindRefreshToken(builder.DB, current.Refresh)
+				logoutCmd := &commands.Logout{UserID: uint64(user.ID), SessionID: uint64(currentToken.SessionID)}
 
 				// Phase 2: Exercise (Act)
-				err := handler.LogoutHandler(ctx, logoutCmd)
+				err = handler.LogoutHandler(ctx, logoutCmd)
 
 				// Phase 3: Verify (Assert)
 				Expect(err).NotTo(HaveOccurred())
-				_, err = helpers.FindTokenByUserIDWithError(builder.DB, user.ID)
+				Expect(helpers.FindSessionByID(builder.DB, currentToken.SessionID).RevokedAt).NotTo(BeNil())
+				Expect(helpers.FindRefreshToken(builder.DB, current.Refresh).RevokedAt).NotTo(BeNil())
+				otherToken := helpers.FindRefreshToken(builder.DB, other.Refresh)
+				Expect(helpers.FindSessionByID(builder.DB, otherToken.SessionID).RevokedAt).To(BeNil())
+			})
+		})
+	})
+
+	Describe("AssignRoleHandler", func() {
+		Context("when assigning a seeded role", func() {
+			It("should grant the permissions of the role", func() {
+				// Phase 1: Setup (Arrange)
+				user := factories.CreateUser(builder.DB, "testuser", "test@example.com", "password123")
+				cmd := &commands.AssignRole{UserName: "testuser", Role: entity.RoleCatalogManager}
+
+				// Phase 2: Exercise (Act)
+				err := handler.AssignRoleHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				permissions := helpers.FindPermissionsByUserID(builder.DB, user.ID)
+				Expect(permissions).To(ConsistOf("products:write", "reviews:moderate"))
+			})
+		})
+
+		Context("when the role is assigned twice", func() {
+			It("should keep a single assignment", func() {
+				// Phase 1: Setup (Arrange)
+				user := factories.CreateUser(builder.DB, "testuser", "test@example.com", "password123")
+				cmd := &commands.AssignRole{UserName: "testuser", Role: entity.RoleSupport}
+				Expect(handler.AssignRoleHandler(ctx, cmd)).To(Succeed())
+
+				// Phase 2: Exercise (Act)
+				err := handler.AssignRoleHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(helpers.FindPermissionsByUserID(builder.DB, user.ID)).To(ConsistOf("users:read", "reviews:moderate"))
+			})
+		})
+
+		Context("when the role does not exist", func() {
+			It("should return not found error", func() {
+				// Phase 1: Setup (Arrange)
+				factories.CreateUser(builder.DB, "testuser", "test@example.com", "password123")
+				cmd := &commands.AssignRole{UserName: "testuser", Role: "superuser"}
+
+				// Phase 2: Exercise (Act)
+				err := handler.AssignRoleHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
 				Expect(err).To(HaveOccurred())
-				Expect(err).To(Equal(repository.ErrTokenNotFound))
+				Expect(helpers.GetErrorType(err)).To(Equal(apperrors.ErrorTypeNotFound))
+			})
+		})
+	})
+
+	Describe("PurgeDeletedAccounts", func() {
+		Context("when a closed account is past its grace period", func() {
+			It("should erase the account along with the rows referencing it", func() {
+				// Phase 1: Setup (Arrange)
+				closed := factories.CreateUser(builder.DB, "closeduser", "closed@example.com", "password123")
+				_, err := handler.LoginHandler(ctx, factories.CreateLoginCommand("closeduser", "password123"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(builder.DB.Create(&entity.Address{UserID: closed.ID, RecipientName: "Closed User", Street: "Valiasr"}).Error).To(Succeed())
+				pending := factories.CreateUser(builder.DB, "pendinguser", "pending@example.com", "password123")
+				now := time.Now()
+				Expect(builder.DB.Model(&entity.User{}).Where("id = ?", uint64(closed.ID)).
+					Updates(map[string]interface{}{"deleted_at": now.Add(-48 * time.Hour), "purge_after": now.Add(-time.Hour)}).Error).To(Succeed())
+				Expect(builder.DB.Model(&entity.User{}).Where("id = ?", uint64(pending.ID)).
+					Updates(map[string]interface{}{"deleted_at": now, "purge_after": now.Add(24 * time.Hour)}).Error).To(Succeed())
+
+				// Phase 2: Exercise (Act)
+				purged, err := handler.PurgeDeletedAccounts(ctx, now)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(purged).To(Equal(int64(1)))
+				var users int64
+				Expect(builder.DB.Unscoped().Model(&entity.User{}).Where("id = ?", uint64(closed.ID)).Count(&users).Error).To(Succeed())
+				Expect(users).To(BeZero())
+				for _, table := range []string{"sessions", "refresh_tokens", "addresses"} {
+					var rows int64
+					Expect(builder.DB.Table(table).Where("user_id = ?", uint64(closed.ID)).Count(&rows).Error).To(Succeed())
+					Expect(rows).To(BeZero(), table)
+				}
+				Expect(builder.DB.Unscoped().Model(&entity.User{}).Where("id = ?", uint64(pending.ID)).Count(&users).Error).To(Succeed())
+				Expect(users).To(Equal(int64(1)))
 			})
 		})
 	})
@@ -2,9 +2,11 @@ package builders
 
 import (
 	"fmt"
+	"net/http"
 	"time"
 
 	"shikposh-backend/config"
+	"shikposh-backend/internal/account/adapter"
 	"shikposh-backend/internal/account/service_layer/command_handler"
 	appadapter "github.com/ali-mahdavi-dev/framework/adapter"
 	"shikposh-backend/internal/unit_of_work"
@@ -53,8 +55,9 @@ func NewUserIntegrationTestBuilder() (*UserIntegrationTestBuilder, error) {
 
 	testConfig := &config.Config{
 		JWT: config.JWTConfig{
-			Secret:                    "test-secret-key-for-integration-tests",
-			AccessTokenExpireDuration: 3600,
+			Secret:                     "test-secret-key-for-integration-tests",
+			AccessTokenExpireDuration:  15,
+			RefreshTokenExpireDuration: 60,
 		},
 	}
 
@@ -65,8 +68,28 @@ func NewUserIntegrationTestBuilder() (*UserIntegrationTestBuilder, error) {
 	}, nil
 }
 
-func (b *UserIntegrationTestBuilder) BuildHandler() *command_handler.UserHandler {
-	return command_handler.NewUserHandler(b.UOW, b.Cfg)
+func (b *UserIntegrationTestBuilder) BuildHandler() (*command_handler.UserHandler, error) {
+	accessTokens, err := adapter.NewAccessTokenKeys(b.Cfg.JWT)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+
+	return command_handler.NewUserHandler(b.UOW, b.Cfg, accessTokens, adapter.NewMemoryLoginAttemptStore()), nil
+}
+
+// BuildOidcHandler signs users in with the given providers, e.g. a fake one.
+func (b *UserIntegrationTestBuilder) BuildOidcHandler(providers ...config.OidcProviderConfig) (*command_handler.OidcHandler, error) {
+	users, err := b.BuildHandler()
+	if err != nil {
+		return nil, err
+	}
+
+	identityProviders, err := adapter.NewIdentityProviders(config.OidcConfig{Providers: providers}, http.DefaultClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create identity providers: %w", err)
+	}
+
+	return command_handler.NewOidcHandler(b.UOW, b.Cfg, identityProviders, users), nil
 }
 
 func (b *UserIntegrationTestBuilder) Cleanup() {
@@ -47,10 +47,3 @@ func CreateLoginCommand(username, password string) *commands.LoginUser {
 		Password: password,
 	}
 }
-
-func CreateToken(db *gorm.DB, userID entity.UserID, tokenValue string) {
-	tokenRepo := repository.NewTokenRepository(db)
-	token := entity.NewToken(tokenValue, userID)
-	err := tokenRepo.Save(context.Background(), token)
-	Expect(err).NotTo(HaveOccurred())
-}
@@ -18,16 +18,32 @@ func FindUserByUsername(db *gorm.DB, username string) *entity.User {
 	return user
 }
 
-func FindTokenByUserID(db *gorm.DB, userID entity.UserID) *entity.Token {
-	tokenRepo := repository.NewTokenRepository(db)
-	token, err := tokenRepo.FindByUserID(context.Background(), userID)
+func FindSessionsByUserID(db *gorm.DB, userID entity.UserID) []*entity.Session {
+	var sessions []*entity.Session
+	err := db.Where("user_id = ?", uint64(userID)).Order("id").Find(&sessions).Error
+	Expect(err).NotTo(HaveOccurred())
+	return sessions
+}
+
+func FindSessionByID(db *gorm.DB, sessionID entity.SessionID) *entity.Session {
+	sessionRepo := repository.NewSessionRepository(db)
+	session, err := sessionRepo.FindBySessionID(context.Background(), sessionID)
+	Expect(err).NotTo(HaveOccurred())
+	return session
+}
+
+func FindRefreshToken(db *gorm.DB, plainToken string) *entity.RefreshToken {
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	token, err := refreshTokenRepo.FindByTokenHash(context.Background(), entity.HashToken(plainToken))
 	Expect(err).NotTo(HaveOccurred())
 	return token
 }
 
-func FindTokenByUserIDWithError(db *gorm.DB, userID entity.UserID) (*entity.Token, error) {
-	tokenRepo := repository.NewTokenRepository(db)
-	return tokenRepo.FindByUserID(context.Background(), userID)
+func FindPermissionsByUserID(db *gorm.DB, userID entity.UserID) []string {
+	userRepo := repository.NewUserRepository(db)
+	permissions, err := userRepo.FindPermissions(context.Background(), userID)
+	Expect(err).NotTo(HaveOccurred())
+	return permissions
 }
 
 func IsPasswordHashed(hashedPassword, plainPassword string) bool {
@@ -0,0 +1,179 @@
+package account_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"time"
+
+	"shikposh-backend/config"
+	"shikposh-backend/internal/account/adapter"
+
+	"github.com/golang-jwt/jwt/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func privateKeyPEM(key interface{}) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func publicKeyPEM(key interface{}) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	Expect(err).NotTo(HaveOccurred())
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+var _ = Describe("AccessTokenKeys", func() {
+	var (
+		rsaKey     *rsa.PrivateKey
+		edPublic   ed25519.PublicKey
+		edPrivate  ed25519.PrivateKey
+		expiresAt  time.Time
+		baseConfig config.JWTConfig
+	)
+
+	BeforeEach(func() {
+		var err error
+		rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		edPublic, edPrivate, err = ed25519.GenerateKey(rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		expiresAt = time.Now().Add(time.Hour)
+		baseConfig = config.JWTConfig{
+			Secret:   "test-secret",
+			Issuer:   "https://api.shikposh.test",
+			Audience: "shikposh",
+		}
+	})
+
+	Context("when signing with an RSA key", func() {
+		It("should issue RS256 tokens carrying the key ID", func() {
+			// Phase 1: Setup (Arrange)
+			cfg := baseConfig
+			cfg.SigningKeyID = "2026-10"
+			cfg.Keys = []config.JWTKeyConfig{{ID: "2026-10", PrivateKey: privateKeyPEM(rsaKey)}}
+			keys, err := adapter.NewAccessTokenKeys(cfg)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Phase 2: Exercise (Act)
+			token, err := keys.Generate(expiresAt, 7, 11)
+			Expect(err).NotTo(HaveOccurred())
+			claims, err := keys.Parse(token)
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(claims.UserID).To(Equal(uint64(7)))
+			Expect(claims.SessionID).To(Equal(uint64(11)))
+			Expect(claims.Issuer).To(Equal(cfg.Issuer))
+			Expect(claims.ID).NotTo(BeEmpty())
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed.Header["alg"]).To(Equal("RS256"))
+			Expect(parsed.Header["kid"]).To(Equal("2026-10"))
+		})
+	})
+
+	Context("when the signing key was rotated", func() {
+		It("should keep verifying tokens of the old key and publish both", func() {
+			// Phase 1: Setup (Arrange)
+			before := baseConfig
+			before.SigningKeyID = "2026-07"
+			before.Keys = []config.JWTKeyConfig{{ID: "2026-07", PrivateKey: privateKeyPEM(rsaKey)}}
+			oldKeys, err := adapter.NewAccessTokenKeys(before)
+			Expect(err).NotTo(HaveOccurred())
+			oldToken, err := oldKeys.Generate(expiresAt, 7, 11)
+			Expect(err).NotTo(HaveOccurred())
+
+			after := baseConfig
+			after.SigningKeyID = "2026-10"
+			after.Keys = []config.JWTKeyConfig{
+				{ID: "2026-10", PrivateKey: privateKeyPEM(edPrivate)},
+				{ID: "2026-07", PublicKey: publicKeyPEM(&rsaKey.PublicKey)},
+			}
+
+			// Phase 2: Exercise (Act)
+			keys, err := adapter.NewAccessTokenKeys(after)
+			Expect(err).NotTo(HaveOccurred())
+			claims, err := keys.Parse(oldToken)
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(claims.UserID).To(Equal(uint64(7)))
+
+			jwks := keys.JWKS()
+			Expect(jwks.Keys).To(HaveLen(2))
+			Expect(jwks.Keys[0].Kid).To(Equal("2026-07"))
+			Expect(jwks.Keys[0].Kty).To(Equal("RSA"))
+			Expect(jwks.Keys[0].Alg).To(Equal("RS256"))
+			Expect(jwks.Keys[1].Kid).To(Equal("2026-10"))
+			Expect(jwks.Keys[1].Kty).To(Equal("OKP"))
+			Expect(jwks.Keys[1].Alg).To(Equal("EdDSA"))
+			Expect(jwks.Keys[1].X).NotTo(BeEmpty())
+		})
+	})
+
+	Context("when keys are configured", func() {
+		It("should reject tokens signed with the secret", func() {
+			// Phase 1: Setup (Arrange)
+			legacyKeys, err := adapter.NewAccessTokenKeys(baseConfig)
+			Expect(err).NotTo(HaveOccurred())
+			legacyToken, err := legacyKeys.Generate(expiresAt, 7, 11)
+			Expect(err).NotTo(HaveOccurred())
+
+			cfg := baseConfig
+			cfg.SigningKeyID = "2026-10"
+			cfg.Keys = []config.JWTKeyConfig{{ID: "2026-10", PrivateKey: privateKeyPEM(edPrivate)}}
+			keys, err := adapter.NewAccessTokenKeys(cfg)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Phase 2: Exercise (Act)
+			claims, err := keys.Parse(legacyToken)
+
+			// Phase 3: Verify (Assert)
+			Expect(claims).To(BeNil())
+			Expect(err).To(MatchError(adapter.ErrInvalidAccessToken))
+		})
+	})
+
+	Context("when the token was issued for another audience", func() {
+		It("should reject it", func() {
+			// Phase 1: Setup (Arrange)
+			other := baseConfig
+			other.Audience = "another-service"
+			otherKeys, err := adapter.NewAccessTokenKeys(other)
+			Expect(err).NotTo(HaveOccurred())
+			token, err := otherKeys.Generate(expiresAt, 7, 11)
+			Expect(err).NotTo(HaveOccurred())
+			keys, err := adapter.NewAccessTokenKeys(baseConfig)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Phase 2: Exercise (Act)
+			_, err = keys.Parse(token)
+
+			// Phase 3: Verify (Assert)
+			Expect(err).To(MatchError(adapter.ErrInvalidAccessToken))
+		})
+	})
+
+	Context("when the signing key can only verify", func() {
+		It("should refuse the configuration", func() {
+			// Phase 1: Setup (Arrange)
+			cfg := baseConfig
+			cfg.SigningKeyID = "2026-07"
+			cfg.Keys = []config.JWTKeyConfig{{ID: "2026-07", PublicKey: publicKeyPEM(edPublic)}}
+
+			// Phase 2: Exercise (Act)
+			keys, err := adapter.NewAccessTokenKeys(cfg)
+
+			// Phase 3: Verify (Assert)
+			Expect(keys).To(BeNil())
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,140 @@
+package account_test
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	"shikposh-backend/internal/account/service_layer/command_handler"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"shikposh-backend/test/unit/testdouble/builders"
+	"shikposh-backend/test/unit/testdouble/factories"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("Account deletion", func() {
+	var (
+		builder *builders.UserTestBuilder
+		handler *command_handler.UserHandler
+		user    *entity.User
+		ctx     context.Context
+	)
+
+	BeforeEach(func() {
+		builder = builders.NewUserTestBuilder().
+			WithUserRepo().
+			WithProfileRepo().
+			WithSessionRepo().
+			WithRefreshTokenRepo().
+			WithReviewRepo().
+			WithSuccessfulTransaction()
+		handler = builder.BuildHandler()
+		ctx = context.Background()
+		user = factories.CreateUser("existinguser", "user@example.com", "password123")
+		builder.MockUserRepo.On("FindByID", mock.Anything, uint64(user.ID)).
+			Return(user, nil).Maybe()
+	})
+
+	Describe("DeleteAccountHandler", func() {
+		Context("when the password is correct", func() {
+			It("should revoke every session, anonymise the reviews and schedule the erasure", func() {
+				// Phase 1: Setup (Arrange)
+				profile := &entity.Profile{ID: 3, UserID: user.ID, Phone: "+989121234567"}
+				builder.MockSessionRepo.On("RevokeByUserID", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).
+					Return(nil).Once()
+				builder.MockRefreshTokenRepo.On("RevokeByUserID", mock.Anything, user.ID).
+					Return(nil).Once()
+				builder.MockReviewRepo.On("AnonymizeByUserID", mock.Anything, user.ID, command_handler.DeletedUserName).
+					Return(nil).Once()
+				builder.MockProfileRepo.On("FindByUserID", mock.Anything, user.ID).
+					Return(profile, nil).Once()
+				builder.MockProfileRepo.On("Remove", mock.Anything, profile, true).
+					Return(nil).Once()
+				builder.MockUserRepo.On("Modify", mock.Anything, user).
+					Return(nil).Once()
+				builder.MockUserRepo.On("Remove", mock.Anything, user, true).
+					Return(nil).Once()
+				cmd := &commands.DeleteAccount{UserID: uint64(user.ID), Password: "password123"}
+
+				// Phase 2: Exercise (Act)
+				err := handler.DeleteAccountHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(user.PurgeAfter).NotTo(BeNil())
+				Expect(*user.PurgeAfter).To(BeTemporally("~", time.Now().Add(30*24*time.Hour), time.Minute))
+				builder.MockSessionRepo.AssertExpectations(GinkgoT())
+				builder.MockRefreshTokenRepo.AssertExpectations(GinkgoT())
+				builder.MockReviewRepo.AssertExpectations(GinkgoT())
+				builder.MockProfileRepo.AssertExpectations(GinkgoT())
+				builder.MockUserRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when the user has no profile and signed up without a password", func() {
+			It("should close the account without asking for a password", func() {
+				// Phase 1: Setup (Arrange)
+				user.Password = ""
+				builder.MockSessionRepo.On("RevokeByUserID", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).
+					Return(nil).Once()
+				builder.MockRefreshTokenRepo.On("RevokeByUserID", mock.Anything, user.ID).
+					Return(nil).Once()
+				builder.MockReviewRepo.On("AnonymizeByUserID", mock.Anything, user.ID, command_handler.DeletedUserName).
+					Return(nil).Once()
+				builder.MockProfileRepo.On("FindByUserID", mock.Anything, user.ID).
+					Return(nil, repository.ErrProfileNotFound).Once()
+				builder.MockUserRepo.On("Modify", mock.Anything, user).
+					Return(nil).Once()
+				builder.MockUserRepo.On("Remove", mock.Anything, user, true).
+					Return(nil).Once()
+				cmd := &commands.DeleteAccount{UserID: uint64(user.ID)}
+
+				// Phase 2: Exercise (Act)
+				err := handler.DeleteAccountHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				builder.MockProfileRepo.AssertNotCalled(GinkgoT(), "Remove", mock.Anything, mock.Anything, mock.Anything)
+				builder.MockUserRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when the password is incorrect", func() {
+			It("should return validation error and keep the account", func() {
+				// Phase 1: Setup (Arrange)
+				cmd := &commands.DeleteAccount{UserID: uint64(user.ID), Password: "wrongpassword"}
+
+				// Phase 2: Exercise (Act)
+				err := handler.DeleteAccountHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+				Expect(user.PurgeAfter).To(BeNil())
+				builder.MockSessionRepo.AssertNotCalled(GinkgoT(), "RevokeByUserID", mock.Anything, mock.Anything, mock.Anything)
+				builder.MockUserRepo.AssertNotCalled(GinkgoT(), "Remove", mock.Anything, mock.Anything, mock.Anything)
+			})
+		})
+	})
+
+	Describe("PurgeDeletedAccounts", func() {
+		It("should return the number of erased accounts", func() {
+			// Phase 1: Setup (Arrange)
+			now := time.Now()
+			builder.MockUserRepo.On("PurgeDeleted", mock.Anything, now).Return(int64(2), nil).Once()
+
+			// Phase 2: Exercise (Act)
+			purged, err := handler.PurgeDeletedAccounts(ctx, now)
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(purged).To(Equal(int64(2)))
+		})
+	})
+})
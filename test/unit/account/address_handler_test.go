@@ -0,0 +1,172 @@
+package account_test
+
+import (
+	"context"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	"shikposh-backend/internal/account/service_layer/command_handler"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"shikposh-backend/test/unit/testdouble/builders"
+	"shikposh-backend/test/unit/testdouble/factories"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("AddressHandler", func() {
+	var (
+		builder *builders.UserTestBuilder
+		handler *command_handler.AddressHandler
+		userID  entity.UserID
+		ctx     context.Context
+	)
+
+	BeforeEach(func() {
+		builder = builders.NewUserTestBuilder().
+			WithAddressRepo().
+			WithSuccessfulTransaction()
+		handler = builder.BuildAddressHandler()
+		userID = 1
+		ctx = context.Background()
+	})
+
+	Describe("CreateAddressHandler", func() {
+		Context("when it is the first address of the user", func() {
+			It("should store it normalised as the default address", func() {
+				// Phase 1: Setup (Arrange)
+				fields := factories.CreateSaveAddress()
+				fields.Phone = "+98 912 123 4567"
+				fields.PostalCode = "۱۱۹۳۶-۵۳۴۷۱"
+				builder.MockAddressRepo.On("FindByUserID", mock.Anything, userID).
+					Return([]*entity.Address{}, nil).Once()
+				builder.MockAddressRepo.On("Save", mock.Anything, mock.MatchedBy(func(a *entity.Address) bool {
+					return a.IsDefault && a.Phone == "+989121234567" && a.PostalCode == "1193653471"
+				})).Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				address, err := handler.CreateAddressHandler(ctx, &commands.CreateAddress{SaveAddress: fields, UserID: uint64(userID)})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(address.UserID).To(Equal(userID))
+				builder.MockAddressRepo.AssertNotCalled(GinkgoT(), "ClearDefault", mock.Anything, mock.Anything)
+				builder.MockAddressRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when a new default address is added to an address book", func() {
+			It("should unset the previous default address", func() {
+				// Phase 1: Setup (Arrange)
+				fields := factories.CreateSaveAddress()
+				fields.IsDefault = true
+				builder.MockAddressRepo.On("FindByUserID", mock.Anything, userID).
+					Return([]*entity.Address{factories.CreateAddress(1, userID, true)}, nil).Once()
+				builder.MockAddressRepo.On("ClearDefault", mock.Anything, userID).Return(nil).Once()
+				builder.MockAddressRepo.On("Save", mock.Anything, mock.MatchedBy(func(a *entity.Address) bool {
+					return a.IsDefault
+				})).Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				_, err := handler.CreateAddressHandler(ctx, &commands.CreateAddress{SaveAddress: fields, UserID: uint64(userID)})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				builder.MockAddressRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		DescribeTable("when the postal code is not a valid Iranian postal code",
+			func(postalCode string) {
+				// Phase 1: Setup (Arrange)
+				fields := factories.CreateSaveAddress()
+				fields.PostalCode = postalCode
+
+				// Phase 2: Exercise (Act)
+				address, err := handler.CreateAddressHandler(ctx, &commands.CreateAddress{SaveAddress: fields, UserID: uint64(userID)})
+
+				// Phase 3: Verify (Assert)
+				Expect(address).To(BeNil())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+				builder.MockAddressRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+			},
+			Entry("too short", "119365347"),
+			Entry("zero in the first four digits", "1093653471"),
+			Entry("five as the fifth digit", "1193553471"),
+			Entry("the digit two", "1193653421"),
+			Entry("the same first four digits", "1111653471"),
+			Entry("letters", "11936A3471"),
+		)
+	})
+
+	Describe("UpdateAddressHandler", func() {
+		Context("when the address belongs to another user", func() {
+			It("should return not found error", func() {
+				// Phase 1: Setup (Arrange)
+				builder.MockAddressRepo.On("FindByIDAndUserID", mock.Anything, entity.AddressID(7), userID).
+					Return(nil, repository.ErrAddressNotFound).Once()
+				cmd := &commands.UpdateAddress{SaveAddress: factories.CreateSaveAddress(), UserID: uint64(userID), AddressID: 7}
+
+				// Phase 2: Exercise (Act)
+				err := handler.UpdateAddressHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeNotFound))
+				builder.MockAddressRepo.AssertNotCalled(GinkgoT(), "Modify", mock.Anything, mock.Anything)
+			})
+		})
+	})
+
+	Describe("DeleteAddressHandler", func() {
+		Context("when the default address is deleted", func() {
+			It("should make the most recent remaining address the default", func() {
+				// Phase 1: Setup (Arrange)
+				deleted := factories.CreateAddress(1, userID, true)
+				remaining := factories.CreateAddress(2, userID, false)
+				builder.MockAddressRepo.On("FindByIDAndUserID", mock.Anything, deleted.ID, userID).
+					Return(deleted, nil).Once()
+				builder.MockAddressRepo.On("Remove", mock.Anything, deleted, true).Return(nil).Once()
+				builder.MockAddressRepo.On("FindByUserID", mock.Anything, userID).
+					Return([]*entity.Address{remaining}, nil).Once()
+				builder.MockAddressRepo.On("Modify", mock.Anything, mock.MatchedBy(func(a *entity.Address) bool {
+					return a.ID == remaining.ID && a.IsDefault
+				})).Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.DeleteAddressHandler(ctx, &commands.DeleteAddress{UserID: uint64(userID), AddressID: uint64(deleted.ID)})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				builder.MockAddressRepo.AssertExpectations(GinkgoT())
+			})
+		})
+	})
+
+	Describe("SetDefaultAddressHandler", func() {
+		Context("when the address is not the default one", func() {
+			It("should unset the previous default address", func() {
+				// Phase 1: Setup (Arrange)
+				address := factories.CreateAddress(2, userID, false)
+				builder.MockAddressRepo.On("FindByIDAndUserID", mock.Anything, address.ID, userID).
+					Return(address, nil).Once()
+				builder.MockAddressRepo.On("ClearDefault", mock.Anything, userID).Return(nil).Once()
+				builder.MockAddressRepo.On("Modify", mock.Anything, mock.MatchedBy(func(a *entity.Address) bool {
+					return a.ID == address.ID && a.IsDefault
+				})).Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.SetDefaultAddressHandler(ctx, &commands.SetDefaultAddress{UserID: uint64(userID), AddressID: uint64(address.ID)})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				builder.MockAddressRepo.AssertExpectations(GinkgoT())
+			})
+		})
+	})
+})
@@ -0,0 +1,177 @@
+package account_test
+
+import (
+	"context"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	"shikposh-backend/internal/account/service_layer/command_handler"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"shikposh-backend/test/unit/testdouble/builders"
+	"shikposh-backend/test/unit/testdouble/factories"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("Admin user management", func() {
+	var (
+		builder *builders.UserTestBuilder
+		handler *command_handler.UserHandler
+		user    *entity.User
+		actorID uint64
+		ctx     context.Context
+	)
+
+	BeforeEach(func() {
+		builder = builders.NewUserTestBuilder().
+			WithUserRepo().
+			WithSessionRepo().
+			WithRefreshTokenRepo().
+			WithRoleRepo().
+			WithSuccessfulTransaction()
+		handler = builder.BuildHandler()
+		ctx = context.Background()
+		user = factories.CreateUser("existinguser", "user@example.com", "password123")
+		actorID = 99
+		builder.MockUserRepo.On("FindByID", mock.Anything, uint64(user.ID)).
+			Return(user, nil).Maybe()
+	})
+
+	Describe("DisableUserHandler", func() {
+		Context("when another user is disabled", func() {
+			It("should refuse their logins and revoke their sessions", func() {
+				// Phase 1: Setup (Arrange)
+				builder.MockUserRepo.On("Modify", mock.Anything, user).Return(nil).Once()
+				builder.MockSessionRepo.On("RevokeByUserID", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).
+					Return(nil).Once()
+				builder.MockRefreshTokenRepo.On("RevokeByUserID", mock.Anything, user.ID).
+					Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.DisableUserHandler(ctx, &commands.DisableUser{UserID: uint64(user.ID), ActorID: actorID})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(user.IsDisabled()).To(BeTrue())
+				builder.MockSessionRepo.AssertExpectations(GinkgoT())
+				builder.MockRefreshTokenRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when administrators disable their own account", func() {
+			It("should return validation error", func() {
+				// Phase 1: Setup (Arrange)
+				cmd := &commands.DisableUser{UserID: uint64(user.ID), ActorID: uint64(user.ID)}
+
+				// Phase 2: Exercise (Act)
+				err := handler.DisableUserHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+				Expect(user.IsDisabled()).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("LoginHandler", func() {
+		Context("when the user is disabled", func() {
+			It("should return forbidden error even for the right password", func() {
+				// Phase 1: Setup (Arrange)
+				user.Disable(entity.UserID(actorID), user.CreatedAt)
+				builder.MockUserRepo.On("FindByUserName", mock.Anything, user.UserName).Return(user, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.LoginHandler(ctx, factories.CreateLoginCommand(user.UserName, "password123"))
+
+				// Phase 3: Verify (Assert)
+				Expect(result).To(BeNil())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeForbidden))
+				builder.MockSessionRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+			})
+		})
+	})
+
+	Describe("AdminResetPasswordHandler", func() {
+		Context("when the user has an email address", func() {
+			It("should drop the password and sign the user out", func() {
+				// Phase 1: Setup (Arrange)
+				builder.MockUserRepo.On("Modify", mock.Anything, mock.MatchedBy(func(u *entity.User) bool {
+					return u.ID == user.ID && u.Password == ""
+				})).Return(nil).Once()
+				builder.MockSessionRepo.On("RevokeByUserID", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).
+					Return(nil).Once()
+				builder.MockRefreshTokenRepo.On("RevokeByUserID", mock.Anything, user.ID).
+					Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.AdminResetPasswordHandler(ctx, &commands.AdminResetPassword{UserID: uint64(user.ID), ActorID: actorID})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				builder.MockUserRepo.AssertExpectations(GinkgoT())
+				builder.MockSessionRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when the user signed up with a phone number", func() {
+			It("should return validation error", func() {
+				// Phase 1: Setup (Arrange)
+				user.Email = ""
+
+				// Phase 2: Exercise (Act)
+				err := handler.AdminResetPasswordHandler(ctx, &commands.AdminResetPassword{UserID: uint64(user.ID), ActorID: actorID})
+
+				// Phase 3: Verify (Assert)
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+				builder.MockUserRepo.AssertNotCalled(GinkgoT(), "Modify", mock.Anything, mock.Anything)
+			})
+		})
+	})
+
+	Describe("ChangeUserRolesHandler", func() {
+		Context("when every role exists", func() {
+			It("should replace the roles of the user", func() {
+				// Phase 1: Setup (Arrange)
+				support := &entity.Role{ID: 3, Name: entity.RoleSupport}
+				builder.MockRoleRepo.On("FindByName", mock.Anything, entity.RoleSupport).Return(support, nil).Once()
+				builder.MockUserRepo.On("ReplaceRoles", mock.Anything, user, []*entity.Role{support}).Return(nil).Once()
+				cmd := &commands.ChangeUserRoles{UserID: uint64(user.ID), ActorID: actorID, Roles: []string{entity.RoleSupport}}
+
+				// Phase 2: Exercise (Act)
+				err := handler.ChangeUserRolesHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(user.Roles).To(ConsistOf(support))
+				builder.MockUserRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when a role does not exist", func() {
+			It("should return not found error and keep the roles", func() {
+				// Phase 1: Setup (Arrange)
+				builder.MockRoleRepo.On("FindByName", mock.Anything, "superuser").
+					Return(nil, repository.ErrRoleNotFound).Once()
+				cmd := &commands.ChangeUserRoles{UserID: uint64(user.ID), ActorID: actorID, Roles: []string{"superuser"}}
+
+				// Phase 2: Exercise (Act)
+				err := handler.ChangeUserRolesHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeNotFound))
+				builder.MockUserRepo.AssertNotCalled(GinkgoT(), "ReplaceRoles", mock.Anything, mock.Anything, mock.Anything)
+			})
+		})
+	})
+})
@@ -0,0 +1,189 @@
+package account_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+
+	account "shikposh-backend/internal/account"
+	"shikposh-backend/internal/account/adapter"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/image/webp"
+)
+
+var _ = Describe("AvatarGenerator", func() {
+	var generator *adapter.AvatarGenerator
+
+	BeforeEach(func() {
+		var err error
+		generator, err = adapter.NewAvatarGenerator(account.AssetsFS)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("Render", func() {
+		It("should resize the avatar and encode it in every format", func() {
+			decoders := map[adapter.AvatarFormat]func([]byte) (image.Image, error){
+				adapter.AvatarFormatPNG:  func(b []byte) (image.Image, error) { return png.Decode(bytes.NewReader(b)) },
+				adapter.AvatarFormatJPEG: func(b []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(b)) },
+				adapter.AvatarFormatWebP: func(b []byte) (image.Image, error) { return webp.Decode(bytes.NewReader(b)) },
+			}
+
+			for format, decode := range decoders {
+				// Phase 1: Setup (Arrange)
+				opts := adapter.AvatarOptions{Size: 64, Format: format}
+
+				// Phase 2: Exercise (Act)
+				avatar, err := generator.Render("avatar123", opts)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(avatar.ContentType).To(Equal(format.ContentType()))
+				img, err := decode(avatar.Body)
+				Expect(err).NotTo(HaveOccurred(), string(format))
+				Expect(img.Bounds().Dx()).To(Equal(64))
+				Expect(img.Bounds().Dy()).To(Equal(64))
+			}
+		})
+
+		It("should encode WebP losslessly", func() {
+			// Phase 1: Setup (Arrange)
+			reference, err := generator.Render("avatar123", adapter.AvatarOptions{Size: 96, Format: adapter.AvatarFormatPNG})
+			Expect(err).NotTo(HaveOccurred())
+			want, err := png.Decode(bytes.NewReader(reference.Body))
+			Expect(err).NotTo(HaveOccurred())
+
+			// Phase 2: Exercise (Act)
+			avatar, err := generator.Render("avatar123", adapter.AvatarOptions{Size: 96, Format: adapter.AvatarFormatWebP})
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			got, err := webp.Decode(bytes.NewReader(avatar.Body))
+			Expect(err).NotTo(HaveOccurred())
+			for y := 0; y < 96; y++ {
+				for x := 0; x < 96; x++ {
+					wantPixel := color.NRGBAModel.Convert(want.At(x, y)).(color.NRGBA)
+					gotPixel := color.NRGBAModel.Convert(got.At(x, y)).(color.NRGBA)
+					if wantPixel.A == 0 {
+						Expect(gotPixel.A).To(BeZero())
+						continue
+					}
+					Expect(gotPixel).To(Equal(wantPixel))
+				}
+			}
+		})
+
+		It("should encode WebP losslessly at the smallest and largest sizes", func() {
+			for _, size := range []int{adapter.MinAvatarSize, adapter.MaxAvatarSize} {
+				// Phase 1: Setup (Arrange)
+				reference, err := generator.Render("avatar123", adapter.AvatarOptions{Size: size, Format: adapter.AvatarFormatPNG})
+				Expect(err).NotTo(HaveOccurred())
+				want, err := png.Decode(bytes.NewReader(reference.Body))
+				Expect(err).NotTo(HaveOccurred())
+
+				// Phase 2: Exercise (Act)
+				avatar, err := generator.Render("avatar123", adapter.AvatarOptions{Size: size, Format: adapter.AvatarFormatWebP})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				got, err := webp.Decode(bytes.NewReader(avatar.Body))
+				Expect(err).NotTo(HaveOccurred(), "size %d", size)
+				Expect(got.Bounds()).To(Equal(image.Rect(0, 0, size, size)))
+				for y := 0; y < size; y++ {
+					for x := 0; x < size; x++ {
+						wantPixel := color.NRGBAModel.Convert(want.At(x, y)).(color.NRGBA)
+						gotPixel := color.NRGBAModel.Convert(got.At(x, y)).(color.NRGBA)
+						if wantPixel.A == 0 {
+							Expect(gotPixel.A).To(BeZero())
+							continue
+						}
+						Expect(gotPixel).To(Equal(wantPixel), "size %d at %d,%d", size, x, y)
+					}
+				}
+			}
+		})
+
+		It("should fill transparent pixels with the background", func() {
+			// Phase 1: Setup (Arrange)
+			background := color.NRGBA{R: 0xff, G: 0xcc, B: 0x00, A: 0xff}
+
+			// Phase 2: Exercise (Act)
+			avatar, err := generator.Render("avatar123", adapter.AvatarOptions{Size: 32, Format: adapter.AvatarFormatPNG, Background: &background})
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			img, err := png.Decode(bytes.NewReader(avatar.Body))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(color.NRGBAModel.Convert(img.At(0, 0))).To(Equal(background))
+		})
+
+		It("should serve a repeated avatar from the cache", func() {
+			// Phase 1: Setup (Arrange)
+			opts := adapter.AvatarOptions{Size: 48, Format: adapter.AvatarFormatPNG}
+			first, err := generator.Render("avatar123", opts)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Phase 2: Exercise (Act)
+			second, err := generator.Render("avatar123", opts)
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(second).To(BeIdenticalTo(first))
+		})
+	})
+
+	Describe("ETag", func() {
+		It("should depend only on the identifier and the options", func() {
+			// Phase 1: Setup (Arrange)
+			opts := adapter.AvatarOptions{Size: 128, Format: adapter.AvatarFormatWebP}
+			other, err := adapter.NewAvatarGenerator(account.AssetsFS)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Phase 2: Exercise (Act)
+			etag := generator.ETag("avatar123", opts)
+
+			// Phase 3: Verify (Assert)
+			Expect(other.ETag("avatar123", opts)).To(Equal(etag))
+			Expect(generator.ETag("avatar124", opts)).NotTo(Equal(etag))
+			Expect(generator.ETag("avatar123", adapter.AvatarOptions{Size: 64, Format: adapter.AvatarFormatWebP})).NotTo(Equal(etag))
+			Expect(generator.ETag("avatar123", adapter.AvatarOptions{Size: 128, Format: adapter.AvatarFormatPNG})).NotTo(Equal(etag))
+		})
+	})
+
+	Describe("ParseAvatarOptions", func() {
+		It("should default to a transparent PNG of the layer size", func() {
+			// Phase 2: Exercise (Act)
+			opts, err := adapter.ParseAvatarOptions("", "", "")
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts).To(Equal(adapter.AvatarOptions{Size: adapter.DefaultAvatarSize, Format: adapter.AvatarFormatPNG}))
+		})
+
+		It("should read the size, format and background", func() {
+			// Phase 2: Exercise (Act)
+			opts, err := adapter.ParseAvatarOptions("64", "JPG", "#ffcc00")
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts.Size).To(Equal(64))
+			Expect(opts.Format).To(Equal(adapter.AvatarFormatJPEG))
+			Expect(*opts.Background).To(Equal(color.NRGBA{R: 0xff, G: 0xcc, B: 0x00, A: 0xff}))
+		})
+
+		It("should reject invalid options", func() {
+			// Phase 2: Exercise (Act)
+			_, sizeErr := adapter.ParseAvatarOptions("4096", "", "")
+			_, formatErr := adapter.ParseAvatarOptions("", "gif", "")
+			_, backgroundErr := adapter.ParseAvatarOptions("", "", "red")
+
+			// Phase 3: Verify (Assert)
+			Expect(sizeErr).To(MatchError(adapter.ErrInvalidAvatarSize))
+			Expect(formatErr).To(MatchError(adapter.ErrUnsupportedAvatarFormat))
+			Expect(backgroundErr).To(MatchError(adapter.ErrInvalidAvatarBackground))
+		})
+	})
+})
@@ -0,0 +1,145 @@
+package account_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"shikposh-backend/config"
+	"shikposh-backend/internal/account/adapter"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	"shikposh-backend/internal/account/domain/events"
+	"shikposh-backend/internal/account/service_layer/command_handler"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"shikposh-backend/test/unit/testdouble/builders"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("AvatarHandler", func() {
+	var (
+		builder *builders.UserTestBuilder
+		handler *command_handler.AvatarHandler
+		dir     string
+		ctx     context.Context
+	)
+
+	pngOf := func(width, height int) []byte {
+		var content bytes.Buffer
+		Expect(png.Encode(&content, image.NewNRGBA(image.Rect(0, 0, width, height)))).To(Succeed())
+		return content.Bytes()
+	}
+
+	BeforeEach(func() {
+		builder = builders.NewUserTestBuilder().
+			WithUserRepo().
+			WithReviewRepo().
+			WithAvatarUploadLimit(64).
+			WithSuccessfulTransaction()
+		dir = GinkgoT().TempDir()
+		storage, err := adapter.NewFileStorage(config.StorageConfig{Dir: dir})
+		Expect(err).NotTo(HaveOccurred())
+		handler = builder.BuildAvatarHandler(storage)
+		ctx = context.Background()
+	})
+
+	Describe("UploadAvatarHandler", func() {
+		Context("when the upload is an image", func() {
+			It("should store it at avatar size and show it on the reviews of the user", func() {
+				// Phase 1: Setup (Arrange)
+				user := &entity.User{ID: 1, UserName: "sara", AvatarIdentifier: "avatar123"}
+				builder.MockUserRepo.On("FindByID", mock.Anything, uint64(1)).Return(user, nil).Once()
+				builder.MockUserRepo.On("Modify", mock.Anything, user).Return(nil).Once()
+				builder.MockReviewRepo.On("UpdateUserAvatar", mock.Anything, user.ID, mock.AnythingOfType("string")).Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.UploadAvatarHandler(ctx, &commands.UploadAvatar{UserID: 1, Content: pngOf(300, 200)})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(user.AvatarURL).NotTo(BeNil())
+				Expect(user.AvatarLink()).To(Equal("/media/" + user.AvatarKey))
+				builder.MockReviewRepo.AssertCalled(GinkgoT(), "UpdateUserAvatar", mock.Anything, user.ID, user.AvatarLink())
+				stored, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(user.AvatarKey)))
+				Expect(err).NotTo(HaveOccurred())
+				img, err := png.Decode(bytes.NewReader(stored))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(img.Bounds().Dx()).To(Equal(adapter.DefaultAvatarSize))
+				Expect(img.Bounds().Dy()).To(Equal(adapter.DefaultAvatarSize))
+			})
+		})
+
+		Context("when the upload is larger than the limit", func() {
+			It("should return validation error without storing it", func() {
+				// Phase 1: Setup (Arrange)
+				content := make([]byte, 65<<10)
+
+				// Phase 2: Exercise (Act)
+				err := handler.UploadAvatarHandler(ctx, &commands.UploadAvatar{UserID: 1, Content: content})
+
+				// Phase 3: Verify (Assert)
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+				Expect(os.ReadDir(dir)).To(BeEmpty())
+			})
+		})
+
+		Context("when the upload is not an image", func() {
+			It("should return validation error", func() {
+				// Phase 2: Exercise (Act)
+				err := handler.UploadAvatarHandler(ctx, &commands.UploadAvatar{UserID: 1, Content: []byte("GIF89a but not really")})
+
+				// Phase 3: Verify (Assert)
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+			})
+		})
+	})
+
+	Describe("RejectAvatarHandler", func() {
+		Context("when the user uploaded an avatar", func() {
+			It("should fall back to the generated avatar and discard the upload", func() {
+				// Phase 1: Setup (Arrange)
+				avatarURL := "/media/avatars/1-abc.png"
+				user := &entity.User{ID: 1, AvatarIdentifier: "avatar123", AvatarKey: "avatars/1-abc.png", AvatarURL: &avatarURL}
+				builder.MockUserRepo.On("FindByID", mock.Anything, uint64(1)).Return(user, nil).Once()
+				builder.MockUserRepo.On("Modify", mock.Anything, user).Return(nil).Once()
+				builder.MockReviewRepo.On("UpdateUserAvatar", mock.Anything, user.ID, "/api/v1/public/avatar/avatar123").Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.RejectAvatarHandler(ctx, &commands.RejectAvatar{UserID: 1, ActorID: 9})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(user.AvatarKey).To(BeEmpty())
+				Expect(user.AvatarURL).To(BeNil())
+				Expect(user.Events()).To(ContainElement(&events.AvatarDiscardedEvent{UserID: 1, Key: "avatars/1-abc.png"}))
+				builder.MockReviewRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when the user has no uploaded avatar", func() {
+			It("should change nothing", func() {
+				// Phase 1: Setup (Arrange)
+				user := &entity.User{ID: 1, AvatarIdentifier: "avatar123"}
+				builder.MockUserRepo.On("FindByID", mock.Anything, uint64(1)).Return(user, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.RejectAvatarHandler(ctx, &commands.RejectAvatar{UserID: 1, ActorID: 9})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				builder.MockUserRepo.AssertNotCalled(GinkgoT(), "Modify", mock.Anything, mock.Anything)
+				builder.MockReviewRepo.AssertNotCalled(GinkgoT(), "UpdateUserAvatar", mock.Anything, mock.Anything, mock.Anything)
+			})
+		})
+	})
+})
@@ -0,0 +1,187 @@
+package account_test
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/config"
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	"shikposh-backend/internal/account/service_layer/command_handler"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"shikposh-backend/test/unit/testdouble/builders"
+	"shikposh-backend/test/unit/testdouble/factories"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("Login protection", func() {
+	var (
+		builder *builders.UserTestBuilder
+		handler *command_handler.UserHandler
+		user    *entity.User
+		ctx     context.Context
+	)
+
+	login := func(password string) (*command_handler.LoginResult, error) {
+		cmd := factories.CreateLoginCommand("existinguser", password)
+		cmd.IP = "10.0.0.1"
+		return handler.LoginHandler(ctx, cmd)
+	}
+
+	BeforeEach(func() {
+		builder = builders.NewUserTestBuilder().
+			WithUserRepo().
+			WithSessionRepo().
+			WithRefreshTokenRepo().
+			WithAccountUnlockRepo().
+			WithSuccessfulTransaction()
+		ctx = context.Background()
+		user = factories.CreateUser("existinguser", "user@example.com", "password123")
+		builder.MockUserRepo.On("FindByUserName", mock.Anything, "existinguser").
+			Return(user, nil).Maybe()
+	})
+
+	Describe("LoginHandler", func() {
+		Context("when the free attempts are used up", func() {
+			It("should throttle the next login without checking the credentials", func() {
+				// Phase 1: Setup (Arrange)
+				handler = builder.
+					WithLoginProtection(config.LoginConfig{FreeAttempts: 2, IPFreeAttempts: 10, BackoffBase: 60}).
+					BuildHandler()
+				for range 3 {
+					_, err := login("wrongpassword")
+					Expect(err).To(HaveOccurred())
+				}
+
+				// Phase 2: Exercise (Act)
+				_, err := login("password123")
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(MatchError(command_handler.ErrTooManyRequests))
+				builder.MockUserRepo.AssertNumberOfCalls(GinkgoT(), "FindByUserName", 3)
+			})
+		})
+
+		Context("when the failures stay within the free attempts", func() {
+			It("should accept the correct password", func() {
+				// Phase 1: Setup (Arrange)
+				handler = builder.
+					WithLoginProtection(config.LoginConfig{FreeAttempts: 2, IPFreeAttempts: 10, BackoffBase: 60}).
+					BuildHandler()
+				_, err := login("wrongpassword")
+				Expect(err).To(HaveOccurred())
+				builder.MockSessionRepo.On("Save", mock.Anything, mock.AnythingOfType("*entity.Session")).
+					Return(nil).Once()
+				builder.MockRefreshTokenRepo.On("Save", mock.Anything, mock.AnythingOfType("*entity.RefreshToken")).
+					Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				result, err := login("password123")
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Access).NotTo(BeEmpty())
+			})
+		})
+
+		Context("when the failures reach the lockout threshold", func() {
+			It("should lock the account", func() {
+				// Phase 1: Setup (Arrange)
+				handler = builder.
+					WithLoginProtection(config.LoginConfig{FreeAttempts: 10, LockoutThreshold: 3, LockoutDuration: 600}).
+					BuildHandler()
+				builder.MockUserRepo.On("Modify", mock.Anything, user).
+					Return(nil).Once()
+				for range 2 {
+					_, err := login("wrongpassword")
+					Expect(err).To(HaveOccurred())
+				}
+
+				// Phase 2: Exercise (Act)
+				_, err := login("wrongpassword")
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeUnauthorized))
+				Expect(user.IsLocked(time.Now())).To(BeTrue())
+				Expect(*user.LockedUntil).To(BeTemporally("~", time.Now().Add(10*time.Minute), time.Second))
+				builder.MockUserRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when the account is locked", func() {
+			It("should return forbidden error even for the correct password", func() {
+				// Phase 1: Setup (Arrange)
+				handler = builder.BuildHandler()
+				user.Lock(time.Now().Add(time.Minute))
+
+				// Phase 2: Exercise (Act)
+				_, err := login("password123")
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeForbidden))
+				builder.MockSessionRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+			})
+		})
+	})
+
+	Describe("UnlockAccountHandler", func() {
+		Context("when the unlock token is valid", func() {
+			It("should unlock the account and forget the failed logins", func() {
+				// Phase 1: Setup (Arrange)
+				handler = builder.
+					WithLoginProtection(config.LoginConfig{BackoffBase: 60}).
+					BuildHandler()
+				_, err := login("wrongpassword")
+				Expect(err).To(HaveOccurred())
+				user.Lock(time.Now().Add(time.Hour))
+				token, plain, _ := entity.NewAccountUnlockToken(user.ID, *user.LockedUntil)
+				builder.MockUnlockRepo.On("FindByTokenHash", mock.Anything, entity.HashToken(plain)).
+					Return(token, nil).Once()
+				builder.MockUnlockRepo.On("MarkUsed", mock.Anything, token, mock.AnythingOfType("time.Time")).
+					Return(true, nil).Once()
+				builder.MockUserRepo.On("FindByID", mock.Anything, uint64(user.ID)).
+					Return(user, nil).Once()
+				builder.MockUserRepo.On("Modify", mock.Anything, user).
+					Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err = handler.UnlockAccountHandler(ctx, &commands.UnlockAccount{Token: plain})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(user.IsLocked(time.Now())).To(BeFalse())
+				blockedFor, err := builder.LoginAttempts.BlockedFor(ctx, "user:existinguser")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(blockedFor).To(BeZero())
+			})
+		})
+
+		Context("when the unlock token is unknown", func() {
+			It("should return validation error", func() {
+				// Phase 1: Setup (Arrange)
+				handler = builder.BuildHandler()
+				builder.MockUnlockRepo.On("FindByTokenHash", mock.Anything, entity.HashToken("unknown")).
+					Return(nil, repository.ErrAccountUnlockTokenNotFound).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.UnlockAccountHandler(ctx, &commands.UnlockAccount{Token: "unknown"})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+			})
+		})
+	})
+})
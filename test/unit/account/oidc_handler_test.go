@@ -0,0 +1,235 @@
+package account_test
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"shikposh-backend/internal/account/adapter"
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	"shikposh-backend/internal/account/service_layer/command_handler"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"shikposh-backend/test/unit/testdouble/builders"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("OidcHandler", func() {
+	var (
+		builder       *builders.UserTestBuilder
+		handler       *command_handler.OidcHandler
+		authorization *entity.OidcAuthorization
+		state         string
+		ctx           context.Context
+	)
+
+	// expectAuthorization makes the state resolve to a fresh authorization of
+	// the fake provider.
+	expectAuthorization := func() {
+		builder.MockOidcAuthRepo.On("FindByStateHash", mock.Anything, entity.HashToken(state)).
+			Return(authorization, nil).Once()
+		builder.MockOidcAuthRepo.On("MarkUsed", mock.Anything, authorization, mock.Anything).
+			Return(true, nil).Once()
+	}
+
+	expectSession := func() {
+		builder.MockSessionRepo.On("Save", mock.Anything, mock.AnythingOfType("*entity.Session")).Return(nil).Once()
+		builder.MockRefreshTokenRepo.On("Save", mock.Anything, mock.AnythingOfType("*entity.RefreshToken")).Return(nil).Once()
+	}
+
+	BeforeEach(func() {
+		builder = builders.NewUserTestBuilder().
+			WithUserRepo().
+			WithSessionRepo().
+			WithRefreshTokenRepo().
+			WithOidcRepos().
+			WithSuccessfulTransaction()
+		handler = builder.BuildOidcHandler()
+		ctx = context.Background()
+
+		var err error
+		authorization, state, err = entity.NewOidcAuthorization("fake", time.Now().Add(time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("StartOidcLoginHandler", func() {
+		Context("when the provider is configured", func() {
+			It("should send the user there with a PKCE challenge and remember the attempt", func() {
+				// Phase 1: Setup (Arrange)
+				var saved *entity.OidcAuthorization
+				builder.MockOidcAuthRepo.On("Save", mock.Anything, mock.AnythingOfType("*entity.OidcAuthorization")).
+					Run(func(args mock.Arguments) {
+						saved = args.Get(1).(*entity.OidcAuthorization)
+					}).Return(nil).Once()
+				builder.MockIdentityProvider.On("AuthCodeURL", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Return("https://idp.test/authorize?state=abc", nil).Once()
+
+				// Phase 2: Exercise (Act)
+				start, err := handler.StartOidcLoginHandler(ctx, &commands.StartOidcLogin{Provider: "fake"})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(start.AuthorizationURL).To(Equal("https://idp.test/authorize?state=abc"))
+				Expect(saved.Provider).To(Equal("fake"))
+				call := builder.MockIdentityProvider.Calls[0]
+				Expect(entity.HashToken(call.Arguments.String(1))).To(Equal(saved.StateHash))
+				Expect(call.Arguments.String(2)).To(Equal(saved.Nonce))
+				Expect(call.Arguments.String(3)).To(Equal(saved.CodeChallenge()))
+				Expect(call.Arguments.String(3)).NotTo(Equal(saved.CodeVerifier))
+			})
+		})
+
+		Context("when the provider is not configured", func() {
+			It("should return validation error", func() {
+				// Phase 2: Exercise (Act)
+				_, err := handler.StartOidcLoginHandler(ctx, &commands.StartOidcLogin{Provider: "myspace"})
+
+				// Phase 3: Verify (Assert)
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+			})
+		})
+	})
+
+	Describe("CompleteOidcLoginHandler", func() {
+		Context("when the provider account is unknown and its email is free", func() {
+			It("should register a user with the verified email and open a session", func() {
+				// Phase 1: Setup (Arrange)
+				expectAuthorization()
+				identity := &adapter.ExternalIdentity{Provider: "fake", Subject: "42", Email: "sara@example.com", EmailVerified: true, GivenName: "Sara"}
+				builder.MockIdentityProvider.On("Exchange", mock.Anything, "code-1", authorization.CodeVerifier, authorization.Nonce).
+					Return(identity, nil).Once()
+				builder.MockExternalIDRepo.On("FindByProviderAndSubject", mock.Anything, "fake", "42").
+					Return(nil, repository.ErrExternalIdentityNotFound).Once()
+				builder.MockUserRepo.On("FindByEmail", mock.Anything, "sara@example.com").
+					Return(nil, repository.ErrUserNotFound).Once()
+				var created *entity.User
+				builder.MockUserRepo.On("Save", mock.Anything, mock.AnythingOfType("*entity.User")).
+					Run(func(args mock.Arguments) {
+						created = args.Get(1).(*entity.User)
+						created.ID = 7
+					}).Return(nil).Once()
+				builder.MockExternalIDRepo.On("Save", mock.Anything, mock.MatchedBy(func(e *entity.ExternalIdentity) bool {
+					return e.UserID == 7 && e.Provider == "fake" && e.Subject == "42"
+				})).Return(nil).Once()
+				expectSession()
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.CompleteOidcLoginHandler(ctx, &commands.CompleteOidcLogin{Provider: "fake", Code: "code-1", State: state})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Access).NotTo(BeEmpty())
+				Expect(created.UserName).To(Equal("fake_42"))
+				Expect(created.FirstName).To(Equal("Sara"))
+				Expect(created.IsEmailVerified()).To(BeTrue())
+				builder.MockExternalIDRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when a verified user already has the verified email", func() {
+			It("should link the provider account to that user", func() {
+				// Phase 1: Setup (Arrange)
+				expectAuthorization()
+				verifiedAt := time.Now().Add(-time.Hour)
+				owner := &entity.User{ID: 3, UserName: "sara", Email: "sara@example.com", EmailVerifiedAt: &verifiedAt}
+				builder.MockIdentityProvider.On("Exchange", mock.Anything, "code-1", mock.Anything, mock.Anything).
+					Return(&adapter.ExternalIdentity{Provider: "fake", Subject: "42", Email: owner.Email, EmailVerified: true}, nil).Once()
+				builder.MockExternalIDRepo.On("FindByProviderAndSubject", mock.Anything, "fake", "42").
+					Return(nil, repository.ErrExternalIdentityNotFound).Once()
+				builder.MockUserRepo.On("FindByEmail", mock.Anything, owner.Email).Return(owner, nil).Once()
+				builder.MockExternalIDRepo.On("Save", mock.Anything, mock.MatchedBy(func(e *entity.ExternalIdentity) bool {
+					return e.UserID == owner.ID
+				})).Return(nil).Once()
+				expectSession()
+
+				// Phase 2: Exercise (Act)
+				_, err := handler.CompleteOidcLoginHandler(ctx, &commands.CompleteOidcLogin{Provider: "fake", Code: "code-1", State: state})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				builder.MockUserRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+				builder.MockExternalIDRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when the provider did not verify an email that is already used", func() {
+			It("should return conflict error", func() {
+				// Phase 1: Setup (Arrange)
+				expectAuthorization()
+				verifiedAt := time.Now().Add(-time.Hour)
+				owner := &entity.User{ID: 3, Email: "sara@example.com", EmailVerifiedAt: &verifiedAt}
+				builder.MockIdentityProvider.On("Exchange", mock.Anything, "code-1", mock.Anything, mock.Anything).
+					Return(&adapter.ExternalIdentity{Provider: "fake", Subject: "42", Email: owner.Email}, nil).Once()
+				builder.MockExternalIDRepo.On("FindByProviderAndSubject", mock.Anything, "fake", "42").
+					Return(nil, repository.ErrExternalIdentityNotFound).Once()
+				builder.MockUserRepo.On("FindByEmail", mock.Anything, owner.Email).Return(owner, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.CompleteOidcLoginHandler(ctx, &commands.CompleteOidcLogin{Provider: "fake", Code: "code-1", State: state})
+
+				// Phase 3: Verify (Assert)
+				Expect(result).To(BeNil())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeConflict))
+				builder.MockExternalIDRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+			})
+		})
+
+		Context("when the state belongs to another provider", func() {
+			It("should return unauthorized error without calling the provider", func() {
+				// Phase 1: Setup (Arrange)
+				authorization.Provider = "other"
+				builder.MockOidcAuthRepo.On("FindByStateHash", mock.Anything, entity.HashToken(state)).
+					Return(authorization, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				_, err := handler.CompleteOidcLoginHandler(ctx, &commands.CompleteOidcLogin{Provider: "fake", Code: "code-1", State: state})
+
+				// Phase 3: Verify (Assert)
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeUnauthorized))
+				builder.MockIdentityProvider.AssertNotCalled(GinkgoT(), "Exchange", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			})
+		})
+
+		Context("when the provider rejects the code", func() {
+			It("should return unauthorized error", func() {
+				// Phase 1: Setup (Arrange)
+				expectAuthorization()
+				builder.MockIdentityProvider.On("Exchange", mock.Anything, "code-1", mock.Anything, mock.Anything).
+					Return(nil, adapter.ErrIdentityProviderRejected).Once()
+
+				// Phase 2: Exercise (Act)
+				_, err := handler.CompleteOidcLoginHandler(ctx, &commands.CompleteOidcLogin{Provider: "fake", Code: "code-1", State: state})
+
+				// Phase 3: Verify (Assert)
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeUnauthorized))
+			})
+		})
+	})
+})
+
+var _ = Describe("OidcAuthorization", func() {
+	It("should derive the S256 challenge of RFC 7636", func() {
+		// Phase 1: Setup (Arrange)
+		authorization := &entity.OidcAuthorization{CodeVerifier: "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"}
+
+		// Phase 2: Exercise (Act)
+		challenge := authorization.CodeChallenge()
+
+		// Phase 3: Verify (Assert)
+		Expect(challenge).To(Equal("E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"))
+		_, err := url.ParseQuery("code_challenge=" + challenge)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
@@ -0,0 +1,239 @@
+package account_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"shikposh-backend/internal/account/adapter"
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	"shikposh-backend/internal/account/service_layer/command_handler"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"shikposh-backend/test/unit/testdouble/builders"
+	"shikposh-backend/test/unit/testdouble/factories"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+// racingOtpStore lets another verification consume each code right after it
+// was read, as a concurrent request with the same code would.
+type racingOtpStore struct {
+	adapter.OtpStore
+}
+
+func (s racingOtpStore) Get(ctx context.Context, phone string) (string, int, error) {
+	codeHash, attempts, err := s.OtpStore.Get(ctx, phone)
+	if err == nil {
+		if _, err := s.OtpStore.Consume(ctx, phone, codeHash); err != nil {
+			return "", 0, err
+		}
+	}
+	return codeHash, attempts, err
+}
+
+var _ = Describe("OtpHandler", func() {
+	var (
+		builder *builders.UserTestBuilder
+		handler *command_handler.OtpHandler
+		store   adapter.OtpStore
+		ctx     context.Context
+	)
+
+	// requestCode asks for a code and returns the one that was texted. Every
+	// spec uses a form of the number 09121234567.
+	requestCode := func(phone string) string {
+		var code string
+		builder.MockSms.On("Send", mock.Anything, "+989121234567", mock.AnythingOfType("string")).
+			Run(func(args mock.Arguments) {
+				code = regexp.MustCompile(`\d+$`).FindString(args.String(2))
+			}).Return(nil).Once()
+		Expect(handler.RequestOtpHandler(ctx, &commands.RequestOtp{Phone: phone})).To(Succeed())
+		return code
+	}
+
+	BeforeEach(func() {
+		builder = builders.NewUserTestBuilder().
+			WithUserRepo().
+			WithProfileRepo().
+			WithSessionRepo().
+			WithRefreshTokenRepo().
+			WithSuccessfulTransaction()
+		store = adapter.NewMemoryOtpStore()
+		handler = builder.BuildOtpHandler(store)
+		ctx = context.Background()
+	})
+
+	Describe("RequestOtpHandler", func() {
+		Context("when the phone is written in international form", func() {
+			It("should text a code to the normalized number", func() {
+				// Phase 1: Setup (Arrange)
+				phone := "+98 912 123 4567"
+
+				// Phase 2: Exercise (Act)
+				code := requestCode(phone)
+
+				// Phase 3: Verify (Assert)
+				Expect(code).To(HaveLen(6))
+				builder.MockSms.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when a code was sent within the limiter window", func() {
+			It("should return too many requests error", func() {
+				// Phase 1: Setup (Arrange)
+				requestCode("09121234567")
+
+				// Phase 2: Exercise (Act)
+				err := handler.RequestOtpHandler(ctx, &commands.RequestOtp{Phone: "09121234567"})
+
+				// Phase 3: Verify (Assert)
+				Expect(errors.Is(err, command_handler.ErrTooManyRequests)).To(BeTrue())
+				builder.MockSms.AssertNumberOfCalls(GinkgoT(), "Send", 1)
+			})
+		})
+
+		Context("when the phone is not an Iranian mobile number", func() {
+			It("should return validation error", func() {
+				// Phase 1: Setup (Arrange)
+				cmd := &commands.RequestOtp{Phone: "02112345678"}
+
+				// Phase 2: Exercise (Act)
+				err := handler.RequestOtpHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+				builder.MockSms.AssertNotCalled(GinkgoT(), "Send", mock.Anything, mock.Anything, mock.Anything)
+			})
+		})
+	})
+
+	Describe("VerifyOtpHandler", func() {
+		Context("when the code is correct and the phone is unknown", func() {
+			It("should create the user with its profile and open a session", func() {
+				// Phase 1: Setup (Arrange)
+				code := requestCode("09121234567")
+				builder.MockProfileRepo.On("FindByPhone", mock.Anything, "+989121234567").
+					Return(nil, repository.ErrProfileNotFound).Once()
+				builder.MockUserRepo.On("Save", mock.Anything, mock.MatchedBy(func(u *entity.User) bool {
+					return u.UserName == "+989121234567" && u.Email == ""
+				})).Return(nil).Once()
+				builder.MockProfileRepo.On("Save", mock.Anything, mock.MatchedBy(func(p *entity.Profile) bool {
+					return p.Phone == "+989121234567"
+				})).Return(nil).Once()
+				builder.MockSessionRepo.On("Save", mock.Anything, mock.AnythingOfType("*entity.Session")).
+					Return(nil).Once()
+				builder.MockRefreshTokenRepo.On("Save", mock.Anything, mock.AnythingOfType("*entity.RefreshToken")).
+					Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.VerifyOtpHandler(ctx, &commands.VerifyOtp{Phone: "09121234567", Code: code})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Access).NotTo(BeEmpty())
+				builder.MockUserRepo.AssertExpectations(GinkgoT())
+				builder.MockProfileRepo.AssertExpectations(GinkgoT())
+				_, _, err = store.Get(ctx, "+989121234567")
+				Expect(err).To(MatchError(adapter.ErrOtpNotFound))
+			})
+		})
+
+		Context("when the code is correct and the phone belongs to a user", func() {
+			It("should log the existing user in", func() {
+				// Phase 1: Setup (Arrange)
+				code := requestCode("09121234567")
+				user := factories.CreateUser("existinguser", "user@example.com", "password123")
+				profile := &entity.Profile{ID: 1, UserID: user.ID, Phone: "+989121234567"}
+				builder.MockProfileRepo.On("FindByPhone", mock.Anything, "+989121234567").
+					Return(profile, nil).Once()
+				builder.MockUserRepo.On("FindByID", mock.Anything, uint64(user.ID)).
+					Return(user, nil).Once()
+				builder.MockSessionRepo.On("Save", mock.Anything, mock.MatchedBy(func(s *entity.Session) bool {
+					return s.UserID == user.ID
+				})).Return(nil).Once()
+				builder.MockRefreshTokenRepo.On("Save", mock.Anything, mock.AnythingOfType("*entity.RefreshToken")).
+					Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.VerifyOtpHandler(ctx, &commands.VerifyOtp{Phone: "09121234567", Code: code})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Refresh).NotTo(BeEmpty())
+				builder.MockUserRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+			})
+		})
+
+		Context("when the phone belongs to a locked user", func() {
+			It("should return forbidden error without opening a session", func() {
+				// Phase 1: Setup (Arrange)
+				code := requestCode("09121234567")
+				user := factories.CreateUser("lockeduser", "locked@example.com", "password123")
+				user.Lock(time.Now().Add(10 * time.Minute))
+				profile := &entity.Profile{ID: 1, UserID: user.ID, Phone: "+989121234567"}
+				builder.MockProfileRepo.On("FindByPhone", mock.Anything, "+989121234567").
+					Return(profile, nil)
+				builder.MockUserRepo.On("FindByID", mock.Anything, uint64(user.ID)).
+					Return(user, nil)
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.VerifyOtpHandler(ctx, &commands.VerifyOtp{Phone: "09121234567", Code: code})
+
+				// Phase 3: Verify (Assert)
+				Expect(result).To(BeNil())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeForbidden))
+				builder.MockSessionRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+			})
+		})
+
+		Context("when another verification consumed the code first", func() {
+			It("should return unauthorized error", func() {
+				// Phase 1: Setup (Arrange)
+				handler = builder.BuildOtpHandler(racingOtpStore{OtpStore: store})
+				code := requestCode("09121234567")
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.VerifyOtpHandler(ctx, &commands.VerifyOtp{Phone: "09121234567", Code: code})
+
+				// Phase 3: Verify (Assert)
+				Expect(result).To(BeNil())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeUnauthorized))
+				builder.MockProfileRepo.AssertNotCalled(GinkgoT(), "FindByPhone", mock.Anything, mock.Anything)
+				builder.MockSessionRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+			})
+		})
+
+		Context("when too many wrong codes were tried", func() {
+			It("should reject even the correct code", func() {
+				// Phase 1: Setup (Arrange)
+				code := requestCode("09121234567")
+				for range 5 {
+					_, err := handler.VerifyOtpHandler(ctx, &commands.VerifyOtp{Phone: "09121234567", Code: "000000" + code})
+					Expect(err).To(HaveOccurred())
+				}
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.VerifyOtpHandler(ctx, &commands.VerifyOtp{Phone: "09121234567", Code: code})
+
+				// Phase 3: Verify (Assert)
+				Expect(result).To(BeNil())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeUnauthorized))
+				builder.MockSessionRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+			})
+		})
+	})
+})
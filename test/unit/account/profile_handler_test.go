@@ -0,0 +1,206 @@
+package account_test
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	"shikposh-backend/internal/account/service_layer/command_handler"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"shikposh-backend/test/unit/testdouble/builders"
+	"shikposh-backend/test/unit/testdouble/factories"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("Profile management", func() {
+	var (
+		builder *builders.UserTestBuilder
+		handler *command_handler.UserHandler
+		user    *entity.User
+		ctx     context.Context
+	)
+
+	BeforeEach(func() {
+		builder = builders.NewUserTestBuilder().
+			WithUserRepo().
+			WithProfileRepo().
+			WithSuccessfulTransaction()
+		handler = builder.BuildHandler()
+		ctx = context.Background()
+		user = factories.CreateUser("existinguser", "user@example.com", "password123")
+		verifiedAt := time.Now()
+		user.EmailVerifiedAt = &verifiedAt
+		builder.MockUserRepo.On("FindByID", mock.Anything, uint64(user.ID)).
+			Return(user, nil).Maybe()
+	})
+
+	Describe("UpdateUserHandler", func() {
+		Context("when the email address changes", func() {
+			It("should save it as unverified", func() {
+				// Phase 1: Setup (Arrange)
+				email := "new@example.com"
+				builder.MockUserRepo.On("FindByEmail", mock.Anything, email).
+					Return(nil, repository.ErrUserNotFound).Once()
+				builder.MockUserRepo.On("Modify", mock.Anything, mock.MatchedBy(func(u *entity.User) bool {
+					return u.Email == email && !u.IsEmailVerified() && u.FirstName == "John"
+				})).Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.UpdateUserHandler(ctx, &commands.UpdateUser{UserID: uint64(user.ID), Email: &email})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				builder.MockUserRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when only the name changes", func() {
+			It("should keep the email address verified", func() {
+				// Phase 1: Setup (Arrange)
+				firstName := "Jane"
+				builder.MockUserRepo.On("Modify", mock.Anything, mock.MatchedBy(func(u *entity.User) bool {
+					return u.FirstName == firstName && u.IsEmailVerified()
+				})).Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.UpdateUserHandler(ctx, &commands.UpdateUser{UserID: uint64(user.ID), FirstName: &firstName})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				builder.MockUserRepo.AssertNotCalled(GinkgoT(), "FindByEmail", mock.Anything, mock.Anything)
+			})
+		})
+
+		Context("when the email address belongs to another user", func() {
+			It("should return conflict error", func() {
+				// Phase 1: Setup (Arrange)
+				email := "taken@example.com"
+				other := factories.CreateUser("otheruser", email, "password123")
+				other.ID = 2
+				builder.MockUserRepo.On("FindByEmail", mock.Anything, email).
+					Return(other, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.UpdateUserHandler(ctx, &commands.UpdateUser{UserID: uint64(user.ID), Email: &email})
+
+				// Phase 3: Verify (Assert)
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeConflict))
+				builder.MockUserRepo.AssertNotCalled(GinkgoT(), "Modify", mock.Anything, mock.Anything)
+			})
+		})
+	})
+
+	Describe("UpdateProfileHandler", func() {
+		var profile *entity.Profile
+
+		BeforeEach(func() {
+			profile = &entity.Profile{ID: 1, UserID: user.ID}
+		})
+
+		Context("when the phone is written in local form", func() {
+			It("should store it in E.164 form", func() {
+				// Phase 1: Setup (Arrange)
+				phone := "0912 123 4567"
+				builder.MockProfileRepo.On("FindByUserID", mock.Anything, user.ID).
+					Return(profile, nil).Once()
+				builder.MockProfileRepo.On("FindByPhone", mock.Anything, "+989121234567").
+					Return(nil, repository.ErrProfileNotFound).Once()
+				builder.MockProfileRepo.On("Modify", mock.Anything, mock.MatchedBy(func(p *entity.Profile) bool {
+					return p.Phone == "+989121234567"
+				})).Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.UpdateProfileHandler(ctx, &commands.UpdateProfile{UserID: uint64(user.ID), Phone: &phone})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				builder.MockProfileRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when the phone is not a mobile number", func() {
+			It("should return validation error", func() {
+				// Phase 1: Setup (Arrange)
+				phone := "021 1234 5678"
+
+				// Phase 2: Exercise (Act)
+				err := handler.UpdateProfileHandler(ctx, &commands.UpdateProfile{UserID: uint64(user.ID), Phone: &phone})
+
+				// Phase 3: Verify (Assert)
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+				builder.MockProfileRepo.AssertNotCalled(GinkgoT(), "FindByUserID", mock.Anything, mock.Anything)
+			})
+		})
+
+		Context("when the phone belongs to another user", func() {
+			It("should return conflict error", func() {
+				// Phase 1: Setup (Arrange)
+				phone := "+989121234567"
+				builder.MockProfileRepo.On("FindByUserID", mock.Anything, user.ID).
+					Return(profile, nil).Once()
+				builder.MockProfileRepo.On("FindByPhone", mock.Anything, phone).
+					Return(&entity.Profile{ID: 2, UserID: 2, Phone: phone}, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.UpdateProfileHandler(ctx, &commands.UpdateProfile{UserID: uint64(user.ID), Phone: &phone})
+
+				// Phase 3: Verify (Assert)
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeConflict))
+				builder.MockProfileRepo.AssertNotCalled(GinkgoT(), "Modify", mock.Anything, mock.Anything)
+			})
+		})
+
+		Context("when a user without password removes their phone", func() {
+			It("should refuse since they could no longer sign in", func() {
+				// Phase 1: Setup (Arrange)
+				phoneUser := entity.NewPhoneUser("+989121234567")
+				phoneUser.ID = 3
+				profile = &entity.Profile{ID: 3, UserID: phoneUser.ID, Phone: "+989121234567"}
+				empty := ""
+				builder.MockUserRepo.On("FindByID", mock.Anything, uint64(phoneUser.ID)).
+					Return(phoneUser, nil).Once()
+				builder.MockProfileRepo.On("FindByUserID", mock.Anything, phoneUser.ID).
+					Return(profile, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.UpdateProfileHandler(ctx, &commands.UpdateProfile{UserID: uint64(phoneUser.ID), Phone: &empty})
+
+				// Phase 3: Verify (Assert)
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+				builder.MockProfileRepo.AssertNotCalled(GinkgoT(), "Modify", mock.Anything, mock.Anything)
+			})
+		})
+
+		Context("when the profile was not created yet", func() {
+			It("should create it", func() {
+				// Phase 1: Setup (Arrange)
+				bio := "Hello"
+				builder.MockProfileRepo.On("FindByUserID", mock.Anything, user.ID).
+					Return(nil, repository.ErrProfileNotFound).Once()
+				builder.MockProfileRepo.On("Save", mock.Anything, mock.MatchedBy(func(p *entity.Profile) bool {
+					return p.UserID == user.ID && p.Bio == bio
+				})).Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.UpdateProfileHandler(ctx, &commands.UpdateProfile{UserID: uint64(user.ID), Bio: &bio})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				builder.MockProfileRepo.AssertExpectations(GinkgoT())
+			})
+		})
+	})
+})
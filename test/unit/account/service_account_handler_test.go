@@ -0,0 +1,196 @@
+package account_test
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	"shikposh-backend/internal/account/service_layer/command_handler"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"shikposh-backend/test/unit/testdouble/builders"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("ServiceAccountHandler", func() {
+	var (
+		builder *builders.UserTestBuilder
+		handler *command_handler.ServiceAccountHandler
+		account *entity.ServiceAccount
+		ctx     context.Context
+	)
+
+	BeforeEach(func() {
+		builder = builders.NewUserTestBuilder().
+			WithServiceAccountRepos().
+			WithRoleRepo().
+			WithSuccessfulTransaction()
+		handler = builder.BuildServiceAccountHandler()
+		account = &entity.ServiceAccount{ID: 3, Name: "erp-import"}
+		ctx = context.Background()
+	})
+
+	Describe("CreateServiceAccountHandler", func() {
+		Context("when every scope names a permission", func() {
+			It("should grant the permissions to the new account", func() {
+				// Phase 1: Setup (Arrange)
+				permissions := []*entity.Permission{{ID: 1, Name: "products:write"}, {ID: 2, Name: "reviews:moderate"}}
+				builder.MockServiceAcctRepo.On("FindByName", mock.Anything, "price-sync").
+					Return(nil, repository.ErrServiceAccountNotFound).Once()
+				builder.MockRoleRepo.On("FindPermissionsByNames", mock.Anything, []string{"products:write", "reviews:moderate"}).
+					Return(permissions, nil).Once()
+				builder.MockServiceAcctRepo.On("Save", mock.Anything, mock.AnythingOfType("*entity.ServiceAccount")).
+					Return(nil).Once()
+				cmd := &commands.CreateServiceAccount{
+					Name:    "price-sync",
+					Scopes:  []string{"reviews:moderate", "products:write", "products:write"},
+					ActorID: 99,
+				}
+
+				// Phase 2: Exercise (Act)
+				created, err := handler.CreateServiceAccountHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(created.ScopeNames()).To(ConsistOf("products:write", "reviews:moderate"))
+				builder.MockServiceAcctRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when a scope names no permission", func() {
+			It("should return validation error", func() {
+				// Phase 1: Setup (Arrange)
+				builder.MockServiceAcctRepo.On("FindByName", mock.Anything, "price-sync").
+					Return(nil, repository.ErrServiceAccountNotFound).Once()
+				builder.MockRoleRepo.On("FindPermissionsByNames", mock.Anything, []string{"prices:write", "products:write"}).
+					Return([]*entity.Permission{{ID: 1, Name: "products:write"}}, nil).Once()
+				cmd := &commands.CreateServiceAccount{Name: "price-sync", Scopes: []string{"products:write", "prices:write"}}
+
+				// Phase 2: Exercise (Act)
+				created, err := handler.CreateServiceAccountHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(created).To(BeNil())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+				builder.MockServiceAcctRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+			})
+		})
+
+		Context("when a scope administers accounts", func() {
+			It("should return validation error", func() {
+				// Phase 1: Setup (Arrange)
+				builder.MockServiceAcctRepo.On("FindByName", mock.Anything, "price-sync").
+					Return(nil, repository.ErrServiceAccountNotFound)
+				cmd := &commands.CreateServiceAccount{
+					Name:   "price-sync",
+					Scopes: []string{"products:write", commands.UsersWritePermission},
+				}
+
+				// Phase 2: Exercise (Act)
+				created, err := handler.CreateServiceAccountHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(created).To(BeNil())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+				builder.MockRoleRepo.AssertNotCalled(GinkgoT(), "FindPermissionsByNames", mock.Anything, mock.Anything)
+				builder.MockServiceAcctRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+			})
+		})
+
+		Context("when the name is already taken", func() {
+			It("should return conflict error", func() {
+				// Phase 1: Setup (Arrange)
+				builder.MockServiceAcctRepo.On("FindByName", mock.Anything, account.Name).Return(account, nil).Once()
+				cmd := &commands.CreateServiceAccount{Name: account.Name, Scopes: []string{"products:write"}}
+
+				// Phase 2: Exercise (Act)
+				_, err := handler.CreateServiceAccountHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeConflict))
+			})
+		})
+	})
+
+	Describe("IssueApiKeyHandler", func() {
+		Context("when the service account exists", func() {
+			It("should store only the hash of the returned key", func() {
+				// Phase 1: Setup (Arrange)
+				builder.MockServiceAcctRepo.On("FindByID", mock.Anything, uint64(account.ID)).Return(account, nil).Once()
+				builder.MockApiKeyRepo.On("Save", mock.Anything, mock.AnythingOfType("*entity.ApiKey")).Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				key, plain, err := handler.IssueApiKeyHandler(ctx, &commands.IssueApiKey{ServiceAccountID: uint64(account.ID)})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(plain).To(HavePrefix(entity.ApiKeyPrefix))
+				Expect(key.KeyHash).To(Equal(entity.HashToken(plain)))
+				Expect(plain).To(HavePrefix(key.Prefix))
+				Expect(key.ServiceAccountID).To(Equal(account.ID))
+				builder.MockApiKeyRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when the expiry is in the past", func() {
+			It("should return validation error", func() {
+				// Phase 1: Setup (Arrange)
+				expiresAt := time.Now().Add(-time.Hour)
+
+				// Phase 2: Exercise (Act)
+				_, _, err := handler.IssueApiKeyHandler(ctx, &commands.IssueApiKey{ServiceAccountID: uint64(account.ID), ExpiresAt: &expiresAt})
+
+				// Phase 3: Verify (Assert)
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+				builder.MockApiKeyRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+			})
+		})
+	})
+
+	Describe("RevokeApiKeyHandler", func() {
+		Context("when the key belongs to the service account", func() {
+			It("should make the key unusable", func() {
+				// Phase 1: Setup (Arrange)
+				key := &entity.ApiKey{ID: 5, ServiceAccountID: account.ID}
+				builder.MockApiKeyRepo.On("FindByIDAndServiceAccountID", mock.Anything, key.ID, account.ID).Return(key, nil).Once()
+				builder.MockApiKeyRepo.On("Modify", mock.Anything, key).Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.RevokeApiKeyHandler(ctx, &commands.RevokeApiKey{ServiceAccountID: uint64(account.ID), ApiKeyID: uint64(key.ID)})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(key.IsUsable(time.Now())).To(BeFalse())
+				builder.MockApiKeyRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when the key belongs to another service account", func() {
+			It("should return not found error", func() {
+				// Phase 1: Setup (Arrange)
+				builder.MockApiKeyRepo.On("FindByIDAndServiceAccountID", mock.Anything, entity.ApiKeyID(5), account.ID).
+					Return(nil, repository.ErrApiKeyNotFound).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.RevokeApiKeyHandler(ctx, &commands.RevokeApiKey{ServiceAccountID: uint64(account.ID), ApiKeyID: 5})
+
+				// Phase 3: Verify (Assert)
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeNotFound))
+			})
+		})
+	})
+})
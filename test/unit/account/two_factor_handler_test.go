@@ -0,0 +1,272 @@
+package account_test
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/internal/account/adapter"
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	"shikposh-backend/internal/account/service_layer/command_handler"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"shikposh-backend/test/unit/testdouble/builders"
+	"shikposh-backend/test/unit/testdouble/factories"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("Two-factor authentication", func() {
+	var (
+		builder *builders.UserTestBuilder
+		handler *command_handler.UserHandler
+		secret  string
+		ctx     context.Context
+	)
+
+	currentCode := func() string {
+		code, err := adapter.TotpCode(secret, time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		return code
+	}
+
+	BeforeEach(func() {
+		builder = builders.NewUserTestBuilder().
+			WithUserRepo().
+			WithSessionRepo().
+			WithRefreshTokenRepo().
+			WithTwoFactorRepos().
+			WithSuccessfulTransaction()
+		handler = builder.BuildHandler()
+		ctx = context.Background()
+
+		var err error
+		secret, err = adapter.GenerateTotpSecret()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("EnrollTotpHandler", func() {
+		Context("when two-factor authentication is off", func() {
+			It("should store a new secret without enabling it", func() {
+				// Phase 1: Setup (Arrange)
+				user := factories.CreateUser("existinguser", "user@example.com", "password123")
+				builder.MockUserRepo.On("FindByID", mock.Anything, uint64(user.ID)).
+					Return(user, nil).Once()
+				builder.MockUserRepo.On("Modify", mock.Anything, user).
+					Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.EnrollTotpHandler(ctx, &commands.EnrollTotp{UserID: uint64(user.ID)})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Secret).To(Equal(user.TotpSecret))
+				Expect(result.ProvisioningURI).To(HavePrefix("otpauth://totp/Shikposh:existinguser?"))
+				Expect(result.ProvisioningURI).To(ContainSubstring("secret=" + result.Secret))
+				Expect(user.IsTwoFactorEnabled()).To(BeFalse())
+			})
+		})
+
+		Context("when two-factor authentication is already enabled", func() {
+			It("should return conflict error without replacing the secret", func() {
+				// Phase 1: Setup (Arrange)
+				user := factories.CreateTwoFactorUser("existinguser", "user@example.com", "password123", secret)
+				builder.MockUserRepo.On("FindByID", mock.Anything, uint64(user.ID)).
+					Return(user, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				_, err := handler.EnrollTotpHandler(ctx, &commands.EnrollTotp{UserID: uint64(user.ID)})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeConflict))
+				Expect(user.TotpSecret).To(Equal(secret))
+			})
+		})
+	})
+
+	Describe("ConfirmTotpHandler", func() {
+		Context("when the code is valid", func() {
+			It("should enable two-factor authentication and return recovery codes", func() {
+				// Phase 1: Setup (Arrange)
+				user := factories.CreateUser("existinguser", "user@example.com", "password123")
+				user.StartTotpEnrollment(secret)
+				builder.MockUserRepo.On("FindByID", mock.Anything, uint64(user.ID)).
+					Return(user, nil).Once()
+				builder.MockUserRepo.On("Modify", mock.Anything, user).
+					Return(nil).Once()
+				builder.MockRecoveryRepo.On("DeleteByUserID", mock.Anything, user.ID).
+					Return(nil).Once()
+				builder.MockRecoveryRepo.On("Save", mock.Anything, mock.AnythingOfType("*entity.RecoveryCode")).
+					Return(nil).Times(10)
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.ConfirmTotpHandler(ctx, &commands.ConfirmTotp{UserID: uint64(user.ID), Code: currentCode()})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(user.IsTwoFactorEnabled()).To(BeTrue())
+				Expect(result.RecoveryCodes).To(HaveLen(10))
+				Expect(result.RecoveryCodes[0]).To(MatchRegexp(`^[a-z2-7]{4}-[a-z2-7]{4}$`))
+				builder.MockRecoveryRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when the code is wrong", func() {
+			It("should return validation error without enabling two-factor authentication", func() {
+				// Phase 1: Setup (Arrange)
+				user := factories.CreateUser("existinguser", "user@example.com", "password123")
+				user.StartTotpEnrollment(secret)
+				builder.MockUserRepo.On("FindByID", mock.Anything, uint64(user.ID)).
+					Return(user, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				_, err := handler.ConfirmTotpHandler(ctx, &commands.ConfirmTotp{UserID: uint64(user.ID), Code: "000000"})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+				Expect(user.IsTwoFactorEnabled()).To(BeFalse())
+				builder.MockUserRepo.AssertNotCalled(GinkgoT(), "Modify", mock.Anything, mock.Anything)
+			})
+		})
+	})
+
+	Describe("LoginHandler", func() {
+		Context("when two-factor authentication is enabled", func() {
+			It("should return a challenge instead of tokens", func() {
+				// Phase 1: Setup (Arrange)
+				user := factories.CreateTwoFactorUser("existinguser", "user@example.com", "password123", secret)
+				builder.MockUserRepo.On("FindByUserName", mock.Anything, "existinguser").
+					Return(user, nil).Once()
+				builder.MockChallengeRepo.On("Save", mock.Anything, mock.MatchedBy(func(c *entity.TwoFactorChallenge) bool {
+					return c.UserID == user.ID
+				})).Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.LoginHandler(ctx, factories.CreateLoginCommand("existinguser", "password123"))
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.TwoFactorRequired).To(BeTrue())
+				Expect(result.ChallengeToken).NotTo(BeEmpty())
+				Expect(result.Access).To(BeEmpty())
+				Expect(result.Refresh).To(BeEmpty())
+				builder.MockSessionRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+				builder.MockChallengeRepo.AssertExpectations(GinkgoT())
+			})
+		})
+	})
+
+	Describe("VerifyTwoFactorHandler", func() {
+		var (
+			user      *entity.User
+			challenge *entity.TwoFactorChallenge
+			plain     string
+		)
+
+		BeforeEach(func() {
+			user = factories.CreateTwoFactorUser("existinguser", "user@example.com", "password123", secret)
+			challenge, plain = factories.CreateTwoFactorChallenge(1, user, time.Now().Add(time.Minute))
+			builder.MockChallengeRepo.On("FindByTokenHash", mock.Anything, entity.HashToken(plain)).
+				Return(challenge, nil).Maybe()
+			builder.MockUserRepo.On("FindByID", mock.Anything, uint64(user.ID)).
+				Return(user, nil).Maybe()
+		})
+
+		expectSession := func() {
+			builder.MockChallengeRepo.On("MarkUsed", mock.Anything, challenge, mock.AnythingOfType("time.Time")).
+				Return(true, nil).Once()
+			builder.MockSessionRepo.On("Save", mock.Anything, mock.AnythingOfType("*entity.Session")).
+				Return(nil).Once()
+			builder.MockRefreshTokenRepo.On("Save", mock.Anything, mock.AnythingOfType("*entity.RefreshToken")).
+				Return(nil).Once()
+		}
+
+		Context("when the TOTP code is valid", func() {
+			It("should consume the challenge and open a session", func() {
+				// Phase 1: Setup (Arrange)
+				builder.MockUserRepo.On("Modify", mock.Anything, user).
+					Return(nil).Once()
+				expectSession()
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.VerifyTwoFactorHandler(ctx, &commands.VerifyTwoFactor{ChallengeToken: plain, Code: currentCode()})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Access).NotTo(BeEmpty())
+				Expect(result.Refresh).NotTo(BeEmpty())
+				Expect(user.TotpLastStep).To(Equal(time.Now().Unix() / 30))
+				builder.MockChallengeRepo.AssertExpectations(GinkgoT())
+				builder.MockSessionRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when the TOTP code was already used", func() {
+			It("should count a failed attempt and return unauthorized error", func() {
+				// Phase 1: Setup (Arrange)
+				user.TotpLastStep = time.Now().Unix()/30 + 1
+				builder.MockRecoveryRepo.On("FindUnusedByHash", mock.Anything, user.ID, mock.AnythingOfType("string")).
+					Return(nil, repository.ErrRecoveryCodeNotFound).Once()
+				builder.MockChallengeRepo.On("Modify", mock.Anything, challenge).
+					Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				_, err := handler.VerifyTwoFactorHandler(ctx, &commands.VerifyTwoFactor{ChallengeToken: plain, Code: currentCode()})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeUnauthorized))
+				Expect(challenge.Attempts).To(Equal(1))
+				builder.MockSessionRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+			})
+		})
+
+		Context("when a recovery code is given", func() {
+			It("should consume the recovery code and open a session", func() {
+				// Phase 1: Setup (Arrange)
+				recoveryCode := &entity.RecoveryCode{ID: 7, UserID: user.ID, CodeHash: entity.HashRecoveryCode("abcd-efgh")}
+				builder.MockRecoveryRepo.On("FindUnusedByHash", mock.Anything, user.ID, entity.HashRecoveryCode("ABCDEFGH")).
+					Return(recoveryCode, nil).Once()
+				builder.MockRecoveryRepo.On("MarkUsed", mock.Anything, recoveryCode, mock.AnythingOfType("time.Time")).
+					Return(true, nil).Once()
+				expectSession()
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.VerifyTwoFactorHandler(ctx, &commands.VerifyTwoFactor{ChallengeToken: plain, Code: "ABCDEFGH"})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Access).NotTo(BeEmpty())
+				builder.MockRecoveryRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when the challenge ran out of attempts", func() {
+			It("should return unauthorized error without checking the code", func() {
+				// Phase 1: Setup (Arrange)
+				challenge.Attempts = entity.MaxTwoFactorAttempts
+
+				// Phase 2: Exercise (Act)
+				_, err := handler.VerifyTwoFactorHandler(ctx, &commands.VerifyTwoFactor{ChallengeToken: plain, Code: currentCode()})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeUnauthorized))
+				builder.MockUserRepo.AssertNotCalled(GinkgoT(), "FindByID", mock.Anything, mock.Anything)
+				builder.MockSessionRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+			})
+		})
+	})
+})
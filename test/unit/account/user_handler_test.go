@@ -3,7 +3,11 @@ package account_test
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
 
+	"shikposh-backend/config"
+	"shikposh-backend/internal/account/adapter"
 	"shikposh-backend/internal/account/adapter/repository"
 	"shikposh-backend/internal/account/domain/commands"
 	"shikposh-backend/internal/account/domain/entity"
@@ -27,7 +31,11 @@ var _ = Describe("UserHandler", func() {
 	BeforeEach(func() {
 		builder = builders.NewUserTestBuilder().
 			WithUserRepo().
-			WithTokenRepo().
+			WithSessionRepo().
+			WithRefreshTokenRepo().
+			WithEmailVerificationRepo().
+			WithPasswordResetRepo().
+			WithRoleRepo().
 			WithSuccessfulTransaction()
 		handler = builder.BuildHandler()
 		ctx = context.Background()
@@ -69,27 +77,69 @@ var _ = Describe("UserHandler", func() {
 				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeConflict))
 			})
 		})
+
+		Context("when the password breaks the password rules", func() {
+			It("should return validation error without saving the user", func() {
+				// Phase 1: Setup (Arrange)
+				handler = builder.WithPasswordRules(config.PasswordConfig{MinLength: 8, IncludeDigits: true}).BuildHandler()
+				cmd := factories.CreateRegisterCommand("newuser", "newuser@example.com", "password")
+
+				// Phase 2: Exercise (Act)
+				err := handler.RegisterHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+				builder.MockUserRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+			})
+		})
+		Context("when the password is longer than bcrypt hashes", func() {
+			It("should return validation error without saving the user", func() {
+				// Phase 1: Setup (Arrange)
+				handler = builder.WithPasswordRules(config.PasswordConfig{MaxLength: 64}).BuildHandler()
+				cmd := factories.CreateRegisterCommand("newuser", "newuser@example.com", strings.Repeat("گ", 40))
+
+				// Phase 2: Exercise (Act)
+				err := handler.RegisterHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+				builder.MockUserRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+			})
+		})
 	})
 
 	Describe("LoginHandler", func() {
 		Context("when credentials are valid", func() {
-			It("should login successfully and return access token", func() {
+			It("should open a session and return access and refresh tokens", func() {
 				// Phase 1: Setup (Arrange)
 				cmd := factories.CreateLoginCommand("existinguser", "password123")
+				cmd.UserAgent = "Mozilla/5.0"
+				cmd.IP = "10.0.0.1"
 				user := factories.CreateUser("existinguser", "user@example.com", "password123")
 				builder.MockUserRepo.On("FindByUserName", mock.Anything, "existinguser").
 					Return(user, nil).Maybe()
-				builder.MockTokenRepo.On("FindByUserID", mock.Anything, entity.UserID(1)).
-					Return(nil, repository.ErrTokenNotFound).Maybe()
-				builder.MockTokenRepo.On("Save", mock.Anything, mock.AnythingOfType("*entity.Token")).
-					Return(nil).Maybe()
+				builder.MockSessionRepo.On("Save", mock.Anything, mock.MatchedBy(func(s *entity.Session) bool {
+					return s.UserID == user.ID && s.UserAgent == "Mozilla/5.0" && s.IP == "10.0.0.1"
+				})).Return(nil).Once()
+				builder.MockRefreshTokenRepo.On("Save", mock.Anything, mock.AnythingOfType("*entity.RefreshToken")).
+					Return(nil).Once()
 
 				// Phase 2: Exercise (Act)
-				token, err := handler.LoginHandler(ctx, cmd)
+				result, err := handler.LoginHandler(ctx, cmd)
 
 				// Phase 3: Verify (Assert)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(token).NotTo(BeEmpty())
+				Expect(result.Access).NotTo(BeEmpty())
+				Expect(result.Refresh).NotTo(BeEmpty())
+				Expect(result.ExpiresIn).To(Equal(int64(15 * 60)))
+				builder.MockSessionRepo.AssertExpectations(GinkgoT())
+				builder.MockRefreshTokenRepo.AssertExpectations(GinkgoT())
 			})
 		})
 
@@ -101,11 +151,11 @@ var _ = Describe("UserHandler", func() {
 					Return(nil, repository.ErrUserNotFound).Maybe()
 
 				// Phase 2: Exercise (Act)
-				token, err := handler.LoginHandler(ctx, cmd)
+				result, err := handler.LoginHandler(ctx, cmd)
 
 				// Phase 3: Verify (Assert)
 				Expect(err).To(HaveOccurred())
-				Expect(token).To(BeEmpty())
+				Expect(result).To(BeNil())
 				appErr, ok := err.(apperrors.Error)
 				Expect(ok).To(BeTrue())
 				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeNotFound))
@@ -121,11 +171,193 @@ var _ = Describe("UserHandler", func() {
 					Return(user, nil).Maybe()
 
 				// Phase 2: Exercise (Act)
-				token, err := handler.LoginHandler(ctx, cmd)
+				result, err := handler.LoginHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				Expect(result).To(BeNil())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeUnauthorized))
+				builder.MockSessionRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+			})
+		})
+		Context("when email verification is required and the email is not verified", func() {
+			It("should return forbidden error without opening a session", func() {
+				// Phase 1: Setup (Arrange)
+				handler = builder.WithRequiredEmailVerification().BuildHandler()
+				cmd := factories.CreateLoginCommand("existinguser", "password123")
+				user := factories.CreateUser("existinguser", "user@example.com", "password123")
+				builder.MockUserRepo.On("FindByUserName", mock.Anything, "existinguser").
+					Return(user, nil).Maybe()
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.LoginHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				Expect(result).To(BeNil())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeForbidden))
+				builder.MockSessionRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+			})
+		})
+	})
+
+	Describe("RefreshHandler", func() {
+		Context("when refresh token is valid", func() {
+			It("should rotate the refresh token and return a new pair", func() {
+				// Phase 1: Setup (Arrange)
+				session := factories.CreateSession(7, 1)
+				token, plain := factories.CreateRefreshToken(1, session)
+				cmd := &commands.RefreshToken{RefreshToken: plain}
+				builder.MockRefreshTokenRepo.On("FindByTokenHash", mock.Anything, entity.HashToken(plain)).
+					Return(token, nil).Once()
+				builder.MockSessionRepo.On("FindBySessionID", mock.Anything, entity.SessionID(7)).
+					Return(session, nil).Once()
+				builder.MockRefreshTokenRepo.On("MarkUsed", mock.Anything, token, mock.AnythingOfType("time.Time")).
+					Return(true, nil).Once()
+				builder.MockSessionRepo.On("Modify", mock.Anything, session).Return(nil).Once()
+				builder.MockRefreshTokenRepo.On("Save", mock.Anything, mock.MatchedBy(func(t *entity.RefreshToken) bool {
+					return t.SessionID == session.ID && t.TokenHash != token.TokenHash
+				})).Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.RefreshHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Access).NotTo(BeEmpty())
+				Expect(result.Refresh).NotTo(Equal(plain))
+				builder.MockRefreshTokenRepo.AssertExpectations(GinkgoT())
+				builder.MockSessionRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when refresh token was already used", func() {
+			It("should revoke the whole session and return unauthorized error", func() {
+				// Phase 1: Setup (Arrange)
+				session := factories.CreateSession(7, 1)
+				token, plain := factories.CreateRefreshToken(1, session)
+				token.MarkUsed(time.Now().Add(-time.Minute))
+				cmd := &commands.RefreshToken{RefreshToken: plain}
+				builder.MockRefreshTokenRepo.On("FindByTokenHash", mock.Anything, entity.HashToken(plain)).
+					Return(token, nil).Once()
+				builder.MockSessionRepo.On("FindBySessionID", mock.Anything, entity.SessionID(7)).
+					Return(session, nil).Once()
+				builder.MockSessionRepo.On("Modify", mock.Anything, session).Return(nil).Once()
+				builder.MockRefreshTokenRepo.On("RevokeBySessionID", mock.Anything, entity.SessionID(7)).
+					Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.RefreshHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				Expect(result).To(BeNil())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeUnauthorized))
+				Expect(session.RevokedAt).NotTo(BeNil())
+				builder.MockRefreshTokenRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+				builder.MockRefreshTokenRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when a concurrent refresh consumed the token first", func() {
+			It("should treat it as reuse and revoke the whole session", func() {
+				// Phase 1: Setup (Arrange)
+				session := factories.CreateSession(7, 1)
+				token, plain := factories.CreateRefreshToken(1, session)
+				cmd := &commands.RefreshToken{RefreshToken: plain}
+				builder.MockRefreshTokenRepo.On("FindByTokenHash", mock.Anything, entity.HashToken(plain)).
+					Return(token, nil).Once()
+				builder.MockSessionRepo.On("FindBySessionID", mock.Anything, entity.SessionID(7)).
+					Return(session, nil).Once()
+				builder.MockRefreshTokenRepo.On("MarkUsed", mock.Anything, token, mock.AnythingOfType("time.Time")).
+					Return(false, nil).Once()
+				builder.MockSessionRepo.On("Modify", mock.Anything, session).Return(nil).Once()
+				builder.MockRefreshTokenRepo.On("RevokeBySessionID", mock.Anything, entity.SessionID(7)).
+					Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.RefreshHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				Expect(result).To(BeNil())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeUnauthorized))
+				Expect(session.RevokedAt).NotTo(BeNil())
+				builder.MockRefreshTokenRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+				builder.MockRefreshTokenRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when refresh token was revoked by logout", func() {
+			It("should return unauthorized error without treating it as reuse", func() {
+				// Phase 1: Setup (Arrange)
+				session := factories.CreateSession(7, 1)
+				session.Revoke(time.Now())
+				token, plain := factories.CreateRefreshToken(1, session)
+				revokedAt := time.Now()
+				token.RevokedAt = &revokedAt
+				cmd := &commands.RefreshToken{RefreshToken: plain}
+				builder.MockRefreshTokenRepo.On("FindByTokenHash", mock.Anything, entity.HashToken(plain)).
+					Return(token, nil).Once()
+				builder.MockSessionRepo.On("FindBySessionID", mock.Anything, entity.SessionID(7)).
+					Return(session, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				_, err := handler.RefreshHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeUnauthorized))
+				builder.MockRefreshTokenRepo.AssertNotCalled(GinkgoT(), "RevokeBySessionID", mock.Anything, mock.Anything)
+				builder.MockSessionRepo.AssertNotCalled(GinkgoT(), "Modify", mock.Anything, mock.Anything)
+			})
+		})
+
+		Context("when session is revoked", func() {
+			It("should return unauthorized error", func() {
+				// Phase 1: Setup (Arrange)
+				session := factories.CreateSession(7, 1)
+				session.Revoke(time.Now())
+				token, plain := factories.CreateRefreshToken(1, session)
+				cmd := &commands.RefreshToken{RefreshToken: plain}
+				builder.MockRefreshTokenRepo.On("FindByTokenHash", mock.Anything, entity.HashToken(plain)).
+					Return(token, nil).Once()
+				builder.MockSessionRepo.On("FindBySessionID", mock.Anything, entity.SessionID(7)).
+					Return(session, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				_, err := handler.RefreshHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeUnauthorized))
+			})
+		})
+
+		Context("when refresh token is unknown", func() {
+			It("should return unauthorized error", func() {
+				// Phase 1: Setup (Arrange)
+				cmd := &commands.RefreshToken{RefreshToken: "unknown"}
+				builder.MockRefreshTokenRepo.On("FindByTokenHash", mock.Anything, entity.HashToken("unknown")).
+					Return(nil, repository.ErrRefreshTokenNotFound).Once()
+
+				// Phase 2: Exercise (Act)
+				_, err := handler.RefreshHandler(ctx, cmd)
 
 				// Phase 3: Verify (Assert)
 				Expect(err).To(HaveOccurred())
-				Expect(token).To(BeEmpty())
 				appErr, ok := err.(apperrors.Error)
 				Expect(ok).To(BeTrue())
 				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeUnauthorized))
@@ -134,34 +366,33 @@ var _ = Describe("UserHandler", func() {
 	})
 
 	Describe("LogoutHandler", func() {
-		Context("when user has valid token", func() {
-			It("should logout successfully", func() {
-				// Phase 1: Setup (Arrange)
-				cmd := &commands.Logout{UserID: 1}
-				token := &entity.Token{
-					ID:     1,
-					UserID: 1,
-					Token:  "test-token",
-				}
-				builder.MockTokenRepo.On("FindByUserID", mock.Anything, entity.UserID(1)).
-					Return(token, nil).Maybe()
-				builder.MockTokenRepo.On("Remove", mock.Anything, token, false).
-					Return(nil).Maybe()
+		Context("when session belongs to the user", func() {
+			It("should revoke the session and its refresh tokens", func() {
+				// Phase 1: Setup (Arrange)
+				cmd := &commands.Logout{UserID: 1, SessionID: 7}
+				session := factories.CreateSession(7, 1)
+				builder.MockSessionRepo.On("FindBySessionID", mock.Anything, entity.SessionID(7)).
+					Return(session, nil).Once()
+				builder.MockSessionRepo.On("Modify", mock.Anything, session).Return(nil).Once()
+				builder.MockRefreshTokenRepo.On("RevokeBySessionID", mock.Anything, entity.SessionID(7)).
+					Return(nil).Once()
 
 				// Phase 2: Exercise (Act)
 				err := handler.LogoutHandler(ctx, cmd)
 
 				// Phase 3: Verify (Assert)
 				Expect(err).NotTo(HaveOccurred())
+				Expect(session.RevokedAt).NotTo(BeNil())
+				builder.MockRefreshTokenRepo.AssertExpectations(GinkgoT())
 			})
 		})
 
-		Context("when token does not exist", func() {
+		Context("when session does not exist", func() {
 			It("should return not found error", func() {
 				// Phase 1: Setup (Arrange)
-				cmd := &commands.Logout{UserID: 999}
-				builder.MockTokenRepo.On("FindByUserID", mock.Anything, entity.UserID(999)).
-					Return(nil, repository.ErrTokenNotFound).Maybe()
+				cmd := &commands.Logout{UserID: 999, SessionID: 999}
+				builder.MockSessionRepo.On("FindBySessionID", mock.Anything, entity.SessionID(999)).
+					Return(nil, repository.ErrSessionNotFound).Maybe()
 
 				// Phase 2: Exercise (Act)
 				err := handler.LogoutHandler(ctx, cmd)
@@ -176,5 +407,354 @@ var _ = Describe("UserHandler", func() {
 				}
 			})
 		})
+
+		Context("when session belongs to another user", func() {
+			It("should return not found error without revoking it", func() {
+				// Phase 1: Setup (Arrange)
+				cmd := &commands.Logout{UserID: 2, SessionID: 7}
+				session := factories.CreateSession(7, 1)
+				builder.MockSessionRepo.On("FindBySessionID", mock.Anything, entity.SessionID(7)).
+					Return(session, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.LogoutHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				Expect(session.RevokedAt).To(BeNil())
+			})
+		})
+	})
+
+	Describe("VerifyEmailHandler", func() {
+		Context("when the token is valid", func() {
+			It("should mark the token used and verify the email", func() {
+				// Phase 1: Setup (Arrange)
+				user := factories.CreateUser("existinguser", "user@example.com", "password123")
+				token, plain := factories.CreateEmailVerificationToken(1, user, time.Now().Add(time.Hour))
+				builder.MockVerificationRepo.On("FindByTokenHash", mock.Anything, entity.HashToken(plain)).
+					Return(token, nil).Once()
+				builder.MockUserRepo.On("FindByID", mock.Anything, uint64(user.ID)).
+					Return(user, nil).Once()
+				builder.MockVerificationRepo.On("MarkUsed", mock.Anything, token, mock.AnythingOfType("time.Time")).
+					Return(true, nil).Once()
+				builder.MockUserRepo.On("Modify", mock.Anything, user).
+					Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.VerifyEmailHandler(ctx, &commands.VerifyEmail{Token: plain})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(user.IsEmailVerified()).To(BeTrue())
+				builder.MockVerificationRepo.AssertExpectations(GinkgoT())
+				builder.MockUserRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when the token has expired", func() {
+			It("should return validation error", func() {
+				// Phase 1: Setup (Arrange)
+				user := factories.CreateUser("existinguser", "user@example.com", "password123")
+				token, plain := factories.CreateEmailVerificationToken(1, user, time.Now().Add(-time.Minute))
+				builder.MockVerificationRepo.On("FindByTokenHash", mock.Anything, entity.HashToken(plain)).
+					Return(token, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.VerifyEmailHandler(ctx, &commands.VerifyEmail{Token: plain})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+				builder.MockVerificationRepo.AssertNotCalled(GinkgoT(), "MarkUsed", mock.Anything, mock.Anything, mock.Anything)
+			})
+		})
+
+		Context("when the token was sent to a previous email address", func() {
+			It("should return validation error without verifying the current email", func() {
+				// Phase 1: Setup (Arrange)
+				user := factories.CreateUser("existinguser", "old@example.com", "password123")
+				token, plain := factories.CreateEmailVerificationToken(1, user, time.Now().Add(time.Hour))
+				user.Email = "new@example.com"
+				builder.MockVerificationRepo.On("FindByTokenHash", mock.Anything, entity.HashToken(plain)).
+					Return(token, nil).Once()
+				builder.MockUserRepo.On("FindByID", mock.Anything, uint64(user.ID)).
+					Return(user, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.VerifyEmailHandler(ctx, &commands.VerifyEmail{Token: plain})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+				Expect(user.IsEmailVerified()).To(BeFalse())
+				builder.MockUserRepo.AssertNotCalled(GinkgoT(), "Modify", mock.Anything, mock.Anything)
+			})
+		})
+
+		Context("when a concurrent request used the token first", func() {
+			It("should return validation error", func() {
+				// Phase 1: Setup (Arrange)
+				user := factories.CreateUser("existinguser", "user@example.com", "password123")
+				token, plain := factories.CreateEmailVerificationToken(1, user, time.Now().Add(time.Hour))
+				builder.MockVerificationRepo.On("FindByTokenHash", mock.Anything, entity.HashToken(plain)).
+					Return(token, nil).Once()
+				builder.MockUserRepo.On("FindByID", mock.Anything, uint64(user.ID)).
+					Return(user, nil).Once()
+				builder.MockVerificationRepo.On("MarkUsed", mock.Anything, token, mock.AnythingOfType("time.Time")).
+					Return(false, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.VerifyEmailHandler(ctx, &commands.VerifyEmail{Token: plain})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+				builder.MockUserRepo.AssertNotCalled(GinkgoT(), "Modify", mock.Anything, mock.Anything)
+			})
+		})
+	})
+
+	Describe("ResendVerificationHandler", func() {
+		Context("when the last email was sent long enough ago", func() {
+			It("should request a new verification email", func() {
+				// Phase 1: Setup (Arrange)
+				user := factories.CreateUser("existinguser", "user@example.com", "password123")
+				latest, _ := factories.CreateEmailVerificationToken(1, user, time.Now().Add(time.Hour))
+				latest.CreatedAt = time.Now().Add(-2 * time.Minute)
+				builder.MockUserRepo.On("FindByEmail", mock.Anything, "user@example.com").
+					Return(user, nil).Once()
+				builder.MockVerificationRepo.On("FindLatestByUserID", mock.Anything, user.ID).
+					Return(latest, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.ResendVerificationHandler(ctx, &commands.ResendVerificationEmail{Email: "user@example.com"})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				builder.MockVerificationRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when the last email was sent within the resend interval", func() {
+			It("should return too many requests error", func() {
+				// Phase 1: Setup (Arrange)
+				user := factories.CreateUser("existinguser", "user@example.com", "password123")
+				latest, _ := factories.CreateEmailVerificationToken(1, user, time.Now().Add(time.Hour))
+				builder.MockUserRepo.On("FindByEmail", mock.Anything, "user@example.com").
+					Return(user, nil).Once()
+				builder.MockVerificationRepo.On("FindLatestByUserID", mock.Anything, user.ID).
+					Return(latest, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.ResendVerificationHandler(ctx, &commands.ResendVerificationEmail{Email: "user@example.com"})
+
+				// Phase 3: Verify (Assert)
+				Expect(errors.Is(err, command_handler.ErrTooManyRequests)).To(BeTrue())
+			})
+		})
+
+		Context("when the email is not registered", func() {
+			It("should succeed without revealing it", func() {
+				// Phase 1: Setup (Arrange)
+				builder.MockUserRepo.On("FindByEmail", mock.Anything, "unknown@example.com").
+					Return(nil, repository.ErrUserNotFound).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.ResendVerificationHandler(ctx, &commands.ResendVerificationEmail{Email: "unknown@example.com"})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				builder.MockVerificationRepo.AssertNotCalled(GinkgoT(), "FindLatestByUserID", mock.Anything, mock.Anything)
+			})
+		})
+	})
+
+	Describe("RequestPasswordResetHandler", func() {
+		Context("when the email is registered", func() {
+			It("should request a password reset mail", func() {
+				// Phase 1: Setup (Arrange)
+				user := factories.CreateUser("existinguser", "user@example.com", "password123")
+				builder.MockUserRepo.On("FindByEmail", mock.Anything, "user@example.com").
+					Return(user, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.RequestPasswordResetHandler(ctx, &commands.RequestPasswordReset{Email: "user@example.com"})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				builder.MockUserRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when the email is not registered", func() {
+			It("should succeed without revealing it", func() {
+				// Phase 1: Setup (Arrange)
+				builder.MockUserRepo.On("FindByEmail", mock.Anything, "unknown@example.com").
+					Return(nil, repository.ErrUserNotFound).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.RequestPasswordResetHandler(ctx, &commands.RequestPasswordReset{Email: "unknown@example.com"})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("ResetPasswordHandler", func() {
+		Context("when the reset token is valid", func() {
+			It("should change the password and revoke every session", func() {
+				// Phase 1: Setup (Arrange)
+				user := factories.CreateUser("existinguser", "user@example.com", "password123")
+				token, plain := factories.CreatePasswordResetToken(1, user, time.Now().Add(time.Hour))
+				builder.MockResetRepo.On("FindByTokenHash", mock.Anything, entity.HashToken(plain)).
+					Return(token, nil).Once()
+				builder.MockResetRepo.On("MarkUsed", mock.Anything, token, mock.AnythingOfType("time.Time")).
+					Return(true, nil).Once()
+				builder.MockUserRepo.On("FindByID", mock.Anything, uint64(user.ID)).
+					Return(user, nil).Once()
+				builder.MockUserRepo.On("Modify", mock.Anything, user).
+					Return(nil).Once()
+				builder.MockSessionRepo.On("RevokeByUserID", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).
+					Return(nil).Once()
+				builder.MockRefreshTokenRepo.On("RevokeByUserID", mock.Anything, user.ID).
+					Return(nil).Once()
+				builder.MockResetRepo.On("InvalidateByUserID", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).
+					Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.ResetPasswordHandler(ctx, &commands.ResetPassword{Token: plain, NewPassword: "newpassword456"})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(adapter.ComparePassword(user.Password, "newpassword456")).To(Succeed())
+				builder.MockSessionRepo.AssertExpectations(GinkgoT())
+				builder.MockRefreshTokenRepo.AssertExpectations(GinkgoT())
+				builder.MockResetRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when the reset token has expired", func() {
+			It("should return validation error without changing the password", func() {
+				// Phase 1: Setup (Arrange)
+				user := factories.CreateUser("existinguser", "user@example.com", "password123")
+				token, plain := factories.CreatePasswordResetToken(1, user, time.Now().Add(-time.Minute))
+				builder.MockResetRepo.On("FindByTokenHash", mock.Anything, entity.HashToken(plain)).
+					Return(token, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.ResetPasswordHandler(ctx, &commands.ResetPassword{Token: plain, NewPassword: "newpassword456"})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+				builder.MockUserRepo.AssertNotCalled(GinkgoT(), "Modify", mock.Anything, mock.Anything)
+			})
+		})
+	})
+
+	Describe("ChangePasswordHandler", func() {
+		Context("when the current password is correct", func() {
+			It("should change the password and revoke every session", func() {
+				// Phase 1: Setup (Arrange)
+				user := factories.CreateUser("existinguser", "user@example.com", "password123")
+				builder.MockUserRepo.On("FindByID", mock.Anything, uint64(user.ID)).
+					Return(user, nil).Once()
+				builder.MockUserRepo.On("Modify", mock.Anything, user).
+					Return(nil).Once()
+				builder.MockSessionRepo.On("RevokeByUserID", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).
+					Return(nil).Once()
+				builder.MockRefreshTokenRepo.On("RevokeByUserID", mock.Anything, user.ID).
+					Return(nil).Once()
+				builder.MockResetRepo.On("InvalidateByUserID", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).
+					Return(nil).Once()
+				cmd := &commands.ChangePassword{UserID: uint64(user.ID), CurrentPassword: "password123", NewPassword: "newpassword456"}
+
+				// Phase 2: Exercise (Act)
+				err := handler.ChangePasswordHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(adapter.ComparePassword(user.Password, "newpassword456")).To(Succeed())
+				builder.MockSessionRepo.AssertExpectations(GinkgoT())
+				builder.MockRefreshTokenRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when the current password is incorrect", func() {
+			It("should return validation error without changing the password", func() {
+				// Phase 1: Setup (Arrange)
+				user := factories.CreateUser("existinguser", "user@example.com", "password123")
+				builder.MockUserRepo.On("FindByID", mock.Anything, uint64(user.ID)).
+					Return(user, nil).Once()
+				cmd := &commands.ChangePassword{UserID: uint64(user.ID), CurrentPassword: "wrongpassword", NewPassword: "newpassword456"}
+
+				// Phase 2: Exercise (Act)
+				err := handler.ChangePasswordHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeValidation))
+				builder.MockUserRepo.AssertNotCalled(GinkgoT(), "Modify", mock.Anything, mock.Anything)
+				builder.MockSessionRepo.AssertNotCalled(GinkgoT(), "RevokeByUserID", mock.Anything, mock.Anything, mock.Anything)
+			})
+		})
+	})
+
+	Describe("AssignRoleHandler", func() {
+		Context("when user and role exist", func() {
+			It("should assign the role to the user", func() {
+				// Phase 1: Setup (Arrange)
+				cmd := &commands.AssignRole{UserName: "existinguser", Role: entity.RoleAdmin}
+				user := factories.CreateUser("existinguser", "user@example.com", "password123")
+				role := &entity.Role{ID: 1, Name: entity.RoleAdmin}
+				builder.MockUserRepo.On("FindByUserName", mock.Anything, "existinguser").
+					Return(user, nil).Once()
+				builder.MockRoleRepo.On("FindByName", mock.Anything, entity.RoleAdmin).
+					Return(role, nil).Once()
+				builder.MockUserRepo.On("AssignRole", mock.Anything, user, role).
+					Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.AssignRoleHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				builder.MockUserRepo.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when role does not exist", func() {
+			It("should return not found error", func() {
+				// Phase 1: Setup (Arrange)
+				cmd := &commands.AssignRole{UserName: "existinguser", Role: "superuser"}
+				user := factories.CreateUser("existinguser", "user@example.com", "password123")
+				builder.MockUserRepo.On("FindByUserName", mock.Anything, "existinguser").
+					Return(user, nil).Once()
+				builder.MockRoleRepo.On("FindByName", mock.Anything, "superuser").
+					Return(nil, repository.ErrRoleNotFound).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.AssignRoleHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeNotFound))
+				builder.MockUserRepo.AssertNotCalled(GinkgoT(), "AssignRole", mock.Anything, mock.Anything, mock.Anything)
+			})
+		})
 	})
 })
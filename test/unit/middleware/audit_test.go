@@ -0,0 +1,96 @@
+package middleware_test
+
+import (
+	"context"
+
+	accountcommands "shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	productcommands "shikposh-backend/internal/products/domain/commands"
+	"shikposh-backend/pkg/middleware"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"shikposh-backend/test/unit/testdouble/mocks"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("CommandAudit", func() {
+	var (
+		mockUOW       *mocks.MockPGUnitOfWork
+		mockAuditRepo *mocks.MockAuditEventRepository
+		mw            *middleware.Middleware
+		ctx           context.Context
+		recorded      *entity.AuditEvent
+	)
+
+	handle := func(ctx context.Context, cmd any, result error) error {
+		next := func(ctx context.Context, cmd any) error {
+			return result
+		}
+		return mw.CommandAudit()(next)(ctx, cmd)
+	}
+
+	BeforeEach(func() {
+		mockUOW = new(mocks.MockPGUnitOfWork)
+		mockAuditRepo = new(mocks.MockAuditEventRepository)
+		mockUOW.On("AuditEvent", mock.Anything).Return(mockAuditRepo).Maybe()
+		mockAuditRepo.On("Save", mock.Anything, mock.AnythingOfType("*entity.AuditEvent")).
+			Run(func(args mock.Arguments) {
+				recorded = args.Get(1).(*entity.AuditEvent)
+			}).Return(nil).Maybe()
+		mw = &middleware.Middleware{Uow: mockUOW}
+		ctx = context.Background()
+		recorded = nil
+	})
+
+	Context("when an audited command succeeds", func() {
+		It("should record the actor, the target and the request", func() {
+			// Phase 1: Setup (Arrange)
+			ctx = middleware.WithIdentity(ctx, middleware.Identity{UserID: 9})
+			ctx = middleware.WithRequestInfo(ctx, middleware.RequestInfo{IP: "203.0.113.7", UserAgent: "curl/8", RequestID: "req-1"})
+
+			// Phase 2: Exercise (Act)
+			err := handle(ctx, &accountcommands.DisableUser{UserID: 3, ActorID: 9}, nil)
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorded.Action).To(Equal("admin.user.disable"))
+			Expect(recorded.Outcome).To(Equal(entity.AuditSuccess))
+			Expect(recorded.ActorType).To(Equal("user"))
+			Expect(*recorded.ActorID).To(Equal(uint64(9)))
+			Expect(recorded.TargetType).To(Equal("user"))
+			Expect(recorded.TargetID).To(Equal("3"))
+			Expect(recorded.IP).To(Equal("203.0.113.7"))
+			Expect(recorded.UserAgent).To(Equal("curl/8"))
+			Expect(recorded.RequestID).To(Equal("req-1"))
+		})
+	})
+
+	Context("when an audited command is refused", func() {
+		It("should record it as denied and return the error unchanged", func() {
+			// Phase 1: Setup (Arrange)
+			refused := apperrors.Forbidden("PermissionDenied", "Permission denied")
+
+			// Phase 2: Exercise (Act)
+			err := handle(ctx, &accountcommands.DisableUser{UserID: 3}, refused)
+
+			// Phase 3: Verify (Assert)
+			Expect(err).To(Equal(refused))
+			Expect(recorded.Outcome).To(Equal(entity.AuditDenied))
+			Expect(recorded.Reason).To(Equal(string(apperrors.ErrorTypeForbidden)))
+			Expect(recorded.ActorID).To(BeNil())
+		})
+	})
+
+	Context("when the command is not audited", func() {
+		It("should record nothing", func() {
+			// Phase 2: Exercise (Act)
+			err := handle(ctx, &productcommands.CreateReview{ProductID: 1}, nil)
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			mockAuditRepo.AssertNotCalled(GinkgoT(), "Save", mock.Anything, mock.Anything)
+		})
+	})
+})
@@ -0,0 +1,135 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/commands"
+	"shikposh-backend/internal/account/domain/entity"
+	"shikposh-backend/pkg/middleware"
+	"shikposh-backend/test/unit/testdouble/mocks"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("AuthMiddleware", func() {
+	var (
+		mockUOW        *mocks.MockPGUnitOfWork
+		mockApiKeyRepo *mocks.MockApiKeyRepository
+		mw             *middleware.Middleware
+		principal      middleware.Principal
+		identity       middleware.Identity
+	)
+
+	BeforeEach(func() {
+		mockUOW = new(mocks.MockPGUnitOfWork)
+		mockApiKeyRepo = new(mocks.MockApiKeyRepository)
+		mockUOW.On("ApiKey", mock.Anything).Return(mockApiKeyRepo).Maybe()
+		mw = &middleware.Middleware{Uow: mockUOW}
+		principal = middleware.Principal{}
+		identity = middleware.Identity{}
+	})
+
+	newRequest := func(apiKey string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/jobs", nil)
+		req.Header.Set("Authorization", "ApiKey "+apiKey)
+		return req
+	}
+
+	newApp := func() *fiber.App {
+		app := fiber.New()
+		app.Get("/jobs", mw.AuthMiddleware(), func(c fiber.Ctx) error {
+			principal, _ = c.Locals("principal").(middleware.Principal)
+			identity, _ = middleware.IdentityFromContext(c.Context())
+			return c.SendStatus(fiber.StatusNoContent)
+		})
+		return app
+	}
+
+	Context("when the API key is usable", func() {
+		It("should authenticate the service account with its scopes", func() {
+			// Phase 1: Setup (Arrange)
+			lastUsedAt := time.Now()
+			key := &entity.ApiKey{
+				ID:               1,
+				ServiceAccountID: 7,
+				LastUsedAt:       &lastUsedAt,
+				ServiceAccount:   &entity.ServiceAccount{ID: 7, Scopes: []*entity.Permission{{Name: "products:write"}}},
+			}
+			mockApiKeyRepo.On("FindByKeyHash", mock.Anything, entity.HashToken("shk_valid")).Return(key, nil).Once()
+
+			// Phase 2: Exercise (Act)
+			resp, err := newApp().Test(newRequest("shk_valid"))
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+			Expect(principal).To(Equal(middleware.Principal{Type: middleware.PrincipalService, ID: 7}))
+			Expect(identity.IsServiceAccount()).To(BeTrue())
+			Expect(identity.Permissions).To(Equal([]string{"products:write"}))
+			mockApiKeyRepo.AssertNotCalled(GinkgoT(), "MarkUsed", mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+
+	Context("when the service account holds a scope administering accounts", func() {
+		It("should leave the scope out of its permissions", func() {
+			// Phase 1: Setup (Arrange)
+			lastUsedAt := time.Now()
+			key := &entity.ApiKey{
+				ID:               1,
+				ServiceAccountID: 7,
+				LastUsedAt:       &lastUsedAt,
+				ServiceAccount: &entity.ServiceAccount{ID: 7, Scopes: []*entity.Permission{
+					{Name: "products:write"},
+					{Name: commands.UsersWritePermission},
+					{Name: commands.ServiceAccountsWritePermission},
+				}},
+			}
+			mockApiKeyRepo.On("FindByKeyHash", mock.Anything, entity.HashToken("shk_admin")).Return(key, nil).Once()
+
+			// Phase 2: Exercise (Act)
+			resp, err := newApp().Test(newRequest("shk_admin"))
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+			Expect(identity.Permissions).To(Equal([]string{"products:write"}))
+		})
+	})
+
+	Context("when the API key has been revoked", func() {
+		It("should return unauthorized status", func() {
+			// Phase 1: Setup (Arrange)
+			revokedAt := time.Now().Add(-time.Hour)
+			key := &entity.ApiKey{ID: 1, ServiceAccountID: 7, RevokedAt: &revokedAt, ServiceAccount: &entity.ServiceAccount{ID: 7}}
+			mockApiKeyRepo.On("FindByKeyHash", mock.Anything, entity.HashToken("shk_revoked")).Return(key, nil).Once()
+
+			// Phase 2: Exercise (Act)
+			resp, err := newApp().Test(newRequest("shk_revoked"))
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Context("when the API key is unknown", func() {
+		It("should return unauthorized status", func() {
+			// Phase 1: Setup (Arrange)
+			mockApiKeyRepo.On("FindByKeyHash", mock.Anything, entity.HashToken("shk_unknown")).
+				Return(nil, repository.ErrApiKeyNotFound).Once()
+
+			// Phase 2: Exercise (Act)
+			resp, err := newApp().Test(newRequest("shk_unknown"))
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+	})
+})
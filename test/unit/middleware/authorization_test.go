@@ -0,0 +1,177 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"shikposh-backend/internal/account/domain/entity"
+	"shikposh-backend/internal/products/domain/commands"
+	"shikposh-backend/pkg/middleware"
+	apperrors "github.com/ali-mahdavi-dev/framework/errors"
+	"shikposh-backend/test/unit/testdouble/mocks"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("Authorization", func() {
+	var (
+		mockUOW      *mocks.MockPGUnitOfWork
+		mockUserRepo *mocks.MockUserRepository
+		mw           *middleware.Middleware
+		ctx          context.Context
+	)
+
+	BeforeEach(func() {
+		mockUOW = new(mocks.MockPGUnitOfWork)
+		mockUserRepo = new(mocks.MockUserRepository)
+		mockUOW.On("User", mock.Anything).Return(mockUserRepo).Maybe()
+		mw = &middleware.Middleware{Uow: mockUOW}
+		ctx = context.Background()
+	})
+
+	Describe("CommandAuthorization", func() {
+		var handled bool
+
+		handle := func(ctx context.Context, cmd any) error {
+			next := func(ctx context.Context, cmd any) error {
+				handled = true
+				return nil
+			}
+			return mw.CommandAuthorization()(next)(ctx, cmd)
+		}
+
+		BeforeEach(func() {
+			handled = false
+		})
+
+		Context("when the command does not require a permission", func() {
+			It("should pass it through without an identity", func() {
+				// Phase 1: Setup (Arrange)
+				cmd := &commands.CreateReview{ProductID: 1}
+
+				// Phase 2: Exercise (Act)
+				err := handle(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(handled).To(BeTrue())
+			})
+		})
+
+		Context("when the caller holds the required permission", func() {
+			It("should handle the command", func() {
+				// Phase 1: Setup (Arrange)
+				ctx = middleware.WithIdentity(ctx, middleware.Identity{UserID: 1})
+				mockUserRepo.On("FindPermissions", mock.Anything, entity.UserID(1)).
+					Return([]string{commands.ProductsWritePermission}, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handle(ctx, &commands.DeleteProduct{ID: 1})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(handled).To(BeTrue())
+			})
+		})
+
+		Context("when the caller lacks the required permission", func() {
+			It("should reject the command", func() {
+				// Phase 1: Setup (Arrange)
+				ctx = middleware.WithIdentity(ctx, middleware.Identity{UserID: 2})
+				mockUserRepo.On("FindPermissions", mock.Anything, entity.UserID(2)).
+					Return([]string{"users:read"}, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handle(ctx, &commands.CreateProduct{Name: "Shirt"})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeForbidden))
+				Expect(handled).To(BeFalse())
+			})
+		})
+
+		Context("when the command is dispatched without an identity", func() {
+			It("should reject the command", func() {
+				// Phase 2: Exercise (Act)
+				err := handle(ctx, &commands.UpdateProduct{ID: 1})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				appErr, ok := err.(apperrors.Error)
+				Expect(ok).To(BeTrue())
+				Expect(appErr.Type()).To(Equal(apperrors.ErrorTypeUnauthorized))
+				Expect(handled).To(BeFalse())
+				mockUserRepo.AssertNotCalled(GinkgoT(), "FindPermissions", mock.Anything, mock.Anything)
+			})
+		})
+	})
+
+	Describe("RequirePermission", func() {
+		newApp := func(identity *middleware.Identity) *fiber.App {
+			app := fiber.New()
+			app.Use(func(c fiber.Ctx) error {
+				if identity != nil {
+					c.SetContext(middleware.WithIdentity(c.Context(), *identity))
+				}
+				return c.Next()
+			})
+			app.Delete("/products/:id", mw.RequirePermission(commands.ProductsWritePermission), func(c fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusNoContent)
+			})
+			return app
+		}
+
+		Context("when the request is not authenticated", func() {
+			It("should return unauthorized status", func() {
+				// Phase 1: Setup (Arrange)
+				req := httptest.NewRequest(http.MethodDelete, "/products/1", nil)
+
+				// Phase 2: Exercise (Act)
+				resp, err := newApp(nil).Test(req)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+			})
+		})
+
+		Context("when the user lacks the permission", func() {
+			It("should return forbidden status", func() {
+				// Phase 1: Setup (Arrange)
+				mockUserRepo.On("FindPermissions", mock.Anything, entity.UserID(3)).
+					Return([]string{}, nil).Once()
+				req := httptest.NewRequest(http.MethodDelete, "/products/1", nil)
+
+				// Phase 2: Exercise (Act)
+				resp, err := newApp(&middleware.Identity{UserID: 3}).Test(req)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+			})
+		})
+
+		Context("when the user holds the permission", func() {
+			It("should call the next handler", func() {
+				// Phase 1: Setup (Arrange)
+				mockUserRepo.On("FindPermissions", mock.Anything, entity.UserID(4)).
+					Return([]string{commands.ProductsWritePermission}, nil).Once()
+				req := httptest.NewRequest(http.MethodDelete, "/products/1", nil)
+
+				// Phase 2: Exercise (Act)
+				resp, err := newApp(&middleware.Identity{UserID: 4}).Test(req)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+			})
+		})
+	})
+})
@@ -0,0 +1,116 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"shikposh-backend/internal/account/domain/entity"
+	"shikposh-backend/pkg/middleware"
+	"shikposh-backend/test/unit/testdouble/mocks"
+
+	"github.com/gofiber/fiber/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("RequireTwoFactorEnrollment", func() {
+	var (
+		mockUOW      *mocks.MockPGUnitOfWork
+		mockUserRepo *mocks.MockUserRepository
+		mw           *middleware.Middleware
+	)
+
+	BeforeEach(func() {
+		mockUOW = new(mocks.MockPGUnitOfWork)
+		mockUserRepo = new(mocks.MockUserRepository)
+		mockUOW.On("User", mock.Anything).Return(mockUserRepo).Maybe()
+		mw = &middleware.Middleware{Uow: mockUOW}
+	})
+
+	newApp := func(userID uint64) *fiber.App {
+		app := fiber.New()
+		app.Use(func(c fiber.Ctx) error {
+			c.SetContext(middleware.WithIdentity(c.Context(), middleware.Identity{UserID: userID}))
+			return c.Next()
+		})
+		app.Get("/admin", mw.RequireTwoFactorEnrollment(), func(c fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusNoContent)
+		})
+		return app
+	}
+
+	Context("when an admin has not enrolled", func() {
+		It("should return forbidden status", func() {
+			// Phase 1: Setup (Arrange)
+			mockUserRepo.On("FindByID", mock.Anything, uint64(1)).
+				Return(&entity.User{ID: 1}, nil).Once()
+			mockUserRepo.On("HasRole", mock.Anything, entity.UserID(1), entity.RoleAdmin).
+				Return(true, nil).Once()
+
+			// Phase 2: Exercise (Act)
+			resp, err := newApp(1).Test(httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+		})
+	})
+
+	Context("when an admin has enabled two-factor authentication", func() {
+		It("should call the next handler without checking the role", func() {
+			// Phase 1: Setup (Arrange)
+			enabledAt := time.Now()
+			mockUserRepo.On("FindByID", mock.Anything, uint64(2)).
+				Return(&entity.User{ID: 2, TotpEnabledAt: &enabledAt}, nil).Once()
+
+			// Phase 2: Exercise (Act)
+			resp, err := newApp(2).Test(httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+			mockUserRepo.AssertNotCalled(GinkgoT(), "HasRole", mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+
+	Context("when the user is not an admin", func() {
+		It("should call the next handler", func() {
+			// Phase 1: Setup (Arrange)
+			mockUserRepo.On("FindByID", mock.Anything, uint64(3)).
+				Return(&entity.User{ID: 3}, nil).Once()
+			mockUserRepo.On("HasRole", mock.Anything, entity.UserID(3), entity.RoleAdmin).
+				Return(false, nil).Once()
+
+			// Phase 2: Exercise (Act)
+			resp, err := newApp(3).Test(httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+		})
+	})
+
+	Context("when the caller is a service account", func() {
+		It("should call the next handler without looking up a user", func() {
+			// Phase 1: Setup (Arrange)
+			app := fiber.New()
+			app.Use(func(c fiber.Ctx) error {
+				c.SetContext(middleware.WithIdentity(c.Context(), middleware.Identity{ServiceAccountID: 7, Permissions: []string{}}))
+				return c.Next()
+			})
+			app.Get("/admin", mw.RequireTwoFactorEnrollment(), func(c fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusNoContent)
+			})
+
+			// Phase 2: Exercise (Act)
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+			// Phase 3: Verify (Assert)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+			mockUserRepo.AssertNotCalled(GinkgoT(), "FindByID", mock.Anything, mock.Anything)
+		})
+	})
+})
@@ -0,0 +1,108 @@
+package products_test
+
+import (
+	"context"
+	"fmt"
+
+	"shikposh-backend/internal/products/adapter/search"
+	"shikposh-backend/internal/products/service_layer/outbox"
+	appadapter "github.com/ali-mahdavi-dev/framework/adapter"
+	"shikposh-backend/test/unit/testdouble/builders"
+	"shikposh-backend/test/unit/testdouble/factories"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("ProductEventHandler", func() {
+	var (
+		builder *builders.ProductTestBuilder
+		handler *outbox.ProductEventHandler
+		ctx     context.Context
+	)
+
+	BeforeEach(func() {
+		builder = builders.NewProductTestBuilder().
+			WithProductRepo().
+			WithSuccessfulTransaction()
+		handler = builder.BuildSearchEventHandler()
+		ctx = context.Background()
+	})
+
+	Describe("HandleEvent", func() {
+		Context("when a product is updated", func() {
+			It("should index the product as it is now at its version", func() {
+				// Phase 1: Setup (Arrange)
+				product := factories.CreateProduct(1, "Shirt", "shirt", "Nike", 1)
+				product.Version = 3
+				builder.MockProductRepo.On("FindByID", mock.Anything, uint64(1)).Return(product, nil)
+				builder.MockIndexAdmin.On("IndexDocument", mock.Anything, search.ProductIndexAlias, mock.MatchedBy(func(document search.Document) bool {
+					return document.ID == "1" && document.Version == 3 && document.Body["name"] == "Shirt"
+				})).Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.HandleEvent(ctx, "ProductUpdatedEvent", map[string]interface{}{"product_id": float64(1), "version": float64(2)})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				builder.MockIndexAdmin.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when the product is indexed at a newer version", func() {
+			It("should skip the event", func() {
+				// Phase 1: Setup (Arrange)
+				product := factories.CreateProduct(1, "Shirt", "shirt", "Nike", 1)
+				builder.MockProductRepo.On("FindByID", mock.Anything, uint64(1)).Return(product, nil)
+				builder.MockIndexAdmin.On("IndexDocument", mock.Anything, search.ProductIndexAlias, mock.Anything).
+					Return(fmt.Errorf("httpIndexAdmin.IndexDocument fail index 1 into products: %w", search.ErrVersionConflict)).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.HandleEvent(ctx, "ProductReviewedEvent", map[string]interface{}{"product_id": float64(1), "version": float64(2)})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the product has been deleted since", func() {
+			It("should not index it", func() {
+				// Phase 1: Setup (Arrange)
+				builder.MockProductRepo.On("FindByID", mock.Anything, uint64(1)).Return(nil, appadapter.ErrEntityNotFound)
+
+				// Phase 2: Exercise (Act)
+				err := handler.HandleEvent(ctx, "ProductUpdatedEvent", map[string]interface{}{"product_id": float64(1), "version": float64(2)})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				builder.MockIndexAdmin.AssertNotCalled(GinkgoT(), "IndexDocument", mock.Anything, mock.Anything, mock.Anything)
+			})
+		})
+
+		Context("when a product is deleted", func() {
+			It("should delete its document as of the version of the deletion", func() {
+				// Phase 1: Setup (Arrange)
+				builder.MockIndexAdmin.On("DeleteDocument", mock.Anything, search.ProductIndexAlias, "1", int64(4)).Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.HandleEvent(ctx, "ProductDeletedEvent", map[string]interface{}{"product_id": float64(1), "version": float64(4)})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				builder.MockIndexAdmin.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when the deletion event has no version", func() {
+			It("should return error", func() {
+				// Phase 2: Exercise (Act)
+				err := handler.HandleEvent(ctx, "ProductDeletedEvent", map[string]interface{}{"product_id": float64(1)})
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				builder.MockIndexAdmin.AssertNotCalled(GinkgoT(), "DeleteDocument", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			})
+		})
+	})
+})
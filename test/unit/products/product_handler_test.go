@@ -7,6 +7,7 @@ import (
 	"shikposh-backend/internal/products/adapter/repository"
 	"shikposh-backend/internal/products/domain/commands"
 	productaggregate "shikposh-backend/internal/products/domain/entity/product_aggregate"
+	"shikposh-backend/internal/products/domain/events"
 	"shikposh-backend/internal/products/service_layer/command_handler"
 	appadapter "github.com/ali-mahdavi-dev/framework/adapter"
 	apperrors "github.com/ali-mahdavi-dev/framework/errors"
@@ -55,6 +56,38 @@ var _ = Describe("ProductCommandHandler", func() {
 			})
 		})
 
+		Context("when the product has several priced details", func() {
+			It("should save the price range, effective price and stock of its details", func() {
+				// Phase 1: Setup (Arrange)
+				cmd := factories.CreateProductCommand("Men's T-Shirt", "Test Brand", 1)
+				originalPrice := 250000.0
+				cmd.Details = []commands.ProductDetailInput{
+					{Price: 200000.0, Discount: 50},
+					{Price: 180000.0, OriginalPrice: &originalPrice, Discount: 28, Stock: 3},
+					{Price: 0},
+				}
+				category := factories.CreateCategory(1, "Clothing", "clothing")
+				builder.MockCategoryRepo.On("FindByID", mock.Anything, uint64(1)).
+					Return(category, nil).Maybe()
+				builder.MockProductRepo.On("FindBySlug", mock.Anything, mock.AnythingOfType("string")).
+					Return(nil, repository.ErrProductNotFound).Maybe()
+				var saved *productaggregate.Product
+				builder.MockProductRepo.On("Save", mock.Anything, mock.AnythingOfType("*product_aggregate.Product")).
+					Run(func(args mock.Arguments) { saved = args.Get(1).(*productaggregate.Product) }).
+					Return(nil)
+
+				// Phase 2: Exercise (Act)
+				err := handler.CreateProductHandler(ctx, cmd)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(saved.MinPrice).To(Equal(180000.0))
+				Expect(saved.MaxPrice).To(Equal(200000.0))
+				Expect(saved.Price).To(Equal(100000.0))
+				Expect(saved.InStock).To(BeTrue())
+			})
+		})
+
 		Context("when category does not exist", func() {
 			It("should return not found error", func() {
 				// Phase 1: Setup (Arrange)
@@ -150,6 +183,8 @@ var _ = Describe("ProductCommandHandler", func() {
 
 				// Phase 3: Verify (Assert)
 				Expect(err).NotTo(HaveOccurred())
+				Expect(product.Version).To(BeNumerically(">", 0))
+				Expect(product.Events()).To(ContainElement(&events.ProductUpdatedEvent{ProductID: 1, Version: product.Version}))
 			})
 		})
 
@@ -193,6 +228,7 @@ var _ = Describe("ProductCommandHandler", func() {
 
 				// Phase 3: Verify (Assert)
 				Expect(err).NotTo(HaveOccurred())
+				Expect(product.Events()).To(ContainElement(&events.ProductDeletedEvent{ProductID: 1, Version: product.Version, SoftDelete: true}))
 			})
 		})
 
@@ -0,0 +1,197 @@
+package products_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"shikposh-backend/internal/products/domain/entity"
+	productaggregate "shikposh-backend/internal/products/domain/entity/product_aggregate"
+	"shikposh-backend/internal/products/adapter/search"
+	"shikposh-backend/internal/products/service_layer/command_handler"
+	"shikposh-backend/test/unit/testdouble/builders"
+	"shikposh-backend/test/unit/testdouble/factories"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("SearchIndexHandler", func() {
+	var (
+		builder *builders.ProductTestBuilder
+		handler *command_handler.SearchIndexHandler
+		ctx     context.Context
+		now     time.Time
+		index   string
+	)
+
+	BeforeEach(func() {
+		builder = builders.NewProductTestBuilder().
+			WithProductRepo().
+			WithOutboxRepo().
+			WithSuccessfulTransaction()
+		handler = builder.BuildSearchIndexHandler()
+		ctx = context.Background()
+		now = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+		index = search.NewProductIndexName(now)
+	})
+
+	Describe("Reindex", func() {
+		Context("when the alias points at an index", func() {
+			It("should index every product in batches and swap the alias to the new index", func() {
+				// Phase 1: Setup (Arrange)
+				first := []*productaggregate.Product{
+					factories.CreateProduct(1, "Shirt", "shirt", "Nike", 1),
+					factories.CreateProduct(2, "Coat", "coat", "Nike", 1),
+				}
+				last := []*productaggregate.Product{factories.CreateProduct(3, "Scarf", "scarf", "Nike", 1)}
+				builder.MockProductRepo.On("FindBatch", mock.Anything, uint64(0), 2).Return(first, nil)
+				builder.MockProductRepo.On("FindBatch", mock.Anything, uint64(2), 2).Return(last, nil)
+				builder.MockIndexAdmin.On("CreateIndex", mock.Anything, index, mock.Anything).Return(nil).Once()
+				builder.MockIndexAdmin.On("Bulk", mock.Anything, index, mock.MatchedBy(func(documents []search.Document) bool {
+					return len(documents) == 2 && documents[0].ID == "1" && documents[1].ID == "2"
+				})).Return(nil).Once()
+				builder.MockIndexAdmin.On("Bulk", mock.Anything, index, mock.MatchedBy(func(documents []search.Document) bool {
+					return len(documents) == 1 && documents[0].ID == "3"
+				})).Return(nil).Once()
+				builder.MockIndexAdmin.On("Refresh", mock.Anything, index).Return(nil).Once()
+				builder.MockIndexAdmin.On("AliasIndices", mock.Anything, search.ProductIndexAlias).Return([]string{"products_old"}, nil).Once()
+				builder.MockIndexAdmin.On("UpdateAliases", mock.Anything, []map[string]interface{}{
+					{"remove": map[string]interface{}{"index": "products_old", "alias": search.ProductIndexAlias}},
+					{"add": map[string]interface{}{"index": index, "alias": search.ProductIndexAlias, "is_write_index": true}},
+				}).Return(nil).Once()
+				builder.MockIndexAdmin.On("DeleteIndex", mock.Anything, "products_old").Return(nil).Once()
+				builder.MockProductRepo.On("FindBatchChangedSince", mock.Anything, mock.Anything, uint64(0), 2).Return([]*productaggregate.Product{}, nil)
+				builder.MockOutboxRepo.On("FindByTypeSince", mock.Anything, "ProductDeletedEvent", mock.Anything).Return([]*entity.OutboxEvent{}, nil)
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.Reindex(ctx, now, 2, false)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Index).To(Equal(index))
+				Expect(result.Indexed).To(Equal(3))
+				Expect(result.Replaced).To(Equal([]string{"products_old"}))
+				builder.MockIndexAdmin.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when products is an index that predates versioned indices", func() {
+			It("should replace the index with the alias in the same step", func() {
+				// Phase 1: Setup (Arrange)
+				builder.MockProductRepo.On("FindBatch", mock.Anything, uint64(0), 500).Return([]*productaggregate.Product{}, nil)
+				builder.MockIndexAdmin.On("CreateIndex", mock.Anything, index, mock.Anything).Return(nil).Once()
+				builder.MockIndexAdmin.On("Refresh", mock.Anything, index).Return(nil).Once()
+				builder.MockIndexAdmin.On("AliasIndices", mock.Anything, search.ProductIndexAlias).Return(nil, nil).Once()
+				builder.MockIndexAdmin.On("IndexExists", mock.Anything, search.ProductIndexAlias).Return(true, nil).Once()
+				builder.MockIndexAdmin.On("UpdateAliases", mock.Anything, []map[string]interface{}{
+					{"remove_index": map[string]interface{}{"index": search.ProductIndexAlias}},
+					{"add": map[string]interface{}{"index": index, "alias": search.ProductIndexAlias, "is_write_index": true}},
+				}).Return(nil).Once()
+				builder.MockProductRepo.On("FindBatchChangedSince", mock.Anything, mock.Anything, uint64(0), 500).Return([]*productaggregate.Product{}, nil)
+				builder.MockOutboxRepo.On("FindByTypeSince", mock.Anything, "ProductDeletedEvent", mock.Anything).Return([]*entity.OutboxEvent{}, nil)
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.Reindex(ctx, now, 0, false)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Indexed).To(BeZero())
+				builder.MockIndexAdmin.AssertExpectations(GinkgoT())
+				builder.MockIndexAdmin.AssertNotCalled(GinkgoT(), "DeleteIndex", mock.Anything, mock.Anything)
+			})
+		})
+
+		Context("when products change while the index is built", func() {
+			It("should apply the changes and deletions made since it started to the new index", func() {
+				// Phase 1: Setup (Arrange)
+				since := now.Add(-time.Minute)
+				changed := factories.CreateProduct(1, "Shirt", "shirt", "Nike", 1)
+				changed.Version = 3
+				stale := factories.CreateProduct(2, "Coat", "coat", "Nike", 1)
+				builder.MockProductRepo.On("FindBatch", mock.Anything, uint64(0), 500).Return([]*productaggregate.Product{}, nil)
+				builder.MockIndexAdmin.On("CreateIndex", mock.Anything, index, mock.Anything).Return(nil).Once()
+				builder.MockIndexAdmin.On("Refresh", mock.Anything, index).Return(nil).Once()
+				builder.MockIndexAdmin.On("AliasIndices", mock.Anything, search.ProductIndexAlias).Return([]string{"products_old"}, nil).Once()
+				builder.MockIndexAdmin.On("UpdateAliases", mock.Anything, mock.Anything).Return(nil).Once()
+				builder.MockIndexAdmin.On("DeleteIndex", mock.Anything, "products_old").Return(nil).Once()
+				builder.MockProductRepo.On("FindBatchChangedSince", mock.Anything, since, uint64(0), 500).
+					Return([]*productaggregate.Product{changed, stale}, nil)
+				builder.MockIndexAdmin.On("IndexDocument", mock.Anything, index, mock.MatchedBy(func(document search.Document) bool {
+					return document.ID == "1" && document.Version == 3
+				})).Return(nil).Once()
+				builder.MockIndexAdmin.On("IndexDocument", mock.Anything, index, mock.MatchedBy(func(document search.Document) bool {
+					return document.ID == "2"
+				})).Return(search.ErrVersionConflict).Once()
+				builder.MockOutboxRepo.On("FindByTypeSince", mock.Anything, "ProductDeletedEvent", since).Return([]*entity.OutboxEvent{
+					{EventType: "ProductDeletedEvent", AggregateID: "5", Payload: map[string]interface{}{"product_id": float64(5), "version": float64(4)}},
+				}, nil)
+				builder.MockIndexAdmin.On("DeleteDocument", mock.Anything, index, "5", int64(4)).Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.Reindex(ctx, now, 0, false)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.CaughtUp).To(Equal(2))
+				builder.MockIndexAdmin.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when indexing a batch fails", func() {
+			It("should delete the new index and leave the alias alone", func() {
+				// Phase 1: Setup (Arrange)
+				products := []*productaggregate.Product{factories.CreateProduct(1, "Shirt", "shirt", "Nike", 1)}
+				builder.MockProductRepo.On("FindBatch", mock.Anything, uint64(0), 500).Return(products, nil)
+				builder.MockIndexAdmin.On("CreateIndex", mock.Anything, index, mock.Anything).Return(nil).Once()
+				builder.MockIndexAdmin.On("Bulk", mock.Anything, index, mock.Anything).Return(errors.New("mapping conflict")).Once()
+				builder.MockIndexAdmin.On("DeleteIndex", mock.Anything, index).Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				result, err := handler.Reindex(ctx, now, 0, false)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).To(HaveOccurred())
+				Expect(result).To(BeNil())
+				builder.MockIndexAdmin.AssertExpectations(GinkgoT())
+				builder.MockIndexAdmin.AssertNotCalled(GinkgoT(), "UpdateAliases", mock.Anything, mock.Anything)
+			})
+		})
+	})
+
+	Describe("EnsureProductIndex", func() {
+		Context("when there is neither an alias nor an index", func() {
+			It("should create a versioned index behind the alias", func() {
+				// Phase 1: Setup (Arrange)
+				builder.MockIndexAdmin.On("AliasIndices", mock.Anything, search.ProductIndexAlias).Return(nil, nil).Once()
+				builder.MockIndexAdmin.On("IndexExists", mock.Anything, search.ProductIndexAlias).Return(false, nil).Once()
+				builder.MockIndexAdmin.On("CreateIndex", mock.Anything, index, search.ProductIndexSettings()).Return(nil).Once()
+				builder.MockIndexAdmin.On("UpdateAliases", mock.Anything, []map[string]interface{}{
+					{"add": map[string]interface{}{"index": index, "alias": search.ProductIndexAlias}},
+				}).Return(nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.EnsureProductIndex(ctx, now)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				builder.MockIndexAdmin.AssertExpectations(GinkgoT())
+			})
+		})
+
+		Context("when the alias exists", func() {
+			It("should leave it alone", func() {
+				// Phase 1: Setup (Arrange)
+				builder.MockIndexAdmin.On("AliasIndices", mock.Anything, search.ProductIndexAlias).Return([]string{index}, nil).Once()
+
+				// Phase 2: Exercise (Act)
+				err := handler.EnsureProductIndex(ctx, now)
+
+				// Phase 3: Verify (Assert)
+				Expect(err).NotTo(HaveOccurred())
+				builder.MockIndexAdmin.AssertNotCalled(GinkgoT(), "CreateIndex", mock.Anything, mock.Anything, mock.Anything)
+			})
+		})
+	})
+})
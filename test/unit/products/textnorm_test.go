@@ -0,0 +1,26 @@
+package products_test
+
+import (
+	"shikposh-backend/pkg/textnorm"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Normalize", func() {
+	DescribeTable("should fold the ways a text can be typed into one",
+		func(input, expected string) {
+			// Phase 2: Exercise (Act)
+			normalized := textnorm.Normalize(input)
+
+			// Phase 3: Verify (Assert)
+			Expect(normalized).To(Equal(expected))
+		},
+		Entry("Arabic yeh and kaf", "كيف", "کیف"),
+		Entry("teh marbuta and alef with hamza", "أسامة", "اسامه"),
+		Entry("Persian and Arabic-Indic digits", "۴۲ ٤٢", "42 42"),
+		Entry("zero-width non-joiner", "می\u200cروم", "میروم"),
+		Entry("diacritics and tatweel", "کِـتاب", "کتاب"),
+		Entry("mixed spacing and case", "  Nike \t Air  ", "nike air"),
+	)
+})
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"shikposh-backend/internal/products/service_layer/command_handler"
+	"shikposh-backend/internal/products/service_layer/outbox"
 	"github.com/ali-mahdavi-dev/framework/service_layer/types"
 	"shikposh-backend/test/unit/testdouble/mocks"
 
@@ -15,6 +16,8 @@ type ProductTestBuilder struct {
 	MockUOW          *mocks.MockPGUnitOfWork
 	MockProductRepo  *mocks.MockProductRepository
 	MockCategoryRepo *mocks.MockCategoryRepository
+	MockIndexAdmin   *mocks.MockIndexAdmin
+	MockOutboxRepo   *mocks.MockOutboxRepository
 }
 
 func NewProductTestBuilder() *ProductTestBuilder {
@@ -22,6 +25,8 @@ func NewProductTestBuilder() *ProductTestBuilder {
 		MockUOW:          new(mocks.MockPGUnitOfWork),
 		MockProductRepo:  new(mocks.MockProductRepository),
 		MockCategoryRepo: new(mocks.MockCategoryRepository),
+		MockIndexAdmin:   new(mocks.MockIndexAdmin),
+		MockOutboxRepo:   new(mocks.MockOutboxRepository),
 	}
 }
 
@@ -29,6 +34,14 @@ func (b *ProductTestBuilder) BuildHandler() *command_handler.ProductCommandHandl
 	return command_handler.NewProductCommandHandler(b.MockUOW)
 }
 
+func (b *ProductTestBuilder) BuildSearchIndexHandler() *command_handler.SearchIndexHandler {
+	return command_handler.NewSearchIndexHandler(b.MockUOW, b.MockIndexAdmin)
+}
+
+func (b *ProductTestBuilder) BuildSearchEventHandler() *outbox.ProductEventHandler {
+	return outbox.NewProductEventHandler(b.MockUOW, b.MockIndexAdmin)
+}
+
 func (b *ProductTestBuilder) WithProductRepo() *ProductTestBuilder {
 	b.MockUOW.On("Product", mock.Anything).Return(b.MockProductRepo).Maybe()
 	return b
@@ -39,6 +52,11 @@ func (b *ProductTestBuilder) WithCategoryRepo() *ProductTestBuilder {
 	return b
 }
 
+func (b *ProductTestBuilder) WithOutboxRepo() *ProductTestBuilder {
+	b.MockUOW.On("Outbox", mock.Anything).Return(b.MockOutboxRepo).Maybe()
+	return b
+}
+
 func (b *ProductTestBuilder) WithSuccessfulTransaction() *ProductTestBuilder {
 	b.MockUOW.On("Do", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
 		fc := args.Get(1).(types.UowUseCase)
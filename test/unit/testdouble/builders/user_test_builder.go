@@ -1,11 +1,9 @@
 package builders
 
 import (
-	"context"
-
 	"shikposh-backend/config"
+	"shikposh-backend/internal/account/adapter"
 	"shikposh-backend/internal/account/service_layer/command_handler"
-	"github.com/ali-mahdavi-dev/framework/service_layer/types"
 	"shikposh-backend/test/unit/testdouble/mocks"
 
 	"github.com/stretchr/testify/mock"
@@ -13,28 +11,97 @@ import (
 
 // UserTestBuilder helps build test scenarios with mocks
 type UserTestBuilder struct {
-	MockUOW       *mocks.MockPGUnitOfWork
-	MockUserRepo  *mocks.MockUserRepository
-	MockTokenRepo *mocks.MockTokenRepository
-	cfg           *config.Config
+	MockUOW              *mocks.MockPGUnitOfWork
+	MockUserRepo         *mocks.MockUserRepository
+	MockSessionRepo      *mocks.MockSessionRepository
+	MockRefreshTokenRepo *mocks.MockRefreshTokenRepository
+	MockRoleRepo         *mocks.MockRoleRepository
+	MockVerificationRepo *mocks.MockEmailVerificationRepository
+	MockResetRepo        *mocks.MockPasswordResetRepository
+	MockProfileRepo      *mocks.MockProfileRepository
+	MockChallengeRepo    *mocks.MockTwoFactorChallengeRepository
+	MockRecoveryRepo     *mocks.MockRecoveryCodeRepository
+	MockUnlockRepo       *mocks.MockAccountUnlockRepository
+	MockAddressRepo      *mocks.MockAddressRepository
+	MockReviewRepo       *mocks.MockReviewRepository
+	MockServiceAcctRepo  *mocks.MockServiceAccountRepository
+	MockApiKeyRepo       *mocks.MockApiKeyRepository
+	MockExternalIDRepo   *mocks.MockExternalIdentityRepository
+	MockOidcAuthRepo     *mocks.MockOidcAuthorizationRepository
+	MockIdentityProvider *mocks.MockIdentityProvider
+	LoginAttempts        adapter.LoginAttemptStore
+	MockSms              *mocks.MockSmsSender
+	cfg                  *config.Config
 }
 
 func NewUserTestBuilder() *UserTestBuilder {
 	return &UserTestBuilder{
-		MockUOW:       new(mocks.MockPGUnitOfWork),
-		MockUserRepo:  new(mocks.MockUserRepository),
-		MockTokenRepo: new(mocks.MockTokenRepository),
+		MockUOW:              new(mocks.MockPGUnitOfWork),
+		MockUserRepo:         new(mocks.MockUserRepository),
+		MockSessionRepo:      new(mocks.MockSessionRepository),
+		MockRefreshTokenRepo: new(mocks.MockRefreshTokenRepository),
+		MockRoleRepo:         new(mocks.MockRoleRepository),
+		MockVerificationRepo: new(mocks.MockEmailVerificationRepository),
+		MockResetRepo:        new(mocks.MockPasswordResetRepository),
+		MockProfileRepo:      new(mocks.MockProfileRepository),
+		MockChallengeRepo:    new(mocks.MockTwoFactorChallengeRepository),
+		MockRecoveryRepo:     new(mocks.MockRecoveryCodeRepository),
+		MockUnlockRepo:       new(mocks.MockAccountUnlockRepository),
+		MockAddressRepo:      new(mocks.MockAddressRepository),
+		MockReviewRepo:       new(mocks.MockReviewRepository),
+		MockServiceAcctRepo:  new(mocks.MockServiceAccountRepository),
+		MockApiKeyRepo:       new(mocks.MockApiKeyRepository),
+		MockExternalIDRepo:   new(mocks.MockExternalIdentityRepository),
+		MockOidcAuthRepo:     new(mocks.MockOidcAuthorizationRepository),
+		MockIdentityProvider: new(mocks.MockIdentityProvider),
+		LoginAttempts:        adapter.NewMemoryLoginAttemptStore(),
+		MockSms:              new(mocks.MockSmsSender),
 		cfg: &config.Config{
 			JWT: config.JWTConfig{
-				Secret:                    "test-secret-key-for-jwt-token-generation",
-				AccessTokenExpireDuration: 3600,
+				Secret:                     "test-secret-key-for-jwt-token-generation",
+				AccessTokenExpireDuration:  15,
+				RefreshTokenExpireDuration: 60,
+			},
+			Verification: config.VerificationConfig{
+				TokenExpireDuration: 1440,
+				ResendInterval:      1,
 			},
 		},
 	}
 }
 
 func (b *UserTestBuilder) BuildHandler() *command_handler.UserHandler {
-	return command_handler.NewUserHandler(b.MockUOW, b.cfg)
+	accessTokens, err := adapter.NewAccessTokenKeys(b.cfg.JWT)
+	if err != nil {
+		panic(err)
+	}
+
+	return command_handler.NewUserHandler(b.MockUOW, b.cfg, accessTokens, b.LoginAttempts)
+}
+
+// BuildOtpHandler returns an OTP handler that keeps its codes in store.
+func (b *UserTestBuilder) BuildOtpHandler(store adapter.OtpStore) *command_handler.OtpHandler {
+	return command_handler.NewOtpHandler(b.MockUOW, b.cfg, store, b.MockSms, b.BuildHandler())
+}
+
+func (b *UserTestBuilder) BuildAddressHandler() *command_handler.AddressHandler {
+	return command_handler.NewAddressHandler(b.MockUOW)
+}
+
+func (b *UserTestBuilder) BuildServiceAccountHandler() *command_handler.ServiceAccountHandler {
+	return command_handler.NewServiceAccountHandler(b.MockUOW)
+}
+
+// BuildOidcHandler returns an OIDC handler whose only provider, "fake", is
+// MockIdentityProvider.
+func (b *UserTestBuilder) BuildOidcHandler() *command_handler.OidcHandler {
+	providers := adapter.IdentityProviders{"fake": b.MockIdentityProvider}
+	return command_handler.NewOidcHandler(b.MockUOW, b.cfg, providers, b.BuildHandler())
+}
+
+// BuildAvatarHandler returns an avatar handler that keeps uploads in storage.
+func (b *UserTestBuilder) BuildAvatarHandler(storage adapter.FileStorage) *command_handler.AvatarHandler {
+	return command_handler.NewAvatarHandler(b.MockUOW, b.cfg, storage)
 }
 
 func (b *UserTestBuilder) WithUserRepo() *UserTestBuilder {
@@ -42,15 +109,95 @@ func (b *UserTestBuilder) WithUserRepo() *UserTestBuilder {
 	return b
 }
 
-func (b *UserTestBuilder) WithTokenRepo() *UserTestBuilder {
-	b.MockUOW.On("Token", mock.Anything).Return(b.MockTokenRepo).Maybe()
+func (b *UserTestBuilder) WithProfileRepo() *UserTestBuilder {
+	b.MockUOW.On("Profile", mock.Anything).Return(b.MockProfileRepo).Maybe()
+	return b
+}
+
+func (b *UserTestBuilder) WithSessionRepo() *UserTestBuilder {
+	b.MockUOW.On("Session", mock.Anything).Return(b.MockSessionRepo).Maybe()
+	return b
+}
+
+func (b *UserTestBuilder) WithRefreshTokenRepo() *UserTestBuilder {
+	b.MockUOW.On("RefreshToken", mock.Anything).Return(b.MockRefreshTokenRepo).Maybe()
+	return b
+}
+
+func (b *UserTestBuilder) WithEmailVerificationRepo() *UserTestBuilder {
+	b.MockUOW.On("EmailVerification", mock.Anything).Return(b.MockVerificationRepo).Maybe()
+	return b
+}
+
+func (b *UserTestBuilder) WithPasswordResetRepo() *UserTestBuilder {
+	b.MockUOW.On("PasswordReset", mock.Anything).Return(b.MockResetRepo).Maybe()
+	return b
+}
+
+func (b *UserTestBuilder) WithTwoFactorRepos() *UserTestBuilder {
+	b.MockUOW.On("TwoFactorChallenge", mock.Anything).Return(b.MockChallengeRepo).Maybe()
+	b.MockUOW.On("RecoveryCode", mock.Anything).Return(b.MockRecoveryRepo).Maybe()
+	return b
+}
+
+func (b *UserTestBuilder) WithAccountUnlockRepo() *UserTestBuilder {
+	b.MockUOW.On("AccountUnlock", mock.Anything).Return(b.MockUnlockRepo).Maybe()
+	return b
+}
+
+func (b *UserTestBuilder) WithAddressRepo() *UserTestBuilder {
+	b.MockUOW.On("Address", mock.Anything).Return(b.MockAddressRepo).Maybe()
+	return b
+}
+
+func (b *UserTestBuilder) WithReviewRepo() *UserTestBuilder {
+	b.MockUOW.On("Review", mock.Anything).Return(b.MockReviewRepo).Maybe()
+	return b
+}
+
+func (b *UserTestBuilder) WithServiceAccountRepos() *UserTestBuilder {
+	b.MockUOW.On("ServiceAccount", mock.Anything).Return(b.MockServiceAcctRepo).Maybe()
+	b.MockUOW.On("ApiKey", mock.Anything).Return(b.MockApiKeyRepo).Maybe()
+	return b
+}
+
+func (b *UserTestBuilder) WithOidcRepos() *UserTestBuilder {
+	b.MockUOW.On("ExternalIdentity", mock.Anything).Return(b.MockExternalIDRepo).Maybe()
+	b.MockUOW.On("OidcAuthorization", mock.Anything).Return(b.MockOidcAuthRepo).Maybe()
+	return b
+}
+
+// WithLoginProtection enables the login backoff and lockout rules.
+func (b *UserTestBuilder) WithLoginProtection(rules config.LoginConfig) *UserTestBuilder {
+	b.cfg.Login = rules
+	return b
+}
+
+// WithPasswordRules enforces the given rules on new passwords.
+func (b *UserTestBuilder) WithPasswordRules(rules config.PasswordConfig) *UserTestBuilder {
+	b.cfg.Password = rules
+	return b
+}
+
+// WithRequiredEmailVerification makes login refuse users whose email is not verified.
+func (b *UserTestBuilder) WithRequiredEmailVerification() *UserTestBuilder {
+	b.cfg.Verification.RequireVerifiedEmail = true
+	return b
+}
+
+func (b *UserTestBuilder) WithAvatarUploadLimit(kilobytes int64) *UserTestBuilder {
+	b.cfg.Avatar.MaxUploadSize = kilobytes
+	return b
+}
+
+func (b *UserTestBuilder) WithRoleRepo() *UserTestBuilder {
+	b.MockUOW.On("Role", mock.Anything).Return(b.MockRoleRepo).Maybe()
 	return b
 }
 
 func (b *UserTestBuilder) WithSuccessfulTransaction() *UserTestBuilder {
-	b.MockUOW.On("Do", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
-		fc := args.Get(1).(types.UowUseCase)
-		fc(args.Get(0).(context.Context))
-	}).Maybe()
+	// MockPGUnitOfWork.Do runs the use case itself; running it here as well
+	// would execute every handler twice.
+	b.MockUOW.On("Do", mock.Anything, mock.Anything).Return(nil).Maybe()
 	return b
 }
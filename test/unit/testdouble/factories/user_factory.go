@@ -1,6 +1,8 @@
 package factories
 
 import (
+	"time"
+
 	"shikposh-backend/internal/account/domain/commands"
 	"shikposh-backend/internal/account/domain/entity"
 
@@ -37,3 +39,83 @@ func CreateUser(username, email, password string) *entity.User {
 		Password:         string(hashedPassword),
 	}
 }
+
+func CreateSession(id entity.SessionID, userID entity.UserID) *entity.Session {
+	return &entity.Session{
+		ID:         id,
+		UserID:     userID,
+		UserAgent:  "test-agent",
+		IP:         "127.0.0.1",
+		LastSeenAt: time.Now(),
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}
+}
+
+// CreateRefreshToken returns a refresh token of the given session together with its plain value.
+func CreateRefreshToken(id entity.RefreshTokenID, session *entity.Session) (*entity.RefreshToken, string) {
+	token, plain, _ := entity.NewRefreshToken(session.ID, session.UserID, session.ExpiresAt)
+	token.ID = id
+	return token, plain
+}
+
+// CreateEmailVerificationToken returns a verification token for the user's
+// current email together with its plain value.
+func CreateEmailVerificationToken(id entity.EmailVerificationTokenID, user *entity.User, expiresAt time.Time) (*entity.EmailVerificationToken, string) {
+	token, plain, _ := entity.NewEmailVerificationToken(user.ID, user.Email, expiresAt)
+	token.ID = id
+	token.CreatedAt = time.Now()
+	return token, plain
+}
+
+// CreatePasswordResetToken returns a reset token of the user together with its plain value.
+func CreatePasswordResetToken(id entity.PasswordResetTokenID, user *entity.User, expiresAt time.Time) (*entity.PasswordResetToken, string) {
+	token, plain, _ := entity.NewPasswordResetToken(user.ID, expiresAt)
+	token.ID = id
+	return token, plain
+}
+
+// CreateTwoFactorUser returns a user with two-factor authentication enabled
+// for the given TOTP secret.
+func CreateTwoFactorUser(username, email, password, secret string) *entity.User {
+	user := CreateUser(username, email, password)
+	user.StartTotpEnrollment(secret)
+	user.ConfirmTotpEnrollment(time.Now())
+	return user
+}
+
+// CreateTwoFactorChallenge returns a login challenge of the user together with its plain token.
+func CreateTwoFactorChallenge(id entity.TwoFactorChallengeID, user *entity.User, expiresAt time.Time) (*entity.TwoFactorChallenge, string) {
+	challenge, plain, _ := entity.NewTwoFactorChallenge(user.ID, expiresAt)
+	challenge.ID = id
+	return challenge, plain
+}
+
+// CreateSaveAddress returns valid address fields as a client would send them.
+func CreateSaveAddress() commands.SaveAddress {
+	return commands.SaveAddress{
+		RecipientName: "John Doe",
+		Phone:         "09121234567",
+		Province:      "Tehran",
+		City:          "Tehran",
+		PostalCode:    "1193653471",
+		Street:        "Valiasr St.",
+		Plate:         "12",
+		Unit:          "3",
+	}
+}
+
+// CreateAddress returns a stored address of the user.
+func CreateAddress(id entity.AddressID, userID entity.UserID, isDefault bool) *entity.Address {
+	address := entity.NewAddress(userID, entity.AddressFields{
+		RecipientName: "John Doe",
+		Phone:         "+989121234567",
+		Province:      "Tehran",
+		City:          "Tehran",
+		PostalCode:    "1193653471",
+		Street:        "Valiasr St.",
+		Plate:         "12",
+	})
+	address.ID = id
+	address.IsDefault = isDefault
+	return address
+}
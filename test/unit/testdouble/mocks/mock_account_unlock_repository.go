@@ -0,0 +1,75 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAccountUnlockRepository is a mock implementation of AccountUnlockRepository
+type MockAccountUnlockRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccountUnlockRepository) FindByID(ctx context.Context, id uint64) (*entity.AccountUnlockToken, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.AccountUnlockToken), args.Error(1)
+}
+
+func (m *MockAccountUnlockRepository) FindByField(ctx context.Context, field string, value interface{}) (*entity.AccountUnlockToken, error) {
+	args := m.Called(ctx, field, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.AccountUnlockToken), args.Error(1)
+}
+
+func (m *MockAccountUnlockRepository) Remove(ctx context.Context, model *entity.AccountUnlockToken, softDelete bool) error {
+	args := m.Called(ctx, model, softDelete)
+	return args.Error(0)
+}
+
+func (m *MockAccountUnlockRepository) Modify(ctx context.Context, model *entity.AccountUnlockToken) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockAccountUnlockRepository) Save(ctx context.Context, model *entity.AccountUnlockToken) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockAccountUnlockRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.AccountUnlockToken, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.AccountUnlockToken), args.Error(1)
+}
+
+func (m *MockAccountUnlockRepository) MarkUsed(ctx context.Context, token *entity.AccountUnlockToken, now time.Time) (bool, error) {
+	args := m.Called(ctx, token, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAccountUnlockRepository) Seen() []adapter.Entity {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]adapter.Entity)
+}
+
+func (m *MockAccountUnlockRepository) SetSeen(model adapter.Entity) {
+	m.Called(model)
+}
+
+var _ repository.AccountUnlockRepository = (*MockAccountUnlockRepository)(nil)
@@ -0,0 +1,82 @@
+package mocks
+
+import (
+	"context"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAddressRepository is a mock implementation of AddressRepository
+type MockAddressRepository struct {
+	mock.Mock
+}
+
+func (m *MockAddressRepository) FindByID(ctx context.Context, id uint64) (*entity.Address, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Address), args.Error(1)
+}
+
+func (m *MockAddressRepository) FindByField(ctx context.Context, field string, value interface{}) (*entity.Address, error) {
+	args := m.Called(ctx, field, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Address), args.Error(1)
+}
+
+func (m *MockAddressRepository) Remove(ctx context.Context, model *entity.Address, softDelete bool) error {
+	args := m.Called(ctx, model, softDelete)
+	return args.Error(0)
+}
+
+func (m *MockAddressRepository) Modify(ctx context.Context, model *entity.Address) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockAddressRepository) Save(ctx context.Context, model *entity.Address) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockAddressRepository) FindByUserID(ctx context.Context, userID entity.UserID) ([]*entity.Address, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Address), args.Error(1)
+}
+
+func (m *MockAddressRepository) FindByIDAndUserID(ctx context.Context, id entity.AddressID, userID entity.UserID) (*entity.Address, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Address), args.Error(1)
+}
+
+func (m *MockAddressRepository) ClearDefault(ctx context.Context, userID entity.UserID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAddressRepository) Seen() []adapter.Entity {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]adapter.Entity)
+}
+
+func (m *MockAddressRepository) SetSeen(model adapter.Entity) {
+	m.Called(model)
+}
+
+var _ repository.AddressRepository = (*MockAddressRepository)(nil)
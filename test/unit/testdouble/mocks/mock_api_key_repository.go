@@ -0,0 +1,83 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockApiKeyRepository is a mock implementation of ApiKeyRepository
+type MockApiKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockApiKeyRepository) FindByID(ctx context.Context, id uint64) (*entity.ApiKey, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) FindByField(ctx context.Context, field string, value interface{}) (*entity.ApiKey, error) {
+	args := m.Called(ctx, field, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) Remove(ctx context.Context, model *entity.ApiKey, softDelete bool) error {
+	args := m.Called(ctx, model, softDelete)
+	return args.Error(0)
+}
+
+func (m *MockApiKeyRepository) Modify(ctx context.Context, model *entity.ApiKey) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockApiKeyRepository) Save(ctx context.Context, model *entity.ApiKey) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockApiKeyRepository) FindByKeyHash(ctx context.Context, keyHash string) (*entity.ApiKey, error) {
+	args := m.Called(ctx, keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) FindByIDAndServiceAccountID(ctx context.Context, id entity.ApiKeyID, serviceAccountID entity.ServiceAccountID) (*entity.ApiKey, error) {
+	args := m.Called(ctx, id, serviceAccountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) MarkUsed(ctx context.Context, key *entity.ApiKey, now time.Time) error {
+	args := m.Called(ctx, key, now)
+	return args.Error(0)
+}
+
+func (m *MockApiKeyRepository) Seen() []adapter.Entity {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]adapter.Entity)
+}
+
+func (m *MockApiKeyRepository) SetSeen(model adapter.Entity) {
+	m.Called(model)
+}
+
+var _ repository.ApiKeyRepository = (*MockApiKeyRepository)(nil)
@@ -0,0 +1,49 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAuditEventRepository is a mock implementation of AuditEventRepository
+type MockAuditEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditEventRepository) Save(ctx context.Context, event *entity.AuditEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockAuditEventRepository) Search(ctx context.Context, search repository.AuditEventSearch) ([]*entity.AuditEvent, int64, error) {
+	args := m.Called(ctx, search)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entity.AuditEvent), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockAuditEventRepository) PurgeBefore(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAuditEventRepository) Seen() []adapter.Entity {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]adapter.Entity)
+}
+
+func (m *MockAuditEventRepository) SetSeen(model adapter.Entity) {
+	m.Called(model)
+}
+
+var _ repository.AuditEventRepository = (*MockAuditEventRepository)(nil)
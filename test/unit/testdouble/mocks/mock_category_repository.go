@@ -62,6 +62,14 @@ func (m *MockCategoryRepository) FindBySlug(ctx context.Context, slug string) (*
 	return args.Get(0).(*entity.Category), args.Error(1)
 }
 
+func (m *MockCategoryRepository) SuggestNames(ctx context.Context, prefix string, limit int) ([]repository.CategorySuggestion, error) {
+	args := m.Called(ctx, prefix, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.CategorySuggestion), args.Error(1)
+}
+
 func (m *MockCategoryRepository) Seen() []adapter.Entity {
 	args := m.Called()
 	if args.Get(0) == nil {
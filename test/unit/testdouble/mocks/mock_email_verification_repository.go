@@ -0,0 +1,83 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockEmailVerificationRepository is a mock implementation of EmailVerificationRepository
+type MockEmailVerificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailVerificationRepository) FindByID(ctx context.Context, id uint64) (*entity.EmailVerificationToken, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EmailVerificationToken), args.Error(1)
+}
+
+func (m *MockEmailVerificationRepository) FindByField(ctx context.Context, field string, value interface{}) (*entity.EmailVerificationToken, error) {
+	args := m.Called(ctx, field, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EmailVerificationToken), args.Error(1)
+}
+
+func (m *MockEmailVerificationRepository) Remove(ctx context.Context, model *entity.EmailVerificationToken, softDelete bool) error {
+	args := m.Called(ctx, model, softDelete)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationRepository) Modify(ctx context.Context, model *entity.EmailVerificationToken) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationRepository) Save(ctx context.Context, model *entity.EmailVerificationToken) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.EmailVerificationToken, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EmailVerificationToken), args.Error(1)
+}
+
+func (m *MockEmailVerificationRepository) FindLatestByUserID(ctx context.Context, userID entity.UserID) (*entity.EmailVerificationToken, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EmailVerificationToken), args.Error(1)
+}
+
+func (m *MockEmailVerificationRepository) MarkUsed(ctx context.Context, token *entity.EmailVerificationToken, now time.Time) (bool, error) {
+	args := m.Called(ctx, token, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockEmailVerificationRepository) Seen() []adapter.Entity {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]adapter.Entity)
+}
+
+func (m *MockEmailVerificationRepository) SetSeen(model adapter.Entity) {
+	m.Called(model)
+}
+
+var _ repository.EmailVerificationRepository = (*MockEmailVerificationRepository)(nil)
@@ -0,0 +1,69 @@
+package mocks
+
+import (
+	"context"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockExternalIdentityRepository is a mock implementation of ExternalIdentityRepository
+type MockExternalIdentityRepository struct {
+	mock.Mock
+}
+
+func (m *MockExternalIdentityRepository) FindByID(ctx context.Context, id uint64) (*entity.ExternalIdentity, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ExternalIdentity), args.Error(1)
+}
+
+func (m *MockExternalIdentityRepository) FindByField(ctx context.Context, field string, value interface{}) (*entity.ExternalIdentity, error) {
+	args := m.Called(ctx, field, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ExternalIdentity), args.Error(1)
+}
+
+func (m *MockExternalIdentityRepository) Remove(ctx context.Context, model *entity.ExternalIdentity, softDelete bool) error {
+	args := m.Called(ctx, model, softDelete)
+	return args.Error(0)
+}
+
+func (m *MockExternalIdentityRepository) Modify(ctx context.Context, model *entity.ExternalIdentity) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockExternalIdentityRepository) Save(ctx context.Context, model *entity.ExternalIdentity) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockExternalIdentityRepository) FindByProviderAndSubject(ctx context.Context, provider, subject string) (*entity.ExternalIdentity, error) {
+	args := m.Called(ctx, provider, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ExternalIdentity), args.Error(1)
+}
+
+func (m *MockExternalIdentityRepository) Seen() []adapter.Entity {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]adapter.Entity)
+}
+
+func (m *MockExternalIdentityRepository) SetSeen(model adapter.Entity) {
+	m.Called(model)
+}
+
+var _ repository.ExternalIdentityRepository = (*MockExternalIdentityRepository)(nil)
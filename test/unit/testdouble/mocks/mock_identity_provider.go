@@ -0,0 +1,34 @@
+package mocks
+
+import (
+	"context"
+
+	"shikposh-backend/internal/account/adapter"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockIdentityProvider is a mock implementation of IdentityProvider
+type MockIdentityProvider struct {
+	mock.Mock
+}
+
+func (m *MockIdentityProvider) Name() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockIdentityProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	args := m.Called(ctx, state, nonce, codeChallenge)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockIdentityProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*adapter.ExternalIdentity, error) {
+	args := m.Called(ctx, code, codeVerifier, nonce)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*adapter.ExternalIdentity), args.Error(1)
+}
+
+var _ adapter.IdentityProvider = (*MockIdentityProvider)(nil)
@@ -0,0 +1,64 @@
+package mocks
+
+import (
+	"context"
+
+	"shikposh-backend/internal/products/adapter/search"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockIndexAdmin is a mock implementation of search.IndexAdmin
+type MockIndexAdmin struct {
+	mock.Mock
+}
+
+func (m *MockIndexAdmin) CreateIndex(ctx context.Context, index string, body map[string]interface{}) error {
+	args := m.Called(ctx, index, body)
+	return args.Error(0)
+}
+
+func (m *MockIndexAdmin) DeleteIndex(ctx context.Context, index string) error {
+	args := m.Called(ctx, index)
+	return args.Error(0)
+}
+
+func (m *MockIndexAdmin) IndexExists(ctx context.Context, index string) (bool, error) {
+	args := m.Called(ctx, index)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockIndexAdmin) AliasIndices(ctx context.Context, alias string) ([]string, error) {
+	args := m.Called(ctx, alias)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockIndexAdmin) UpdateAliases(ctx context.Context, actions []map[string]interface{}) error {
+	args := m.Called(ctx, actions)
+	return args.Error(0)
+}
+
+func (m *MockIndexAdmin) Bulk(ctx context.Context, index string, documents []search.Document) error {
+	args := m.Called(ctx, index, documents)
+	return args.Error(0)
+}
+
+func (m *MockIndexAdmin) Refresh(ctx context.Context, index string) error {
+	args := m.Called(ctx, index)
+	return args.Error(0)
+}
+
+func (m *MockIndexAdmin) IndexDocument(ctx context.Context, index string, document search.Document) error {
+	args := m.Called(ctx, index, document)
+	return args.Error(0)
+}
+
+func (m *MockIndexAdmin) DeleteDocument(ctx context.Context, index, id string, version int64) error {
+	args := m.Called(ctx, index, id, version)
+	return args.Error(0)
+}
+
+var _ search.IndexAdmin = (*MockIndexAdmin)(nil)
@@ -0,0 +1,75 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOidcAuthorizationRepository is a mock implementation of OidcAuthorizationRepository
+type MockOidcAuthorizationRepository struct {
+	mock.Mock
+}
+
+func (m *MockOidcAuthorizationRepository) FindByID(ctx context.Context, id uint64) (*entity.OidcAuthorization, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.OidcAuthorization), args.Error(1)
+}
+
+func (m *MockOidcAuthorizationRepository) FindByField(ctx context.Context, field string, value interface{}) (*entity.OidcAuthorization, error) {
+	args := m.Called(ctx, field, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.OidcAuthorization), args.Error(1)
+}
+
+func (m *MockOidcAuthorizationRepository) Remove(ctx context.Context, model *entity.OidcAuthorization, softDelete bool) error {
+	args := m.Called(ctx, model, softDelete)
+	return args.Error(0)
+}
+
+func (m *MockOidcAuthorizationRepository) Modify(ctx context.Context, model *entity.OidcAuthorization) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockOidcAuthorizationRepository) Save(ctx context.Context, model *entity.OidcAuthorization) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockOidcAuthorizationRepository) FindByStateHash(ctx context.Context, stateHash string) (*entity.OidcAuthorization, error) {
+	args := m.Called(ctx, stateHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.OidcAuthorization), args.Error(1)
+}
+
+func (m *MockOidcAuthorizationRepository) MarkUsed(ctx context.Context, authorization *entity.OidcAuthorization, now time.Time) (bool, error) {
+	args := m.Called(ctx, authorization, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockOidcAuthorizationRepository) Seen() []adapter.Entity {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]adapter.Entity)
+}
+
+func (m *MockOidcAuthorizationRepository) SetSeen(model adapter.Entity) {
+	m.Called(model)
+}
+
+var _ repository.OidcAuthorizationRepository = (*MockOidcAuthorizationRepository)(nil)
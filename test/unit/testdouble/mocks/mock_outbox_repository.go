@@ -0,0 +1,112 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/internal/products/adapter/repository"
+	"shikposh-backend/internal/products/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockOutboxRepository is a mock implementation of OutboxRepository
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) FindByID(ctx context.Context, id uint64) (*entity.OutboxEvent, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.OutboxEvent), args.Error(1)
+}
+
+func (m *MockOutboxRepository) FindByField(ctx context.Context, field string, value interface{}) (*entity.OutboxEvent, error) {
+	args := m.Called(ctx, field, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.OutboxEvent), args.Error(1)
+}
+
+func (m *MockOutboxRepository) Remove(ctx context.Context, model *entity.OutboxEvent, softDelete bool) error {
+	args := m.Called(ctx, model, softDelete)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) Modify(ctx context.Context, model *entity.OutboxEvent) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) Save(ctx context.Context, model *entity.OutboxEvent) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) Model(ctx context.Context) *gorm.DB {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(*gorm.DB)
+}
+
+func (m *MockOutboxRepository) Create(ctx context.Context, event *entity.OutboxEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) GetPendingEvents(ctx context.Context, limit int) ([]*entity.OutboxEvent, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.OutboxEvent), args.Error(1)
+}
+
+func (m *MockOutboxRepository) MarkAsProcessing(ctx context.Context, id entity.OutboxEventID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) MarkAsCompleted(ctx context.Context, id entity.OutboxEventID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) MarkAsFailed(ctx context.Context, id entity.OutboxEventID, errorMsg string) error {
+	args := m.Called(ctx, id, errorMsg)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) IncrementRetry(ctx context.Context, id entity.OutboxEventID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) FindByTypeSince(ctx context.Context, eventType string, since time.Time) ([]*entity.OutboxEvent, error) {
+	args := m.Called(ctx, eventType, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.OutboxEvent), args.Error(1)
+}
+
+func (m *MockOutboxRepository) Seen() []adapter.Entity {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]adapter.Entity)
+}
+
+func (m *MockOutboxRepository) SetSeen(model adapter.Entity) {
+	m.Called(model)
+}
+
+var _ repository.OutboxRepository = (*MockOutboxRepository)(nil)
@@ -0,0 +1,80 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPasswordResetRepository is a mock implementation of PasswordResetRepository
+type MockPasswordResetRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasswordResetRepository) FindByID(ctx context.Context, id uint64) (*entity.PasswordResetToken, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.PasswordResetToken), args.Error(1)
+}
+
+func (m *MockPasswordResetRepository) FindByField(ctx context.Context, field string, value interface{}) (*entity.PasswordResetToken, error) {
+	args := m.Called(ctx, field, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.PasswordResetToken), args.Error(1)
+}
+
+func (m *MockPasswordResetRepository) Remove(ctx context.Context, model *entity.PasswordResetToken, softDelete bool) error {
+	args := m.Called(ctx, model, softDelete)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetRepository) Modify(ctx context.Context, model *entity.PasswordResetToken) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetRepository) Save(ctx context.Context, model *entity.PasswordResetToken) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.PasswordResetToken), args.Error(1)
+}
+
+func (m *MockPasswordResetRepository) MarkUsed(ctx context.Context, token *entity.PasswordResetToken, now time.Time) (bool, error) {
+	args := m.Called(ctx, token, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPasswordResetRepository) InvalidateByUserID(ctx context.Context, userID entity.UserID, now time.Time) error {
+	args := m.Called(ctx, userID, now)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetRepository) Seen() []adapter.Entity {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]adapter.Entity)
+}
+
+func (m *MockPasswordResetRepository) SetSeen(model adapter.Entity) {
+	m.Called(model)
+}
+
+var _ repository.PasswordResetRepository = (*MockPasswordResetRepository)(nil)
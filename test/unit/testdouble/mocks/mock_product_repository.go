@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"shikposh-backend/internal/products/adapter/repository"
 	"shikposh-backend/internal/products/domain/entity"
@@ -47,12 +48,12 @@ func (m *MockProductRepository) Save(ctx context.Context, model *productaggregat
 	return args.Error(0)
 }
 
-func (m *MockProductRepository) GetAll(ctx context.Context) ([]*productaggregate.Product, error) {
-	args := m.Called(ctx)
+func (m *MockProductRepository) GetAll(ctx context.Context, page repository.PageRequest) (*repository.Page[*productaggregate.Product], error) {
+	args := m.Called(ctx, page)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*productaggregate.Product), args.Error(1)
+	return args.Get(0).(*repository.Page[*productaggregate.Product]), args.Error(1)
 }
 
 func (m *MockProductRepository) FindBySlug(ctx context.Context, slug string) (*productaggregate.Product, error) {
@@ -71,16 +72,32 @@ func (m *MockProductRepository) FindByCategoryID(ctx context.Context, categoryID
 	return args.Get(0).([]*productaggregate.Product), args.Error(1)
 }
 
-func (m *MockProductRepository) FindByCategorySlug(ctx context.Context, categorySlug string) ([]*productaggregate.Product, error) {
-	args := m.Called(ctx, categorySlug)
+func (m *MockProductRepository) FindByCategorySlug(ctx context.Context, categorySlug string, page repository.PageRequest) (*repository.Page[*productaggregate.Product], error) {
+	args := m.Called(ctx, categorySlug, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.Page[*productaggregate.Product]), args.Error(1)
+}
+
+func (m *MockProductRepository) FindFeatured(ctx context.Context, page repository.PageRequest) (*repository.Page[*productaggregate.Product], error) {
+	args := m.Called(ctx, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.Page[*productaggregate.Product]), args.Error(1)
+}
+
+func (m *MockProductRepository) FindBatch(ctx context.Context, afterID uint64, limit int) ([]*productaggregate.Product, error) {
+	args := m.Called(ctx, afterID, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*productaggregate.Product), args.Error(1)
 }
 
-func (m *MockProductRepository) FindFeatured(ctx context.Context) ([]*productaggregate.Product, error) {
-	args := m.Called(ctx)
+func (m *MockProductRepository) FindBatchChangedSince(ctx context.Context, since time.Time, afterID uint64, limit int) ([]*productaggregate.Product, error) {
+	args := m.Called(ctx, since, afterID, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -95,12 +112,36 @@ func (m *MockProductRepository) Search(ctx context.Context, query string) ([]*pr
 	return args.Get(0).([]*productaggregate.Product), args.Error(1)
 }
 
-func (m *MockProductRepository) Filter(ctx context.Context, filters repository.ProductFilters) ([]*productaggregate.Product, error) {
-	args := m.Called(ctx, filters)
+func (m *MockProductRepository) Filter(ctx context.Context, filters repository.ProductFilters, page repository.PageRequest) (*repository.Page[*productaggregate.Product], error) {
+	args := m.Called(ctx, filters, page)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*productaggregate.Product), args.Error(1)
+	return args.Get(0).(*repository.Page[*productaggregate.Product]), args.Error(1)
+}
+
+func (m *MockProductRepository) Facets(ctx context.Context, filters repository.ProductFilters, options repository.FacetOptions) (*repository.ProductFacets, error) {
+	args := m.Called(ctx, filters, options)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.ProductFacets), args.Error(1)
+}
+
+func (m *MockProductRepository) SuggestNames(ctx context.Context, prefix string, limit int) ([]repository.ProductSuggestion, error) {
+	args := m.Called(ctx, prefix, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.ProductSuggestion), args.Error(1)
+}
+
+func (m *MockProductRepository) SuggestBrands(ctx context.Context, prefix string, limit int) ([]string, error) {
+	args := m.Called(ctx, prefix, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
 }
 
 func (m *MockProductRepository) ClearFeatures(ctx context.Context, product *productaggregate.Product) error {
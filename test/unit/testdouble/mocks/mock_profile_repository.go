@@ -0,0 +1,85 @@
+package mocks
+
+import (
+	"context"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockProfileRepository is a mock implementation of ProfileRepository
+type MockProfileRepository struct {
+	mock.Mock
+}
+
+func (m *MockProfileRepository) FindByID(ctx context.Context, id uint64) (*entity.Profile, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Profile), args.Error(1)
+}
+
+func (m *MockProfileRepository) FindByField(ctx context.Context, field string, value interface{}) (*entity.Profile, error) {
+	args := m.Called(ctx, field, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Profile), args.Error(1)
+}
+
+func (m *MockProfileRepository) Remove(ctx context.Context, model *entity.Profile, softDelete bool) error {
+	args := m.Called(ctx, model, softDelete)
+	return args.Error(0)
+}
+
+func (m *MockProfileRepository) Modify(ctx context.Context, model *entity.Profile) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockProfileRepository) Save(ctx context.Context, model *entity.Profile) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockProfileRepository) FindByUserID(ctx context.Context, userID entity.UserID) (*entity.Profile, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Profile), args.Error(1)
+}
+
+func (m *MockProfileRepository) FindByPhone(ctx context.Context, phone string) (*entity.Profile, error) {
+	args := m.Called(ctx, phone)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Profile), args.Error(1)
+}
+
+func (m *MockProfileRepository) FindByUserIDs(ctx context.Context, userIDs []entity.UserID) ([]*entity.Profile, error) {
+	args := m.Called(ctx, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Profile), args.Error(1)
+}
+
+func (m *MockProfileRepository) Seen() []adapter.Entity {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]adapter.Entity)
+}
+
+func (m *MockProfileRepository) SetSeen(model adapter.Entity) {
+	m.Called(model)
+}
+
+var _ repository.ProfileRepository = (*MockProfileRepository)(nil)
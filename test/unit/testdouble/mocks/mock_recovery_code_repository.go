@@ -0,0 +1,80 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRecoveryCodeRepository is a mock implementation of RecoveryCodeRepository
+type MockRecoveryCodeRepository struct {
+	mock.Mock
+}
+
+func (m *MockRecoveryCodeRepository) FindByID(ctx context.Context, id uint64) (*entity.RecoveryCode, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RecoveryCode), args.Error(1)
+}
+
+func (m *MockRecoveryCodeRepository) FindByField(ctx context.Context, field string, value interface{}) (*entity.RecoveryCode, error) {
+	args := m.Called(ctx, field, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RecoveryCode), args.Error(1)
+}
+
+func (m *MockRecoveryCodeRepository) Remove(ctx context.Context, model *entity.RecoveryCode, softDelete bool) error {
+	args := m.Called(ctx, model, softDelete)
+	return args.Error(0)
+}
+
+func (m *MockRecoveryCodeRepository) Modify(ctx context.Context, model *entity.RecoveryCode) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockRecoveryCodeRepository) Save(ctx context.Context, model *entity.RecoveryCode) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockRecoveryCodeRepository) FindUnusedByHash(ctx context.Context, userID entity.UserID, codeHash string) (*entity.RecoveryCode, error) {
+	args := m.Called(ctx, userID, codeHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RecoveryCode), args.Error(1)
+}
+
+func (m *MockRecoveryCodeRepository) MarkUsed(ctx context.Context, code *entity.RecoveryCode, now time.Time) (bool, error) {
+	args := m.Called(ctx, code, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRecoveryCodeRepository) DeleteByUserID(ctx context.Context, userID entity.UserID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockRecoveryCodeRepository) Seen() []adapter.Entity {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]adapter.Entity)
+}
+
+func (m *MockRecoveryCodeRepository) SetSeen(model adapter.Entity) {
+	m.Called(model)
+}
+
+var _ repository.RecoveryCodeRepository = (*MockRecoveryCodeRepository)(nil)
@@ -0,0 +1,85 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRefreshTokenRepository is a mock implementation of RefreshTokenRepository
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefreshTokenRepository) FindByID(ctx context.Context, id uint64) (*entity.RefreshToken, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) FindByField(ctx context.Context, field string, value interface{}) (*entity.RefreshToken, error) {
+	args := m.Called(ctx, field, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) Remove(ctx context.Context, model *entity.RefreshToken, softDelete bool) error {
+	args := m.Called(ctx, model, softDelete)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) Modify(ctx context.Context, model *entity.RefreshToken) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) Save(ctx context.Context, model *entity.RefreshToken) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) MarkUsed(ctx context.Context, token *entity.RefreshToken, now time.Time) (bool, error) {
+	args := m.Called(ctx, token, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) RevokeBySessionID(ctx context.Context, sessionID entity.SessionID) error {
+	args := m.Called(ctx, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeByUserID(ctx context.Context, userID entity.UserID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) Seen() []adapter.Entity {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]adapter.Entity)
+}
+
+func (m *MockRefreshTokenRepository) SetSeen(model adapter.Entity) {
+	m.Called(model)
+}
+
+var _ repository.RefreshTokenRepository = (*MockRefreshTokenRepository)(nil)
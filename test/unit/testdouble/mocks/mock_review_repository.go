@@ -0,0 +1,89 @@
+package mocks
+
+import (
+	"context"
+
+	accountentity "shikposh-backend/internal/account/domain/entity"
+	"shikposh-backend/internal/products/adapter/repository"
+	"shikposh-backend/internal/products/domain/entity"
+	productaggregate "shikposh-backend/internal/products/domain/entity/product_aggregate"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReviewRepository is a mock implementation of ReviewRepository
+type MockReviewRepository struct {
+	mock.Mock
+}
+
+func (m *MockReviewRepository) FindByID(ctx context.Context, id uint64) (*entity.Review, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) FindByField(ctx context.Context, field string, value interface{}) (*entity.Review, error) {
+	args := m.Called(ctx, field, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) Remove(ctx context.Context, model *entity.Review, softDelete bool) error {
+	args := m.Called(ctx, model, softDelete)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) Modify(ctx context.Context, model *entity.Review) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) Save(ctx context.Context, model *entity.Review) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) FindByProductID(ctx context.Context, productID productaggregate.ProductID, page repository.PageRequest) (*repository.Page[*entity.Review], error) {
+	args := m.Called(ctx, productID, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.Page[*entity.Review]), args.Error(1)
+}
+
+func (m *MockReviewRepository) FindByUserID(ctx context.Context, userID accountentity.UserID) ([]*entity.Review, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) AnonymizeByUserID(ctx context.Context, userID accountentity.UserID, userName string) error {
+	args := m.Called(ctx, userID, userName)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) UpdateUserAvatar(ctx context.Context, userID accountentity.UserID, avatar string) error {
+	args := m.Called(ctx, userID, avatar)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) Seen() []adapter.Entity {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]adapter.Entity)
+}
+
+func (m *MockReviewRepository) SetSeen(model adapter.Entity) {
+	m.Called(model)
+}
+
+var _ repository.ReviewRepository = (*MockReviewRepository)(nil)
@@ -0,0 +1,77 @@
+package mocks
+
+import (
+	"context"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRoleRepository is a mock implementation of RoleRepository
+type MockRoleRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoleRepository) FindByID(ctx context.Context, id uint64) (*entity.Role, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) FindByField(ctx context.Context, field string, value interface{}) (*entity.Role, error) {
+	args := m.Called(ctx, field, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) Remove(ctx context.Context, model *entity.Role, softDelete bool) error {
+	args := m.Called(ctx, model, softDelete)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) Modify(ctx context.Context, model *entity.Role) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) Save(ctx context.Context, model *entity.Role) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) FindByName(ctx context.Context, name string) (*entity.Role, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) FindPermissionsByNames(ctx context.Context, names []string) ([]*entity.Permission, error) {
+	args := m.Called(ctx, names)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Permission), args.Error(1)
+}
+
+func (m *MockRoleRepository) Seen() []adapter.Entity {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]adapter.Entity)
+}
+
+func (m *MockRoleRepository) SetSeen(model adapter.Entity) {
+	m.Called(model)
+}
+
+var _ repository.RoleRepository = (*MockRoleRepository)(nil)
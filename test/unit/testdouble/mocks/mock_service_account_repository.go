@@ -0,0 +1,77 @@
+package mocks
+
+import (
+	"context"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockServiceAccountRepository is a mock implementation of ServiceAccountRepository
+type MockServiceAccountRepository struct {
+	mock.Mock
+}
+
+func (m *MockServiceAccountRepository) FindByID(ctx context.Context, id uint64) (*entity.ServiceAccount, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ServiceAccount), args.Error(1)
+}
+
+func (m *MockServiceAccountRepository) FindByField(ctx context.Context, field string, value interface{}) (*entity.ServiceAccount, error) {
+	args := m.Called(ctx, field, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ServiceAccount), args.Error(1)
+}
+
+func (m *MockServiceAccountRepository) Remove(ctx context.Context, model *entity.ServiceAccount, softDelete bool) error {
+	args := m.Called(ctx, model, softDelete)
+	return args.Error(0)
+}
+
+func (m *MockServiceAccountRepository) Modify(ctx context.Context, model *entity.ServiceAccount) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockServiceAccountRepository) Save(ctx context.Context, model *entity.ServiceAccount) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockServiceAccountRepository) FindByName(ctx context.Context, name string) (*entity.ServiceAccount, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ServiceAccount), args.Error(1)
+}
+
+func (m *MockServiceAccountRepository) FindAll(ctx context.Context) ([]*entity.ServiceAccount, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.ServiceAccount), args.Error(1)
+}
+
+func (m *MockServiceAccountRepository) Seen() []adapter.Entity {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]adapter.Entity)
+}
+
+func (m *MockServiceAccountRepository) SetSeen(model adapter.Entity) {
+	m.Called(model)
+}
+
+var _ repository.ServiceAccountRepository = (*MockServiceAccountRepository)(nil)
@@ -0,0 +1,83 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSessionRepository is a mock implementation of SessionRepository
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) FindByID(ctx context.Context, id uint64) (*entity.Session, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) FindByField(ctx context.Context, field string, value interface{}) (*entity.Session, error) {
+	args := m.Called(ctx, field, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) Remove(ctx context.Context, model *entity.Session, softDelete bool) error {
+	args := m.Called(ctx, model, softDelete)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) Modify(ctx context.Context, model *entity.Session) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) Save(ctx context.Context, model *entity.Session) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) FindBySessionID(ctx context.Context, id entity.SessionID) (*entity.Session, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) RevokeByUserID(ctx context.Context, userID entity.UserID, now time.Time) error {
+	args := m.Called(ctx, userID, now)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) FindActiveByUserID(ctx context.Context, userID entity.UserID, now time.Time) ([]*entity.Session, error) {
+	args := m.Called(ctx, userID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) Seen() []adapter.Entity {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]adapter.Entity)
+}
+
+func (m *MockSessionRepository) SetSeen(model adapter.Entity) {
+	m.Called(model)
+}
+
+var _ repository.SessionRepository = (*MockSessionRepository)(nil)
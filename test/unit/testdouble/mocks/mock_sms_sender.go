@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"context"
+
+	"shikposh-backend/internal/account/adapter"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSmsSender is a mock implementation of SmsSender
+type MockSmsSender struct {
+	mock.Mock
+}
+
+func (m *MockSmsSender) Send(ctx context.Context, phone, message string) error {
+	args := m.Called(ctx, phone, message)
+	return args.Error(0)
+}
+
+var _ adapter.SmsSender = (*MockSmsSender)(nil)
@@ -0,0 +1,75 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"shikposh-backend/internal/account/adapter/repository"
+	"shikposh-backend/internal/account/domain/entity"
+	"github.com/ali-mahdavi-dev/framework/adapter"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTwoFactorChallengeRepository is a mock implementation of TwoFactorChallengeRepository
+type MockTwoFactorChallengeRepository struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorChallengeRepository) FindByID(ctx context.Context, id uint64) (*entity.TwoFactorChallenge, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.TwoFactorChallenge), args.Error(1)
+}
+
+func (m *MockTwoFactorChallengeRepository) FindByField(ctx context.Context, field string, value interface{}) (*entity.TwoFactorChallenge, error) {
+	args := m.Called(ctx, field, value)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.TwoFactorChallenge), args.Error(1)
+}
+
+func (m *MockTwoFactorChallengeRepository) Remove(ctx context.Context, model *entity.TwoFactorChallenge, softDelete bool) error {
+	args := m.Called(ctx, model, softDelete)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorChallengeRepository) Modify(ctx context.Context, model *entity.TwoFactorChallenge) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorChallengeRepository) Save(ctx context.Context, model *entity.TwoFactorChallenge) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorChallengeRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*entity.TwoFactorChallenge, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.TwoFactorChallenge), args.Error(1)
+}
+
+func (m *MockTwoFactorChallengeRepository) MarkUsed(ctx context.Context, challenge *entity.TwoFactorChallenge, now time.Time) (bool, error) {
+	args := m.Called(ctx, challenge, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTwoFactorChallengeRepository) Seen() []adapter.Entity {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]adapter.Entity)
+}
+
+func (m *MockTwoFactorChallengeRepository) SetSeen(model adapter.Entity) {
+	m.Called(model)
+}
+
+var _ repository.TwoFactorChallengeRepository = (*MockTwoFactorChallengeRepository)(nil)
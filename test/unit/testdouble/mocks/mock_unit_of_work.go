@@ -48,9 +48,39 @@ func (m *MockPGUnitOfWork) User(ctx context.Context) repository.UserRepository {
 	return args.Get(0).(repository.UserRepository)
 }
 
-func (m *MockPGUnitOfWork) Token(ctx context.Context) repository.TokenRepository {
+func (m *MockPGUnitOfWork) Session(ctx context.Context) repository.SessionRepository {
 	args := m.Called(ctx)
-	return args.Get(0).(repository.TokenRepository)
+	return args.Get(0).(repository.SessionRepository)
+}
+
+func (m *MockPGUnitOfWork) RefreshToken(ctx context.Context) repository.RefreshTokenRepository {
+	args := m.Called(ctx)
+	return args.Get(0).(repository.RefreshTokenRepository)
+}
+
+func (m *MockPGUnitOfWork) EmailVerification(ctx context.Context) repository.EmailVerificationRepository {
+	args := m.Called(ctx)
+	return args.Get(0).(repository.EmailVerificationRepository)
+}
+
+func (m *MockPGUnitOfWork) PasswordReset(ctx context.Context) repository.PasswordResetRepository {
+	args := m.Called(ctx)
+	return args.Get(0).(repository.PasswordResetRepository)
+}
+
+func (m *MockPGUnitOfWork) TwoFactorChallenge(ctx context.Context) repository.TwoFactorChallengeRepository {
+	args := m.Called(ctx)
+	return args.Get(0).(repository.TwoFactorChallengeRepository)
+}
+
+func (m *MockPGUnitOfWork) RecoveryCode(ctx context.Context) repository.RecoveryCodeRepository {
+	args := m.Called(ctx)
+	return args.Get(0).(repository.RecoveryCodeRepository)
+}
+
+func (m *MockPGUnitOfWork) AccountUnlock(ctx context.Context) repository.AccountUnlockRepository {
+	args := m.Called(ctx)
+	return args.Get(0).(repository.AccountUnlockRepository)
 }
 
 func (m *MockPGUnitOfWork) Profile(ctx context.Context) repository.ProfileRepository {
@@ -58,6 +88,41 @@ func (m *MockPGUnitOfWork) Profile(ctx context.Context) repository.ProfileReposi
 	return args.Get(0).(repository.ProfileRepository)
 }
 
+func (m *MockPGUnitOfWork) Address(ctx context.Context) repository.AddressRepository {
+	args := m.Called(ctx)
+	return args.Get(0).(repository.AddressRepository)
+}
+
+func (m *MockPGUnitOfWork) ServiceAccount(ctx context.Context) repository.ServiceAccountRepository {
+	args := m.Called(ctx)
+	return args.Get(0).(repository.ServiceAccountRepository)
+}
+
+func (m *MockPGUnitOfWork) ApiKey(ctx context.Context) repository.ApiKeyRepository {
+	args := m.Called(ctx)
+	return args.Get(0).(repository.ApiKeyRepository)
+}
+
+func (m *MockPGUnitOfWork) ExternalIdentity(ctx context.Context) repository.ExternalIdentityRepository {
+	args := m.Called(ctx)
+	return args.Get(0).(repository.ExternalIdentityRepository)
+}
+
+func (m *MockPGUnitOfWork) OidcAuthorization(ctx context.Context) repository.OidcAuthorizationRepository {
+	args := m.Called(ctx)
+	return args.Get(0).(repository.OidcAuthorizationRepository)
+}
+
+func (m *MockPGUnitOfWork) AuditEvent(ctx context.Context) repository.AuditEventRepository {
+	args := m.Called(ctx)
+	return args.Get(0).(repository.AuditEventRepository)
+}
+
+func (m *MockPGUnitOfWork) Role(ctx context.Context) repository.RoleRepository {
+	args := m.Called(ctx)
+	return args.Get(0).(repository.RoleRepository)
+}
+
 func (m *MockPGUnitOfWork) Product(ctx context.Context) productrepository.ProductRepository {
 	args := m.Called(ctx)
 	return args.Get(0).(productrepository.ProductRepository)
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"shikposh-backend/internal/account/adapter/repository"
 	"shikposh-backend/internal/account/domain/entity"
@@ -54,6 +55,14 @@ func (m *MockUserRepository) FindByUserName(ctx context.Context, username string
 	return args.Get(0).(*entity.User), args.Error(1)
 }
 
+func (m *MockUserRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
 func (m *MockUserRepository) FindByUsernameExcludingID(ctx context.Context, username string, id uint) (*entity.User, error) {
 	args := m.Called(ctx, username, id)
 	if args.Get(0) == nil {
@@ -62,6 +71,50 @@ func (m *MockUserRepository) FindByUsernameExcludingID(ctx context.Context, user
 	return args.Get(0).(*entity.User), args.Error(1)
 }
 
+func (m *MockUserRepository) FindPermissions(ctx context.Context, userID entity.UserID) ([]string, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockUserRepository) HasRole(ctx context.Context, userID entity.UserID, roleName string) (bool, error) {
+	args := m.Called(ctx, userID, roleName)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) AssignRole(ctx context.Context, user *entity.User, role *entity.Role) error {
+	args := m.Called(ctx, user, role)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) PurgeDeleted(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepository) FindByIDWithRoles(ctx context.Context, id entity.UserID) (*entity.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Search(ctx context.Context, search repository.UserSearch) ([]*entity.User, int64, error) {
+	args := m.Called(ctx, search)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*entity.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockUserRepository) ReplaceRoles(ctx context.Context, user *entity.User, roles []*entity.Role) error {
+	args := m.Called(ctx, user, roles)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) Seen() []adapter.Entity {
 	args := m.Called()
 	if args.Get(0) == nil {